@@ -0,0 +1,45 @@
+package objsto
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+const modulePath = "github.com/clarktrimble/objsto"
+
+// Version returns the library version as found in build info, "devel" when not known.
+func Version() string {
+
+	return version()
+}
+
+// unexported
+
+var version = sync.OnceValue(func() string {
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+
+	if info.Main.Path == modulePath && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			return dep.Version
+		}
+	}
+
+	return "devel"
+})
+
+func userAgent(suffix string) string {
+
+	agent := "objsto/" + Version()
+	if suffix != "" {
+		agent += " (" + suffix + ")"
+	}
+
+	return agent
+}
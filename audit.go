@@ -0,0 +1,230 @@
+package objsto
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AuditRecord is an entry in an access trail, one per operation.
+type AuditRecord struct {
+	Time      time.Time `json:"time"`
+	Principal string    `json:"principal,omitempty"`
+	Operation string    `json:"operation"`
+	Bucket    string    `json:"bucket,omitempty"`
+	Key       string    `json:"key"`
+	Result    string    `json:"result"`
+	Error     string    `json:"error,omitempty"`
+	Bytes     int64     `json:"bytes"`
+}
+
+// Audit results.
+const (
+	AuditOK       = "ok"
+	AuditNotFound = "not_found"
+	AuditError    = "error"
+)
+
+// AuditFunc receives audit records, called synchronously so that it may apply backpressure.
+type AuditFunc func(ctx context.Context, rec AuditRecord)
+
+// AuditChannel returns an AuditFunc sending records to ch, waiting for a receiver however long it takes,
+// even once ctx is done, so that none are lost.
+func AuditChannel(ch chan<- AuditRecord) AuditFunc {
+
+	return func(ctx context.Context, rec AuditRecord) {
+
+		ch <- rec
+	}
+}
+
+// LossyAuditChannel returns an AuditFunc sending records to ch, waiting for a receiver unless ctx is done,
+// in which case the record is dropped and counted in dropped.
+func LossyAuditChannel(ch chan<- AuditRecord, dropped *atomic.Int64) AuditFunc {
+
+	return func(ctx context.Context, rec AuditRecord) {
+
+		select {
+		case ch <- rec:
+		case <-ctx.Done():
+			dropped.Add(1)
+		}
+	}
+}
+
+// WithPrincipal returns a context attributing operations made with it to principal in audit records.
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// AuditStore is an ObjectStore recording each operation to an AuditFunc, independent of provider logs.
+//
+// Records for Get are made once the reader is closed, counting bytes read.
+//
+// Only the ObjectStore methods are offered, refusing the optional interfaces of the store,
+// such as RangeGetter, BatchDeleter, StreamPutter, and Tagger, so that nothing done through it
+// goes unrecorded. Consumers checking for them fall back to recorded methods.
+// Operations made on the store directly, as with Client's Copy, bypass the trail.
+type AuditStore struct {
+	ObjectStore
+	audit  AuditFunc
+	bucket string
+	clock  Clock
+}
+
+// AuditOption sets an optional AuditStore setting.
+type AuditOption func(*AuditStore)
+
+// WithAuditBucket sets the bucket recorded, defaulting to that of a store with a Bucket method, such as Client.
+func WithAuditBucket(bucket string) AuditOption {
+
+	return func(as *AuditStore) {
+		as.bucket = bucket
+	}
+}
+
+// WithAuditClock sets the clock records are timed by, as for testing.
+func WithAuditClock(clock Clock) AuditOption {
+
+	return func(as *AuditStore) {
+		as.clock = clock
+	}
+}
+
+// NewAuditStore creates an AuditStore wrapping store, recording to audit.
+func NewAuditStore(store ObjectStore, audit AuditFunc, opts ...AuditOption) *AuditStore {
+
+	as := &AuditStore{
+		ObjectStore: store,
+		audit:       audit,
+		clock:       systemClock{},
+	}
+	if bucketer, ok := store.(interface{ Bucket() string }); ok {
+		as.bucket = bucketer.Bucket()
+	}
+	for _, opt := range opts {
+		opt(as)
+	}
+
+	return as
+}
+
+// Get gets an object.
+func (as *AuditStore) Get(ctx context.Context, object string) (reader io.ReadCloser, err error) {
+
+	reader, err = as.ObjectStore.Get(ctx, object)
+	if err != nil {
+		as.record(ctx, "get", object, 0, err)
+		return
+	}
+
+	reader = &auditReader{ReadCloser: reader, ctx: ctx, store: as, object: object}
+	return
+}
+
+// Put puts an object.
+func (as *AuditStore) Put(ctx context.Context, object string, reader io.ReadSeeker, opts ...PutOption) (err error) {
+
+	size, err := remaining(reader)
+	if err == nil {
+		err = as.ObjectStore.Put(ctx, object, reader, opts...)
+	}
+
+	as.record(ctx, "put", object, size, err)
+	return
+}
+
+// Delete deletes an object.
+func (as *AuditStore) Delete(ctx context.Context, object string) (err error) {
+
+	err = as.ObjectStore.Delete(ctx, object)
+
+	as.record(ctx, "delete", object, 0, err)
+	return
+}
+
+// List returns object keys matching prefix, recorded with the prefix as key.
+func (as *AuditStore) List(ctx context.Context, prefix string) (keys []string, err error) {
+
+	keys, err = as.ObjectStore.List(ctx, prefix)
+
+	as.record(ctx, "list", prefix, 0, err)
+	return
+}
+
+// Stat gets an object's info.
+func (as *AuditStore) Stat(ctx context.Context, object string) (info ObjectInfo, err error) {
+
+	info, err = as.ObjectStore.Stat(ctx, object)
+
+	as.record(ctx, "stat", object, 0, err)
+	return
+}
+
+// unexported
+
+type principalKey struct{}
+
+func (as *AuditStore) record(ctx context.Context, operation, key string, size int64, err error) {
+
+	principal, _ := ctx.Value(principalKey{}).(string)
+
+	rec := AuditRecord{
+		Time:      as.clock.Now(),
+		Principal: principal,
+		Operation: operation,
+		Bucket:    as.bucket,
+		Key:       key,
+		Result:    AuditOK,
+		Bytes:     size,
+	}
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		rec.Result = AuditNotFound
+	case err != nil:
+		rec.Result = AuditError
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	as.audit(ctx, rec)
+}
+
+// auditReader records a get when closed.
+type auditReader struct {
+	io.ReadCloser
+	ctx    context.Context
+	store  *AuditStore
+	object string
+	size   int64
+	err    error
+	closed bool
+}
+
+func (ar *auditReader) Read(buf []byte) (n int, err error) {
+
+	n, err = ar.ReadCloser.Read(buf)
+	ar.size += int64(n)
+	if err != nil && err != io.EOF {
+		ar.err = err
+	}
+	return
+}
+
+func (ar *auditReader) Close() (err error) {
+
+	err = ar.ReadCloser.Close()
+	if ar.closed {
+		return
+	}
+	ar.closed = true
+
+	ar.store.record(ar.ctx, "get", ar.object, ar.size, ar.err)
+	return
+}
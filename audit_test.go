@@ -0,0 +1,94 @@
+package objsto_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+var _ = Describe("AuditStore", func() {
+	var (
+		ctx     = context.Background()
+		now     = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+		records chan objsto.AuditRecord
+		store   *objsto.AuditStore
+	)
+
+	BeforeEach(func() {
+		records = make(chan objsto.AuditRecord, 10)
+		store = objsto.NewAuditStore(memstore.New(), objsto.AuditChannel(records),
+			objsto.WithAuditBucket("ledger"), objsto.WithAuditClock(&stepClock{now: now}))
+	})
+
+	It("records each operation", func() {
+		ctx := objsto.WithPrincipal(ctx, "alice")
+
+		Expect(store.Put(ctx, "a.txt", strings.NewReader("abc"))).To(Succeed())
+
+		reader, err := store.Get(ctx, "a.txt")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(io.ReadAll(reader)).To(Equal([]byte("abc")))
+		Expect(reader.Close()).To(Succeed())
+
+		_, err = store.Stat(ctx, "b.txt")
+		Expect(err).To(MatchError(objsto.ErrNotFound))
+
+		Expect(<-records).To(Equal(objsto.AuditRecord{
+			Time: now, Principal: "alice", Operation: "put", Bucket: "ledger", Key: "a.txt", Result: objsto.AuditOK, Bytes: 3,
+		}))
+		Expect(<-records).To(And(HaveField("Operation", "get"), HaveField("Bytes", int64(3))))
+		Expect(<-records).To(And(HaveField("Operation", "stat"), HaveField("Result", objsto.AuditNotFound)))
+		Expect(records).To(BeEmpty())
+	})
+
+	It("refuses optional interfaces, which would go unrecorded", func() {
+		client := objsto.New(&objsto.Config{Host: "test-host", Bucket: "ledger"})
+		var audited any = objsto.NewAuditStore(client, objsto.AuditChannel(records))
+
+		_, ok := audited.(objsto.RangeGetter)
+		Expect(ok).To(BeFalse())
+		_, ok = audited.(objsto.ReaderPutter)
+		Expect(ok).To(BeFalse())
+		_, ok = audited.(objsto.StreamPutter)
+		Expect(ok).To(BeFalse())
+		_, ok = audited.(objsto.BatchDeleter)
+		Expect(ok).To(BeFalse())
+		_, ok = audited.(objsto.Tagger)
+		Expect(ok).To(BeFalse())
+		_, ok = audited.(objsto.ObjectLister)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("waits for a receiver even once ctx is done", func() {
+		unbuffered := make(chan objsto.AuditRecord)
+		store = objsto.NewAuditStore(memstore.New(), objsto.AuditChannel(unbuffered))
+
+		done, cancel := context.WithCancel(ctx)
+		cancel()
+
+		go func() {
+			defer GinkgoRecover()
+			store.Delete(done, "a.txt")
+		}()
+		Eventually(unbuffered).Should(Receive(HaveField("Operation", "delete")))
+	})
+
+	It("drops records once ctx is done when lossy, counting them", func() {
+		var dropped atomic.Int64
+		store = objsto.NewAuditStore(memstore.New(), objsto.LossyAuditChannel(make(chan objsto.AuditRecord), &dropped))
+
+		done, cancel := context.WithCancel(ctx)
+		cancel()
+
+		Expect(store.Delete(done, "a.txt")).To(Succeed())
+		Expect(dropped.Load()).To(Equal(int64(1)))
+	})
+})
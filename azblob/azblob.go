@@ -0,0 +1,461 @@
+// Package azblob implements objsto.ObjectStore with the Azure Blob REST API,
+// authorized via shared key, so that application code is portable across clouds.
+package azblob
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clarktrimble/launch"
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+)
+
+const (
+	apiVersion = "2021-08-06"
+	metaPrefix = "X-Ms-Meta-"
+)
+
+// Config is Client configurables tagged for use with envconfig.
+//
+// Host defaults to <account>.blob.core.windows.net.
+// When set, as for Azurite, requests are path-style with the account leading the path.
+type Config struct {
+	Account   string        `json:"account" desc:"storage account name" required:"true"`
+	Key       launch.Redact `json:"key" desc:"base64 account key or path to file" required:"true"`
+	Container string        `json:"container" desc:"container name" required:"true"`
+	Scheme    string        `json:"scheme" desc:"http or https" default:"https"`
+	Host      string        `json:"host" desc:"endpoint host, blank for azure public cloud"`
+}
+
+// Client is an Azure Blob client.
+type Client struct {
+	account   string
+	key       []byte
+	container string
+	scheme    string
+	host      string
+	base      string
+	client    objsto.HttpDoer
+	logger    objsto.Logger
+}
+
+var _ objsto.ObjectStore = &Client{}
+
+// New creates Client from Config, nil client and logger for defaults.
+func (cfg *Config) New(client objsto.HttpDoer, lgr objsto.Logger) (azc *Client, err error) {
+
+	key, err := base64.StdEncoding.DecodeString(string(cfg.Key))
+	if err != nil {
+		err = errors.Wrap(err, "failed to decode account key")
+		return
+	}
+
+	host := cfg.Host
+	base := ""
+	if host == "" {
+		host = fmt.Sprintf("%s.blob.core.windows.net", cfg.Account)
+	} else {
+		base = "/" + cfg.Account
+	}
+
+	scheme := cfg.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+
+	if client == nil {
+		client = objsto.NewHTTPClient(nil)
+	}
+	if lgr == nil {
+		lgr = noopLogger{}
+	}
+
+	azc = &Client{
+		account:   cfg.Account,
+		key:       key,
+		container: cfg.Container,
+		scheme:    scheme,
+		host:      host,
+		base:      base,
+		client:    client,
+		logger:    lgr,
+	}
+	return
+}
+
+// Get gets a blob.
+func (azc *Client) Get(ctx context.Context, object string) (reader io.ReadCloser, err error) {
+
+	azc.logger.Info(ctx, "getting from azure", "object", object)
+
+	resp, err := azc.do(ctx, "GET", object, nil, nil, 0, nil)
+	if err != nil {
+		return
+	}
+
+	reader = resp.Body
+	return
+}
+
+// Put puts a block blob.
+// Storage class maps to access tier, tags are not supported.
+func (azc *Client) Put(ctx context.Context, object string, reader io.ReadSeeker, opts ...objsto.PutOption) (err error) {
+
+	azc.logger.Info(ctx, "putting to azure", "object", object)
+
+	size, err := reader.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = reader.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "failed to seek %q", object)
+		return
+	}
+
+	po := objsto.NewPutOptions(opts...)
+
+	hdr := http.Header{}
+	hdr.Set("X-Ms-Blob-Type", "BlockBlob")
+	if po.ContentType != "" {
+		hdr.Set("Content-Type", po.ContentType)
+	}
+	if po.ContentEncoding != "" {
+		hdr.Set("X-Ms-Blob-Content-Encoding", po.ContentEncoding)
+	}
+	if po.StorageClass != "" {
+		hdr.Set("X-Ms-Access-Tier", po.StorageClass)
+	}
+	for key, val := range po.Metadata {
+		hdr.Set(metaPrefix+key, val)
+	}
+	if po.IfMatch != "" {
+		hdr.Set("If-Match", quote(po.IfMatch))
+	}
+	if po.IfNoneMatch != "" {
+		hdr.Set("If-None-Match", quote(po.IfNoneMatch))
+	}
+
+	var body io.Reader = reader
+	if size == 0 {
+		body = http.NoBody
+	}
+
+	resp, err := azc.do(ctx, "PUT", object, nil, hdr, size, body)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+
+	po.Record(objsto.PutResult{
+		Key:       object,
+		ETag:      strings.Trim(resp.Header.Get("ETag"), `"`),
+		VersionID: resp.Header.Get("X-Ms-Version-Id"),
+	})
+
+	return
+}
+
+// Delete deletes a blob.
+// As with S3, deleting a blob that does not exist is not an error.
+func (azc *Client) Delete(ctx context.Context, object string) (err error) {
+
+	azc.logger.Info(ctx, "deleting from azure", "object", object)
+
+	resp, err := azc.do(ctx, "DELETE", object, nil, nil, 0, nil)
+	if errors.Is(err, objsto.ErrNotFound) {
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+
+	return
+}
+
+// List returns blob names matching the given prefix, following markers for more than a page.
+func (azc *Client) List(ctx context.Context, prefix string) (keys []string, err error) {
+
+	azc.logger.Info(ctx, "listing from azure", "prefix", prefix)
+
+	marker := ""
+	for {
+		query := url.Values{}
+		query.Set("restype", "container")
+		query.Set("comp", "list")
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		var result enumerationResults
+		result, err = azc.list(ctx, query)
+		if err != nil {
+			return
+		}
+
+		for _, blob := range result.Blobs {
+			keys = append(keys, blob.Name)
+		}
+
+		marker = result.NextMarker
+		if marker == "" {
+			return
+		}
+	}
+}
+
+// Stat gets a blob's properties.
+func (azc *Client) Stat(ctx context.Context, object string) (info objsto.ObjectInfo, err error) {
+
+	azc.logger.Info(ctx, "statting in azure", "object", object)
+
+	resp, err := azc.do(ctx, "HEAD", object, nil, nil, 0, nil)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+
+	info = objsto.ObjectInfo{
+		Key:             object,
+		Size:            resp.ContentLength,
+		ETag:            strings.Trim(resp.Header.Get("ETag"), `"`),
+		ContentType:     resp.Header.Get("Content-Type"),
+		ContentEncoding: resp.Header.Get("Content-Encoding"),
+	}
+
+	modified, err := http.ParseTime(resp.Header.Get("Last-Modified"))
+	if err == nil {
+		info.LastModified = modified
+	}
+	err = nil
+
+	for name, vals := range resp.Header {
+		if !strings.HasPrefix(name, metaPrefix) || len(vals) == 0 {
+			continue
+		}
+		if info.Metadata == nil {
+			info.Metadata = map[string]string{}
+		}
+		info.Metadata[strings.ToLower(name[len(metaPrefix):])] = vals[0]
+	}
+
+	return
+}
+
+// unexported
+
+type noopLogger struct{}
+
+func (nl noopLogger) Info(ctx context.Context, msg string, kv ...any)             {}
+func (nl noopLogger) Debug(ctx context.Context, msg string, kv ...any)            {}
+func (nl noopLogger) Trace(ctx context.Context, msg string, kv ...any)            {}
+func (nl noopLogger) Error(ctx context.Context, msg string, err error, kv ...any) {}
+
+type enumerationResults struct {
+	Blobs []struct {
+		Name string `xml:"Name"`
+	} `xml:"Blobs>Blob"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+type azError struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func (azc *Client) list(ctx context.Context, query url.Values) (result enumerationResults, err error) {
+
+	resp, err := azc.do(ctx, "GET", "", query, nil, 0, nil)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	err = xml.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		err = errors.Wrap(err, "failed to parse list response")
+	}
+
+	return
+}
+
+// do builds, signs, and sends a request for object, or the container when blank.
+func (azc *Client) do(ctx context.Context, method, object string, query url.Values, hdr http.Header, size int64, body io.Reader) (resp *http.Response, err error) {
+
+	if object == "" && query == nil {
+		err = errors.Errorf("object cannot be blank")
+		return
+	}
+
+	path := fmt.Sprintf("%s/%s", azc.base, azc.container)
+	if object != "" {
+		path = fmt.Sprintf("%s/%s", path, object)
+	}
+
+	uri := &url.URL{
+		Scheme:   azc.scheme,
+		Host:     azc.host,
+		Path:     path,
+		RawQuery: query.Encode(),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, uri.String(), body)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to create request to %q", uri)
+		return
+	}
+	maps.Copy(req.Header, hdr)
+	req.ContentLength = size
+
+	req.Header.Set("X-Ms-Date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("X-Ms-Version", apiVersion)
+	req.Header.Set("Authorization", azc.authorization(req))
+
+	azc.logger.Debug(ctx, "signed request", "url", req.URL.String())
+
+	start := time.Now()
+	resp, err = azc.client.Do(req)
+	if err != nil {
+		err = errors.Wrapf(err, "failed request to %q", req.URL)
+		return
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		err = parseError(resp)
+		return
+	}
+
+	azc.logger.Info(ctx, "azure response", "status", resp.StatusCode, "elapsed", time.Since(start))
+	return
+}
+
+// authorization computes the shared key authorization header.
+func (azc *Client) authorization(req *http.Request) string {
+
+	length := ""
+	if req.ContentLength > 0 {
+		length = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	hdr := req.Header
+	parts := []string{
+		req.Method,
+		hdr.Get("Content-Encoding"),
+		hdr.Get("Content-Language"),
+		length,
+		hdr.Get("Content-MD5"),
+		hdr.Get("Content-Type"),
+		"", // date, superseded by x-ms-date
+		hdr.Get("If-Modified-Since"),
+		hdr.Get("If-Match"),
+		hdr.Get("If-None-Match"),
+		hdr.Get("If-Unmodified-Since"),
+		hdr.Get("Range"),
+	}
+
+	toSign := strings.Join(parts, "\n") + "\n" + canonicalHeaders(hdr) + azc.canonicalResource(req.URL)
+
+	mac := hmac.New(sha256.New, azc.key)
+	mac.Write([]byte(toSign))
+
+	return fmt.Sprintf("SharedKey %s:%s", azc.account, base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+}
+
+func canonicalHeaders(hdr http.Header) string {
+
+	var names []string
+	for key := range hdr {
+		name := strings.ToLower(key)
+		if strings.HasPrefix(name, "x-ms-") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var builder strings.Builder
+	for _, name := range names {
+		builder.WriteString(name)
+		builder.WriteByte(':')
+		builder.WriteString(strings.TrimSpace(hdr.Get(name)))
+		builder.WriteByte('\n')
+	}
+
+	return builder.String()
+}
+
+func (azc *Client) canonicalResource(uri *url.URL) string {
+
+	var builder strings.Builder
+	builder.WriteByte('/')
+	builder.WriteString(azc.account)
+	builder.WriteString(uri.EscapedPath())
+
+	query := uri.Query()
+	names := slices.Sorted(maps.Keys(query))
+	for _, name := range names {
+		vals := query[name]
+		slices.Sort(vals)
+
+		builder.WriteByte('\n')
+		builder.WriteString(strings.ToLower(name))
+		builder.WriteByte(':')
+		builder.WriteString(strings.Join(vals, ","))
+	}
+
+	return builder.String()
+}
+
+// quote quotes an etag as returned by azure, leaving "*" be.
+func quote(etag string) string {
+
+	if etag == "*" {
+		return etag
+	}
+	return `"` + strings.Trim(etag, `"`) + `"`
+}
+
+func parseError(resp *http.Response) error {
+
+	cause := objsto.ErrRequestFailed
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		cause = objsto.ErrNotFound
+	case http.StatusPreconditionFailed:
+		cause = objsto.ErrPreconditionFailed
+	}
+
+	bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024*4))
+
+	var azErr azError
+	err := xml.Unmarshal(bodyBytes, &azErr)
+	if err != nil {
+		return errors.Wrapf(cause, "http error, status: %d, body: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	// returned for If-None-Match: * rather than 412
+	if azErr.Code == "BlobAlreadyExists" {
+		cause = objsto.ErrPreconditionFailed
+	}
+
+	return errors.Wrapf(cause, "azure error, code: %s, request_id: %s, message: %s",
+		azErr.Code, resp.Header.Get("X-Ms-Request-Id"), azErr.Message)
+}
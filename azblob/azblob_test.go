@@ -0,0 +1,178 @@
+package azblob_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/azblob"
+)
+
+func TestAzBlob(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AzBlob Suite")
+}
+
+var _ = Describe("Client", func() {
+	var (
+		ctx      = context.Background()
+		key      = []byte("test-account-key")
+		srv      *httptest.Server
+		client   *azblob.Client
+		requests []*http.Request
+		bodies   []string
+		handler  http.HandlerFunc
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		bodies = nil
+		handler = func(w http.ResponseWriter, r *http.Request) {}
+
+		srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			requests = append(requests, r)
+			bodies = append(bodies, string(body))
+			handler(w, r)
+		}))
+		DeferCleanup(srv.Close)
+
+		cfg := &azblob.Config{
+			Account:   "devstoreaccount1",
+			Key:       "dGVzdC1hY2NvdW50LWtleQ==",
+			Container: "test-container",
+			Scheme:    "http",
+			Host:      srv.Listener.Addr().String(),
+		}
+
+		var err error
+		client, err = cfg.New(nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	sign := func(toSign string) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(toSign))
+		return "SharedKey devstoreaccount1:" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	Describe("Put", func() {
+		It("sends a signed block blob", func() {
+			err := client.Put(ctx, "dir/test blob.txt", bytes.NewReader([]byte("upload content")),
+				objsto.WithContentType("text/plain"),
+				objsto.WithMetadata(map[string]string{"owner": "bob"}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(requests).To(HaveLen(1))
+
+			req := requests[0]
+			Expect(req.Method).To(Equal("PUT"))
+			Expect(req.URL.EscapedPath()).To(Equal("/devstoreaccount1/test-container/dir/test%20blob.txt"))
+			Expect(req.Header.Get("X-Ms-Blob-Type")).To(Equal("BlockBlob"))
+			Expect(bodies[0]).To(Equal("upload content"))
+
+			toSign := "PUT\n\n\n14\n\ntext/plain\n\n\n\n\n\n\n" +
+				"x-ms-blob-type:BlockBlob\n" +
+				"x-ms-date:" + req.Header.Get("X-Ms-Date") + "\n" +
+				"x-ms-meta-owner:bob\n" +
+				"x-ms-version:2021-08-06\n" +
+				"/devstoreaccount1/devstoreaccount1/test-container/dir/test%20blob.txt"
+			Expect(req.Header.Get("Authorization")).To(Equal(sign(toSign)))
+		})
+	})
+
+	Describe("List", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("marker") == "" {
+					_, _ = w.Write([]byte(`<EnumerationResults><Blobs>
+<Blob><Name>export_1_a.json</Name></Blob>
+<Blob><Name>export_1_b.json</Name></Blob>
+</Blobs><NextMarker>page2</NextMarker></EnumerationResults>`))
+					return
+				}
+				_, _ = w.Write([]byte(`<EnumerationResults><Blobs>
+<Blob><Name>export_1_c.json</Name></Blob>
+</Blobs><NextMarker/></EnumerationResults>`))
+			}
+		})
+
+		It("follows markers with signed queries", func() {
+			keys, err := client.List(ctx, "export_1_")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(keys).To(Equal([]string{"export_1_a.json", "export_1_b.json", "export_1_c.json"}))
+			Expect(requests).To(HaveLen(2))
+
+			req := requests[1]
+			toSign := "GET\n\n\n\n\n\n\n\n\n\n\n\n" +
+				"x-ms-date:" + req.Header.Get("X-Ms-Date") + "\n" +
+				"x-ms-version:2021-08-06\n" +
+				"/devstoreaccount1/devstoreaccount1/test-container\n" +
+				"comp:list\nmarker:page2\nprefix:export_1_\nrestype:container"
+			Expect(req.Header.Get("Authorization")).To(Equal(sign(toSign)))
+		})
+	})
+
+	Describe("Stat", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/devstoreaccount1/test-container/test-object.txt" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Header().Set("Content-Length", "42")
+				w.Header().Set("ETag", `"0x8D9"`)
+				w.Header().Set("X-Ms-Meta-Owner", "bob")
+			}
+		})
+
+		It("returns properties", func() {
+			info, err := client.Stat(ctx, "test-object.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Size).To(Equal(int64(42)))
+			Expect(info.ETag).To(Equal("0x8D9"))
+			Expect(info.Metadata).To(Equal(map[string]string{"owner": "bob"}))
+		})
+
+		It("returns not found for missing blobs", func() {
+			_, err := client.Stat(ctx, "nope.txt")
+			Expect(errors.Is(err, objsto.ErrNotFound)).To(BeTrue())
+		})
+	})
+
+	Describe("Get and Delete", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case "GET":
+					_, _ = w.Write([]byte("test content"))
+				case "DELETE":
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`<Error><Code>BlobNotFound</Code><Message>nope</Message></Error>`))
+				}
+			}
+		})
+
+		It("gets content", func() {
+			reader, err := client.Get(ctx, "test-object.txt")
+			Expect(err).ToNot(HaveOccurred())
+			content, _ := io.ReadAll(reader)
+			Expect(string(content)).To(Equal("test content"))
+		})
+
+		It("deletes missing blobs without error", func() {
+			Expect(client.Delete(ctx, "test-object.txt")).To(Succeed())
+		})
+	})
+})
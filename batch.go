@@ -0,0 +1,122 @@
+package objsto
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MaxBatchDelete is the most objects S3 deletes in one request.
+const MaxBatchDelete = 1000
+
+// DeleteObjects deletes up to MaxBatchDelete objects in one request,
+// returning errors for any that failed by key.
+// As with Delete, deleting an object that does not exist is not an error.
+func (c *Client) DeleteObjects(ctx context.Context, objects []string) (failed map[string]error, err error) {
+
+	c.logger.Info(ctx, "batch deleting from S3", "count", len(objects))
+
+	if len(objects) == 0 {
+		return
+	}
+	if len(objects) > MaxBatchDelete {
+		err = errors.Errorf("cannot delete %d objects in a batch, max is %d", len(objects), MaxBatchDelete)
+		return
+	}
+
+	// keys as stored, to map errors back to objects
+	stored := map[string]string{}
+	request := deleteRequest{Quiet: true}
+	for _, object := range objects {
+		key := object
+		if c.policy != nil {
+			key, err = c.policy.Apply(key)
+			if err != nil {
+				return
+			}
+		}
+		key = c.prefix + key
+
+		stored[key] = object
+		request.Objects = append(request.Objects, deleteObject{Key: key})
+	}
+
+	body, err := xml.Marshal(request)
+	if err != nil {
+		err = errors.Wrap(err, "failed to encode delete request")
+		return
+	}
+
+	sum := md5.Sum(body)
+	hdr := http.Header{}
+	hdr.Set("Content-Type", "application/xml")
+	hdr.Set("Content-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+
+	hash, size, err := hashPayload(bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	req, err := c.newRequest(ctx, "POST", "", url.Values{"delete": {""}}, bytes.NewReader(body), size, hash, hdr)
+	if err != nil {
+		return
+	}
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var result deleteResult
+	err = xml.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		err = errors.Wrap(err, "failed to parse delete response")
+		return
+	}
+
+	ids := requestIDs(resp)
+	for _, derr := range result.Errors {
+		object, ok := stored[derr.Key]
+		if !ok {
+			object = strings.TrimPrefix(derr.Key, c.prefix)
+		}
+		if failed == nil {
+			failed = map[string]error{}
+		}
+		failed[object] = &responseError{
+			error: errors.Wrapf(ErrRequestFailed, "s3 error, code: %s, request_id: %s, host_id: %s, message: %s",
+				derr.Code, ids.RequestID, ids.HostID, derr.Message),
+			ids: ids,
+		}
+	}
+
+	return
+}
+
+// unexported
+
+type deleteRequest struct {
+	XMLName xml.Name       `xml:"Delete"`
+	Quiet   bool           `xml:"Quiet"`
+	Objects []deleteObject `xml:"Object"`
+}
+
+type deleteObject struct {
+	Key string `xml:"Key"`
+}
+
+type deleteResult struct {
+	Errors []struct {
+		Key     string `xml:"Key"`
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	} `xml:"Error"`
+}
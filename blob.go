@@ -0,0 +1,176 @@
+package objsto
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultBlobPrefix is where BlobStore keeps blobs by default.
+const DefaultBlobPrefix = "blobs/sha256/"
+
+// BlobStore is a content-addressable layer over an ObjectStore, keeping blobs under keys
+// derived from their SHA-256, so that the same content is stored once.
+//
+// With a ref prefix, names such as "build/1234/app.tar" can be pointed at blobs,
+// each ref being a small object holding a blob key.
+type BlobStore struct {
+	store     ObjectStore
+	prefix    string
+	refPrefix string
+}
+
+// BlobOption sets an optional BlobStore setting.
+type BlobOption func(*BlobStore)
+
+// WithBlobPrefix sets where blobs are kept, defaulting to DefaultBlobPrefix.
+func WithBlobPrefix(prefix string) BlobOption {
+
+	return func(bs *BlobStore) {
+		bs.prefix = prefix
+	}
+}
+
+// WithRefPrefix keeps refs under prefix, enabling PutRef and GetRef.
+func WithRefPrefix(prefix string) BlobOption {
+
+	return func(bs *BlobStore) {
+		bs.refPrefix = prefix
+	}
+}
+
+// NewBlobStore creates a BlobStore over store.
+func NewBlobStore(store ObjectStore, opts ...BlobOption) *BlobStore {
+
+	bs := &BlobStore{
+		store:  store,
+		prefix: DefaultBlobPrefix,
+	}
+	for _, opt := range opts {
+		opt(bs)
+	}
+
+	return bs
+}
+
+// PutBlob puts content from reader, returning its key.
+// Reader is read once to hash it, from where it is to the end,
+// and the upload is skipped when the blob exists already.
+func (bs *BlobStore) PutBlob(ctx context.Context, reader io.ReadSeeker, opts ...PutOption) (key string, err error) {
+
+	start, err := reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		err = errors.Wrap(err, "failed to seek blob")
+		return
+	}
+
+	sum := sha256.New()
+	_, err = io.Copy(sum, reader)
+	if err != nil {
+		err = errors.Wrap(err, "failed to hash blob")
+		return
+	}
+
+	key = bs.prefix + hex.EncodeToString(sum.Sum(nil))
+
+	_, err = bs.store.Stat(ctx, key)
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, ErrNotFound) {
+		err = errors.Wrapf(err, "failed to check for blob %q", key)
+		return
+	}
+
+	_, err = reader.Seek(start, io.SeekStart)
+	if err != nil {
+		err = errors.Wrap(err, "failed to rewind blob")
+		return
+	}
+
+	err = bs.store.Put(ctx, key, reader, opts...)
+	return
+}
+
+// GetBlob gets a blob by key, verifying its content as read.
+// Reading to the end returns an error satisfying errors.Is(err, ErrChecksum) if it doesn't match.
+func (bs *BlobStore) GetBlob(ctx context.Context, key string) (reader io.ReadCloser, err error) {
+
+	want, err := hex.DecodeString(path.Base(key))
+	if err != nil || len(want) != sha256.Size || !strings.HasPrefix(key, bs.prefix) {
+		err = errors.Wrapf(ErrInvalidKey, "not a blob key %q", key)
+		return
+	}
+
+	reader, err = bs.store.Get(ctx, key)
+	if err != nil {
+		return
+	}
+
+	reader = &verifyReader{ReadCloser: reader, hash: sha256.New(), want: want, key: key}
+	return
+}
+
+// PutRef points name at a blob key.
+func (bs *BlobStore) PutRef(ctx context.Context, name, key string) (err error) {
+
+	if bs.refPrefix == "" {
+		err = errors.New("no ref prefix configured")
+		return
+	}
+
+	err = bs.store.Put(ctx, bs.refPrefix+name, strings.NewReader(key), WithContentType("text/plain"))
+	return
+}
+
+// GetRef gets the blob key name points at.
+func (bs *BlobStore) GetRef(ctx context.Context, name string) (key string, err error) {
+
+	if bs.refPrefix == "" {
+		err = errors.New("no ref prefix configured")
+		return
+	}
+
+	reader, err := bs.store.Get(ctx, bs.refPrefix+name)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, 1024))
+	if err != nil {
+		err = errors.Wrapf(err, "failed to read ref %q", name)
+		return
+	}
+
+	key = string(bytes.TrimSpace(data))
+	return
+}
+
+// unexported
+
+// verifyReader hashes as it reads, checking the sum at EOF.
+type verifyReader struct {
+	io.ReadCloser
+	hash hash.Hash
+	want []byte
+	key  string
+}
+
+func (vr *verifyReader) Read(buf []byte) (n int, err error) {
+
+	n, err = vr.ReadCloser.Read(buf)
+	vr.hash.Write(buf[:n])
+
+	if err == io.EOF && !bytes.Equal(vr.hash.Sum(nil), vr.want) {
+		err = errors.Wrapf(ErrChecksum, "content of %q", vr.key)
+	}
+	return
+}
@@ -0,0 +1,92 @@
+package objsto_test
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+var _ = Describe("BlobStore", func() {
+	var (
+		ctx   = context.Background()
+		inner *memstore.Store
+		ops   map[memstore.Op]int
+		bs    *objsto.BlobStore
+	)
+
+	BeforeEach(func() {
+		ops = map[memstore.Op]int{}
+		inner = memstore.New(memstore.WithFault(func(op memstore.Op, object string) error {
+			ops[op]++
+			return nil
+		}))
+		bs = objsto.NewBlobStore(inner, objsto.WithRefPrefix("refs/"))
+	})
+
+	It("keys blobs by content, uploading each once", func() {
+		key, err := bs.PutBlob(ctx, strings.NewReader("artifact"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(key).To(Equal("blobs/sha256/c7c5c1d70c5dec4416ab6158afd0b223ef40c29b1dc1f97ed9428b94d4cadb1c"))
+
+		again, err := bs.PutBlob(ctx, strings.NewReader("artifact"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(again).To(Equal(key))
+		Expect(ops[memstore.OpPut]).To(Equal(1))
+
+		reader, err := bs.GetBlob(ctx, key)
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(reader)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("artifact"))
+	})
+
+	It("puts a reader from where it is", func() {
+		reader := strings.NewReader("header:artifact")
+		_, err := reader.Seek(int64(len("header:")), io.SeekStart)
+		Expect(err).ToNot(HaveOccurred())
+
+		key, err := bs.PutBlob(ctx, reader)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(key).To(Equal("blobs/sha256/c7c5c1d70c5dec4416ab6158afd0b223ef40c29b1dc1f97ed9428b94d4cadb1c"))
+
+		blob, err := bs.GetBlob(ctx, key)
+		Expect(err).ToNot(HaveOccurred())
+		data, err := io.ReadAll(blob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("artifact"))
+	})
+
+	It("catches corrupted blobs", func() {
+		key, err := bs.PutBlob(ctx, strings.NewReader("artifact"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inner.Put(ctx, key, strings.NewReader("artefact"))).To(Succeed())
+
+		reader, err := bs.GetBlob(ctx, key)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = io.ReadAll(reader)
+		Expect(errors.Is(err, objsto.ErrChecksum)).To(BeTrue())
+	})
+
+	It("refuses keys not its own", func() {
+		_, err := bs.GetBlob(ctx, "blobs/sha256/nope")
+		Expect(errors.Is(err, objsto.ErrInvalidKey)).To(BeTrue())
+	})
+
+	It("points refs at blobs", func() {
+		key, err := bs.PutBlob(ctx, strings.NewReader("artifact"))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(bs.PutRef(ctx, "build/1234/app.tar", key)).To(Succeed())
+
+		got, err := bs.GetRef(ctx, "build/1234/app.tar")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(Equal(key))
+	})
+})
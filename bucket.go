@@ -0,0 +1,102 @@
+package objsto
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// CreateBucket creates the client's bucket in its region.
+// Creating a bucket that exists fails with ErrConflict, even when it's the caller's.
+func (c *Client) CreateBucket(ctx context.Context) (err error) {
+
+	c.logger.Info(ctx, "creating bucket in S3", "bucket", c.bucket, "region", c.region)
+
+	// us-east-1 is the default and rejected as a constraint
+	var body []byte
+	if c.region != "" && c.region != "us-east-1" {
+		body, err = xml.Marshal(createBucketConfiguration{LocationConstraint: c.region})
+		if err != nil {
+			err = errors.Wrap(err, "failed to marshal bucket configuration")
+			return
+		}
+	}
+
+	pyld := bytes.NewReader(body)
+	hash, size, err := hashPayload(pyld)
+	if err != nil {
+		return
+	}
+	var reader io.Reader
+	if size > 0 {
+		reader = pyld
+	}
+
+	req, err := c.newRequest(ctx, "PUT", "", nil, reader, size, hash, nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+
+	return
+}
+
+// DeleteBucket deletes the client's bucket, failing with ErrConflict unless it's empty,
+// incomplete uploads and object versions included.
+func (c *Client) DeleteBucket(ctx context.Context) (err error) {
+
+	c.logger.Info(ctx, "deleting bucket from S3", "bucket", c.bucket)
+
+	req, err := c.newRequest(ctx, "DELETE", "", nil, nil, 0, emptyHash, nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+
+	return
+}
+
+// BucketExists is true when the client's bucket exists, with an error for other failures,
+// such as credentials lacking access.
+func (c *Client) BucketExists(ctx context.Context) (exists bool, err error) {
+
+	c.logger.Info(ctx, "checking bucket in S3", "bucket", c.bucket)
+
+	req, err := c.newRequest(ctx, "HEAD", "", nil, nil, 0, emptyHash, nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := c.sendRequest(ctx, req)
+	if errors.Is(err, ErrNotFound) {
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+
+	exists = true
+	return
+}
+
+// unexported
+
+type createBucketConfiguration struct {
+	XMLName            xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CreateBucketConfiguration"`
+	LocationConstraint string   `xml:"LocationConstraint"`
+}
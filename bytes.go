@@ -0,0 +1,83 @@
+package objsto
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MaxPutSize is the largest object S3 accepts in a single put.
+const MaxPutSize int64 = 5 << 30
+
+// GetBytes gets an object into memory, failing with ErrTooLarge when bigger than maxSize.
+func (c *Client) GetBytes(ctx context.Context, object string, maxSize int64) (data []byte, err error) {
+
+	resp, err := c.get(ctx, object, nil)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.ContentLength > maxSize {
+		err = errors.Wrapf(ErrTooLarge, "%q is %d bytes, over limit of %d", object, resp.ContentLength, maxSize)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if resp.ContentLength > 0 {
+		buf.Grow(int(resp.ContentLength))
+	}
+
+	// length may be unknown, so read one past the limit to tell
+	_, err = io.Copy(buf, io.LimitReader(resp.Body, onePast(maxSize)))
+	if err != nil {
+		err = errors.Wrapf(err, "failed to read %q", object)
+		return
+	}
+	if int64(buf.Len()) > maxSize {
+		err = errors.Wrapf(ErrTooLarge, "%q is over limit of %d", object, maxSize)
+		return
+	}
+
+	data = buf.Bytes()
+	return
+}
+
+// PutBytes puts an object from data, failing with ErrTooLarge when over MaxPutSize.
+func (c *Client) PutBytes(ctx context.Context, object string, data []byte, opts ...PutOption) (err error) {
+
+	if int64(len(data)) > MaxPutSize {
+		err = errors.Wrapf(ErrTooLarge, "%q is %d bytes, over limit of %d", object, len(data), MaxPutSize)
+		return
+	}
+
+	err = c.Put(ctx, object, bytes.NewReader(data), opts...)
+	return
+}
+
+// PutString puts an object from str, failing with ErrTooLarge when over MaxPutSize.
+func (c *Client) PutString(ctx context.Context, object, str string, opts ...PutOption) (err error) {
+
+	if int64(len(str)) > MaxPutSize {
+		err = errors.Wrapf(ErrTooLarge, "%q is %d bytes, over limit of %d", object, len(str), MaxPutSize)
+		return
+	}
+
+	err = c.Put(ctx, object, strings.NewReader(str), opts...)
+	return
+}
+
+// unexported
+
+// onePast is limit plus one, to read past it and tell when over, short of overflowing.
+func onePast(limit int64) int64 {
+
+	if limit < math.MaxInt64 {
+		limit++
+	}
+	return limit
+}
@@ -0,0 +1,124 @@
+package objsto_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var _ = Describe("Bytes", func() {
+	var (
+		ctx    context.Context
+		mock   *HttpDoerMock
+		client *objsto.Client
+		stored []byte
+		length int64
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		stored = []byte("test content")
+		length = int64(len(stored))
+
+		mock = &HttpDoerMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if req.Method == "PUT" {
+					stored, _ = io.ReadAll(req.Body)
+				}
+				return &http.Response{
+					StatusCode:    200,
+					ContentLength: length,
+					Body:          io.NopCloser(bytes.NewReader(stored)),
+				}, nil
+			},
+		}
+
+		client = objsto.New(&objsto.Config{
+			Region:    "test-region",
+			Scheme:    "https",
+			Host:      "test-host",
+			Bucket:    "test-bucket",
+			AccessKey: "test-access-key",
+			SecretKey: "test-secret-key",
+		}, objsto.WithHTTPClient(mock))
+	})
+
+	Describe("GetBytes", func() {
+		var (
+			maxSize int64
+			data    []byte
+			err     error
+		)
+
+		JustBeforeEach(func() {
+			data, err = client.GetBytes(ctx, "test-object.txt", maxSize)
+		})
+
+		When("under the limit", func() {
+			BeforeEach(func() {
+				maxSize = 100
+			})
+
+			It("returns the data", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(data)).To(Equal("test content"))
+			})
+		})
+
+		When("the limit is as big as can be", func() {
+			BeforeEach(func() {
+				maxSize = math.MaxInt64
+				length = -1
+			})
+
+			It("returns the data", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(data)).To(Equal("test content"))
+			})
+		})
+
+		When("content length is over the limit", func() {
+			BeforeEach(func() {
+				maxSize = 5
+			})
+
+			It("returns too large", func() {
+				Expect(errors.Is(err, objsto.ErrTooLarge)).To(BeTrue())
+			})
+		})
+
+		When("unknown length turns out over the limit", func() {
+			BeforeEach(func() {
+				maxSize = 5
+				length = -1
+			})
+
+			It("returns too large", func() {
+				Expect(errors.Is(err, objsto.ErrTooLarge)).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("PutBytes and PutString", func() {
+		It("puts bytes", func() {
+			err := client.PutBytes(ctx, "test-object.txt", []byte("some bytes"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(stored)).To(Equal("some bytes"))
+		})
+
+		It("puts a string with options", func() {
+			err := client.PutString(ctx, "test-object.txt", "some string", objsto.WithContentType("text/plain"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(stored)).To(Equal("some string"))
+			Expect(mock.DoCalls()[0].Request.Header.Get("Content-Type")).To(Equal("text/plain"))
+		})
+	})
+})
@@ -0,0 +1,436 @@
+package objsto
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultCacheTTL is how long a cached object is served before revalidating by default.
+const DefaultCacheTTL = time.Minute
+
+// CachingStore wraps an ObjectStore, serving hot Gets from an in-memory LRU bounded by total size.
+//
+// A miss when the store is a ConditionalGetter gets the object and its info in one request,
+// and otherwise, or with a disk cache to check first, stats and then gets,
+// caching objects no bigger than the max object size.
+// Entries older than the TTL are revalidated with If-None-Match when the store is a ConditionalGetter,
+// a 304 being a hit, and otherwise with a Stat, refetching when the ETag has changed.
+// Put and Delete through the wrapper invalidate, while changes made otherwise are seen on revalidation.
+type CachingStore struct {
+	ObjectStore
+	ttl       time.Duration
+	negTTL    time.Duration
+	maxObject int64
+	lru       *lru
+	disk      *diskCache
+	clock     Clock
+	stats     cacheCounters
+}
+
+var _ ObjectStore = &CachingStore{}
+
+// CacheStats counts CachingStore outcomes, for tuning TTLs.
+// Hits are served without fetching the object, and misses fetch it.
+// Revalidations check with the store once the TTL has passed, each then a hit or a miss.
+type CacheStats struct {
+	Hits          int64 `json:"hits"`
+	Misses        int64 `json:"misses"`
+	Revalidations int64 `json:"revalidations"`
+}
+
+// CacheOption sets an optional CachingStore setting.
+type CacheOption func(*CachingStore)
+
+// WithCacheTTL sets how long an object is served before revalidating, defaulting to DefaultCacheTTL.
+func WithCacheTTL(ttl time.Duration) CacheOption {
+
+	return func(cs *CachingStore) {
+		cs.ttl = ttl
+	}
+}
+
+// WithNegativeTTL caches not-found results for ttl, sparing the store repeated probes for keys that don't exist.
+// Keep it short, objects put other than through the wrapper aren't seen until it passes.
+func WithNegativeTTL(ttl time.Duration) CacheOption {
+
+	return func(cs *CachingStore) {
+		cs.negTTL = ttl
+	}
+}
+
+// WithMaxCacheObject sets the size of the biggest object cached, defaulting to an eighth of the cache.
+func WithMaxCacheObject(size int64) CacheOption {
+
+	return func(cs *CachingStore) {
+		cs.maxObject = size
+	}
+}
+
+// WithDiskCache spills objects too big for memory to files in dir, up to maxBytes in all,
+// evicting those least recently used. Files are named for key and ETag,
+// so they survive restarts and are never served for a changed object.
+func WithDiskCache(dir string, maxBytes int64) CacheOption {
+
+	return func(cs *CachingStore) {
+		cs.disk = &diskCache{dir: dir, maxBytes: maxBytes}
+	}
+}
+
+// WithCacheClock sets the clock used for ages, as for testing.
+func WithCacheClock(clock Clock) CacheOption {
+
+	return func(cs *CachingStore) {
+		cs.clock = clock
+	}
+}
+
+// NewCachingStore creates a CachingStore wrapping store, holding up to maxBytes of objects.
+func NewCachingStore(store ObjectStore, maxBytes int64, opts ...CacheOption) *CachingStore {
+
+	cs := &CachingStore{
+		ObjectStore: store,
+		ttl:         DefaultCacheTTL,
+		maxObject:   maxBytes / 8,
+		lru:         newLRU(maxBytes),
+		clock:       systemClock{},
+	}
+	for _, opt := range opts {
+		opt(cs)
+	}
+
+	return cs
+}
+
+// Get gets an object, from the cache when fresh.
+func (cs *CachingStore) Get(ctx context.Context, object string) (reader io.ReadCloser, err error) {
+
+	ent, info, body, err := cs.entry(ctx, object)
+	switch {
+	case err != nil:
+	case ent != nil:
+		reader = io.NopCloser(bytes.NewReader(ent.data))
+	case body != nil:
+		reader = body
+	case cs.disk != nil && info.ETag != "" && info.Size <= cs.disk.maxBytes:
+		var hit bool
+		reader, hit, err = cs.disk.get(ctx, cs.ObjectStore, info)
+		cs.stats.count(hit)
+	default:
+		cs.stats.count(false)
+		reader, err = cs.ObjectStore.Get(ctx, object)
+	}
+
+	return
+}
+
+// Stat gets an object's info, from the cache when fresh.
+func (cs *CachingStore) Stat(ctx context.Context, object string) (info ObjectInfo, err error) {
+
+	ent, ok := cs.lru.get(object)
+	if ok && cs.fresh(ent) {
+		info = ent.info
+		err = ent.err(object)
+		return
+	}
+
+	info, err = cs.ObjectStore.Stat(ctx, object)
+	cs.notFound(object, err)
+	return
+}
+
+// Put puts an object, invalidating any cached.
+func (cs *CachingStore) Put(ctx context.Context, object string, reader io.ReadSeeker, opts ...PutOption) (err error) {
+
+	cs.lru.remove(object)
+	err = cs.ObjectStore.Put(ctx, object, reader, opts...)
+	cs.lru.remove(object)
+	return
+}
+
+// Delete deletes an object, invalidating any cached.
+func (cs *CachingStore) Delete(ctx context.Context, object string) (err error) {
+
+	cs.lru.remove(object)
+	err = cs.ObjectStore.Delete(ctx, object)
+	cs.lru.remove(object)
+	return
+}
+
+// Stats returns counts of outcomes so far.
+func (cs *CachingStore) Stats() CacheStats {
+
+	return CacheStats{
+		Hits:          cs.stats.hits.Load(),
+		Misses:        cs.stats.misses.Load(),
+		Revalidations: cs.stats.revalidations.Load(),
+	}
+}
+
+// Len returns the number of objects and bytes cached, counting any not-found markers and their keys.
+func (cs *CachingStore) Len() (objects int, size int64) {
+
+	return cs.lru.len()
+}
+
+// unexported
+
+// entry finds a fresh cache entry for object, fetching or revalidating as needed.
+// Otherwise it's a body too big to cache from revalidating,
+// or info from a stat of an object too big to cache in memory.
+func (cs *CachingStore) entry(ctx context.Context, object string) (ent *cacheEntry, info ObjectInfo, body io.ReadCloser, err error) {
+
+	cached, ok := cs.lru.get(object)
+	if ok && cs.fresh(cached) {
+		cs.stats.count(true)
+		ent = cached
+		err = ent.err(object)
+		return
+	}
+	if ok && cached.missing {
+		cs.lru.remove(object)
+		cached, ok = nil, false
+	}
+
+	// a disk cache is checked by etag, wanting a stat before any get
+	cg, conditional := cs.ObjectStore.(ConditionalGetter)
+	if conditional && (cs.disk == nil || ok && cached.info.ETag != "") {
+		ent, body, err = cs.getIfNoneMatch(ctx, cg, object, cached)
+		return
+	}
+
+	info, err = cs.ObjectStore.Stat(ctx, object)
+	if err != nil {
+		cs.lru.remove(object)
+		cs.notFound(object, err)
+		return
+	}
+
+	if ok {
+		cs.stats.revalidations.Add(1)
+		if info.ETag != "" && info.ETag == cached.info.ETag {
+			cs.stats.count(true)
+			ent = cs.refresh(cached)
+			return
+		}
+	}
+
+	if info.Size > cs.maxObject {
+		cs.lru.remove(object)
+		return
+	}
+
+	// stat before get, so that data is never older than the etag it's cached with
+	reader, err := cs.ObjectStore.Get(ctx, object)
+	if err != nil {
+		return
+	}
+
+	cs.stats.count(false)
+	ent, body, err = cs.load(info, reader)
+	return
+}
+
+// getIfNoneMatch gets an object in one request, unless unchanged since cached when there's an entry with an etag.
+func (cs *CachingStore) getIfNoneMatch(ctx context.Context, cg ConditionalGetter, object string, cached *cacheEntry) (ent *cacheEntry, body io.ReadCloser, err error) {
+
+	var etag string
+	if cached != nil {
+		cs.stats.revalidations.Add(1)
+		etag = cached.info.ETag
+	}
+
+	reader, info, err := cg.GetIfNoneMatch(ctx, object, etag)
+	if errors.Is(err, ErrNotModified) {
+		err = nil
+		cs.stats.count(true)
+		ent = cs.refresh(cached)
+		return
+	}
+	if err != nil {
+		cs.lru.remove(object)
+		cs.notFound(object, err)
+		return
+	}
+
+	cs.stats.count(false)
+	ent, body, err = cs.load(info, reader)
+	return
+}
+
+// load caches from reader when small enough, and otherwise hands back a body reading all of it.
+func (cs *CachingStore) load(info ObjectInfo, reader io.ReadCloser) (ent *cacheEntry, body io.ReadCloser, err error) {
+
+	data, err := io.ReadAll(io.LimitReader(reader, onePast(cs.maxObject)))
+	if err != nil {
+		reader.Close()
+		return
+	}
+
+	if int64(len(data)) > cs.maxObject {
+		cs.lru.remove(info.Key)
+		body = &joinedReader{Reader: io.MultiReader(bytes.NewReader(data), reader), Closer: reader}
+		return
+	}
+	reader.Close()
+
+	info.Size = int64(len(data))
+	ent = &cacheEntry{key: info.Key, data: data, info: info, fetched: cs.clock.Now()}
+	cs.lru.add(ent)
+	return
+}
+
+func (cs *CachingStore) refresh(cached *cacheEntry) (ent *cacheEntry) {
+
+	ent = &cacheEntry{key: cached.key, data: cached.data, info: cached.info, fetched: cs.clock.Now()}
+	cs.lru.add(ent)
+	return
+}
+
+// notFound caches a not-found result when negative caching is on.
+func (cs *CachingStore) notFound(object string, err error) {
+
+	if cs.negTTL <= 0 || !errors.Is(err, ErrNotFound) {
+		return
+	}
+
+	cs.lru.add(&cacheEntry{key: object, missing: true, fetched: cs.clock.Now()})
+}
+
+func (cs *CachingStore) fresh(ent *cacheEntry) bool {
+
+	ttl := cs.ttl
+	if ent.missing {
+		ttl = cs.negTTL
+	}
+
+	return cs.clock.Now().Sub(ent.fetched) < ttl
+}
+
+type cacheCounters struct {
+	hits          atomic.Int64
+	misses        atomic.Int64
+	revalidations atomic.Int64
+}
+
+func (cc *cacheCounters) count(hit bool) {
+
+	if hit {
+		cc.hits.Add(1)
+		return
+	}
+	cc.misses.Add(1)
+}
+
+type joinedReader struct {
+	io.Reader
+	io.Closer
+}
+
+type cacheEntry struct {
+	key     string
+	data    []byte
+	info    ObjectInfo
+	missing bool
+	fetched time.Time
+}
+
+func (ent *cacheEntry) err(object string) (err error) {
+
+	if ent.missing {
+		err = errors.Wrapf(ErrNotFound, "no such object %q, cached", object)
+	}
+	return
+}
+
+// size is what an entry counts against the cache, not-found markers counting their keys.
+func (ent *cacheEntry) size() int64 {
+
+	if ent.missing {
+		return int64(len(ent.key))
+	}
+	return int64(len(ent.data))
+}
+
+// lru holds entries up to a total size, evicting the least recently used.
+type lru struct {
+	maxBytes int64
+	size     int64
+	order    *list.List
+	items    map[string]*list.Element
+	mu       sync.Mutex
+}
+
+func newLRU(maxBytes int64) *lru {
+
+	return &lru{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		items:    map[string]*list.Element{},
+	}
+}
+
+func (l *lru) get(key string) (ent *cacheEntry, ok bool) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.items[key]
+	if !ok {
+		return
+	}
+
+	l.order.MoveToFront(elem)
+	ent = elem.Value.(*cacheEntry)
+	return
+}
+
+func (l *lru) add(ent *cacheEntry) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.removeLocked(ent.key)
+
+	l.items[ent.key] = l.order.PushFront(ent)
+	l.size += ent.size()
+
+	for l.size > l.maxBytes && l.order.Len() > 0 {
+		oldest := l.order.Back().Value.(*cacheEntry)
+		l.removeLocked(oldest.key)
+	}
+}
+
+func (l *lru) remove(key string) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.removeLocked(key)
+}
+
+func (l *lru) removeLocked(key string) {
+
+	elem, ok := l.items[key]
+	if !ok {
+		return
+	}
+
+	l.order.Remove(elem)
+	delete(l.items, key)
+	l.size -= elem.Value.(*cacheEntry).size()
+}
+
+func (l *lru) len() (objects int, size int64) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.order.Len(), l.size
+}
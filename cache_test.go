@@ -0,0 +1,234 @@
+package objsto_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+var _ = Describe("CachingStore", func() {
+	var (
+		ctx   = context.Background()
+		inner *memstore.Store
+		clock *stepClock
+		ops   map[memstore.Op]int
+		cs    *objsto.CachingStore
+	)
+
+	BeforeEach(func() {
+		ops = map[memstore.Op]int{}
+		inner = memstore.New(memstore.WithFault(func(op memstore.Op, object string) error {
+			ops[op]++
+			return nil
+		}))
+		clock = &stepClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+
+		Expect(inner.Put(ctx, "hot.txt", strings.NewReader("hot stuff"))).To(Succeed())
+		Expect(inner.Put(ctx, "big.bin", bytes.NewReader(make([]byte, 200)))).To(Succeed())
+		ops = map[memstore.Op]int{}
+
+		cs = objsto.NewCachingStore(inner, 800, objsto.WithCacheClock(clock))
+	})
+
+	read := func(object string) string {
+
+		reader, err := cs.Get(ctx, object)
+		Expect(err).ToNot(HaveOccurred())
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	It("serves hot gets locally", func() {
+		Expect(read("hot.txt")).To(Equal("hot stuff"))
+		Expect(read("hot.txt")).To(Equal("hot stuff"))
+
+		Expect(ops[memstore.OpGet]).To(Equal(1))
+		objects, size := cs.Len()
+		Expect(objects).To(Equal(1))
+		Expect(size).To(Equal(int64(9)))
+	})
+
+	It("revalidates stale entries, refetching only when changed", func() {
+		read("hot.txt")
+
+		clock.now = clock.now.Add(2 * objsto.DefaultCacheTTL)
+		Expect(read("hot.txt")).To(Equal("hot stuff"))
+		Expect(ops[memstore.OpGet]).To(Equal(1))
+		Expect(ops[memstore.OpStat]).To(Equal(2))
+
+		Expect(inner.Put(ctx, "hot.txt", strings.NewReader("hotter stuff"))).To(Succeed())
+		Expect(read("hot.txt")).To(Equal("hot stuff"))
+
+		clock.now = clock.now.Add(2 * objsto.DefaultCacheTTL)
+		Expect(read("hot.txt")).To(Equal("hotter stuff"))
+		Expect(ops[memstore.OpGet]).To(Equal(2))
+	})
+
+	It("counts hits, misses, and revalidations", func() {
+		read("hot.txt")
+		read("hot.txt")
+
+		clock.now = clock.now.Add(2 * objsto.DefaultCacheTTL)
+		read("hot.txt")
+		read("big.bin")
+
+		Expect(cs.Stats()).To(Equal(objsto.CacheStats{Hits: 2, Misses: 2, Revalidations: 1}))
+	})
+
+	When("the store gets conditionally", func() {
+		var mock *HttpDoerMock
+
+		BeforeEach(func() {
+			mock = &HttpDoerMock{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.Header.Get("If-None-Match") == `"v1"` {
+						return &http.Response{StatusCode: 304, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+					}
+					return &http.Response{
+						StatusCode:    200,
+						Header:        http.Header{"Etag": {`"v1"`}},
+						ContentLength: 9,
+						Body:          io.NopCloser(strings.NewReader("hot stuff")),
+					}, nil
+				},
+			}
+
+			cfg := &objsto.Config{
+				Region:    "test-region",
+				Scheme:    "https",
+				Host:      "test-host",
+				Bucket:    "test-bucket",
+				AccessKey: "test-access-key",
+				SecretKey: "test-secret-key",
+			}
+
+			cs = objsto.NewCachingStore(cfg.New(mock, nil), 800, objsto.WithCacheClock(clock))
+		})
+
+		It("gets a miss in one request and revalidates with If-None-Match, a 304 being a hit", func() {
+			Expect(read("hot.txt")).To(Equal("hot stuff"))
+
+			clock.now = clock.now.Add(2 * objsto.DefaultCacheTTL)
+			Expect(read("hot.txt")).To(Equal("hot stuff"))
+
+			calls := mock.DoCalls()
+			Expect(calls).To(HaveLen(2))
+			Expect(calls[0].Request.Method).To(Equal("GET"))
+			Expect(calls[0].Request.Header.Get("If-None-Match")).To(BeEmpty())
+			Expect(calls[1].Request.Method).To(Equal("GET"))
+			Expect(calls[1].Request.Header.Get("If-None-Match")).To(Equal(`"v1"`))
+			Expect(cs.Stats()).To(Equal(objsto.CacheStats{Hits: 1, Misses: 1, Revalidations: 1}))
+		})
+	})
+
+	When("caching not found", func() {
+		BeforeEach(func() {
+			cs = objsto.NewCachingStore(inner, 800, objsto.WithCacheClock(clock), objsto.WithNegativeTTL(time.Second))
+		})
+
+		It("spares the store repeated probes until the ttl passes or it's put", func() {
+			for range 3 {
+				_, err := cs.Get(ctx, "sidecar.json")
+				Expect(err).To(MatchError(objsto.ErrNotFound))
+				_, err = cs.Stat(ctx, "sidecar.json")
+				Expect(err).To(MatchError(objsto.ErrNotFound))
+			}
+			Expect(ops[memstore.OpStat]).To(Equal(1))
+
+			clock.now = clock.now.Add(2 * time.Second)
+			_, err := cs.Stat(ctx, "sidecar.json")
+			Expect(err).To(MatchError(objsto.ErrNotFound))
+			Expect(ops[memstore.OpStat]).To(Equal(2))
+
+			Expect(cs.Put(ctx, "sidecar.json", strings.NewReader("{}"))).To(Succeed())
+			Expect(read("sidecar.json")).To(Equal("{}"))
+		})
+	})
+
+	It("invalidates on put and delete", func() {
+		read("hot.txt")
+
+		Expect(cs.Put(ctx, "hot.txt", strings.NewReader("new"))).To(Succeed())
+		Expect(read("hot.txt")).To(Equal("new"))
+
+		Expect(cs.Delete(ctx, "hot.txt")).To(Succeed())
+		_, err := cs.Get(ctx, "hot.txt")
+		Expect(err).To(MatchError(objsto.ErrNotFound))
+	})
+
+	It("passes big objects through", func() {
+		Expect(read("big.bin")).To(HaveLen(200))
+		Expect(read("big.bin")).To(HaveLen(200))
+
+		Expect(ops[memstore.OpGet]).To(Equal(2))
+		objects, _ := cs.Len()
+		Expect(objects).To(BeZero())
+	})
+
+	When("spilling to disk", func() {
+		var dir string
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			cs = objsto.NewCachingStore(inner, 800, objsto.WithCacheClock(clock), objsto.WithDiskCache(dir, 500))
+		})
+
+		It("serves big objects from files surviving a restart", func() {
+			Expect(read("big.bin")).To(HaveLen(200))
+			Expect(read("big.bin")).To(HaveLen(200))
+			Expect(ops[memstore.OpGet]).To(Equal(1))
+
+			cs = objsto.NewCachingStore(inner, 800, objsto.WithDiskCache(dir, 500))
+			Expect(read("big.bin")).To(HaveLen(200))
+			Expect(ops[memstore.OpGet]).To(Equal(1))
+		})
+
+		It("refetches a changed object and evicts by size", func() {
+			read("big.bin")
+
+			Expect(inner.Put(ctx, "big.bin", bytes.NewReader(make([]byte, 400)))).To(Succeed())
+			Expect(read("big.bin")).To(HaveLen(400))
+			Expect(ops[memstore.OpGet]).To(Equal(2))
+
+			entries, err := os.ReadDir(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+		})
+	})
+
+	It("evicts the least recently used", func() {
+		cs = objsto.NewCachingStore(inner, 15, objsto.WithMaxCacheObject(15), objsto.WithCacheClock(clock))
+		Expect(inner.Put(ctx, "warm.txt", strings.NewReader("warm stuff"))).To(Succeed())
+
+		read("hot.txt")
+		read("warm.txt")
+		read("hot.txt")
+		read("warm.txt")
+		Expect(ops[memstore.OpGet]).To(Equal(4))
+
+		objects, size := cs.Len()
+		Expect(objects).To(Equal(1))
+		Expect(size).To(Equal(int64(10)))
+	})
+})
+
+type stepClock struct {
+	now time.Time
+}
+
+func (sc *stepClock) Now() time.Time {
+	return sc.now
+}
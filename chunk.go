@@ -0,0 +1,332 @@
+package objsto
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultChunkSize is the size of the chunks ChunkStore splits objects into by default.
+	DefaultChunkSize = 64 << 20
+	// DefaultChunkPrefix is where ChunkStore keeps chunks by default.
+	DefaultChunkPrefix = ".chunks/"
+	// maxManifest is the most read of a manifest, plenty for a few thousand chunks.
+	maxManifest = 4 << 20
+)
+
+// ChunkManifest lists the chunks of an object put with ChunkStore.
+type ChunkManifest struct {
+	Size        int64   `json:"size"`
+	ChunkSize   int64   `json:"chunk_size"`
+	ContentType string  `json:"content_type,omitempty"`
+	Chunks      []Chunk `json:"chunks"`
+}
+
+// Chunk is one piece of a chunked object.
+type Chunk struct {
+	Key    string `json:"key"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ChunkStore wraps an ObjectStore, splitting objects into fixed-size chunks
+// for providers with small object limits.
+//
+// Put stores the chunks under the chunk prefix and then a JSON ChunkManifest under the object's key,
+// so that readers see the old object or the new one, not a mix.
+// Get reassembles the chunks as a single reader, checking the SHA-256 of each as it's read.
+// Stat reports the size and content type as put, and List leaves out the chunks.
+// Each chunk is buffered in memory on Put.
+type ChunkStore struct {
+	ObjectStore
+	chunkSize int64
+	prefix    string
+}
+
+var _ ObjectStore = &ChunkStore{}
+
+// ChunkOption sets an optional ChunkStore setting.
+type ChunkOption func(*ChunkStore)
+
+// WithChunkSize sets the size of chunks, defaulting to DefaultChunkSize.
+func WithChunkSize(size int64) ChunkOption {
+
+	return func(cs *ChunkStore) {
+		cs.chunkSize = size
+	}
+}
+
+// WithChunkPrefix sets where chunks are kept, defaulting to DefaultChunkPrefix.
+func WithChunkPrefix(prefix string) ChunkOption {
+
+	return func(cs *ChunkStore) {
+		cs.prefix = prefix
+	}
+}
+
+// NewChunkStore creates a ChunkStore wrapping store.
+func NewChunkStore(store ObjectStore, opts ...ChunkOption) *ChunkStore {
+
+	cs := &ChunkStore{
+		ObjectStore: store,
+		chunkSize:   DefaultChunkSize,
+		prefix:      DefaultChunkPrefix,
+	}
+	for _, opt := range opts {
+		opt(cs)
+	}
+	cs.chunkSize = max(cs.chunkSize, 1)
+
+	return cs
+}
+
+// Get gets an object, reading its chunks in turn.
+// A chunk not matching the manifest gives an error satisfying errors.Is(err, ErrChecksum) from Read.
+func (cs *ChunkStore) Get(ctx context.Context, object string) (reader io.ReadCloser, err error) {
+
+	manifest, err := cs.Manifest(ctx, object)
+	if err != nil {
+		return
+	}
+
+	reader = &chunkReader{ctx: ctx, store: cs.ObjectStore, chunks: manifest.Chunks}
+	return
+}
+
+// Put puts an object as chunks and a manifest, removing any chunks it replaces.
+func (cs *ChunkStore) Put(ctx context.Context, object string, reader io.ReadSeeker, opts ...PutOption) (err error) {
+
+	old, oldErr := cs.Manifest(ctx, object)
+
+	id, err := uploadID()
+	if err != nil {
+		return
+	}
+
+	manifest := ChunkManifest{
+		ChunkSize:   cs.chunkSize,
+		ContentType: NewPutOptions(opts...).ContentType,
+		Chunks:      []Chunk{},
+	}
+
+	buf := make([]byte, cs.chunkSize)
+	for idx := 0; ; idx++ {
+		var n int
+		n, err = io.ReadFull(reader, buf)
+		if err == io.EOF && idx > 0 {
+			err = nil
+			break
+		}
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			err = errors.Wrapf(err, "failed to read %q", object)
+			return
+		}
+		last := err != nil
+
+		sum := sha256.Sum256(buf[:n])
+		chunk := Chunk{
+			Key:    fmt.Sprintf("%s%s/%s/%06d", cs.prefix, object, id, idx),
+			Size:   int64(n),
+			SHA256: hex.EncodeToString(sum[:]),
+		}
+
+		err = cs.ObjectStore.Put(ctx, chunk.Key, bytes.NewReader(buf[:n]), WithContentType("application/octet-stream"))
+		if err != nil {
+			err = errors.Wrapf(err, "failed to put chunk %d of %q", idx, object)
+			return
+		}
+
+		manifest.Chunks = append(manifest.Chunks, chunk)
+		manifest.Size += int64(n)
+		if last {
+			break
+		}
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		err = errors.Wrap(err, "failed to encode manifest")
+		return
+	}
+
+	opts = append(opts, WithContentType(jsonType))
+	err = cs.ObjectStore.Put(ctx, object, bytes.NewReader(data), opts...)
+	if err != nil || oldErr != nil {
+		return
+	}
+
+	cs.deleteChunks(ctx, old)
+	return
+}
+
+// Delete deletes an object's manifest and then its chunks.
+func (cs *ChunkStore) Delete(ctx context.Context, object string) (err error) {
+
+	manifest, err := cs.Manifest(ctx, object)
+	if err != nil {
+		return
+	}
+
+	err = cs.ObjectStore.Delete(ctx, object)
+	if err != nil {
+		return
+	}
+
+	cs.deleteChunks(ctx, manifest)
+	return
+}
+
+// List lists objects, leaving out chunks.
+func (cs *ChunkStore) List(ctx context.Context, prefix string) (objects []string, err error) {
+
+	keys, err := cs.ObjectStore.List(ctx, prefix)
+	if err != nil {
+		return
+	}
+
+	for _, key := range keys {
+		if !strings.HasPrefix(key, cs.prefix) {
+			objects = append(objects, key)
+		}
+	}
+
+	return
+}
+
+// Stat gets an object's info, with the size and content type as put.
+func (cs *ChunkStore) Stat(ctx context.Context, object string) (info ObjectInfo, err error) {
+
+	info, err = cs.ObjectStore.Stat(ctx, object)
+	if err != nil {
+		return
+	}
+
+	manifest, err := cs.Manifest(ctx, object)
+	if err != nil {
+		return
+	}
+
+	info.Size = manifest.Size
+	info.ContentType = manifest.ContentType
+	return
+}
+
+// Manifest gets the manifest of a chunked object.
+func (cs *ChunkStore) Manifest(ctx context.Context, object string) (manifest ChunkManifest, err error) {
+
+	reader, err := cs.ObjectStore.Get(ctx, object)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	err = json.NewDecoder(io.LimitReader(reader, maxManifest)).Decode(&manifest)
+	if err == nil && manifest.Chunks == nil {
+		err = errors.New("no chunks")
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "failed to decode manifest %q", object)
+	}
+
+	return
+}
+
+// unexported
+
+func (cs *ChunkStore) deleteChunks(ctx context.Context, manifest ChunkManifest) {
+
+	// best effort, leftover chunks are only wasted space
+	for _, chunk := range manifest.Chunks {
+		cs.ObjectStore.Delete(ctx, chunk.Key)
+	}
+}
+
+func uploadID() (id string, err error) {
+
+	buf := make([]byte, 8)
+	_, err = rand.Read(buf)
+	if err != nil {
+		err = errors.Wrap(err, "failed to read random")
+		return
+	}
+
+	id = hex.EncodeToString(buf)
+	return
+}
+
+// chunkReader reads chunks in turn, getting each when the last is done.
+type chunkReader struct {
+	ctx     context.Context
+	store   ObjectStore
+	chunks  []Chunk
+	current io.ReadCloser
+}
+
+func (cr *chunkReader) Read(buf []byte) (n int, err error) {
+
+	for {
+		if cr.current == nil {
+			if len(cr.chunks) == 0 {
+				err = io.EOF
+				return
+			}
+
+			err = cr.next()
+			if err != nil {
+				return
+			}
+		}
+
+		n, err = cr.current.Read(buf)
+		if err != io.EOF {
+			return
+		}
+
+		cr.current.Close()
+		cr.current = nil
+		if n > 0 {
+			err = nil
+			return
+		}
+	}
+}
+
+func (cr *chunkReader) next() (err error) {
+
+	chunk := cr.chunks[0]
+	cr.chunks = cr.chunks[1:]
+
+	want, err := hex.DecodeString(chunk.SHA256)
+	if err != nil {
+		err = errors.Wrapf(err, "bad checksum for chunk %q", chunk.Key)
+		return
+	}
+
+	reader, err := cr.store.Get(cr.ctx, chunk.Key)
+	if err != nil {
+		return
+	}
+
+	cr.current = &verifyReader{ReadCloser: reader, hash: sha256.New(), want: want, key: chunk.Key}
+	return
+}
+
+func (cr *chunkReader) Close() (err error) {
+
+	if cr.current != nil {
+		err = cr.current.Close()
+		cr.current = nil
+	}
+	cr.chunks = nil
+
+	return
+}
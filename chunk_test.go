@@ -0,0 +1,80 @@
+package objsto_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+var _ = Describe("ChunkStore", func() {
+	var (
+		ctx     = context.Background()
+		inner   *memstore.Store
+		cs      *objsto.ChunkStore
+		payload = strings.Repeat("0123456789", 25)
+	)
+
+	BeforeEach(func() {
+		inner = memstore.New()
+		cs = objsto.NewChunkStore(inner, objsto.WithChunkSize(100))
+
+		Expect(cs.Put(ctx, "huge.bin", strings.NewReader(payload), objsto.WithContentType("text/plain"))).To(Succeed())
+	})
+
+	read := func(object string) (string, error) {
+
+		reader, err := cs.Get(ctx, object)
+		Expect(err).ToNot(HaveOccurred())
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		return string(data), err
+	}
+
+	It("splits into chunks and reassembles", func() {
+		manifest, err := cs.Manifest(ctx, "huge.bin")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest.Size).To(Equal(int64(250)))
+		Expect(manifest.Chunks).To(HaveLen(3))
+		Expect(manifest.Chunks[2].Size).To(Equal(int64(50)))
+
+		data, err := read("huge.bin")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(payload))
+
+		info, err := cs.Stat(ctx, "huge.bin")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Size).To(Equal(int64(250)))
+		Expect(info.ContentType).To(Equal("text/plain"))
+
+		Expect(cs.List(ctx, "")).To(Equal([]string{"huge.bin"}))
+	})
+
+	It("catches a corrupted chunk", func() {
+		manifest, err := cs.Manifest(ctx, "huge.bin")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inner.Put(ctx, manifest.Chunks[1].Key, bytes.NewReader(make([]byte, 100)))).To(Succeed())
+
+		_, err = read("huge.bin")
+		Expect(errors.Is(err, objsto.ErrChecksum)).To(BeTrue())
+	})
+
+	It("cleans up chunks on replace and delete", func() {
+		Expect(cs.Put(ctx, "huge.bin", strings.NewReader("small"))).To(Succeed())
+		data, err := read("huge.bin")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal("small"))
+		Expect(inner.Len()).To(Equal(2))
+
+		Expect(cs.Delete(ctx, "huge.bin")).To(Succeed())
+		Expect(inner.Len()).To(BeZero())
+	})
+})
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	mrand "math/rand/v2"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+)
+
+func bench(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	sizeArg := flags.String("size", "1M", "bytes per object, such as 4K or 16M")
+	concurrency := flags.Int("concurrency", 8, "operations in flight")
+	duration := flags.Duration("duration", 30*time.Second, "time to run for, after writing objects to read")
+	readPct := flags.Int("read-percent", 50, "percent of operations that are reads, the rest writes")
+	objects := flags.Int("objects", 100, "objects read and overwritten")
+	keep := flags.Bool("keep", false, "leave the objects rather than deleting them after")
+
+	pos, err := parse(flags, env, args, 0, 1)
+	if err != nil {
+		return
+	}
+	size, err := parseSize(*sizeArg)
+	switch {
+	case err != nil:
+		return
+	case size > objsto.MaxPutSize:
+		return errors.Wrapf(errUsage, "-size is over the %d bytes of a single put", objsto.MaxPutSize)
+	case *readPct < 0 || *readPct > 100:
+		return errors.Wrap(errUsage, "-read-percent is from 0 to 100")
+	case *objects < 1 || *concurrency < 1:
+		return errors.Wrap(errUsage, "-objects and -concurrency need to be at least 1")
+	}
+
+	client, prefix := env.client, "objsto-bench/"
+	if len(pos) == 1 {
+		client, prefix = object(env, pos[0])
+	}
+
+	payload := make([]byte, size)
+	rand.Read(payload)
+
+	keys := make([]string, *objects)
+	for idx := range keys {
+		keys[idx] = fmt.Sprintf("%s%06d", prefix, idx)
+	}
+	if !*keep {
+		defer cleanup(context.WithoutCancel(ctx), env, client, keys)
+	}
+
+	ctx, mtr := env.meter(ctx, -1, client)
+	defer mtr.stop()
+
+	if *readPct > 0 {
+		errs := make([]error, len(keys))
+		parallel(*concurrency, keys, func(idx int, key string) {
+			errs[idx] = client.Put(ctx, key, bytes.NewReader(payload))
+		})
+		for _, perr := range errs {
+			if perr != nil {
+				return errors.Wrap(perr, "failed to write objects to read")
+			}
+		}
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, *duration)
+	defer cancel()
+
+	start := time.Now()
+	workers := make([]benchWorker, *concurrency)
+	parallel(*concurrency, workers, func(idx int, _ benchWorker) {
+		workers[idx].run(runCtx, client, keys, payload, *readPct)
+	})
+	elapsed := time.Since(start)
+	mtr.stop()
+
+	rpt := benchReport{
+		Size:        size,
+		Concurrency: *concurrency,
+		ReadPercent: *readPct,
+		Seconds:     elapsed.Seconds(),
+	}
+	failures, total := 0, 0
+	for _, op := range []string{"read", "write"} {
+		tallies := make([]*opTally, len(workers))
+		for idx := range workers {
+			tallies[idx] = workers[idx].tally(op)
+		}
+		summary := summarize(op, tallies, size, elapsed)
+		if summary.Count+summary.Errors == 0 {
+			continue
+		}
+		rpt.Ops = append(rpt.Ops, summary)
+
+		failures += summary.Errors
+		total += summary.Count + summary.Errors
+		if first := firstErr(tallies); first != nil {
+			fmt.Fprintf(env.stderr, "first %s error: %v\n", op, first)
+		}
+	}
+
+	if env.json {
+		err = printJSON(env.stdout, rpt)
+	} else {
+		err = rpt.print(env.stdout)
+	}
+	if err != nil {
+		return
+	}
+
+	if failures > 0 {
+		err = errors.Errorf("%d of %d operations failed", failures, total)
+	}
+	return
+}
+
+// benchReport is throughput and latency per operation.
+type benchReport struct {
+	Size        int64      `json:"size"`
+	Concurrency int        `json:"concurrency"`
+	ReadPercent int        `json:"read_percent"`
+	Seconds     float64    `json:"seconds"`
+	Ops         []opReport `json:"ops"`
+}
+
+// opReport is a summary of reads or writes, with latencies in milliseconds.
+type opReport struct {
+	Op          string  `json:"op"`
+	Count       int     `json:"count"`
+	Errors      int     `json:"errors"`
+	OpsPerSec   float64 `json:"ops_per_sec"`
+	BytesPerSec float64 `json:"bytes_per_sec"`
+	P50         float64 `json:"p50_ms"`
+	P90         float64 `json:"p90_ms"`
+	P99         float64 `json:"p99_ms"`
+	Max         float64 `json:"max_ms"`
+}
+
+func (rpt benchReport) print(writer io.Writer) (err error) {
+
+	fmt.Fprintf(writer, "%s objects, %d in flight, %d%% reads, for %s\n\n",
+		bytesize(rpt.Size), rpt.Concurrency, rpt.ReadPercent, time.Duration(rpt.Seconds*float64(time.Second)).Round(time.Millisecond))
+
+	tabs := tabwriter.NewWriter(writer, 1, 0, 2, ' ', 0)
+	fmt.Fprintln(tabs, "op\tcount\terrors\tops/s\tthroughput\tp50\tp90\tp99\tmax")
+	for _, op := range rpt.Ops {
+		fmt.Fprintf(tabs, "%s\t%d\t%d\t%.1f\t%s/s\t%s\t%s\t%s\t%s\n", op.Op, op.Count, op.Errors, op.OpsPerSec,
+			bytesize(int64(op.BytesPerSec)), millis(op.P50), millis(op.P90), millis(op.P99), millis(op.Max))
+	}
+
+	err = tabs.Flush()
+	return
+}
+
+// benchWorker does reads and writes one after another, tallying them.
+type benchWorker struct {
+	reads  opTally
+	writes opTally
+}
+
+// opTally is latency of operations that worked, and failures with the first of them.
+type opTally struct {
+	latency []time.Duration
+	errors  int
+	first   error
+}
+
+func (wkr *benchWorker) run(ctx context.Context, client *objsto.Client, keys []string, payload []byte, readPct int) {
+
+	for ctx.Err() == nil {
+		key := keys[mrand.IntN(len(keys))]
+
+		start := time.Now()
+		var err error
+		tally := &wkr.writes
+		if mrand.IntN(100) < readPct {
+			tally = &wkr.reads
+			_, _, err = client.GetInto(ctx, key, io.Discard)
+		} else {
+			err = client.Put(ctx, key, bytes.NewReader(payload))
+		}
+		took := time.Since(start)
+
+		switch {
+		case ctx.Err() != nil:
+			// cut off at the end rather than failed
+		case err != nil:
+			tally.errors++
+			if tally.first == nil {
+				tally.first = err
+			}
+		default:
+			tally.latency = append(tally.latency, took)
+		}
+	}
+}
+
+func (wkr *benchWorker) tally(op string) *opTally {
+
+	if op == "read" {
+		return &wkr.reads
+	}
+
+	return &wkr.writes
+}
+
+func summarize(op string, tallies []*opTally, size int64, elapsed time.Duration) (rpt opReport) {
+
+	latency := []time.Duration{}
+	for _, tally := range tallies {
+		latency = append(latency, tally.latency...)
+		rpt.Errors += tally.errors
+	}
+	slices.Sort(latency)
+
+	secs := max(elapsed.Seconds(), 0.001)
+	rpt.Op = op
+	rpt.Count = len(latency)
+	rpt.OpsPerSec = float64(rpt.Count) / secs
+	rpt.BytesPerSec = float64(int64(rpt.Count)*size) / secs
+	rpt.P50 = ms(percentile(latency, 0.5))
+	rpt.P90 = ms(percentile(latency, 0.9))
+	rpt.P99 = ms(percentile(latency, 0.99))
+	rpt.Max = ms(percentile(latency, 1))
+	return
+}
+
+// percentile is the latency p of the way through sorted, by nearest rank.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	return sorted[max(int(math.Ceil(p*float64(len(sorted))))-1, 0)]
+}
+
+func ms(dur time.Duration) float64 {
+
+	return float64(dur) / float64(time.Millisecond)
+}
+
+func millis(val float64) string {
+
+	return time.Duration(val * float64(time.Millisecond)).Round(10 * time.Microsecond).String()
+}
+
+func firstErr(tallies []*opTally) error {
+
+	for _, tally := range tallies {
+		if tally.first != nil {
+			return tally.first
+		}
+	}
+
+	return nil
+}
+
+// cleanup deletes objects written by bench, reporting failure on stderr.
+func cleanup(ctx context.Context, env *env, client *objsto.Client, keys []string) {
+
+	for batch := range slices.Chunk(keys, objsto.MaxBatchDelete) {
+		failed, err := client.DeleteObjects(ctx, batch)
+		if err == nil {
+			for _, ferr := range failed {
+				err = ferr
+				break
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(env.stderr, "failed to clean up %s...: %v\n", batch[0], err)
+			return
+		}
+	}
+}
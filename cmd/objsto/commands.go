@@ -0,0 +1,1859 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/objsync"
+)
+
+func init() {
+
+	commands["put"] = command{usage: "[-type content-type] [-part-size bytes] <file|-> <key|bucket:key>", run: put}
+	commands["get"] = command{usage: "[-o file|dir] [-range start-end] [-resume] <key|pattern>", run: get}
+	commands["cat"] = command{usage: "[-range start-end] <key>", run: cat}
+	commands["find"] = command{usage: "[-name regexp] [-newer-than age] [-older-than age] [-larger-than size] [-smaller-than size] [-l] [-0] [-delete | -copy-to bucket:prefix/] [prefix|bucket:prefix]", run: find}
+	commands["ls"] = command{usage: "[-l] [-r] [-d delimiter] [-0] [prefix]", run: ls}
+	commands["tree"] = command{usage: "[-depth n] [-s] [prefix|bucket:prefix]", run: tree}
+	commands["du"] = command{usage: "[-depth n] [-h] [prefix|bucket:prefix]", run: du}
+	commands["mb"] = command{usage: "[bucket]", run: mb}
+	commands["rb"] = command{usage: "[-force] [bucket]", run: rb}
+	commands["rm"] = command{usage: "[-r] [-dry-run] [-f] [-concurrency n] <key|prefix|pattern>...", run: rm}
+	commands["cp"] = command{usage: "[-type type] [-concurrency n] <file|-|bucket:key>... <file|-|bucket:key>", run: cp}
+	commands["sync"] = command{usage: "[-delete] [-dry-run] [-checksum] [-concurrency n] [-json] <dir|bucket:prefix> <dir|bucket:prefix>", run: synchronize}
+	commands["tag"] = command{usage: "get [-json] <key> | set [-json] <key> [name=value...] | rm <key> [name...]", run: tag}
+	commands["watch"] = command{usage: "[-interval 10s] [-initial] [-json] [prefix|bucket:prefix]", run: watch}
+	commands["bench"] = command{usage: "[-size 1M] [-concurrency 8] [-duration 30s] [-read-percent 50] [-objects 100] [-keep] [prefix|bucket:prefix]", run: bench}
+	commands["whoami"] = command{usage: "[-key probe] [-read-only] [bucket]", run: whoami}
+	commands["presign"] = command{usage: "[-method GET] [-expires 1h] <key>", run: presign}
+	commands["stat"] = command{usage: "[-json] <key|bucket:key>", run: stat}
+	commands["completion"] = command{usage: "bash | zsh | fish", run: completion, offline: true}
+	commands["__complete"] = command{usage: "[words...]", run: complete, offline: true}
+}
+
+func put(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("put", flag.ContinueOnError)
+	contentType := flags.String("type", "", "content type, guessed from the key by default")
+	partSize := flags.Int64("part-size", objsto.DefaultPartSize, "bytes per part when streaming from stdin, buffered in memory")
+
+	pos, err := parse(flags, env, args, 2, 2)
+	if err != nil {
+		return
+	}
+
+	client, key := object(env, pos[1])
+
+	ctx, mtr := env.meter(ctx, fileSize(pos[0]), client)
+	defer mtr.stop()
+
+	if !env.json {
+		err = upload(ctx, env, pos[0], client, key, *contentType, *partSize)
+		return
+	}
+
+	res := objsto.PutResult{}
+	err = upload(ctx, env, pos[0], client, key, *contentType, *partSize, objsto.WithResult(&res))
+	if err != nil {
+		return
+	}
+
+	err = printLine(env.stdout, res)
+	return
+}
+
+func get(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("get", flag.ContinueOnError)
+	output := flags.String("o", "-", "file to write, - for stdout")
+	rng := flags.String("range", "", "bytes to get, as start-end inclusive or start- to the end")
+	resume := flags.Bool("resume", false, "continue a partial -o file, assuming the object unchanged")
+
+	pos, err := parse(flags, env, args, 1, 1)
+	if err != nil {
+		return
+	}
+	key := pos[0]
+
+	offset, length, err := parseRange(*rng)
+	if err != nil {
+		return
+	}
+
+	if isGlob(key) {
+		if *rng != "" || *resume {
+			return errors.Wrap(errUsage, "-range and -resume need a key rather than a pattern")
+		}
+		return getGlob(ctx, env, key, *output)
+	}
+
+	ctx, mtr := env.meter(ctx, 0, env.client)
+	defer mtr.stop()
+
+	if *output == "-" {
+		if *resume {
+			return errors.Wrap(errUsage, "-resume needs -o")
+		}
+		return getTo(ctx, env.client, key, offset, length, env.stdout)
+	}
+
+	if !*resume {
+		err = download(ctx, env.client, key, offset, length, *output)
+		if err != nil {
+			return
+		}
+		return env.fetched(key, *output)
+	}
+
+	file, err := os.OpenFile(*output, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	fi, err := file.Stat()
+	if err != nil {
+		return
+	}
+	have := fi.Size()
+
+	if length < 0 {
+		var info objsto.ObjectInfo
+		info, err = env.client.Stat(ctx, key)
+		if err != nil {
+			return
+		}
+		length = info.Size - offset
+	}
+	switch {
+	case have == length:
+		return
+	case have > length:
+		return errors.Errorf("%s is larger than the %d bytes to get", *output, length)
+	}
+
+	err = getTo(ctx, env.client, key, offset+have, length-have, file)
+	if err != nil {
+		return
+	}
+
+	err = file.Close()
+	if err != nil {
+		return
+	}
+
+	err = env.fetched(key, *output)
+	return
+}
+
+// getGlob gets the objects matching pattern, one after another to stdout for "-",
+// otherwise into the output directory, refusing keys that would land on the same file.
+func getGlob(ctx context.Context, env *env, pattern, output string) (err error) {
+
+	keys, err := glob(ctx, env.client, pattern)
+	if err != nil {
+		return
+	}
+
+	dir := location{path: output}
+	if output != "-" {
+		if !dir.folder() {
+			return errors.Wrap(errUsage, "a pattern needs -o ending in / or a directory")
+		}
+		err = sameBase(keys)
+		if err != nil {
+			return
+		}
+		err = os.MkdirAll(output, 0755)
+		if err != nil {
+			return
+		}
+	}
+
+	ctx, mtr := env.meter(ctx, -1, env.client)
+	defer mtr.stop()
+
+	for _, key := range keys {
+		if output == "-" {
+			err = getTo(ctx, env.client, key, 0, -1, env.stdout)
+			if err != nil {
+				return
+			}
+			continue
+		}
+
+		target := filepath.Join(output, path.Base(key))
+		err = download(ctx, env.client, key, 0, -1, target)
+		if err != nil {
+			return
+		}
+		err = env.fetched(key, target)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// sameBase fails on keys sharing a base name, which would overwrite one another in a directory.
+func sameBase(keys []string) (err error) {
+
+	seen := map[string]string{}
+	for _, key := range keys {
+		base := path.Base(key)
+		if other, ok := seen[base]; ok {
+			return errors.Errorf("%s and %s would both be got to %s", other, key, base)
+		}
+		seen[base] = key
+	}
+
+	return
+}
+
+// getTo copies length bytes of an object starting at offset to writer, the whole object when
+// offset is zero and length negative.
+func getTo(ctx context.Context, client *objsto.Client, key string, offset, length int64, writer io.Writer) (err error) {
+
+	var reader io.ReadCloser
+	if offset == 0 && length < 0 {
+		reader, err = client.Get(ctx, key)
+	} else {
+		reader, err = client.GetRange(ctx, key, offset, length)
+	}
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	_, err = io.Copy(writer, reader)
+	return
+}
+
+// upload puts a file, or stdin for "-", to key, guessing the content type from key when blank.
+// Stdin, and files too big for a single put, are streamed as a multipart upload of partSize parts.
+func upload(ctx context.Context, env *env, src string, client *objsto.Client, key, contentType string, partSize int64, opts ...objsto.PutOption) (err error) {
+
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(key))
+	}
+
+	if contentType != "" {
+		opts = append(opts, objsto.WithContentType(contentType))
+	}
+
+	if src == "-" {
+		err = client.PutStream(ctx, key, env.stdin, partSize, opts...)
+		return
+	}
+
+	file, err := os.Open(src)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	fi, err := file.Stat()
+	if err != nil {
+		return
+	}
+	if fi.Size() > objsto.MaxPutSize {
+		err = client.PutStream(ctx, key, file, partSize, opts...)
+		return
+	}
+
+	err = client.Put(ctx, key, file, opts...)
+	return
+}
+
+// download gets an object to path, writing alongside then renaming so that a failure leaves no partial file.
+func download(ctx context.Context, client *objsto.Client, key string, offset, length int64, path string) (err error) {
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".objsto-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	err = getTo(ctx, client, key, offset, length, tmp)
+	if err != nil {
+		return
+	}
+	err = tmp.Close()
+	if err != nil {
+		return
+	}
+
+	// temp files are private, unlike the one being got
+	err = os.Chmod(tmp.Name(), 0644)
+	if err != nil {
+		return
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	return
+}
+
+// parseRange parses start-end, inclusive, or start- into offset and length, negative to the end.
+func parseRange(rng string) (offset, length int64, err error) {
+
+	length = -1
+	if rng == "" {
+		return
+	}
+
+	first, last, ok := strings.Cut(rng, "-")
+	if !ok {
+		err = errors.Wrapf(errUsage, "range %q is not start-end", rng)
+		return
+	}
+
+	offset, err = strconv.ParseInt(first, 10, 64)
+	if err != nil || offset < 0 {
+		err = errors.Wrapf(errUsage, "range %q has a bad start", rng)
+		return
+	}
+	if last == "" {
+		return
+	}
+
+	end, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || end < offset {
+		err = errors.Wrapf(errUsage, "range %q has a bad end", rng)
+		return
+	}
+
+	length = end - offset + 1
+	return
+}
+
+func cat(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("cat", flag.ContinueOnError)
+	rng := flags.String("range", "", "bytes to get, as start-end inclusive or start- to the end")
+
+	pos, err := parse(flags, env, args, 1, 1)
+	if err != nil {
+		return
+	}
+
+	offset, length, err := parseRange(*rng)
+	if err != nil {
+		return
+	}
+
+	err = getTo(ctx, env.client, pos[0], offset, length, env.stdout)
+	return
+}
+
+func ls(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("ls", flag.ContinueOnError)
+	long := flags.Bool("l", false, "show size, modification time and etag")
+	recursive := flags.Bool("recursive", false, "list everything under prefix rather than a folder view")
+	flags.BoolVar(recursive, "r", false, "short for -recursive")
+	delimiter := flags.String("d", "/", "delimiter folders are rolled up at")
+	null := flags.Bool("0", false, "end keys with NUL rather than newline, as for xargs -0")
+
+	pos, err := parse(flags, env, args, 0, 1)
+	if err != nil {
+		return
+	}
+
+	input := objsto.ListInput{}
+	if len(pos) == 1 {
+		input.Prefix = pos[0]
+	}
+	if !*recursive {
+		input.Delimiter = *delimiter
+	}
+
+	end := "\n"
+	if *null {
+		end = "\x00"
+	}
+
+	tabs := tabwriter.NewWriter(env.stdout, 1, 0, 2, ' ', 0)
+	defer tabs.Flush()
+
+	pgr := env.client.NewPaginator(input)
+	for pgr.HasMore() {
+		var page objsto.ListPage
+		page, err = pgr.NextPage(ctx)
+		if err != nil {
+			return
+		}
+
+		for _, prefix := range page.CommonPrefixes {
+			if env.json {
+				err = printLine(env.stdout, folderLine{Prefix: prefix})
+				if err != nil {
+					return
+				}
+				continue
+			}
+			if *long {
+				fmt.Fprintf(tabs, "DIR\t\t\t%s\n", prefix)
+				continue
+			}
+			fmt.Fprint(env.stdout, prefix, end)
+		}
+
+		for _, info := range page.Objects {
+			if env.json {
+				err = printLine(env.stdout, info)
+				if err != nil {
+					return
+				}
+				continue
+			}
+			if *long {
+				fmt.Fprintf(tabs, "%d\t%s\t%s\t%s\n",
+					info.Size, info.LastModified.Format(time.RFC3339), info.ETag, info.Key)
+				continue
+			}
+			fmt.Fprint(env.stdout, info.Key, end)
+		}
+	}
+
+	return
+}
+
+func find(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("find", flag.ContinueOnError)
+	name := flags.String("name", "", "regexp matched against the last path segment of keys")
+	newer := flags.String("newer-than", "", "age such as 36h or 7d objects must be modified within")
+	older := flags.String("older-than", "", "age such as 36h or 7d objects must be modified before")
+	larger := flags.String("larger-than", "", "size such as 512K or 1G objects must exceed")
+	smaller := flags.String("smaller-than", "", "size objects must be under")
+	long := flags.Bool("l", false, "show size, modification time and etag")
+	null := flags.Bool("0", false, "end keys with NUL rather than newline, as for xargs -0")
+	del := flags.Bool("delete", false, "delete matches")
+	copyTo := flags.String("copy-to", "", "copy matches into a bucket:prefix/ or directory")
+	concurrency := flags.Int("concurrency", objsync.DefaultConcurrency, "deletes or copies in flight")
+
+	pos, err := parse(flags, env, args, 0, 1)
+	if err != nil {
+		return
+	}
+	if *del && *copyTo != "" {
+		return errors.Wrap(errUsage, "-delete and -copy-to don't go together")
+	}
+
+	client, prefix := env.client, ""
+	if len(pos) == 1 {
+		client, prefix = object(env, pos[0])
+	}
+
+	filter, err := newFilter(*name, *newer, *older, *larger, *smaller)
+	if err != nil {
+		return
+	}
+
+	end := "\n"
+	if *null {
+		end = "\x00"
+	}
+
+	tabs := tabwriter.NewWriter(env.stdout, 1, 0, 2, ' ', 0)
+	matches := []objsto.ObjectInfo{}
+	for info, err := range client.ListObjects(ctx, objsto.ListInput{Prefix: prefix}) {
+		if err != nil {
+			return errors.Wrapf(err, "failed to list %q", prefix)
+		}
+		if !filter.match(info) {
+			continue
+		}
+
+		matches = append(matches, info)
+		if env.json {
+			printLine(env.stdout, info)
+			continue
+		}
+		if *long {
+			fmt.Fprintf(tabs, "%d\t%s\t%s\t%s\n",
+				info.Size, info.LastModified.Format(time.RFC3339), info.ETag, info.Key)
+			continue
+		}
+		fmt.Fprint(env.stdout, info.Key, end)
+	}
+	err = tabs.Flush()
+	if err != nil || len(matches) == 0 {
+		return
+	}
+
+	switch {
+	case *del:
+		keys := make([]string, len(matches))
+		for idx, info := range matches {
+			keys[idx] = info.Key
+		}
+		err = deleteKeys(ctx, env, client, keys, *concurrency)
+	case *copyTo != "":
+		err = copyMatches(ctx, env, client, matches, locate(env, *copyTo), *concurrency)
+	}
+
+	return
+}
+
+// copyMatches copies objects found into dst, a folder.
+func copyMatches(ctx context.Context, env *env, client *objsto.Client, matches []objsto.ObjectInfo, dst location, concurrency int) (err error) {
+
+	if !dst.folder() {
+		return errors.Wrap(errUsage, "-copy-to needs a destination ending in / or a directory")
+	}
+
+	errs := make([]error, len(matches))
+	parallel(concurrency, matches, func(idx int, info objsto.ObjectInfo) {
+		src := location{arg: info.Key, client: client, path: info.Key, remote: true}
+		var target string
+		target, errs[idx] = copyOne(ctx, env, src, dst, "")
+		if errs[idx] != nil {
+			env.failed("copy", info.Key, errs[idx])
+			return
+		}
+		if env.json {
+			printLine(env.stdout, outcome{Key: info.Key, Target: target})
+		}
+	})
+
+	failures := len(slices.DeleteFunc(errs, func(err error) bool { return err == nil }))
+	if failures > 0 {
+		err = errors.Errorf("failed to copy %d of %d", failures, len(matches))
+	}
+	return
+}
+
+// filter matches objects by name, age and size, unset criteria matching all.
+type filter struct {
+	name    *regexp.Regexp
+	after   time.Time
+	before  time.Time
+	larger  int64
+	smaller int64
+}
+
+func newFilter(name, newer, older, larger, smaller string) (flt filter, err error) {
+
+	flt.larger, flt.smaller = -1, -1
+
+	if name != "" {
+		flt.name, err = regexp.Compile(name)
+		if err != nil {
+			return flt, errors.Wrapf(errUsage, "bad -name: %v", err)
+		}
+	}
+
+	now := time.Now()
+	for _, age := range []struct {
+		val  string
+		time *time.Time
+	}{{newer, &flt.after}, {older, &flt.before}} {
+		if age.val == "" {
+			continue
+		}
+		var dur time.Duration
+		dur, err = parseAge(age.val)
+		if err != nil {
+			return
+		}
+		*age.time = now.Add(-dur)
+	}
+
+	for _, size := range []struct {
+		val string
+		n   *int64
+	}{{larger, &flt.larger}, {smaller, &flt.smaller}} {
+		if size.val == "" {
+			continue
+		}
+		*size.n, err = parseSize(size.val)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (flt filter) match(info objsto.ObjectInfo) bool {
+
+	switch {
+	case flt.name != nil && !flt.name.MatchString(path.Base(info.Key)):
+		return false
+	case !flt.after.IsZero() && !info.LastModified.After(flt.after):
+		return false
+	case !flt.before.IsZero() && !info.LastModified.Before(flt.before):
+		return false
+	case flt.larger >= 0 && info.Size <= flt.larger:
+		return false
+	case flt.smaller >= 0 && info.Size >= flt.smaller:
+		return false
+	}
+
+	return true
+}
+
+// parseAge parses a duration, as with time.ParseDuration, or a number of days such as "7d".
+func parseAge(val string) (age time.Duration, err error) {
+
+	if days, ok := strings.CutSuffix(val, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		if err == nil {
+			age = time.Duration(n) * 24 * time.Hour
+			return
+		}
+	}
+
+	age, err = time.ParseDuration(val)
+	if err != nil {
+		err = errors.Wrapf(errUsage, "bad age %q", val)
+	}
+	return
+}
+
+// parseSize parses bytes with an optional binary K, M, G or T suffix, such as "512K".
+func parseSize(val string) (size int64, err error) {
+
+	num, mult := val, int64(1)
+	if idx := strings.IndexAny(strings.ToUpper(val), "KMGT"); idx > 0 {
+		num = val[:idx]
+		mult = int64(1) << (10 * (1 + strings.IndexByte("KMGT", strings.ToUpper(val)[idx])))
+		if rest := strings.ToUpper(val[idx+1:]); rest != "" && rest != "B" && rest != "IB" {
+			return 0, errors.Wrapf(errUsage, "bad size %q", val)
+		}
+	}
+
+	size, err = strconv.ParseInt(num, 10, 64)
+	if err != nil || size < 0 {
+		return 0, errors.Wrapf(errUsage, "bad size %q", val)
+	}
+
+	size *= mult
+	return
+}
+
+func tree(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("tree", flag.ContinueOnError)
+	depth := flags.Int("depth", 3, "folder levels to descend, 0 for all")
+	sizes := flags.Bool("s", false, "show object sizes")
+
+	pos, err := parse(flags, env, args, 0, 1)
+	if err != nil {
+		return
+	}
+
+	client, prefix := env.client, ""
+	if len(pos) == 1 {
+		client, prefix = object(env, pos[0])
+	}
+
+	tw := &treeWriter{
+		client: client,
+		writer: env.stdout,
+		depth:  *depth,
+		sizes:  *sizes,
+		json:   env.json,
+	}
+	if tw.json {
+		err = tw.walk(ctx, prefix, "", 1)
+		return
+	}
+
+	fmt.Fprintln(env.stdout, cmp.Or(prefix, client.Bucket()+":"))
+	err = tw.walk(ctx, prefix, "", 1)
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintf(env.stdout, "\n%d folders, %d objects\n", tw.folders, tw.objects)
+	return
+}
+
+// treeWriter draws a tree of folders and objects, a delimiter listing per folder.
+type treeWriter struct {
+	client  *objsto.Client
+	writer  io.Writer
+	depth   int
+	sizes   bool
+	json    bool
+	folders int
+	objects int
+}
+
+type treeEntry struct {
+	name   string
+	size   int64
+	folder bool
+}
+
+// line is the entry as a json line, with the folder level it's at.
+func (entry treeEntry) line(level int) any {
+
+	if entry.folder {
+		return struct {
+			Prefix string `json:"prefix"`
+			Depth  int    `json:"depth"`
+		}{entry.name, level}
+	}
+
+	return struct {
+		Key   string `json:"key"`
+		Size  int64  `json:"size"`
+		Depth int    `json:"depth"`
+	}{entry.name, entry.size, level}
+}
+
+func (tw *treeWriter) walk(ctx context.Context, prefix, indent string, level int) (err error) {
+
+	entries := []treeEntry{}
+	pgr := tw.client.NewPaginator(objsto.ListInput{Prefix: prefix, Delimiter: "/"})
+	for pgr.HasMore() {
+		var page objsto.ListPage
+		page, err = pgr.NextPage(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "failed to list %q", prefix)
+		}
+
+		for _, folder := range page.CommonPrefixes {
+			entries = append(entries, treeEntry{name: folder, folder: true})
+		}
+		for _, info := range page.Objects {
+			entries = append(entries, treeEntry{name: info.Key, size: info.Size})
+		}
+	}
+	slices.SortFunc(entries, func(a, b treeEntry) int { return strings.Compare(a.name, b.name) })
+
+	for idx, entry := range entries {
+		branch, next := "├── ", "│   "
+		if idx == len(entries)-1 {
+			branch, next = "└── ", "    "
+		}
+
+		if tw.json {
+			err = printLine(tw.writer, entry.line(level))
+			if err != nil {
+				return
+			}
+		} else {
+			label := strings.TrimPrefix(entry.name, prefix)
+			if tw.sizes && !entry.folder {
+				label = fmt.Sprintf("[%s]  %s", bytesize(entry.size), label)
+			}
+			fmt.Fprintf(tw.writer, "%s%s%s\n", indent, branch, label)
+		}
+
+		if !entry.folder {
+			tw.objects++
+			continue
+		}
+		tw.folders++
+
+		if tw.depth == 0 || level < tw.depth {
+			err = tw.walk(ctx, entry.name, indent+next, level+1)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+func du(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("du", flag.ContinueOnError)
+	depth := flags.Int("depth", 1, "folder levels below prefix to total, 0 for only the prefix")
+	human := flags.Bool("h", false, "show sizes as KiB, MiB and so on")
+
+	pos, err := parse(flags, env, args, 0, 1)
+	if err != nil {
+		return
+	}
+
+	client, prefix := env.client, ""
+	if len(pos) == 1 {
+		client, prefix = object(env, pos[0])
+	}
+
+	total := tally{}
+	folders := map[string]*tally{}
+	for info, err := range client.ListObjects(ctx, objsto.ListInput{Prefix: prefix}) {
+		if err != nil {
+			return errors.Wrapf(err, "failed to list %q", prefix)
+		}
+
+		total.add(info.Size)
+		for _, folder := range ancestors(prefix, info.Key, *depth) {
+			if folders[folder] == nil {
+				folders[folder] = &tally{}
+			}
+			folders[folder].add(info.Size)
+		}
+	}
+
+	size := func(n int64) string {
+		if *human {
+			return bytesize(n)
+		}
+		return strconv.FormatInt(n, 10)
+	}
+
+	if env.json {
+		for _, folder := range slices.Sorted(maps.Keys(folders)) {
+			printLine(env.stdout, folders[folder].line(folder))
+		}
+		err = printLine(env.stdout, total.line(prefix))
+		return
+	}
+
+	tabs := tabwriter.NewWriter(env.stdout, 1, 0, 2, ' ', 0)
+	for _, folder := range slices.Sorted(maps.Keys(folders)) {
+		fmt.Fprintf(tabs, "%s\t%d\t%s\n", size(folders[folder].bytes), folders[folder].objects, folder)
+	}
+	fmt.Fprintf(tabs, "%s\t%d\t%s\n", size(total.bytes), total.objects, cmp.Or(prefix, "total"))
+
+	err = tabs.Flush()
+	return
+}
+
+// tally counts objects and their bytes.
+type tally struct {
+	objects int
+	bytes   int64
+}
+
+func (tl *tally) add(size int64) {
+
+	tl.objects++
+	tl.bytes += size
+}
+
+// line is the tally for prefix as a json line.
+func (tl *tally) line(prefix string) any {
+
+	return struct {
+		Prefix  string `json:"prefix"`
+		Objects int    `json:"objects"`
+		Bytes   int64  `json:"bytes"`
+	}{prefix, tl.objects, tl.bytes}
+}
+
+// ancestors are the folders of key below prefix, down to depth levels, as with
+// "logs/2026/" and "logs/2026/03/" for "logs/2026/03/app.log" under "logs/".
+func ancestors(prefix, key string, depth int) (folders []string) {
+
+	segments := strings.Split(strings.TrimPrefix(key, prefix), "/")
+	for idx := 1; idx < len(segments) && idx <= depth; idx++ {
+		folders = append(folders, prefix+strings.Join(segments[:idx], "/")+"/")
+	}
+
+	return
+}
+
+func mb(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("mb", flag.ContinueOnError)
+
+	pos, err := parse(flags, env, args, 0, 1)
+	if err != nil {
+		return
+	}
+
+	err = bucket(env, pos).CreateBucket(ctx)
+	return
+}
+
+func rb(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("rb", flag.ContinueOnError)
+	force := flags.Bool("force", false, "empty the bucket first, deleting objects and aborting uploads")
+
+	pos, err := parse(flags, env, args, 0, 1)
+	if err != nil {
+		return
+	}
+	client := bucket(env, pos)
+
+	if *force {
+		err = empty(ctx, env, client)
+		if err != nil {
+			return
+		}
+	}
+
+	err = client.DeleteBucket(ctx)
+	return
+}
+
+// deleteKeys deletes keys in batches, concurrency at a time, reporting failures to stderr
+// and, for -json, each key deleted to stdout.
+func deleteKeys(ctx context.Context, env *env, client *objsto.Client, keys []string, concurrency int) (err error) {
+
+	batches := slices.Collect(slices.Chunk(keys, objsto.MaxBatchDelete))
+	errs := make([]error, len(batches))
+	failed := make([]map[string]error, len(batches))
+	parallel(concurrency, batches, func(idx int, batch []string) {
+		failed[idx], errs[idx] = client.DeleteObjects(ctx, batch)
+	})
+
+	failures := 0
+	for idx, batch := range batches {
+		if errs[idx] != nil {
+			for _, key := range batch {
+				env.failed("delete", key, errs[idx])
+			}
+			failures += len(batch)
+			continue
+		}
+		for _, key := range batch {
+			ferr, ok := failed[idx][key]
+			switch {
+			case ok:
+				env.failed("delete", key, ferr)
+				failures++
+			case env.json:
+				printLine(env.stdout, outcome{Key: key})
+			}
+		}
+	}
+
+	if failures > 0 {
+		err = errors.Errorf("failed to delete %d of %d objects", failures, len(keys))
+		return
+	}
+	if !env.json {
+		fmt.Fprintf(env.stderr, "deleted %d objects\n", len(keys))
+	}
+	return
+}
+
+// bucket is a client for the bucket given, if any, defaulting to the url's.
+func bucket(env *env, pos []string) *objsto.Client {
+
+	if len(pos) == 0 || pos[0] == env.client.Bucket() {
+		return env.client
+	}
+
+	return env.client.Clone(objsto.WithBucket(pos[0]))
+}
+
+// empty deletes all objects in a bucket and aborts its incomplete uploads.
+// Versions of objects in a versioned bucket are left.
+func empty(ctx context.Context, env *env, client *objsto.Client) (err error) {
+
+	keys := []string{}
+	for info, err := range client.ListObjects(ctx, objsto.ListInput{}) {
+		if err != nil {
+			return errors.Wrapf(err, "failed to list %s", client.Bucket())
+		}
+		keys = append(keys, info.Key)
+	}
+
+	for batch := range slices.Chunk(keys, objsto.MaxBatchDelete) {
+		var failed map[string]error
+		failed, err = client.DeleteObjects(ctx, batch)
+		if err != nil {
+			return
+		}
+		for key, ferr := range failed {
+			return errors.Wrapf(ferr, "failed to delete %q", key)
+		}
+	}
+
+	uploads, err := client.ListUploads(ctx, "")
+	if err != nil {
+		return
+	}
+	for _, upl := range uploads {
+		err = client.AbortUpload(ctx, upl.Key, upl.UploadID)
+		if err != nil {
+			return
+		}
+	}
+
+	if !env.json {
+		fmt.Fprintf(env.stderr, "deleted %d objects and aborted %d uploads\n", len(keys), len(uploads))
+	}
+	return
+}
+
+func rm(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("rm", flag.ContinueOnError)
+	recursive := flags.Bool("recursive", false, "delete everything under each prefix, or key matching a pattern")
+	flags.BoolVar(recursive, "r", false, "short for -recursive")
+	dryRun := flags.Bool("dry-run", false, "list what would be deleted without deleting")
+	force := flags.Bool("f", false, "skip confirming a recursive or pattern delete")
+	concurrency := flags.Int("concurrency", objsync.DefaultConcurrency, "batch deletes in flight")
+
+	keys, err := parse(flags, env, args, 1, -1)
+	if err != nil {
+		return
+	}
+
+	globbed := slices.ContainsFunc(keys, isGlob)
+	if globbed {
+		keys, err = expandGlobs(ctx, env.client, keys)
+		if err != nil {
+			return
+		}
+	}
+
+	if *recursive {
+		keys, err = listAll(ctx, env, keys)
+		if err != nil {
+			return
+		}
+	}
+
+	if *dryRun {
+		for _, key := range keys {
+			if env.json {
+				printLine(env.stdout, outcome{Key: key})
+				continue
+			}
+			fmt.Fprintln(env.stdout, key)
+		}
+		return
+	}
+
+	if (*recursive || globbed) && !*force {
+		if len(keys) == 0 {
+			return
+		}
+		if !confirm(env, fmt.Sprintf("delete %d objects?", len(keys))) {
+			return errors.Errorf("not confirmed, nothing deleted")
+		}
+	}
+
+	if len(keys) == 1 && !*recursive && !globbed {
+		err = env.client.Delete(ctx, keys[0])
+		if err != nil || !env.json {
+			return
+		}
+		err = printLine(env.stdout, outcome{Key: keys[0]})
+		return
+	}
+
+	err = deleteKeys(ctx, env, env.client, keys, *concurrency)
+	return
+}
+
+func cp(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("cp", flag.ContinueOnError)
+	contentType := flags.String("type", "", "content type of an upload, guessed from the key by default")
+	concurrency := flags.Int("concurrency", objsync.DefaultConcurrency, "copies in flight, given several sources")
+
+	pos, err := parse(flags, env, args, 2, -1)
+	if err != nil {
+		return
+	}
+
+	dst := locate(env, pos[len(pos)-1])
+	srcs := []location{}
+	for _, arg := range pos[:len(pos)-1] {
+		src := locate(env, arg)
+		if !src.remote || !isGlob(src.path) {
+			srcs = append(srcs, src)
+			continue
+		}
+
+		var keys []string
+		keys, err = glob(ctx, src.client, src.path)
+		if err != nil {
+			return
+		}
+		for _, key := range keys {
+			srcs = append(srcs, location{arg: key, client: src.client, path: key, remote: true})
+		}
+	}
+
+	if len(srcs) > 1 && !dst.folder() {
+		return errors.Wrap(errUsage, "several sources need a destination ending in / or a directory")
+	}
+
+	clients := []*objsto.Client{dst.client}
+	for _, src := range srcs {
+		switch {
+		case !src.remote && !dst.remote:
+			return errors.Wrap(errUsage, "one of source and destination must be bucket:key")
+		case src.path == "-" && dst.folder():
+			return errors.Wrap(errUsage, "destination key needed for stdin")
+		}
+		clients = append(clients, src.client)
+	}
+
+	ctx, mtr := env.meter(ctx, copyTotal(srcs, dst), remotes(clients...)...)
+	defer mtr.stop()
+
+	if len(srcs) == 1 {
+		var target string
+		target, err = copyOne(ctx, env, srcs[0], dst, *contentType)
+		if err != nil || !env.json || target == "-" {
+			return
+		}
+		err = printLine(env.stdout, outcome{Key: srcs[0].arg, Target: target})
+		return
+	}
+
+	errs := make([]error, len(srcs))
+	parallel(*concurrency, srcs, func(idx int, src location) {
+		target, err := copyOne(ctx, env, src, dst, *contentType)
+		switch {
+		case err != nil:
+			errs[idx] = err
+			if env.json {
+				env.failed("copy", src.arg, err)
+				return
+			}
+			mtr.println("failed to copy %q: %v", src.arg, err)
+		case env.json:
+			if target != "-" {
+				printLine(env.stdout, outcome{Key: src.arg, Target: target})
+			}
+		default:
+			mtr.println("copied %s to %s", src.arg, target)
+		}
+	})
+
+	failures := len(slices.DeleteFunc(errs, func(err error) bool { return err == nil }))
+	if failures > 0 {
+		err = errors.Errorf("failed to copy %d of %d", failures, len(srcs))
+	}
+	return
+}
+
+func synchronize(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	del := flags.Bool("delete", false, "delete what's not in the source")
+	dryRun := flags.Bool("dry-run", false, "report without transferring")
+	checksum := flags.Bool("checksum", false, "compare by sha256 rather than size and modification time")
+	concurrency := flags.Int("concurrency", objsync.DefaultConcurrency, "transfers in flight")
+	asJSON := flags.Bool("json", env.json, "print the report as json")
+
+	pos, err := parse(flags, env, args, 2, 2)
+	if err != nil {
+		return
+	}
+
+	opts := []objsync.Option{objsync.WithConcurrency(*concurrency)}
+	if *del {
+		opts = append(opts, objsync.WithDelete())
+	}
+	if *dryRun {
+		opts = append(opts, objsync.WithDryRun())
+	}
+	if *checksum {
+		opts = append(opts, objsync.WithCompare(objsync.CompareChecksum))
+	}
+
+	srcClient, src, srcRemote := remote(env, pos[0])
+	dstClient, dst, dstRemote := remote(env, pos[1])
+
+	switch {
+	case !srcRemote && !dstRemote:
+		return errors.Wrap(errUsage, "one of source and destination must be bucket:prefix")
+	case srcRemote && dstRemote && src != dst:
+		return errors.Wrap(errUsage, "bucket to bucket sync keeps keys, so source and destination prefix must match")
+	}
+
+	ctx, mtr := env.meter(ctx, -1, remotes(srcClient, dstClient)...)
+	defer mtr.stop()
+
+	var report objsync.Report
+	switch {
+	case !srcRemote:
+		report, err = objsync.Push(ctx, dstClient, src, dst, opts...)
+	case !dstRemote:
+		report, err = objsync.Pull(ctx, srcClient, src, dst, opts...)
+	default:
+		report, err = objsync.Mirror(ctx, srcClient, dstClient, src, opts...)
+	}
+	mtr.stop()
+
+	if *asJSON {
+		encodeErr := printJSON(env.stdout, report)
+		if err == nil {
+			err = encodeErr
+		}
+	} else {
+		for _, failure := range report.Failures {
+			fmt.Fprintf(env.stderr, "failed to sync %q: %s\n", failure.Key, failure.Error)
+		}
+		fmt.Fprintf(env.stdout, "transferred %d, deleted %d, skipped %d, %d bytes in %s\n",
+			len(report.Transferred), len(report.Deleted), report.Skipped, report.Bytes, report.Elapsed.Round(time.Millisecond))
+	}
+
+	if err == nil && len(report.Failures) > 0 {
+		err = errors.Errorf("failed to sync %d objects", len(report.Failures))
+	}
+	return
+}
+
+func tag(ctx context.Context, env *env, args []string) (err error) {
+
+	if len(args) == 0 {
+		return errors.Wrap(errUsage, "tag needs get, set or rm")
+	}
+
+	flags := flag.NewFlagSet("tag "+args[0], flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print tags, or read them from stdin for set, as a json object")
+
+	switch args[0] {
+	case "get":
+		var pos []string
+		pos, err = parse(flags, env, args[1:], 1, 1)
+		if err != nil {
+			return
+		}
+		return getTags(ctx, env, pos[0], *asJSON || env.json)
+	case "set":
+		var pos []string
+		pos, err = parse(flags, env, args[1:], 1, -1)
+		if err != nil {
+			return
+		}
+		return setTags(ctx, env, pos[0], pos[1:], *asJSON)
+	case "rm":
+		var pos []string
+		pos, err = parse(flag.NewFlagSet("tag rm", flag.ContinueOnError), env, args[1:], 1, -1)
+		if err != nil {
+			return
+		}
+		return rmTags(ctx, env, pos[0], pos[1:])
+	}
+
+	return errors.Wrapf(errUsage, "unknown tag action %q", args[0])
+}
+
+func getTags(ctx context.Context, env *env, arg string, asJSON bool) (err error) {
+
+	client, key := object(env, arg)
+
+	tags, err := client.GetTags(ctx, key)
+	if err != nil {
+		return
+	}
+
+	if asJSON {
+		err = printJSON(env.stdout, tags)
+		return
+	}
+	for _, name := range slices.Sorted(maps.Keys(tags)) {
+		fmt.Fprintf(env.stdout, "%s=%s\n", name, tags[name])
+	}
+
+	return
+}
+
+// setTags replaces tags with those given as name=value or, with asJSON, read from stdin.
+func setTags(ctx context.Context, env *env, arg string, pairs []string, asJSON bool) (err error) {
+
+	client, key := object(env, arg)
+
+	tags := map[string]string{}
+	if asJSON {
+		if len(pairs) > 0 {
+			return errors.Wrap(errUsage, "-json reads tags from stdin rather than arguments")
+		}
+		err = json.NewDecoder(env.stdin).Decode(&tags)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode tags from stdin")
+		}
+	}
+	for _, pair := range pairs {
+		name, val, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return errors.Wrapf(errUsage, "tag %q is not name=value", pair)
+		}
+		tags[name] = val
+	}
+
+	err = client.PutTags(ctx, key, tags)
+	return
+}
+
+// rmTags removes the named tags, or all of them when none are.
+func rmTags(ctx context.Context, env *env, arg string, names []string) (err error) {
+
+	client, key := object(env, arg)
+
+	if len(names) == 0 {
+		err = client.DeleteTags(ctx, key)
+		return
+	}
+
+	tags, err := client.GetTags(ctx, key)
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		delete(tags, name)
+	}
+
+	err = client.PutTags(ctx, key, tags)
+	return
+}
+
+// object picks apart a key or bucket:key argument, with a client for the bucket, the url's for a key.
+func object(env *env, arg string) (client *objsto.Client, key string) {
+
+	client, key, ok := remote(env, arg)
+	if !ok {
+		return env.client, arg
+	}
+
+	return
+}
+
+func watch(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	interval := flags.Duration("interval", objsto.DefaultWatchInterval, "time between listings")
+	initial := flags.Bool("initial", false, "report objects already there as created")
+	asJSON := flags.Bool("json", env.json, "print events as json lines")
+
+	pos, err := parse(flags, env, args, 0, 1)
+	if err != nil {
+		return
+	}
+
+	client, prefix := env.client, ""
+	if len(pos) == 1 {
+		client, prefix = object(env, pos[0])
+	}
+
+	opts := []objsto.WatchOption{
+		objsto.WithWatchInterval(*interval),
+		objsto.WithWatchErrorFunc(func(err error) {
+			fmt.Fprintf(env.stderr, "failed to list %q: %v\n", prefix, err)
+		}),
+	}
+	if *initial {
+		opts = append(opts, objsto.WithInitialEvents())
+	}
+	watcher := objsto.NewWatcher(client, prefix, opts...)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- watcher.Run(ctx)
+	}()
+
+	enc := json.NewEncoder(env.stdout)
+	for event := range watcher.Events() {
+		if *asJSON {
+			err = enc.Encode(event)
+			if err != nil {
+				return
+			}
+			continue
+		}
+		fmt.Fprintf(env.stdout, "%s  %-7s  %s\n", time.Now().Format(time.RFC3339), event.Type, event.Info.Key)
+	}
+
+	// interrupted is how watching ends
+	err = <-done
+	if errors.Is(err, context.Canceled) {
+		err = nil
+	}
+	return
+}
+
+func whoami(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("whoami", flag.ContinueOnError)
+	probe := flags.String("key", ".objsto-whoami", "object put, read back and deleted to check write, read and delete")
+	readOnly := flags.Bool("read-only", false, "skip write and delete, reading an object listed instead")
+
+	pos, err := parse(flags, env, args, 0, 1)
+	if err != nil {
+		return
+	}
+	client := bucket(env, pos)
+
+	rpt := &report{
+		Endpoint:  client.Endpoint(),
+		Bucket:    client.Bucket(),
+		Region:    env.config.Region,
+		AccessKey: env.config.AccessKey,
+	}
+
+	problems := []string{}
+	if env.config.Region == "" {
+		problems = append(problems, "no region")
+	}
+	if env.config.AccessKey == "" {
+		problems = append(problems, "no access key")
+	}
+	if env.config.SecretKey == "" && env.config.SecretFile == "" {
+		problems = append(problems, "no secret")
+	}
+	if len(problems) > 0 {
+		rpt.fail("config", errors.New(strings.Join(problems, ", ")))
+	} else {
+		rpt.pass("config")
+	}
+
+	exists, err := client.BucketExists(ctx)
+	switch {
+	case err != nil:
+		rpt.fail("bucket", err)
+	case !exists:
+		rpt.fail("bucket", errors.Wrapf(objsto.ErrNotFound, "no bucket %q", client.Bucket()))
+	default:
+		rpt.pass("bucket")
+	}
+
+	// an object listed to read when not writing one
+	var listed string
+	page, err := client.NewPaginator(objsto.ListInput{MaxKeys: 100}).NextPage(ctx)
+	if err != nil {
+		rpt.fail("list", err)
+	} else {
+		rpt.pass("list")
+		idx := slices.IndexFunc(page.Objects, func(info objsto.ObjectInfo) bool { return info.Size > 0 })
+		if idx >= 0 {
+			listed = page.Objects[idx].Key
+		}
+	}
+
+	written := false
+	if *readOnly {
+		rpt.skip("write", "-read-only")
+	} else {
+		err = client.PutString(ctx, *probe, "objsto whoami\n", objsto.WithContentType("text/plain"))
+		if err != nil {
+			rpt.fail("write", err)
+		} else {
+			rpt.pass("write")
+			written = true
+		}
+	}
+
+	readKey := listed
+	if written {
+		readKey = *probe
+	}
+	if readKey == "" {
+		rpt.skip("read", "nothing to read")
+	} else {
+		var reader io.ReadCloser
+		reader, err = client.GetRange(ctx, readKey, 0, 1)
+		if err == nil {
+			_, err = io.Copy(io.Discard, reader)
+			reader.Close()
+		}
+		rpt.check("read", errors.Wrapf(err, "failed to read %q", readKey))
+	}
+
+	// only ever deleting the probe, never something found
+	if !written {
+		rpt.skip("delete", "nothing written")
+	} else {
+		rpt.check("delete", client.Delete(ctx, *probe))
+	}
+
+	if env.json {
+		err = printJSON(env.stdout, rpt)
+	} else {
+		err = rpt.print(env.stdout)
+	}
+	if err != nil {
+		return
+	}
+
+	failures := 0
+	for _, chk := range rpt.Checks {
+		if chk.Status == "failed" {
+			failures++
+		}
+	}
+	if failures > 0 {
+		err = errors.Errorf("%d of %d checks failed", failures, len(rpt.Checks))
+	}
+	return
+}
+
+// report is who the url connects as and which of what they might do works.
+type report struct {
+	Endpoint  string  `json:"endpoint"`
+	Bucket    string  `json:"bucket"`
+	Region    string  `json:"region"`
+	AccessKey string  `json:"access_key"`
+	Checks    []check `json:"checks"`
+}
+
+// check is the outcome of trying something, one of ok, failed and skipped, with why when not ok.
+type check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func (rpt *report) pass(name string) {
+
+	rpt.Checks = append(rpt.Checks, check{Name: name, Status: "ok"})
+}
+
+func (rpt *report) fail(name string, err error) {
+
+	rpt.Checks = append(rpt.Checks, check{Name: name, Status: "failed", Detail: err.Error()})
+}
+
+func (rpt *report) skip(name, why string) {
+
+	rpt.Checks = append(rpt.Checks, check{Name: name, Status: "skipped", Detail: why})
+}
+
+func (rpt *report) check(name string, err error) {
+
+	if err != nil {
+		rpt.fail(name, err)
+		return
+	}
+	rpt.pass(name)
+}
+
+func (rpt *report) print(writer io.Writer) (err error) {
+
+	tabs := tabwriter.NewWriter(writer, 1, 0, 2, ' ', 0)
+	fmt.Fprintf(tabs, "endpoint:\t%s\n", rpt.Endpoint)
+	fmt.Fprintf(tabs, "bucket:\t%s\n", rpt.Bucket)
+	fmt.Fprintf(tabs, "region:\t%s\n", rpt.Region)
+	fmt.Fprintf(tabs, "access key:\t%s\n", rpt.AccessKey)
+	fmt.Fprintln(tabs)
+	for _, chk := range rpt.Checks {
+		detail, _, _ := strings.Cut(chk.Detail, "\n")
+		fmt.Fprintf(tabs, "%s\t%s\t%s\n", chk.Name, chk.Status, detail)
+	}
+
+	err = tabs.Flush()
+	return
+}
+
+func presign(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("presign", flag.ContinueOnError)
+	method := flags.String("method", "GET", "method allowed")
+	expires := flags.Duration("expires", time.Hour, "time until expiry")
+
+	pos, err := parse(flags, env, args, 1, 1)
+	if err != nil {
+		return
+	}
+
+	uri, err := env.client.Presign(ctx, strings.ToUpper(*method), pos[0], *expires)
+	if err != nil {
+		return
+	}
+
+	if env.json {
+		err = printLine(env.stdout, struct {
+			URL     string    `json:"url"`
+			Method  string    `json:"method"`
+			Expires time.Time `json:"expires"`
+		}{uri, strings.ToUpper(*method), time.Now().Add(*expires).UTC()})
+		return
+	}
+
+	fmt.Fprintln(env.stdout, uri)
+	return
+}
+
+func stat(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("stat", flag.ContinueOnError)
+	asJSON := flags.Bool("json", env.json, "print as json")
+
+	pos, err := parse(flags, env, args, 1, 1)
+	if err != nil {
+		return
+	}
+
+	client, key := object(env, pos[0])
+
+	info, err := client.Stat(ctx, key)
+	if err != nil {
+		return
+	}
+
+	if *asJSON {
+		err = printJSON(env.stdout, info)
+		return
+	}
+
+	tabs := tabwriter.NewWriter(env.stdout, 1, 0, 2, ' ', 0)
+	fmt.Fprintf(tabs, "key:\t%s\n", info.Key)
+	fmt.Fprintf(tabs, "size:\t%d\n", info.Size)
+	fmt.Fprintf(tabs, "modified:\t%s\n", info.LastModified.Format(time.RFC3339))
+	fmt.Fprintf(tabs, "etag:\t%s\n", info.ETag)
+	optional := []struct{ name, val string }{
+		{"content-type", info.ContentType},
+		{"content-encoding", info.ContentEncoding},
+		{"storage-class", info.StorageClass},
+		{"sha256", info.Checksum},
+	}
+	for _, field := range optional {
+		if field.val != "" {
+			fmt.Fprintf(tabs, "%s:\t%s\n", field.name, field.val)
+		}
+	}
+	if info.EncodedSize > 0 {
+		fmt.Fprintf(tabs, "encoded-size:\t%d\n", info.EncodedSize)
+	}
+	for _, name := range slices.Sorted(maps.Keys(info.Metadata)) {
+		fmt.Fprintf(tabs, "meta %s:\t%s\n", name, info.Metadata[name])
+	}
+
+	err = tabs.Flush()
+	return
+}
+
+// remote picks apart a bucket:key argument, with "s3:" or ":" for the configured bucket,
+// giving a client for the bucket and the key.
+// Arguments without a colon, or with a slash before it, are local paths.
+func remote(env *env, arg string) (client *objsto.Client, key string, ok bool) {
+
+	bucket, key, ok := strings.Cut(arg, ":")
+	if !ok || strings.Contains(bucket, "/") {
+		return nil, arg, false
+	}
+
+	client = env.client
+	if bucket != "" && bucket != "s3" && bucket != client.Bucket() {
+		client = client.Clone(objsto.WithBucket(bucket))
+	}
+
+	return
+}
+
+// location is a local path or, when remote, an object with the client for its bucket, as given by arg.
+type location struct {
+	arg    string
+	client *objsto.Client
+	path   string
+	remote bool
+}
+
+// locate picks apart an argument per remote.
+func locate(env *env, arg string) location {
+
+	client, path, ok := remote(env, arg)
+	return location{arg: arg, client: client, path: path, remote: ok}
+}
+
+// folder is true for a prefix ending in "/" or an existing directory or one ending in a separator.
+func (loc location) folder() bool {
+
+	if loc.remote {
+		return loc.path == "" || strings.HasSuffix(loc.path, "/")
+	}
+
+	fi, err := os.Stat(loc.path)
+	return (err == nil && fi.IsDir()) || strings.HasSuffix(loc.path, string(filepath.Separator))
+}
+
+// copyOne copies src to dst, into it when a folder, giving where it went.
+func copyOne(ctx context.Context, env *env, src, dst location, contentType string) (target string, err error) {
+
+	switch {
+	case !src.remote:
+		target = dst.path
+		if dst.folder() {
+			target += filepath.Base(src.path)
+		}
+		err = upload(ctx, env, src.path, dst.client, target, contentType, 0)
+	case !dst.remote:
+		if dst.path == "-" {
+			return dst.path, getTo(ctx, src.client, src.path, 0, -1, env.stdout)
+		}
+		target = dst.path
+		if dst.folder() {
+			err = os.MkdirAll(dst.path, 0755)
+			if err != nil {
+				return
+			}
+			target = filepath.Join(dst.path, path.Base(src.path))
+		}
+		err = download(ctx, src.client, src.path, 0, -1, target)
+	default:
+		target = dst.path
+		if dst.folder() {
+			target += path.Base(src.path)
+		}
+		err = dst.client.Copy(ctx, src.client, src.path, target)
+	}
+
+	return
+}
+
+// copyTotal is the bytes to be copied, with a single download's to be learned and otherwise unknown
+// unless all are uploads, per meter.
+func copyTotal(srcs []location, dst location) (total int64) {
+
+	switch {
+	case !dst.remote && len(srcs) == 1:
+		return 0
+	case !dst.remote:
+		return -1
+	}
+
+	for _, src := range srcs {
+		size := fileSize(src.path)
+		if src.remote || size < 0 {
+			return -1
+		}
+		total += size
+	}
+
+	return
+}
+
+// parallel calls fn with each item and its index, n at a time, returning when all are done.
+func parallel[T any](n int, items []T, fn func(int, T)) {
+
+	sem := make(chan struct{}, max(n, 1))
+	wg := sync.WaitGroup{}
+
+	for idx, item := range items {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			fn(idx, item)
+		})
+	}
+
+	wg.Wait()
+}
+
+// remotes are the distinct clients that aren't nil, as from remote.
+func remotes(clients ...*objsto.Client) []*objsto.Client {
+
+	distinct := []*objsto.Client{}
+	for _, client := range clients {
+		if client != nil && !slices.Contains(distinct, client) {
+			distinct = append(distinct, client)
+		}
+	}
+
+	return distinct
+}
+
+// fileSize is the size of a file, -1 for stdin or when unknown.
+func fileSize(path string) int64 {
+
+	fi, err := os.Stat(path)
+	if path == "-" || err != nil {
+		return -1
+	}
+
+	return fi.Size()
+}
+
+// isGlob is true when arg has any of the metacharacters matched against keys per path.Match,
+// "*" matching within a path segment.
+func isGlob(arg string) bool {
+
+	return strings.ContainsAny(arg, "*?[")
+}
+
+// glob lists the keys matching pattern, listing from the prefix before its first metacharacter
+// and filtering here, failing with ErrNotFound when none match.
+func glob(ctx context.Context, client *objsto.Client, pattern string) (keys []string, err error) {
+
+	_, err = path.Match(pattern, "")
+	if err != nil {
+		err = errors.Wrapf(errUsage, "bad pattern %q", pattern)
+		return
+	}
+	prefix := pattern[:strings.IndexAny(pattern, "*?[\\")]
+
+	keys = []string{}
+	for info, err := range client.ListObjects(ctx, objsto.ListInput{Prefix: prefix}) {
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list %q", prefix)
+		}
+		if ok, _ := path.Match(pattern, info.Key); ok {
+			keys = append(keys, info.Key)
+		}
+	}
+
+	if len(keys) == 0 {
+		err = errors.Wrapf(objsto.ErrNotFound, "no objects match %q", pattern)
+	}
+	return
+}
+
+// expandGlobs replaces args that are patterns with the keys matching them.
+func expandGlobs(ctx context.Context, client *objsto.Client, args []string) (keys []string, err error) {
+
+	for _, arg := range args {
+		if !isGlob(arg) {
+			keys = append(keys, arg)
+			continue
+		}
+
+		var matched []string
+		matched, err = glob(ctx, client, arg)
+		if err != nil {
+			return
+		}
+		keys = append(keys, matched...)
+	}
+
+	return
+}
+
+// listAll lists the keys of all objects under each prefix.
+func listAll(ctx context.Context, env *env, prefixes []string) (keys []string, err error) {
+
+	keys = []string{}
+	for _, prefix := range prefixes {
+		for info, err := range env.client.ListObjects(ctx, objsto.ListInput{Prefix: prefix}) {
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to list %q", prefix)
+			}
+			keys = append(keys, info.Key)
+		}
+	}
+
+	return
+}
+
+// confirm asks a yes or no question on stderr, reading the answer from stdin and taking no answer as no.
+func confirm(env *env, question string) bool {
+
+	fmt.Fprintf(env.stderr, "%s [y/N] ", question)
+
+	answer, _ := bufio.NewReader(env.stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+)
+
+// completeTimeout bounds listing keys to complete, keeping the shell responsive.
+const completeTimeout = 3 * time.Second
+
+var (
+	// flagPattern finds a command's flags in its usage, with a placeholder for flags taking a value.
+	flagPattern = regexp.MustCompile(`(?:^|[\s\[|])-([a-z0-9][\w-]*)(?: ([^\s\[\]|<-][^\s\]|]*))?`)
+	// actionPattern finds a command's leading words in its usage, as with tag's get, set and rm.
+	actionPattern = regexp.MustCompile(`(?:^|\| )([a-z]+)(?: |$)`)
+)
+
+// scripts are completions per shell, each handing the words typed to __complete.
+var scripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+func completion(ctx context.Context, env *env, args []string) (err error) {
+
+	flags := flag.NewFlagSet("completion", flag.ContinueOnError)
+
+	pos, err := parse(flags, env, args, 1, 1)
+	if err != nil {
+		return
+	}
+
+	script, ok := scripts[pos[0]]
+	if !ok {
+		return errors.Wrapf(errUsage, "no completion for %q", pos[0])
+	}
+
+	_, err = fmt.Fprint(env.stdout, script)
+	return
+}
+
+// complete prints candidates for the last of args, the words typed after objsto.
+// Keys are listed with the url's client, from -url or OBJSTO_URL, when there is one.
+func complete(ctx context.Context, env *env, args []string) (err error) {
+
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	words, cur := args[:len(args)-1], args[len(args)-1]
+
+	// past global flags to the command
+	idx := 0
+	for idx < len(words) && strings.HasPrefix(words[idx], "-") {
+		if takesValue(env.flags.Lookup(strings.TrimLeft(words[idx], "-"))) && !strings.Contains(words[idx], "=") {
+			idx++
+		}
+		idx++
+	}
+
+	var candidates []string
+	switch {
+	case idx >= len(words) && strings.HasPrefix(cur, "-"):
+		env.flags.VisitAll(func(fl *flag.Flag) {
+			candidates = append(candidates, "-"+fl.Name)
+		})
+	case idx >= len(words):
+		for _, name := range slices.Sorted(maps.Keys(commands)) {
+			if !strings.HasPrefix(name, "_") {
+				candidates = append(candidates, name)
+			}
+		}
+	default:
+		candidates = completeArg(ctx, env, commands[words[idx]].usage, words[idx+1:], cur)
+	}
+
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, cur) {
+			fmt.Fprintln(env.stdout, candidate)
+		}
+	}
+
+	return
+}
+
+// completeArg gives candidates for a command's arg, from its usage and keys listed.
+func completeArg(ctx context.Context, env *env, usage string, words []string, cur string) (candidates []string) {
+
+	valued := map[string]bool{}
+	for _, match := range flagPattern.FindAllStringSubmatch(usage, -1) {
+		valued["-"+match[1]] = match[2] != ""
+		candidates = append(candidates, "-"+match[1])
+	}
+	if strings.HasPrefix(cur, "-") {
+		return
+	}
+	candidates = nil
+
+	positional := 0
+	for idx := 0; idx < len(words); idx++ {
+		switch {
+		case valued[words[idx]]:
+			idx++
+		case !strings.HasPrefix(words[idx], "-"):
+			positional++
+		}
+	}
+	if len(words) > 0 && valued[words[len(words)-1]] {
+		// a flag's value, left to the shell
+		return
+	}
+
+	actions := actionPattern.FindAllStringSubmatch(usage, -1)
+	if positional == 0 && len(actions) > 0 {
+		for _, match := range actions {
+			candidates = append(candidates, match[1])
+		}
+		return
+	}
+
+	// local paths are left to the shell
+	if strings.HasPrefix(cur, ".") || strings.HasPrefix(cur, "/") || strings.HasPrefix(cur, "~") {
+		return
+	}
+
+	candidates = completeKeys(ctx, env, cur)
+	return
+}
+
+// completeKeys lists keys and folders starting with cur, any bucket: included, ignoring errors.
+func completeKeys(ctx context.Context, env *env, cur string) (candidates []string) {
+
+	if env.client == nil {
+		return
+	}
+
+	client, prefix, ok := remote(env, cur)
+	if !ok {
+		client, prefix = env.client, cur
+	}
+	lead := strings.TrimSuffix(cur, prefix)
+
+	ctx, cancel := context.WithTimeout(ctx, completeTimeout)
+	defer cancel()
+
+	page, err := client.NewPaginator(objsto.ListInput{Prefix: prefix, Delimiter: "/"}).NextPage(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, folder := range page.CommonPrefixes {
+		candidates = append(candidates, lead+folder)
+	}
+	for _, info := range page.Objects {
+		candidates = append(candidates, lead+info.Key)
+	}
+
+	return
+}
+
+// takesValue is true for a flag other than a bool.
+func takesValue(fl *flag.Flag) bool {
+
+	if fl == nil {
+		return false
+	}
+
+	bf, ok := fl.Value.(interface{ IsBoolFlag() bool })
+	return !ok || !bf.IsBoolFlag()
+}
+
+const bashCompletion = `# bash completion for objsto, as with: source <(objsto completion bash)
+
+_objsto() {
+	local line=${COMP_LINE:0:COMP_POINT}
+	local -a words
+	read -ra words <<< "$line"
+	[[ $line == *" " ]] && words+=("")
+	local cur=${words[-1]}
+
+	local IFS=$'\n'
+	COMPREPLY=($(objsto __complete "${words[@]:1}" 2>/dev/null))
+
+	# bash splits words at colons, as in bucket:key
+	if [[ $cur == *:* && $COMP_WORDBREAKS == *:* ]]; then
+		local colon=${cur%"${cur##*:}"}
+		COMPREPLY=("${COMPREPLY[@]#"$colon"}")
+	fi
+	[[ ${#COMPREPLY[@]} -eq 1 && ${COMPREPLY[0]} == */ ]] && compopt -o nospace
+}
+
+complete -o default -F _objsto objsto
+`
+
+const zshCompletion = `#compdef objsto
+# zsh completion for objsto, as with: source <(objsto completion zsh)
+
+_objsto() {
+	local -a candidates
+	candidates=(${(f)"$(objsto __complete "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+	if (( ${#candidates} == 0 )); then
+		_files
+		return
+	fi
+
+	compadd -Q -S '' -- ${(M)candidates:#*/}
+	compadd -Q -- ${candidates:#*/}
+}
+
+if [[ $funcstack[1] == _objsto ]]; then
+	_objsto "$@"
+else
+	compdef _objsto objsto
+fi
+`
+
+const fishCompletion = `# fish completion for objsto, as with: objsto completion fish | source
+
+function __objsto_complete
+	set -l words (commandline -opc) (commandline -ct)
+	objsto __complete $words[2..-1] 2>/dev/null
+end
+
+complete -c objsto -a '(__objsto_complete)'
+`
@@ -0,0 +1,259 @@
+// Command objsto is a minimal s3cmd built on the objsto library.
+//
+// Connection info comes from an s3:// url, as parsed by objsto.ParseURL,
+// given with -url or in the OBJSTO_URL environment variable.
+//
+// The whoami command checks the url, and which of list, read, write and delete its credentials allow.
+//
+// Commands taking both local paths and objects, such as cp, spell objects as bucket:key,
+// with s3:key for the url's bucket.
+// Keys given to get, rm and cp can be patterns such as logs/2025-01-*.gz, matched per path.Match
+// against a listing of the prefix before the first metacharacter.
+//
+// With -json, results are printed as a json object, or json lines for listings and
+// for each of several objects deleted or copied, and errors are a json line on stderr
+// with the exit code.
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var (
+	version string
+	release string
+)
+
+// exit codes
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitNotFound = 3
+)
+
+// errUsage is the cause of errors for bad arguments.
+var errUsage = errors.New("usage")
+
+// command is a subcommand, run with its arguments, flags and all.
+// Commands named with a leading underscore are for scripts and go unlisted.
+type command struct {
+	usage   string
+	run     func(ctx context.Context, env *env, args []string) error
+	offline bool // runs without a client when there's no good url, as for completion
+}
+
+var commands = map[string]command{}
+
+// env is what commands run with.
+type env struct {
+	client   *objsto.Client
+	config   *objsto.Config
+	flags    *flag.FlagSet
+	stdin    io.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+	progress bool
+	json     bool
+}
+
+func main() {
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+
+	os.Exit(code)
+}
+
+// run runs a command, with client options after the defaults, as for a test's http client.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer, opts ...objsto.ClientOption) int {
+
+	// shared by transfers running in parallel and the debug log
+	terminal := isTerminal(stderr)
+	stdout, stderr = &syncWriter{writer: stdout}, &syncWriter{writer: stderr}
+
+	flags := flag.NewFlagSet("objsto", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { usage(flags) }
+
+	// default after parsing, keeping credentials out of usage
+	dsn := flags.String("url", "", "s3://access:secret@host/bucket?region=... url, defaulting to $OBJSTO_URL")
+	prefix := flags.String("prefix", "", "key prefix added to objects")
+	timeout := flags.Duration("timeout", 0, "per operation timeout, zero for none")
+	retries := flags.Int("retries", 2, "retries per request after the first attempt")
+	debug := flags.Bool("debug", false, "log requests to stderr")
+	asJSON := flags.Bool("json", false, "print results as json, listings as json lines, and errors as json on stderr")
+	quiet := flags.Bool("quiet", false, "hide transfer progress, shown when stderr is a terminal and not -json")
+	flags.BoolVar(quiet, "q", false, "short for -quiet")
+	showVersion := flags.Bool("version", false, "show version")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return exitUsage
+	}
+	if *showVersion {
+		fmt.Fprintf(stdout, "objsto %s %s\n", version, release)
+		return exitOK
+	}
+
+	args = flags.Args()
+	if len(args) == 0 {
+		usage(flags)
+		return exitUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "error: unknown command %q\n", args[0])
+		usage(flags)
+		return exitUsage
+	}
+
+	raw := cmp.Or(*dsn, os.Getenv("OBJSTO_URL"))
+	client, cfg, err := newClient(raw, *prefix, *timeout, *retries, *debug, stderr, opts)
+	switch {
+	case err != nil && !cmd.offline:
+		fmt.Fprintf(stderr, "error: %s\n", redactSecret(err.Error(), raw))
+		return exitUsage
+	case err == nil:
+		defer client.Close()
+	}
+
+	env := &env{
+		client:   client,
+		config:   cfg,
+		flags:    flags,
+		stdin:    stdin,
+		stdout:   stdout,
+		stderr:   stderr,
+		progress: !*quiet && !*debug && !*asJSON && terminal,
+		json:     *asJSON,
+	}
+
+	err = cmd.run(ctx, env, args[1:])
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+
+	code := exitError
+	switch {
+	case errors.Is(err, errUsage):
+		code = exitUsage
+	case errors.Is(err, objsto.ErrNotFound):
+		code = exitNotFound
+	}
+
+	switch {
+	case *asJSON:
+		printLine(stderr, map[string]any{"error": err.Error(), "exit": code})
+	case code == exitUsage:
+		fmt.Fprintf(stderr, "error: %v\nusage: objsto %s %s\n", err, args[0], cmd.usage)
+	default:
+		fmt.Fprintf(stderr, "error: %v\n", err)
+	}
+	return code
+}
+
+func newClient(dsn, prefix string, timeout time.Duration, retries int, debug bool, stderr io.Writer, extra []objsto.ClientOption) (client *objsto.Client, cfg *objsto.Config, err error) {
+
+	if dsn == "" {
+		err = errors.Errorf("no url, set -url or OBJSTO_URL")
+		return
+	}
+
+	cfg, err = objsto.ParseURL(dsn)
+	if err != nil {
+		return
+	}
+
+	opts := []objsto.ClientOption{
+		objsto.WithKeyPrefix(prefix),
+		objsto.WithTimeout(timeout),
+		objsto.WithUserAgent("objsto-cli/" + version),
+		objsto.WithRetryPolicy(objsto.Backoff{MaxAttempts: retries + 1, Base: 100 * time.Millisecond, Max: 5 * time.Second}),
+	}
+	if debug {
+		lgr := slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		opts = append(opts, objsto.WithLogger(objsto.NewSlogLogger(lgr)))
+	}
+
+	client = objsto.New(cfg, append(opts, extra...)...)
+	return
+}
+
+// redactSecret masks the secret in the userinfo of dsn wherever msg has it, plain or escaped,
+// as when an error quotes a url too malformed to parse.
+func redactSecret(msg, dsn string) string {
+
+	_, rest, _ := strings.Cut(dsn, "://")
+	authority, _, _ := strings.Cut(rest, "/")
+	at := strings.LastIndex(authority, "@")
+	if at < 0 {
+		return msg
+	}
+
+	_, secret, _ := strings.Cut(authority[:at], ":")
+	if secret == "" {
+		return msg
+	}
+
+	msg = strings.ReplaceAll(msg, secret, "REDACTED")
+	if unescaped, err := url.PathUnescape(secret); err == nil && unescaped != "" {
+		msg = strings.ReplaceAll(msg, unescaped, "REDACTED")
+	}
+
+	return msg
+}
+
+func usage(flags *flag.FlagSet) {
+
+	out := flags.Output()
+	fmt.Fprintf(out, "usage: objsto [flags] <command> [args]\n\ncommands:\n")
+	for _, name := range slices.Sorted(maps.Keys(commands)) {
+		if strings.HasPrefix(name, "_") {
+			continue
+		}
+		fmt.Fprintf(out, "  %-8s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintf(out, "\nflags:\n")
+	flags.PrintDefaults()
+	fmt.Fprintf(out, "\nexit codes: %d ok, %d error, %d usage, %d not found\n", exitOK, exitError, exitUsage, exitNotFound)
+}
+
+// parse parses a command's flags, checking the count of positional args is within min and max, -1 for any.
+func parse(flags *flag.FlagSet, env *env, args []string, min, max int) (pos []string, err error) {
+
+	flags.SetOutput(env.stderr)
+
+	err = flags.Parse(args)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			err = errors.Wrap(errUsage, err.Error())
+		}
+		return
+	}
+
+	pos = flags.Args()
+	if len(pos) < min || (max >= 0 && len(pos) > max) {
+		err = errors.Wrapf(errUsage, "wrong number of arguments: %s", strings.Join(pos, " "))
+	}
+
+	return
+}
@@ -0,0 +1,1321 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/objstotest"
+	"github.com/clarktrimble/objsto/objsync"
+)
+
+func TestObjsto(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Objsto Suite")
+}
+
+const (
+	testSecret = "very-secret-key"
+	testURL    = "s3://test-access:" + testSecret + "@objsto.test/" + objstotest.DefaultBucket + "?scheme=http&region=us-east-1"
+)
+
+// serveDoer sends requests straight to a handler, in process.
+type serveDoer struct {
+	http.Handler
+}
+
+func (sd serveDoer) Do(request *http.Request) (*http.Response, error) {
+
+	// as the server would see it
+	request = request.Clone(request.Context())
+	request.RequestURI = request.URL.RequestURI()
+	if request.Body == nil {
+		request.Body = http.NoBody
+	}
+
+	rec := httptest.NewRecorder()
+	sd.ServeHTTP(rec, request)
+	return rec.Result(), nil
+}
+
+type doerFunc func(request *http.Request) (*http.Response, error)
+
+func (fn doerFunc) Do(request *http.Request) (*http.Response, error) {
+	return fn(request)
+}
+
+var _ = Describe("run", func() {
+	var (
+		ctx    = context.Background()
+		srv    *objstotest.Server
+		stdin  *bytes.Buffer
+		stdout *bytes.Buffer
+		stderr *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		srv = objstotest.New(objstotest.WithCredentials("test-access", testSecret))
+		DeferCleanup(srv.Close)
+
+		stdin = &bytes.Buffer{}
+		stdout = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+
+		GinkgoT().Setenv("OBJSTO_URL", testURL)
+	})
+
+	cli := func(args ...string) int {
+
+		stdout.Reset()
+		stderr.Reset()
+		return run(ctx, args, stdin, stdout, stderr, objsto.WithHTTPClient(serveDoer{srv}), objsto.WithRetryPolicy(objsto.NoRetry{}))
+	}
+
+	putString := func(key, content string) {
+
+		stdin.WriteString(content)
+		Expect(cli("put", "-", key)).To(Equal(exitOK), stderr.String())
+	}
+
+	Describe("argument parsing", func() {
+
+		It("shows usage given no command", func() {
+			Expect(cli()).To(Equal(exitUsage))
+			Expect(stderr.String()).To(HavePrefix("usage: objsto [flags] <command> [args]"))
+			Expect(stderr.String()).To(ContainSubstring("  cat      [-range start-end] <key>"))
+			Expect(stderr.String()).ToNot(ContainSubstring("__complete"))
+		})
+
+		It("refuses an unknown command", func() {
+			Expect(cli("frob")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(HavePrefix(`error: unknown command "frob"`))
+		})
+
+		It("refuses an unknown flag", func() {
+			Expect(cli("-frob", "ls")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring("flag provided but not defined: -frob"))
+		})
+
+		It("refuses the wrong number of arguments, with the command's usage", func() {
+			Expect(cli("cat", "a.txt", "b.txt")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(Equal("error: wrong number of arguments: a.txt b.txt: usage\nusage: objsto cat [-range start-end] <key>\n"))
+		})
+
+		It("refuses a bad command flag value", func() {
+			Expect(cli("cat", "-range", "backwards", "a.txt")).To(Equal(exitUsage))
+		})
+
+		It("takes the url from -url over OBJSTO_URL", func() {
+			putString("a.txt", "alpha")
+			GinkgoT().Setenv("OBJSTO_URL", "s3://test-access:"+testSecret+"@objsto.test/nowhere?scheme=http&region=us-east-1")
+
+			Expect(cli("cat", "a.txt")).ToNot(Equal(exitOK))
+			Expect(cli("-url", testURL, "cat", "a.txt")).To(Equal(exitOK))
+			Expect(stdout.String()).To(Equal("alpha"))
+		})
+
+		It("adds the prefix to keys", func() {
+			stdin.WriteString("alpha")
+			Expect(cli("-prefix", "pre/", "put", "-", "a.txt")).To(Equal(exitOK), stderr.String())
+
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(Equal([]string{"pre/a.txt"}))
+		})
+
+		It("retries as many times as asked", func() {
+			attempts := 0
+			flaky := doerFunc(func(request *http.Request) (*http.Response, error) {
+				attempts++
+				rec := httptest.NewRecorder()
+				rec.WriteHeader(http.StatusServiceUnavailable)
+				return rec.Result(), nil
+			})
+
+			code := run(ctx, []string{"-retries", "1", "cat", "a.txt"}, stdin, stdout, stderr, objsto.WithHTTPClient(flaky))
+			Expect(code).To(Equal(exitError))
+			Expect(attempts).To(Equal(2))
+		})
+
+		It("shows the version", func() {
+			Expect(cli("-version")).To(Equal(exitOK))
+			Expect(stdout.String()).To(HavePrefix("objsto "))
+		})
+	})
+
+	Describe("exit codes", func() {
+
+		It("is zero on success", func() {
+			putString("a.txt", "alpha")
+
+			Expect(cli("cat", "a.txt")).To(Equal(exitOK))
+			Expect(stdout.String()).To(Equal("alpha"))
+		})
+
+		It("is not found for a missing object", func() {
+			Expect(cli("cat", "missing.txt")).To(Equal(exitNotFound))
+			Expect(stderr.String()).To(HavePrefix("error: "))
+		})
+
+		It("is usage without a url", func() {
+			GinkgoT().Setenv("OBJSTO_URL", "")
+
+			Expect(cli("ls")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(Equal("error: no url, set -url or OBJSTO_URL\n"))
+		})
+
+		It("is an error otherwise", func() {
+			Expect(cli("put", filepath.Join(GinkgoT().TempDir(), "missing.txt"), "a.txt")).To(Equal(exitError))
+		})
+
+		It("is an error when the credentials are refused", func() {
+			GinkgoT().Setenv("OBJSTO_URL", "s3://test-access:wrong@objsto.test/"+objstotest.DefaultBucket+"?scheme=http&region=us-east-1")
+
+			Expect(cli("ls")).To(Equal(exitError))
+		})
+
+		It("runs offline commands without a url", func() {
+			GinkgoT().Setenv("OBJSTO_URL", "")
+
+			Expect(cli("completion", "bash")).To(Equal(exitOK))
+			Expect(stdout.String()).To(ContainSubstring("__complete"))
+		})
+	})
+
+	Describe("output formats", func() {
+
+		BeforeEach(func() {
+			putString("logs/a.txt", "alpha")
+			putString("logs/old/b.txt", "bravo")
+			putString("top.txt", "top")
+		})
+
+		It("lists keys and folders as lines", func() {
+			Expect(cli("ls")).To(Equal(exitOK))
+			Expect(stdout.String()).To(Equal("logs/\ntop.txt\n"))
+
+			Expect(cli("ls", "-r", "logs/")).To(Equal(exitOK))
+			Expect(stdout.String()).To(Equal("logs/a.txt\nlogs/old/b.txt\n"))
+		})
+
+		It("lists NUL terminated with -0", func() {
+			Expect(cli("ls", "-r", "-0", "logs/")).To(Equal(exitOK))
+			Expect(stdout.String()).To(Equal("logs/a.txt\x00logs/old/b.txt\x00"))
+		})
+
+		It("lists as json lines with -json", func() {
+			Expect(cli("-json", "ls")).To(Equal(exitOK))
+
+			var lines []map[string]any
+			scanner := bufio.NewScanner(stdout)
+			for scanner.Scan() {
+				var line map[string]any
+				Expect(json.Unmarshal(scanner.Bytes(), &line)).To(Succeed())
+				lines = append(lines, line)
+			}
+
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(Equal(map[string]any{"prefix": "logs/"}))
+			Expect(lines[1]).To(HaveKeyWithValue("key", "top.txt"))
+			Expect(lines[1]).To(HaveKeyWithValue("size", BeNumerically("==", 3)))
+		})
+
+		It("stats as text or json", func() {
+			Expect(cli("stat", "top.txt")).To(Equal(exitOK))
+			Expect(stdout.String()).To(MatchRegexp(`(?m)^key: +top\.txt$`))
+			Expect(stdout.String()).To(MatchRegexp(`(?m)^size: +3$`))
+
+			Expect(cli("-json", "stat", "top.txt")).To(Equal(exitOK))
+			var info objsto.ObjectInfo
+			Expect(json.Unmarshal(stdout.Bytes(), &info)).To(Succeed())
+			Expect(info.Key).To(Equal("top.txt"))
+			Expect(info.Size).To(BeNumerically("==", 3))
+		})
+
+		It("reports errors as a json line on stderr with -json", func() {
+			Expect(cli("-json", "cat", "missing.txt")).To(Equal(exitNotFound))
+
+			var line map[string]any
+			Expect(json.Unmarshal(stderr.Bytes(), &line)).To(Succeed())
+			Expect(line).To(HaveKeyWithValue("exit", BeNumerically("==", exitNotFound)))
+			Expect(line).To(HaveKeyWithValue("error", ContainSubstring("not found")))
+			Expect(stdout.String()).To(BeEmpty())
+		})
+
+		It("writes gets to a file", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "a.txt")
+
+			Expect(cli("-json", "get", "-o", path, "logs/a.txt")).To(Equal(exitOK))
+			Expect(stdout.String()).To(MatchJSON(`{"key": "logs/a.txt", "path": "` + path + `", "bytes": 5}`))
+
+			data, err := os.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("alpha"))
+		})
+	})
+
+	Describe("credentials", func() {
+
+		It("are kept out of usage", func() {
+			Expect(cli()).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring("-url string"))
+			Expect(stderr.String()).ToNot(ContainSubstring(testSecret))
+
+			Expect(cli("frob")).To(Equal(exitUsage))
+			Expect(stderr.String()).ToNot(ContainSubstring(testSecret))
+
+			Expect(cli("-help")).To(Equal(exitUsage))
+			Expect(stderr.String()).ToNot(ContainSubstring(testSecret))
+		})
+
+		DescribeTable("are kept out of errors for a malformed url",
+			func(raw string) {
+				GinkgoT().Setenv("OBJSTO_URL", raw)
+
+				Expect(cli("ls")).To(Equal(exitUsage))
+				Expect(stderr.String()).To(HavePrefix("error: "))
+				Expect(stderr.String()).ToNot(ContainSubstring(testSecret))
+			},
+			Entry("bad port", "s3://test-access:"+testSecret+"@objsto.test:bad/bucket"),
+			Entry("bad escape", "s3://test-access:"+testSecret+"%zz@objsto.test/bucket"),
+			Entry("bad parameter", "s3://test-access:"+testSecret+"@objsto.test/bucket?regoin=x"),
+		)
+
+		It("are kept out of errors for a malformed -url", func() {
+			Expect(cli("-url", "s3://test-access:"+testSecret+"@objsto.test:bad/bucket", "ls")).To(Equal(exitUsage))
+			Expect(stderr.String()).ToNot(ContainSubstring(testSecret))
+		})
+
+		It("are kept out of errors and debug logs", func() {
+			Expect(cli("-debug", "cat", "missing.txt")).To(Equal(exitNotFound))
+			Expect(stderr.String()).To(ContainSubstring("missing.txt"))
+			Expect(stderr.String()).ToNot(ContainSubstring(testSecret))
+
+			Expect(cli("-json", "-debug", "whoami")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String() + stderr.String()).ToNot(ContainSubstring(testSecret))
+		})
+	})
+
+	Describe("get and cat", func() {
+
+		BeforeEach(func() {
+			putString("logs/a.txt", "alpha")
+			putString("logs/b.txt", "bravo")
+			putString("old/a.txt", "old alpha")
+		})
+
+		It("cats a range", func() {
+			Expect(cli("cat", "-range", "1-3", "logs/a.txt")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("lph"))
+
+			Expect(cli("get", "-range", "2-", "logs/b.txt")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("avo"))
+		})
+
+		It("gets to a file readable by others, leaving no temp file", func() {
+			dir := GinkgoT().TempDir()
+			path := filepath.Join(dir, "a.txt")
+
+			Expect(cli("get", "-o", path, "logs/a.txt")).To(Equal(exitOK), stderr.String())
+
+			fi, err := os.Stat(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fi.Mode().Perm()).To(Equal(os.FileMode(0644)))
+
+			entries, err := os.ReadDir(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+		})
+
+		It("gets a pattern into a directory", func() {
+			dir := GinkgoT().TempDir() + "/"
+
+			Expect(cli("get", "-o", dir, "logs/*.txt")).To(Equal(exitOK), stderr.String())
+
+			data, err := os.ReadFile(filepath.Join(dir, "b.txt"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("bravo"))
+		})
+
+		It("refuses a pattern matching keys that would land on the same file", func() {
+			dir := GinkgoT().TempDir() + "/"
+
+			Expect(cli("get", "-o", dir, "*/a.txt")).To(Equal(exitError))
+			Expect(stderr.String()).To(ContainSubstring("logs/a.txt and old/a.txt would both be got to a.txt"))
+
+			entries, err := os.ReadDir(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+	})
+
+	Describe("ls", func() {
+
+		BeforeEach(func() {
+			putString("logs/a.txt", "alpha")
+			putString("logs-old/b.txt", "bravo")
+		})
+
+		It("lists long with size, time and etag", func() {
+			Expect(cli("ls", "-l")).To(Equal(exitOK), stderr.String())
+
+			Expect(stdout.String()).To(MatchRegexp(`(?m)^DIR +logs/$`))
+			Expect(stdout.String()).To(MatchRegexp(`(?m)^DIR +logs-old/$`))
+
+			Expect(cli("ls", "-l", "logs/")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(MatchRegexp(`^5 +\d{4}-\d\d-\d\dT\S+ +\S+ +logs/a\.txt\n$`))
+		})
+
+		It("rolls up folders at the delimiter given", func() {
+			Expect(cli("ls", "-d", "-")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("logs-\nlogs/a.txt\n"))
+		})
+
+		It("lists under a prefix", func() {
+			Expect(cli("ls", "logs-")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("logs-old/\n"))
+		})
+	})
+
+	Describe("rm", func() {
+
+		BeforeEach(func() {
+			putString("logs/a.txt", "alpha")
+			putString("logs/old/b.txt", "bravo")
+			putString("top.txt", "top")
+		})
+
+		It("deletes a key without asking", func() {
+			Expect(cli("rm", "top.txt")).To(Equal(exitOK), stderr.String())
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(Equal([]string{"logs/a.txt", "logs/old/b.txt"}))
+		})
+
+		It("lists what would be deleted on a dry run, deleting nothing", func() {
+			Expect(cli("rm", "-r", "-dry-run", "logs/")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("logs/a.txt\nlogs/old/b.txt\n"))
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(HaveLen(3))
+		})
+
+		It("confirms a recursive delete", func() {
+			stdin.WriteString("n\n")
+			Expect(cli("rm", "-r", "logs/")).To(Equal(exitError))
+			Expect(stderr.String()).To(HavePrefix("delete 2 objects? [y/N] "))
+			Expect(stderr.String()).To(ContainSubstring("not confirmed, nothing deleted"))
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(HaveLen(3))
+
+			stdin.WriteString("y\n")
+			Expect(cli("rm", "-r", "logs/")).To(Equal(exitOK), stderr.String())
+			Expect(stderr.String()).To(ContainSubstring("deleted 2 objects"))
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(Equal([]string{"top.txt"}))
+		})
+
+		It("confirms a pattern delete unless forced", func() {
+			Expect(cli("rm", "logs/*")).To(Equal(exitError))
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(HaveLen(3))
+
+			Expect(cli("rm", "-f", "logs/*")).To(Equal(exitOK), stderr.String())
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(Equal([]string{"logs/old/b.txt", "top.txt"}))
+		})
+	})
+
+	Describe("cp", func() {
+		var dir string
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			putString("logs/a.txt", "alpha")
+			putString("logs/b.txt", "bravo")
+			Expect(cli("mb", "other")).To(Equal(exitOK), stderr.String())
+		})
+
+		It("copies a file to a key and back", func() {
+			local := filepath.Join(dir, "up.txt")
+			Expect(os.WriteFile(local, []byte("uploaded"), 0644)).To(Succeed())
+
+			Expect(cli("cp", local, ":up/")).To(Equal(exitOK), stderr.String())
+			Expect(cli("cat", "up/up.txt")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("uploaded"))
+
+			back := filepath.Join(dir, "back.txt")
+			Expect(cli("cp", ":up/up.txt", back)).To(Equal(exitOK), stderr.String())
+			data, err := os.ReadFile(back)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("uploaded"))
+		})
+
+		It("copies remote to remote, across buckets", func() {
+			Expect(cli("cp", ":logs/a.txt", "other:copied/a.txt")).To(Equal(exitOK), stderr.String())
+
+			Expect(srv.Keys("other")).To(Equal([]string{"copied/a.txt"}))
+			Expect(cli("cp", "other:copied/a.txt", "-")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("alpha"))
+		})
+
+		It("copies a pattern into a folder, reporting each", func() {
+			Expect(cli("-json", "cp", ":logs/*.txt", "other:kept/")).To(Equal(exitOK), stderr.String())
+
+			Expect(srv.Keys("other")).To(Equal([]string{"kept/a.txt", "kept/b.txt"}))
+			Expect(stdout.String()).To(ContainSubstring(`"target":"kept/a.txt"`))
+			Expect(stdout.String()).To(ContainSubstring(`"target":"kept/b.txt"`))
+		})
+
+		It("copies stdin to a key and a key to stdout", func() {
+			stdin.WriteString("piped")
+			Expect(cli("cp", "-", ":piped.txt")).To(Equal(exitOK), stderr.String())
+
+			Expect(cli("cp", ":piped.txt", "-")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("piped"))
+		})
+
+		It("refuses copying local to local or several sources to one key", func() {
+			Expect(cli("cp", filepath.Join(dir, "a"), filepath.Join(dir, "b"))).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring("one of source and destination must be bucket:key"))
+
+			Expect(cli("cp", ":logs/a.txt", ":logs/b.txt", "other:one.txt")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring("several sources need a destination ending in /"))
+		})
+	})
+
+	Describe("sync", func() {
+		var dir string
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			Expect(os.MkdirAll(filepath.Join(dir, "sub"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "a.txt"), []byte("alpha"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "sub", "b.txt"), []byte("bravo"), 0644)).To(Succeed())
+		})
+
+		report := func() (rpt objsync.Report) {
+
+			Expect(json.Unmarshal(stdout.Bytes(), &rpt)).To(Succeed())
+			return
+		}
+
+		It("pushes a directory, skipping what's unchanged", func() {
+			Expect(cli("sync", dir, ":site/")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(HavePrefix("transferred 2, deleted 0, skipped 0, 10 bytes in "))
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(Equal([]string{"site/a.txt", "site/sub/b.txt"}))
+
+			Expect(cli("sync", "-json", dir, ":site/")).To(Equal(exitOK), stderr.String())
+			Expect(report().Skipped).To(Equal(2))
+		})
+
+		It("deletes what's not in the source, unless a dry run", func() {
+			putString("site/gone.txt", "gone")
+
+			Expect(cli("-json", "sync", "-delete", "-dry-run", dir, ":site/")).To(Equal(exitOK), stderr.String())
+			Expect(report().Deleted).To(Equal([]string{"site/gone.txt"}))
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(Equal([]string{"site/gone.txt"}))
+
+			Expect(cli("sync", "-delete", dir, ":site/")).To(Equal(exitOK), stderr.String())
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(Equal([]string{"site/a.txt", "site/sub/b.txt"}))
+		})
+
+		It("pulls a prefix into a directory", func() {
+			putString("site/c.txt", "charlie")
+			pulled := GinkgoT().TempDir()
+
+			Expect(cli("sync", ":site/", pulled)).To(Equal(exitOK), stderr.String())
+
+			data, err := os.ReadFile(filepath.Join(pulled, "c.txt"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("charlie"))
+		})
+
+		It("refuses local to local and bucket to bucket with other prefixes", func() {
+			Expect(cli("sync", dir, GinkgoT().TempDir())).To(Equal(exitUsage))
+			Expect(cli("sync", ":site/", "other:mirror/")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring("source and destination prefix must match"))
+		})
+	})
+
+	Describe("put", func() {
+
+		It("puts stdin with a content type", func() {
+			stdin.WriteString(`{"a":1}`)
+			Expect(cli("put", "-type", "application/json", "-", "data/a")).To(Equal(exitOK), stderr.String())
+
+			Expect(cli("-json", "stat", "data/a")).To(Equal(exitOK), stderr.String())
+			var info objsto.ObjectInfo
+			Expect(json.Unmarshal(stdout.Bytes(), &info)).To(Succeed())
+			Expect(info.ContentType).To(Equal("application/json"))
+			Expect(info.Size).To(BeNumerically("==", 7))
+		})
+
+		It("guesses the content type from the key", func() {
+			putString("page.html", "<p>hi</p>")
+
+			Expect(cli("-json", "stat", "page.html")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(ContainSubstring(`"text/html`))
+		})
+
+		It("streams stdin bigger than a part as a multipart upload", func() {
+			data := bytes.Repeat([]byte("0123456789abcdef"), (2*objsto.MinPartSize+1024)/16)
+			stdin.Write(data)
+
+			Expect(cli("put", "-part-size", strconv.Itoa(objsto.MinPartSize), "-", "big.bin")).To(Equal(exitOK), stderr.String())
+
+			Expect(cli("cat", "big.bin")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.Len()).To(Equal(len(data)))
+			Expect(bytes.Equal(stdout.Bytes(), data)).To(BeTrue())
+		})
+
+		It("puts to another bucket", func() {
+			Expect(cli("mb", "other")).To(Equal(exitOK), stderr.String())
+			stdin.WriteString("elsewhere")
+
+			Expect(cli("put", "-", "other:a.txt")).To(Equal(exitOK), stderr.String())
+			Expect(srv.Keys("other")).To(Equal([]string{"a.txt"}))
+		})
+	})
+
+	Describe("stat", func() {
+
+		BeforeEach(func() {
+			stdin.WriteString("a,b\n")
+			Expect(cli("put", "-type", "text/csv", "-", "data.csv")).To(Equal(exitOK), stderr.String())
+		})
+
+		It("shows optional fields only when set", func() {
+			Expect(cli("stat", "data.csv")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(MatchRegexp(`(?m)^content-type: +text/csv$`))
+			Expect(stdout.String()).To(MatchRegexp(`(?m)^etag: +\S+$`))
+			Expect(stdout.String()).ToNot(ContainSubstring("content-encoding:"))
+			Expect(stdout.String()).ToNot(ContainSubstring("encoded-size:"))
+		})
+
+		It("prints json with its own flag", func() {
+			Expect(cli("stat", "-json", "data.csv")).To(Equal(exitOK), stderr.String())
+
+			var info objsto.ObjectInfo
+			Expect(json.Unmarshal(stdout.Bytes(), &info)).To(Succeed())
+			Expect(info.ContentType).To(Equal("text/csv"))
+		})
+
+		It("stats in another bucket", func() {
+			Expect(cli("mb", "other")).To(Equal(exitOK), stderr.String())
+			Expect(cli("stat", "other:data.csv")).To(Equal(exitNotFound))
+
+			Expect(cli("cp", ":data.csv", "other:data.csv")).To(Equal(exitOK), stderr.String())
+			Expect(cli("stat", "other:data.csv")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(MatchRegexp(`(?m)^content-type: +text/csv$`))
+		})
+
+		It("is not found for a missing object", func() {
+			Expect(cli("stat", "missing.csv")).To(Equal(exitNotFound))
+		})
+	})
+
+	Describe("progress", func() {
+
+		DescribeTable("sizes in binary units",
+			func(n int64, expected string) {
+				Expect(bytesize(n)).To(Equal(expected))
+			},
+			Entry("bytes", int64(1023), "1023B"),
+			Entry("kibibytes", int64(1536), "1.5KiB"),
+			Entry("mebibytes", int64(5<<20), "5.0MiB"),
+			Entry("gibibytes", int64(3<<30)/2, "1.5GiB"),
+		)
+
+		It("draws a final line with the total learned, lines printed above it", func() {
+			putString("big.bin", strings.Repeat("x", 3072))
+			client := srv.Client(objstotest.DefaultBucket, objsto.WithHTTPClient(serveDoer{srv}))
+
+			meterCtx, mtr := (&env{stderr: stderr, progress: true}).meter(ctx, 0, client)
+			reader, err := client.Get(meterCtx, "big.bin")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = io.Copy(io.Discard, reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reader.Close()).To(Succeed())
+
+			mtr.println("got %s", "big.bin")
+			mtr.stop()
+			mtr.stop()
+
+			Expect(stderr.String()).To(ContainSubstring("\rgot big.bin\x1b[K\n"))
+			Expect(stderr.String()).To(MatchRegexp(`\r3\.0KiB / 3\.0KiB  100%  \S+/s\x1b\[K\n$`))
+		})
+
+		It("just prints lines when not shown", func() {
+			_, mtr := (&env{stderr: stderr}).meter(ctx, 0)
+			mtr.println("copied %d", 1)
+			mtr.stop()
+
+			Expect(stderr.String()).To(Equal("copied 1\n"))
+		})
+
+		It("is hidden when stderr isn't a terminal", func() {
+			putString("a.txt", "alpha")
+
+			Expect(cli("get", "-o", filepath.Join(GinkgoT().TempDir(), "a.txt"), "a.txt")).To(Equal(exitOK), stderr.String())
+			Expect(stderr.String()).To(BeEmpty())
+		})
+	})
+
+	Describe("concurrency", func() {
+
+		It("runs each item once, no more than n at a time", func() {
+			var (
+				mu      sync.Mutex
+				running int
+				most    int
+				seen    = map[int]string{}
+			)
+			items := []string{"a", "b", "c", "d", "e", "f", "g"}
+
+			parallel(3, items, func(idx int, item string) {
+				mu.Lock()
+				running++
+				most = max(most, running)
+				seen[idx] = item
+				mu.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				mu.Lock()
+				running--
+				mu.Unlock()
+			})
+
+			Expect(most).To(BeNumerically("<=", 3))
+			Expect(seen).To(HaveLen(len(items)))
+			for idx, item := range items {
+				Expect(seen[idx]).To(Equal(item))
+			}
+		})
+
+		It("copies several files at once, reporting each and any failures", func() {
+			dir := GinkgoT().TempDir()
+			var paths []string
+			for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+				path := filepath.Join(dir, name)
+				Expect(os.WriteFile(path, []byte(name), 0644)).To(Succeed())
+				paths = append(paths, path)
+			}
+			paths = append(paths, filepath.Join(dir, "missing.txt"))
+
+			args := append([]string{"cp", "-concurrency", "2"}, paths...)
+			Expect(cli(append(args, ":up/")...)).To(Equal(exitError))
+
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(Equal([]string{"up/a.txt", "up/b.txt", "up/c.txt"}))
+			Expect(stderr.String()).To(ContainSubstring("copied " + paths[1] + " to up/b.txt\n"))
+			Expect(stderr.String()).To(ContainSubstring(`failed to copy "` + paths[3] + `"`))
+			Expect(stderr.String()).To(ContainSubstring("failed to copy 1 of 4"))
+		})
+
+		It("deletes in batches at once", func() {
+			for idx := range 5 {
+				putString(fmt.Sprintf("logs/%d.txt", idx), "x")
+			}
+
+			Expect(cli("rm", "-r", "-f", "-concurrency", "4", "logs/")).To(Equal(exitOK), stderr.String())
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(BeEmpty())
+		})
+	})
+
+	Describe("patterns", func() {
+
+		BeforeEach(func() {
+			for _, key := range []string{"logs/a.txt", "logs/b.log", "logs/old/c.txt", "logs/[x].txt", "top.txt"} {
+				putString(key, "content")
+			}
+		})
+
+		DescribeTable("match keys a segment at a time",
+			func(pattern string, expected []string) {
+				client := srv.Client(objstotest.DefaultBucket, objsto.WithHTTPClient(serveDoer{srv}))
+
+				keys, err := glob(ctx, client, pattern)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(keys).To(Equal(expected))
+			},
+			Entry("star", "logs/*.txt", []string{"logs/[x].txt", "logs/a.txt"}),
+			Entry("question mark", "logs/?.log", []string{"logs/b.log"}),
+			Entry("class", "logs/[ab].*", []string{"logs/a.txt", "logs/b.log"}),
+			Entry("escaped", `logs/\[x\].txt`, []string{"logs/[x].txt"}),
+			Entry("across segments", "*/*/*.txt", []string{"logs/old/c.txt"}),
+		)
+
+		It("refuses a bad pattern", func() {
+			Expect(cli("rm", "-f", "logs/[")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring(`bad pattern "logs/["`))
+		})
+
+		It("is not found when nothing matches", func() {
+			Expect(cli("get", "-o", GinkgoT().TempDir()+"/", "logs/*.csv")).To(Equal(exitNotFound))
+			Expect(stderr.String()).To(ContainSubstring(`no objects match "logs/*.csv"`))
+		})
+
+		It("expands among literal keys", func() {
+			Expect(cli("rm", "-f", "top.txt", "logs/*.txt")).To(Equal(exitOK), stderr.String())
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(Equal([]string{"logs/b.log", "logs/old/c.txt"}))
+		})
+	})
+
+	Describe("du", func() {
+
+		BeforeEach(func() {
+			putString("logs/2026/03/a.log", "alpha")
+			putString("logs/2026/04/b.log", "bravo!")
+			putString("logs/c.log", "c")
+			putString("top.txt", strings.Repeat("t", 2048))
+		})
+
+		It("totals folders a level down", func() {
+			Expect(cli("du")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("12    3  logs/\n2060  4  total\n"))
+		})
+
+		It("totals deeper under a prefix", func() {
+			Expect(cli("du", "-depth", "2", "logs/")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal(
+				"11  2  logs/2026/\n" +
+					"5   1  logs/2026/03/\n" +
+					"6   1  logs/2026/04/\n" +
+					"12  3  logs/\n"))
+		})
+
+		It("shows human sizes, or json lines", func() {
+			Expect(cli("du", "-depth", "0", "-h")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("2.0KiB  4  total\n"))
+
+			Expect(cli("-json", "du", "logs/")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal(
+				`{"prefix":"logs/2026/","objects":2,"bytes":11}` + "\n" +
+					`{"prefix":"logs/","objects":3,"bytes":12}` + "\n"))
+		})
+	})
+
+	Describe("mb and rb", func() {
+
+		It("makes and removes a bucket", func() {
+			Expect(cli("mb", "scratch")).To(Equal(exitOK), stderr.String())
+			Expect(srv.Keys("scratch")).To(BeEmpty())
+
+			Expect(cli("mb", "scratch")).To(Equal(exitError))
+			Expect(stderr.String()).To(ContainSubstring("BucketAlreadyOwnedByYou"))
+
+			Expect(cli("rb", "scratch")).To(Equal(exitOK), stderr.String())
+			Expect(cli("mb", "scratch")).To(Equal(exitOK), stderr.String())
+		})
+
+		It("removes a bucket with objects only when forced", func() {
+			Expect(cli("mb", "scratch")).To(Equal(exitOK), stderr.String())
+			stdin.WriteString("left behind")
+			Expect(cli("put", "-", "scratch:a.txt")).To(Equal(exitOK), stderr.String())
+
+			Expect(cli("rb", "scratch")).To(Equal(exitError))
+			Expect(stderr.String()).To(ContainSubstring("BucketNotEmpty"))
+
+			Expect(cli("rb", "-force", "scratch")).To(Equal(exitOK), stderr.String())
+			Expect(stderr.String()).To(Equal("deleted 1 objects and aborted 0 uploads\n"))
+			Expect(cli("mb", "scratch")).To(Equal(exitOK), stderr.String())
+		})
+
+		It("defaults to the url's bucket", func() {
+			Expect(cli("rb")).To(Equal(exitOK), stderr.String())
+			Expect(cli("ls")).To(Equal(exitNotFound))
+		})
+	})
+
+	Describe("find", func() {
+
+		BeforeEach(func() {
+			putString("logs/a.log", "aaaa")
+			putString("logs/b.txt", strings.Repeat("b", 2048))
+			putString("logs/old/c.log", "c")
+			putString("top.log", "tt")
+		})
+
+		It("finds by name and size", func() {
+			Expect(cli("find", "-name", `\.log$`)).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("logs/a.log\nlogs/old/c.log\ntop.log\n"))
+
+			Expect(cli("find", "-larger-than", "1K", "logs/")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("logs/b.txt\n"))
+
+			Expect(cli("find", "-smaller-than", "3", "-0")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("logs/old/c.log\x00top.log\x00"))
+		})
+
+		It("refuses a bad name or a delete with a copy", func() {
+			Expect(cli("find", "-name", "(")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring("bad -name"))
+
+			Expect(cli("find", "-delete", "-copy-to", "dst/")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring("don't go together"))
+		})
+
+		It("deletes matches", func() {
+			Expect(cli("find", "-name", `\.log$`, "-delete", "logs/")).To(Equal(exitOK), stderr.String())
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(ConsistOf("logs/b.txt", "top.log"))
+		})
+
+		It("copies matches under a prefix", func() {
+			Expect(cli("find", "-name", `\.log$`, "-copy-to", ":keep/", "logs/")).To(Equal(exitOK), stderr.String())
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(ContainElements("keep/a.log", "keep/c.log"))
+
+			Expect(cli("find", "-name", `^c`, "-copy-to", ":keep")).To(Equal(exitUsage))
+		})
+	})
+
+	Describe("tree", func() {
+
+		BeforeEach(func() {
+			putString("logs/2026/03/a.log", "aaaa")
+			putString("logs/2026/b.log", strings.Repeat("b", 2048))
+			putString("logs/c.log", "c")
+			putString("top.txt", "tt")
+		})
+
+		It("draws the bucket three levels down", func() {
+			Expect(cli("tree")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal(objstotest.DefaultBucket + ":\n" +
+				"├── logs/\n" +
+				"│   ├── 2026/\n" +
+				"│   │   ├── 03/\n" +
+				"│   │   └── b.log\n" +
+				"│   └── c.log\n" +
+				"└── top.txt\n" +
+				"\n3 folders, 3 objects\n"))
+
+			Expect(cli("tree", "-depth", "0", "logs/2026/")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("logs/2026/\n" +
+				"├── 03/\n" +
+				"│   └── a.log\n" +
+				"└── b.log\n" +
+				"\n1 folders, 2 objects\n"))
+		})
+
+		It("stops at depth, with sizes", func() {
+			Expect(cli("tree", "-depth", "1", "-s", "logs/")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("logs/\n" +
+				"├── 2026/\n" +
+				"└── [1B]  c.log\n" +
+				"\n1 folders, 1 objects\n"))
+		})
+
+		It("gives json lines with depths", func() {
+			Expect(cli("-json", "tree", "-depth", "2", "logs/")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal(
+				`{"prefix":"logs/2026/","depth":1}` + "\n" +
+					`{"prefix":"logs/2026/03/","depth":2}` + "\n" +
+					`{"key":"logs/2026/b.log","size":2048,"depth":2}` + "\n" +
+					`{"key":"logs/c.log","size":1,"depth":1}` + "\n"))
+		})
+	})
+
+	Describe("tag", func() {
+		type tagPair struct {
+			Key   string `xml:"Key"`
+			Value string `xml:"Value"`
+		}
+		type tagSet struct {
+			XMLName xml.Name  `xml:"Tagging"`
+			Tags    []tagPair `xml:"TagSet>Tag"`
+		}
+
+		var (
+			tags    map[string]map[string]string
+			tagging doerFunc
+		)
+
+		BeforeEach(func() {
+			putString("a.txt", "alpha")
+
+			// objstotest refuses subresources, so tags are kept here by path
+			tags = map[string]map[string]string{}
+			tagging = func(request *http.Request) (*http.Response, error) {
+				if !request.URL.Query().Has("tagging") {
+					return serveDoer{srv}.Do(request)
+				}
+
+				rec := httptest.NewRecorder()
+				path := request.URL.Path
+				switch request.Method {
+				case http.MethodGet:
+					set := tagSet{}
+					for name, val := range tags[path] {
+						set.Tags = append(set.Tags, tagPair{name, val})
+					}
+					xml.NewEncoder(rec).Encode(set)
+				case http.MethodPut:
+					set := tagSet{}
+					Expect(xml.NewDecoder(request.Body).Decode(&set)).To(Succeed())
+					tags[path] = map[string]string{}
+					for _, tag := range set.Tags {
+						tags[path][tag.Key] = tag.Value
+					}
+				case http.MethodDelete:
+					delete(tags, path)
+					rec.WriteHeader(http.StatusNoContent)
+				}
+				return rec.Result(), nil
+			}
+		})
+
+		tag := func(args ...string) int {
+
+			stdout.Reset()
+			stderr.Reset()
+			return run(ctx, append([]string{"tag"}, args...), stdin, stdout, stderr,
+				objsto.WithHTTPClient(tagging), objsto.WithRetryPolicy(objsto.NoRetry{}))
+		}
+
+		It("sets, gets and removes tags", func() {
+			Expect(tag("set", "a.txt", "tier=cold", "team=ops")).To(Equal(exitOK), stderr.String())
+			Expect(tags["/"+objstotest.DefaultBucket+"/a.txt"]).To(Equal(map[string]string{"tier": "cold", "team": "ops"}))
+
+			Expect(tag("get", "a.txt")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(Equal("team=ops\ntier=cold\n"))
+
+			Expect(tag("rm", "a.txt", "tier")).To(Equal(exitOK), stderr.String())
+			Expect(tag("get", "-json", "a.txt")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(MatchJSON(`{"team":"ops"}`))
+
+			Expect(tag("rm", "a.txt")).To(Equal(exitOK), stderr.String())
+			Expect(tags).To(BeEmpty())
+		})
+
+		It("sets tags from json on stdin", func() {
+			stdin.WriteString(`{"tier":"hot"}`)
+			Expect(tag("set", "-json", "a.txt")).To(Equal(exitOK), stderr.String())
+			Expect(tags["/"+objstotest.DefaultBucket+"/a.txt"]).To(Equal(map[string]string{"tier": "hot"}))
+
+			Expect(tag("set", "-json", "a.txt", "tier=cold")).To(Equal(exitUsage))
+		})
+
+		It("refuses a bad pair or action", func() {
+			Expect(tag("set", "a.txt", "tier")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring(`tag "tier" is not name=value`))
+
+			Expect(tag("add", "a.txt")).To(Equal(exitUsage))
+			Expect(tag()).To(Equal(exitUsage))
+		})
+	})
+
+	Describe("watch", func() {
+		var (
+			client *objsto.Client
+			out    *syncBuffer
+			cancel context.CancelFunc
+			code   chan int
+			listed chan struct{}
+		)
+
+		BeforeEach(func() {
+			putString("a.txt", "alpha")
+			client = srv.Client(objstotest.DefaultBucket, objsto.WithHTTPClient(serveDoer{srv}))
+			out = &syncBuffer{}
+			code = make(chan int, 1)
+			listed = make(chan struct{}, 1)
+		})
+
+		watch := func(args ...string) {
+
+			var watchCtx context.Context
+			watchCtx, cancel = context.WithCancel(ctx)
+			DeferCleanup(cancel)
+			listing := doerFunc(func(request *http.Request) (*http.Response, error) {
+				resp, err := serveDoer{srv}.Do(request)
+				select {
+				case listed <- struct{}{}:
+				default:
+				}
+				return resp, err
+			})
+			go func() {
+				code <- run(watchCtx, append([]string{"watch", "-interval", "5ms"}, args...), stdin, out, stderr,
+					objsto.WithHTTPClient(listing), objsto.WithRetryPolicy(objsto.NoRetry{}))
+			}()
+			Eventually(listed).Should(Receive())
+		}
+
+		It("prints changes until interrupted", func() {
+			watch("-initial")
+			Eventually(out.String).Should(MatchRegexp(`^\S+  created  a\.txt\n$`))
+
+			Expect(client.PutString(ctx, "b.txt", "bravo")).To(Succeed())
+			Eventually(out.String).Should(MatchRegexp(`  created  b\.txt\n$`))
+
+			Expect(client.Delete(ctx, "a.txt")).To(Succeed())
+			Eventually(out.String).Should(MatchRegexp(`  deleted  a\.txt\n$`))
+
+			cancel()
+			Eventually(code).Should(Receive(Equal(exitOK)))
+		})
+
+		It("prints json lines under a prefix, without what was there", func() {
+			watch("-json", "logs/")
+
+			Expect(client.PutString(ctx, "b.txt", "bravo")).To(Succeed())
+			Expect(client.PutString(ctx, "logs/c.txt", "charlie")).To(Succeed())
+			Eventually(out.String).Should(ContainSubstring(`"key":"logs/c.txt"`))
+
+			Expect(client.PutString(ctx, "logs/c.txt", "changed")).To(Succeed())
+			Eventually(out.String).Should(ContainSubstring(`{"type":"updated"`))
+
+			cancel()
+			Eventually(code).Should(Receive(Equal(exitOK)))
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(HavePrefix(`{"type":"created","info":{"key":"logs/c.txt"`))
+		})
+	})
+
+	Describe("json", func() {
+
+		lines := func(data string) (all []map[string]any) {
+
+			for line := range strings.Lines(data) {
+				var val map[string]any
+				Expect(json.Unmarshal([]byte(line), &val)).To(Succeed(), line)
+				all = append(all, val)
+			}
+			return
+		}
+
+		It("gives a put's result", func() {
+			stdin.WriteString("alpha")
+			Expect(cli("-json", "put", "-", "a.txt")).To(Equal(exitOK), stderr.String())
+
+			var res objsto.PutResult
+			Expect(json.Unmarshal(stdout.Bytes(), &res)).To(Succeed())
+			Expect(res.Key).To(Equal("a.txt"))
+			Expect(res.ETag).ToNot(BeEmpty())
+		})
+
+		It("gives a line per key removed", func() {
+			putString("logs/a.txt", "alpha")
+			putString("logs/b.txt", "bravo")
+
+			Expect(cli("-json", "rm", "-r", "-f", "logs/")).To(Equal(exitOK), stderr.String())
+			Expect(lines(stdout.String())).To(ConsistOf(
+				map[string]any{"key": "logs/a.txt"},
+				map[string]any{"key": "logs/b.txt"}))
+			Expect(stderr.String()).To(BeEmpty())
+		})
+
+		It("gives a presigned url with its method and expiry", func() {
+			Expect(cli("-json", "presign", "-method", "PUT", "a.txt")).To(Equal(exitOK), stderr.String())
+
+			var line map[string]any
+			Expect(json.Unmarshal(stdout.Bytes(), &line)).To(Succeed())
+			Expect(line).To(HaveKeyWithValue("method", "PUT"))
+			Expect(line).To(HaveKeyWithValue("url", ContainSubstring("X-Amz-Signature=")))
+			Expect(line).To(HaveKey("expires"))
+		})
+
+		It("reports a usage error with its exit code", func() {
+			Expect(cli("-json", "stat")).To(Equal(exitUsage))
+			Expect(lines(stderr.String())).To(ConsistOf(
+				HaveKeyWithValue("exit", BeNumerically("==", exitUsage))))
+		})
+	})
+
+	Describe("whoami", func() {
+
+		checks := func() (statuses map[string]string) {
+
+			var rpt report
+			Expect(json.Unmarshal(stdout.Bytes(), &rpt)).To(Succeed())
+			Expect(rpt.AccessKey).To(Equal("test-access"))
+
+			statuses = map[string]string{}
+			for _, chk := range rpt.Checks {
+				statuses[chk.Name] = chk.Status
+			}
+			return
+		}
+
+		It("passes every check, leaving no probe behind", func() {
+			Expect(cli("whoami")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(MatchRegexp(`(?m)^bucket: +` + objstotest.DefaultBucket + `$`))
+			Expect(stdout.String()).To(MatchRegexp(`(?m)^write +ok +$`))
+
+			Expect(cli("-json", "whoami")).To(Equal(exitOK), stderr.String())
+			Expect(checks()).To(Equal(map[string]string{
+				"config": "ok", "bucket": "ok", "list": "ok", "write": "ok", "read": "ok", "delete": "ok"}))
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(BeEmpty())
+		})
+
+		It("reads what's listed when read-only", func() {
+			Expect(cli("-json", "whoami", "-read-only")).To(Equal(exitOK), stderr.String())
+			Expect(checks()).To(HaveKeyWithValue("read", "skipped"))
+
+			putString("a.txt", "alpha")
+			Expect(cli("-json", "whoami", "-read-only")).To(Equal(exitOK), stderr.String())
+			Expect(checks()).To(Equal(map[string]string{
+				"config": "ok", "bucket": "ok", "list": "ok", "write": "skipped", "read": "ok", "delete": "skipped"}))
+		})
+
+		It("fails checks for a missing bucket, or a wrong secret", func() {
+			Expect(cli("-json", "whoami", "missing")).To(Equal(exitError))
+			Expect(checks()).To(HaveKeyWithValue("bucket", "failed"))
+
+			wrong := strings.Replace(testURL, testSecret, "wrong-secret", 1)
+			Expect(cli("-json", "-url", wrong, "whoami")).To(Equal(exitError))
+			Expect(checks()).To(HaveKeyWithValue("list", "failed"))
+			Expect(checks()).To(HaveKeyWithValue("write", "failed"))
+		})
+
+		It("fails the config check without a region", func() {
+			bare := strings.Replace(testURL, "&region=us-east-1", "", 1)
+			Expect(cli("-json", "-url", bare, "whoami")).To(Equal(exitError))
+
+			var rpt report
+			Expect(json.Unmarshal(stdout.Bytes(), &rpt)).To(Succeed())
+			Expect(rpt.Checks[0]).To(Equal(check{Name: "config", Status: "failed", Detail: "no region"}))
+		})
+	})
+
+	Describe("completion", func() {
+
+		complete := func(words ...string) []string {
+
+			Expect(cli(append([]string{"__complete"}, words...)...)).To(Equal(exitOK), stderr.String())
+			return strings.Fields(stdout.String())
+		}
+
+		It("emits a script per shell", func() {
+			for _, shell := range []string{"bash", "zsh", "fish"} {
+				Expect(cli("completion", shell)).To(Equal(exitOK), stderr.String())
+				Expect(stdout.String()).To(ContainSubstring("objsto __complete"), shell)
+			}
+
+			Expect(cli("completion", "tcsh")).To(Equal(exitUsage))
+			Expect(stderr.String()).To(ContainSubstring(`no completion for "tcsh"`))
+		})
+
+		It("completes commands and flags", func() {
+			Expect(complete("s")).To(Equal([]string{"stat", "sync"}))
+			Expect(complete("-q")).To(Equal([]string{"-q", "-quiet"}))
+			Expect(complete("-json", "-url", "s3://x", "tr")).To(Equal([]string{"tree"}))
+
+			Expect(complete("rm", "-d")).To(Equal([]string{"-dry-run"}))
+			Expect(complete("tag", "")).To(Equal([]string{"get", "set", "rm"}))
+		})
+
+		It("completes keys a folder at a time", func() {
+			putString("logs/a.txt", "alpha")
+			putString("logs/old/b.txt", "bravo")
+			putString("top.txt", "top")
+
+			Expect(complete("cat", "")).To(Equal([]string{"logs/", "top.txt"}))
+			Expect(complete("cat", "logs/")).To(Equal([]string{"logs/old/", "logs/a.txt"}))
+			Expect(complete("cp", "s3:lo")).To(Equal([]string{"s3:logs/"}))
+		})
+
+		It("leaves local paths and flag values to the shell", func() {
+			putString("top.txt", "top")
+
+			Expect(complete("put", "./")).To(BeEmpty())
+			Expect(complete("get", "-o", "")).To(BeEmpty())
+			Expect(complete("tag", "get", "t")).To(Equal([]string{"top.txt"}))
+		})
+
+		It("completes without keys when there's no url", func() {
+			GinkgoT().Setenv("OBJSTO_URL", "")
+
+			Expect(complete("cat", "")).To(BeEmpty())
+			Expect(complete("mb", "-")).To(BeEmpty())
+			Expect(complete("rb", "-")).To(Equal([]string{"-force"}))
+		})
+	})
+
+	Describe("bench", func() {
+
+		bench := func(args ...string) (rpt benchReport) {
+
+			args = append([]string{"-json", "bench", "-size", "1K", "-objects", "4", "-concurrency", "2", "-duration", "50ms"}, args...)
+			Expect(cli(args...)).To(Equal(exitOK), stderr.String())
+			Expect(json.Unmarshal(stdout.Bytes(), &rpt)).To(Succeed())
+			return
+		}
+
+		It("reads and writes, cleaning up after", func() {
+			rpt := bench()
+			Expect(rpt.Size).To(Equal(int64(1024)))
+			Expect(rpt.Concurrency).To(Equal(2))
+			Expect(rpt.Ops).To(HaveLen(2))
+			for _, op := range rpt.Ops {
+				Expect(op.Count).To(BeNumerically(">", 0), op.Op)
+				Expect(op.Errors).To(BeZero(), op.Op)
+				Expect(op.Max).To(BeNumerically(">=", op.P50), op.Op)
+			}
+
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(BeEmpty())
+		})
+
+		It("keeps objects under a prefix when asked", func() {
+			bench("-keep", "-read-percent", "100", "perf/")
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(Equal([]string{
+				"perf/000000", "perf/000001", "perf/000002", "perf/000003"}))
+		})
+
+		It("only writes given no reads", func() {
+			rpt := bench("-read-percent", "0")
+			Expect(rpt.Ops).To(HaveLen(1))
+			Expect(rpt.Ops[0].Op).To(Equal("write"))
+		})
+
+		It("prints a table", func() {
+			Expect(cli("bench", "-size", "1K", "-objects", "2", "-duration", "20ms")).To(Equal(exitOK), stderr.String())
+			Expect(stdout.String()).To(HavePrefix("1.0KiB objects, 8 in flight, 50% reads, for "))
+			Expect(stdout.String()).To(MatchRegexp(`(?m)^op +count +errors +ops/s +throughput +p50 +p90 +p99 +max$`))
+			Expect(stdout.String()).To(MatchRegexp(`(?m)^read +\d+ +0 `))
+		})
+
+		It("refuses bad settings", func() {
+			Expect(cli("bench", "-read-percent", "101")).To(Equal(exitUsage))
+			Expect(cli("bench", "-objects", "0")).To(Equal(exitUsage))
+			Expect(cli("bench", "-size", "6G")).To(Equal(exitUsage))
+		})
+
+		It("takes percentiles by nearest rank", func() {
+			sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+			Expect(percentile(sorted, 0.5)).To(Equal(time.Duration(5)))
+			Expect(percentile(sorted, 0.99)).To(Equal(time.Duration(10)))
+			Expect(percentile(sorted, 0)).To(Equal(time.Duration(1)))
+			Expect(percentile(nil, 0.5)).To(BeZero())
+		})
+	})
+})
+
+// syncBuffer is a buffer safe to write while being read, as for a command left running.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (sb *syncBuffer) Write(data []byte) (int, error) {
+
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.Write(data)
+}
+
+func (sb *syncBuffer) String() string {
+
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.String()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// outcome is what became of one of several objects deleted or copied, a json line for -json.
+type outcome struct {
+	Key    string `json:"key"`
+	Target string `json:"target,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// folderLine is a common prefix in a listing, a json line alongside objects for -json.
+type folderLine struct {
+	Prefix string `json:"prefix"`
+}
+
+// fetched reports an object gotten to a file, as a json line for -json and otherwise not at all.
+func (env *env) fetched(key, path string) (err error) {
+
+	if !env.json {
+		return
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+
+	err = printLine(env.stdout, struct {
+		Key   string `json:"key"`
+		Path  string `json:"path"`
+		Bytes int64  `json:"bytes"`
+	}{key, path, fi.Size()})
+	return
+}
+
+// failed reports what became of an object not deleted or copied, on stderr.
+func (env *env) failed(verb, key string, err error) {
+
+	if env.json {
+		printLine(env.stderr, outcome{Key: key, Error: err.Error()})
+		return
+	}
+
+	fmt.Fprintf(env.stderr, "failed to %s %q: %v\n", verb, key, err)
+}
+
+// printJSON writes val as indented json.
+func printJSON(writer io.Writer, val any) (err error) {
+
+	enc := json.NewEncoder(writer)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(val)
+	return
+}
+
+// printLine writes val as a json line, leaving the likes of & in urls unescaped.
+func printLine(writer io.Writer, val any) (err error) {
+
+	enc := json.NewEncoder(writer)
+	enc.SetEscapeHTML(false)
+	err = enc.Encode(val)
+	return
+}
+
+// syncWriter serializes writes, as from transfers running in parallel.
+type syncWriter struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func (sw *syncWriter) Write(data []byte) (n int, err error) {
+
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	n, err = sw.writer.Write(data)
+	return
+}
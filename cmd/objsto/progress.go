@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/clarktrimble/objsto"
+)
+
+// meterInterval is the time between progress lines.
+const meterInterval = 250 * time.Millisecond
+
+// meter shows the progress of transfers on stderr, redrawing a single line,
+// with lines for finished transfers printed above it.
+//
+// Bytes are as counted by the clients, so concurrent transfers add up,
+// and the total is given or learned from objsto's progress callback.
+type meter struct {
+	writer  io.Writer
+	active  bool
+	clients []*objsto.Client
+	base    int64
+	total   atomic.Int64
+	start   time.Time
+	lines   chan string
+	done    chan struct{}
+	drawn   chan struct{}
+	stopped atomic.Bool
+}
+
+// meter starts showing progress of transfers made by clients, when enabled, until stopped.
+// A total of zero is learned from the progress of the first request with a length, as for a get,
+// and one less than zero is unknown.
+func (env *env) meter(ctx context.Context, total int64, clients ...*objsto.Client) (meterCtx context.Context, mtr *meter) {
+
+	mtr = &meter{
+		writer:  env.stderr,
+		active:  env.progress,
+		clients: clients,
+		start:   time.Now(),
+		lines:   make(chan string, 64),
+		done:    make(chan struct{}),
+		drawn:   make(chan struct{}),
+	}
+	if !mtr.active {
+		return ctx, mtr
+	}
+
+	mtr.base = mtr.bytes()
+	mtr.total.Store(total)
+
+	meterCtx = ctx
+	if total == 0 {
+		meterCtx = objsto.WithProgress(ctx, func(_, total int64) {
+			if total > 0 {
+				mtr.total.CompareAndSwap(0, total)
+			}
+		})
+	}
+
+	go mtr.run()
+	return
+}
+
+// stop draws the final progress line, waiting for it.
+func (mtr *meter) stop() {
+
+	if mtr.active && mtr.stopped.CompareAndSwap(false, true) {
+		close(mtr.done)
+		<-mtr.drawn
+	}
+}
+
+// println prints a line above the progress line, or just prints it when progress isn't shown.
+func (mtr *meter) println(format string, args ...any) {
+
+	line := fmt.Sprintf(format, args...)
+	if !mtr.active || mtr.stopped.Load() {
+		fmt.Fprintln(mtr.writer, line)
+		return
+	}
+
+	mtr.lines <- line
+}
+
+// unexported
+
+func (mtr *meter) run() {
+
+	defer close(mtr.drawn)
+
+	ticker := time.NewTicker(meterInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case line := <-mtr.lines:
+			fmt.Fprintf(mtr.writer, "\r%s\x1b[K\n", line)
+			mtr.draw()
+		case <-mtr.done:
+			for len(mtr.lines) > 0 {
+				fmt.Fprintf(mtr.writer, "\r%s\x1b[K\n", <-mtr.lines)
+			}
+			mtr.draw()
+			fmt.Fprintln(mtr.writer)
+			return
+		case <-ticker.C:
+			mtr.draw()
+		}
+	}
+}
+
+func (mtr *meter) draw() {
+
+	done := mtr.bytes() - mtr.base
+	elapsed := time.Since(mtr.start)
+	rate := float64(done) / max(elapsed.Seconds(), 0.001)
+
+	line := fmt.Sprintf("%s  %s/s", bytesize(done), bytesize(int64(rate)))
+
+	total := mtr.total.Load()
+	if total > 0 {
+		line = fmt.Sprintf("%s / %s  %3.0f%%  %s/s", bytesize(done), bytesize(total),
+			100*float64(min(done, total))/float64(total), bytesize(int64(rate)))
+		if rate > 0 && done < total {
+			eta := time.Duration(float64(total-done) / rate * float64(time.Second))
+			line += "  eta " + eta.Round(time.Second).String()
+		}
+	}
+
+	// back to the start of the line and clear the rest
+	fmt.Fprintf(mtr.writer, "\r%s\x1b[K", line)
+}
+
+func (mtr *meter) bytes() (n int64) {
+
+	for _, client := range mtr.clients {
+		stats := client.Stats()
+		n += stats.BytesUp + stats.BytesDown
+	}
+
+	return
+}
+
+// bytesize formats n bytes with a binary unit, as with "12.3MiB".
+func bytesize(n int64) string {
+
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// isTerminal is true when writer is a terminal.
+func isTerminal(writer io.Writer) bool {
+
+	file, ok := writer.(*os.File)
+	if !ok {
+		return false
+	}
+
+	fi, err := file.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
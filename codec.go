@@ -0,0 +1,104 @@
+package objsto
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Codec encodes and decodes values for storage.
+// JSON and Gob are provided here, and msgpack by the msgpack module.
+type Codec interface {
+	ContentType() string
+	Encode(writer io.Writer, val any) error
+	Decode(reader io.Reader, val any) error
+}
+
+var (
+	// JSON is a Codec for encoding/json.
+	JSON Codec = jsonCodec{}
+	// Gob is a Codec for encoding/gob.
+	Gob Codec = gobCodec{}
+)
+
+// PutValue puts val encoded with codec, with Content-Type set to match.
+func PutValue[T any](ctx context.Context, client ObjectStore, codec Codec, object string, val T, opts ...PutOption) (err error) {
+
+	buf := &bytes.Buffer{}
+
+	err = codec.Encode(buf, val)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to encode %q", object)
+		return
+	}
+
+	opts = append([]PutOption{WithContentType(codec.ContentType())}, opts...)
+
+	err = client.Put(ctx, object, bytes.NewReader(buf.Bytes()), opts...)
+	return
+}
+
+// GetValue gets an object decoded with codec.
+func GetValue[T any](ctx context.Context, client ObjectStore, codec Codec, object string) (val T, err error) {
+
+	reader, err := client.Get(ctx, object)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	err = decode(codec, object, reader, &val)
+	return
+}
+
+// unexported
+
+func decode(codec Codec, object string, reader io.Reader, val any) (err error) {
+
+	err = codec.Decode(reader, val)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to decode %q", object)
+	}
+
+	return
+}
+
+type jsonCodec struct{}
+
+func (jc jsonCodec) ContentType() string {
+	return jsonType
+}
+
+func (jc jsonCodec) Encode(writer io.Writer, val any) error {
+
+	// marshal rather than encode to skip the trailing newline
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(data)
+	return err
+}
+
+func (jc jsonCodec) Decode(reader io.Reader, val any) error {
+	return json.NewDecoder(reader).Decode(val)
+}
+
+type gobCodec struct{}
+
+func (gc gobCodec) ContentType() string {
+	return "application/x-gob"
+}
+
+func (gc gobCodec) Encode(writer io.Writer, val any) error {
+	return gob.NewEncoder(writer).Encode(val)
+}
+
+func (gc gobCodec) Decode(reader io.Reader, val any) error {
+	return gob.NewDecoder(reader).Decode(val)
+}
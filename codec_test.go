@@ -0,0 +1,77 @@
+package objsto_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var _ = Describe("Codec", func() {
+	type record struct {
+		Name string
+		Tags []string
+	}
+
+	var (
+		ctx    context.Context
+		mock   *HttpDoerMock
+		client *objsto.Client
+		stored []byte
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		stored = nil
+
+		mock = &HttpDoerMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if req.Method == "PUT" {
+					stored, _ = io.ReadAll(req.Body)
+				}
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewReader(stored)),
+				}, nil
+			},
+		}
+
+		client = objsto.New(&objsto.Config{
+			Region:    "test-region",
+			Scheme:    "https",
+			Host:      "test-host",
+			Bucket:    "test-bucket",
+			AccessKey: "test-access-key",
+			SecretKey: "test-secret-key",
+		}, objsto.WithHTTPClient(mock))
+	})
+
+	DescribeTable("round tripping typed values",
+		func(codec objsto.Codec, contentType string) {
+			val := record{Name: "bob", Tags: []string{"a", "b"}}
+
+			err := objsto.PutValue(ctx, client, codec, "test-object", val)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mock.DoCalls()[0].Request.Header.Get("Content-Type")).To(Equal(contentType))
+
+			got, err := objsto.GetValue[record](ctx, client, codec, "test-object")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got).To(Equal(val))
+		},
+		Entry("json", objsto.JSON, "application/json"),
+		Entry("gob", objsto.Gob, "application/x-gob"),
+	)
+
+	It("returns error when the body does not decode", func() {
+		stored = []byte("garbage")
+
+		_, err := objsto.GetValue[record](ctx, client, objsto.Gob, "test-object")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`failed to decode "test-object"`))
+	})
+})
@@ -0,0 +1,266 @@
+package objsto
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// UncompressedSizeKey is the metadata key for the size of an object before CompressStore compressed it.
+const UncompressedSizeKey = "uncompressed-size"
+
+// Compression compresses and decompresses objects, named by their Content-Encoding.
+// Gzip is provided here, and zstd by the zstd module.
+type Compression interface {
+	Encoding() string
+	NewWriter(writer io.Writer) (io.WriteCloser, error)
+	NewReader(reader io.Reader) (io.ReadCloser, error)
+}
+
+// Gzip is a Compression for compress/gzip at the default level.
+var Gzip = GzipLevel(gzip.DefaultCompression)
+
+// GzipLevel is a Compression for compress/gzip at level.
+func GzipLevel(level int) Compression {
+
+	return gzipCompression{level: level}
+}
+
+// Incompressible are content type prefixes left uncompressed by default, being compressed already.
+var Incompressible = []string{
+	"image/", "video/", "audio/",
+	"application/gzip", "application/zip", "application/zstd", "application/x-7z-compressed",
+	"application/x-bzip2", "application/x-xz",
+}
+
+// CompressStore wraps an ObjectStore, compressing objects on Put and decompressing them on Get.
+//
+// Objects put are compressed when their content type or key matches, or all of them when nothing is set to match,
+// unless already encoded or of an Incompressible type.
+// They're stored with Content-Encoding set and their original size as metadata,
+// and Stat reports that size, so that callers see objects as they were put.
+// Get stats first to learn whether and how an object was compressed, costing a request,
+// and reads gzip as well as the configured compression, so objects put before a switch stay readable.
+type CompressStore struct {
+	ObjectStore
+	compression Compression
+	types       []string
+	suffixes    []string
+	skip        []string
+}
+
+var _ ObjectStore = &CompressStore{}
+
+// CompressOption sets an optional CompressStore setting.
+type CompressOption func(*CompressStore)
+
+// WithCompression sets the compression used on Put, defaulting to Gzip.
+func WithCompression(compression Compression) CompressOption {
+
+	return func(cs *CompressStore) {
+		cs.compression = compression
+	}
+}
+
+// WithCompressTypes compresses objects with content types starting with any of types, such as "text/" or "application/json".
+func WithCompressTypes(types ...string) CompressOption {
+
+	return func(cs *CompressStore) {
+		cs.types = append(cs.types, types...)
+	}
+}
+
+// WithCompressSuffixes compresses objects with keys ending in any of suffixes, such as ".json".
+func WithCompressSuffixes(suffixes ...string) CompressOption {
+
+	return func(cs *CompressStore) {
+		cs.suffixes = append(cs.suffixes, suffixes...)
+	}
+}
+
+// WithSkipTypes replaces Incompressible as the content type prefixes never compressed.
+func WithSkipTypes(types ...string) CompressOption {
+
+	return func(cs *CompressStore) {
+		cs.skip = types
+	}
+}
+
+// NewCompressStore creates a CompressStore wrapping store.
+func NewCompressStore(store ObjectStore, opts ...CompressOption) *CompressStore {
+
+	cs := &CompressStore{
+		ObjectStore: store,
+		compression: Gzip,
+		skip:        Incompressible,
+	}
+	for _, opt := range opts {
+		opt(cs)
+	}
+
+	return cs
+}
+
+// Get gets an object, decompressing it when stored compressed.
+func (cs *CompressStore) Get(ctx context.Context, object string) (reader io.ReadCloser, err error) {
+
+	// keep the http client from gunzipping on its own
+	ctx = WithHeaders(ctx, http.Header{"Accept-Encoding": {"identity"}})
+
+	info, err := cs.ObjectStore.Stat(ctx, object)
+	if err != nil {
+		return
+	}
+
+	compression, ok := cs.decoder(info.ContentEncoding)
+
+	reader, err = cs.ObjectStore.Get(ctx, object)
+	if err != nil || !ok {
+		return
+	}
+
+	decompressed, err := compression.NewReader(reader)
+	if err != nil {
+		reader.Close()
+		err = errors.Wrapf(err, "failed to decompress %q", object)
+		return
+	}
+
+	reader = &decompressReader{ReadCloser: decompressed, body: reader}
+	return
+}
+
+// Put puts an object, compressing it when it matches.
+func (cs *CompressStore) Put(ctx context.Context, object string, reader io.ReadSeeker, opts ...PutOption) (err error) {
+
+	po := NewPutOptions(opts...)
+	if po.ContentEncoding != "" || !cs.matches(object, po.ContentType) {
+		err = cs.ObjectStore.Put(ctx, object, reader, opts...)
+		return
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	writer, err := cs.compression.NewWriter(buf)
+	if err != nil {
+		err = errors.Wrap(err, "failed to create compressor")
+		return
+	}
+
+	size, err := io.Copy(writer, reader)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "failed to compress %q", object)
+		return
+	}
+
+	opts = append(opts,
+		WithContentEncoding(cs.compression.Encoding()),
+		WithMetadata(map[string]string{UncompressedSizeKey: strconv.FormatInt(size, 10)}),
+	)
+
+	err = cs.ObjectStore.Put(ctx, object, bytes.NewReader(buf.Bytes()), opts...)
+	return
+}
+
+// Stat gets an object's info, with the size and encoding as put.
+func (cs *CompressStore) Stat(ctx context.Context, object string) (info ObjectInfo, err error) {
+
+	info, err = cs.ObjectStore.Stat(ctx, object)
+	if err != nil {
+		return
+	}
+	if _, ok := cs.decoder(info.ContentEncoding); !ok {
+		return
+	}
+
+	size, parseErr := strconv.ParseInt(info.Metadata[UncompressedSizeKey], 10, 64)
+	if parseErr != nil {
+		return
+	}
+
+	info.Size = size
+	info.ContentEncoding = ""
+	info.Metadata = maps.Clone(info.Metadata)
+	delete(info.Metadata, UncompressedSizeKey)
+	return
+}
+
+// unexported
+
+func (cs *CompressStore) matches(object, contentType string) bool {
+
+	for _, prefix := range cs.skip {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+
+	if len(cs.types) == 0 && len(cs.suffixes) == 0 {
+		return true
+	}
+
+	for _, prefix := range cs.types {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	for _, suffix := range cs.suffixes {
+		if strings.HasSuffix(object, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cs *CompressStore) decoder(encoding string) (compression Compression, ok bool) {
+
+	switch encoding {
+	case "":
+		return
+	case cs.compression.Encoding():
+		return cs.compression, true
+	case Gzip.Encoding():
+		return Gzip, true
+	}
+
+	return
+}
+
+type gzipCompression struct {
+	level int
+}
+
+func (gc gzipCompression) Encoding() string {
+	return "gzip"
+}
+
+func (gc gzipCompression) NewWriter(writer io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(writer, gc.level)
+}
+
+func (gc gzipCompression) NewReader(reader io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(reader)
+}
+
+type decompressReader struct {
+	io.ReadCloser
+	body io.ReadCloser
+}
+
+func (dr *decompressReader) Close() error {
+
+	dr.ReadCloser.Close()
+	return dr.body.Close()
+}
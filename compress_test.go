@@ -0,0 +1,130 @@
+package objsto_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+var _ = Describe("CompressStore", func() {
+	var (
+		ctx   = context.Background()
+		inner *memstore.Store
+		cs    *objsto.CompressStore
+		doc   = `{"name": "` + strings.Repeat("example ", 100) + `"}`
+	)
+
+	BeforeEach(func() {
+		inner = memstore.New()
+		cs = objsto.NewCompressStore(inner, objsto.WithCompressTypes("application/json"), objsto.WithCompressSuffixes(".csv"))
+	})
+
+	read := func(reader io.ReadCloser, err error) string {
+
+		Expect(err).ToNot(HaveOccurred())
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	When("putting a matching object", func() {
+		BeforeEach(func() {
+			err := cs.Put(ctx, "doc.json", strings.NewReader(doc), objsto.WithContentType("application/json"))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("stores it gzipped with its size", func() {
+			info, err := inner.Stat(ctx, "doc.json")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.ContentEncoding).To(Equal("gzip"))
+			Expect(info.Size).To(BeNumerically("<", len(doc)))
+			Expect(info.Metadata).To(HaveKeyWithValue(objsto.UncompressedSizeKey, "812"))
+
+			gzr, err := gzip.NewReader(bytes.NewReader([]byte(read(inner.Get(ctx, "doc.json")))))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(read(io.NopCloser(gzr), nil)).To(Equal(doc))
+		})
+
+		It("gets it as put", func() {
+			Expect(read(cs.Get(ctx, "doc.json"))).To(Equal(doc))
+
+			info, err := cs.Stat(ctx, "doc.json")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Size).To(Equal(int64(len(doc))))
+			Expect(info.ContentEncoding).To(BeEmpty())
+			Expect(info.Metadata).ToNot(HaveKey(objsto.UncompressedSizeKey))
+		})
+	})
+
+	It("compresses by key suffix", func() {
+		Expect(cs.Put(ctx, "rows.csv", strings.NewReader("a,b\n"))).To(Succeed())
+
+		info, err := inner.Stat(ctx, "rows.csv")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.ContentEncoding).To(Equal("gzip"))
+		Expect(read(cs.Get(ctx, "rows.csv"))).To(Equal("a,b\n"))
+	})
+
+	It("leaves compressed types alone", func() {
+		cs = objsto.NewCompressStore(inner)
+		Expect(cs.Put(ctx, "pic.jpg", strings.NewReader("jpg"), objsto.WithContentType("image/jpeg"))).To(Succeed())
+		Expect(cs.Put(ctx, "notes.txt", strings.NewReader("notes"))).To(Succeed())
+
+		info, err := inner.Stat(ctx, "pic.jpg")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.ContentEncoding).To(BeEmpty())
+
+		info, err = inner.Stat(ctx, "notes.txt")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.ContentEncoding).To(Equal("gzip"))
+	})
+
+	It("plugs in other compression, still reading gzip", func() {
+		Expect(cs.Put(ctx, "old.json", strings.NewReader(doc), objsto.WithContentType("application/json"))).To(Succeed())
+
+		cs = objsto.NewCompressStore(inner, objsto.WithCompression(deflateDict{dict: []byte(`{"name": "example`)}))
+		Expect(cs.Put(ctx, "new.json", strings.NewReader(doc))).To(Succeed())
+
+		info, err := inner.Stat(ctx, "new.json")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.ContentEncoding).To(Equal("deflate"))
+
+		Expect(read(cs.Get(ctx, "new.json"))).To(Equal(doc))
+		Expect(read(cs.Get(ctx, "old.json"))).To(Equal(doc))
+	})
+
+	It("passes others through", func() {
+		Expect(cs.Put(ctx, "pic.png", strings.NewReader("png"), objsto.WithContentType("image/png"))).To(Succeed())
+
+		Expect(read(inner.Get(ctx, "pic.png"))).To(Equal("png"))
+		Expect(read(cs.Get(ctx, "pic.png"))).To(Equal("png"))
+	})
+})
+
+// deflateDict is a Compression other than gzip, with a dictionary
+type deflateDict struct {
+	dict []byte
+}
+
+func (dd deflateDict) Encoding() string {
+	return "deflate"
+}
+
+func (dd deflateDict) NewWriter(writer io.Writer) (io.WriteCloser, error) {
+	return flate.NewWriterDict(writer, flate.BestCompression, dd.dict)
+}
+
+func (dd deflateDict) NewReader(reader io.Reader) (io.ReadCloser, error) {
+	return flate.NewReaderDict(reader, dd.dict), nil
+}
@@ -0,0 +1,132 @@
+package objsto
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Copy copies srcObject from src to object, server-side without the data passing through.
+// Src must be on the same endpoint, perhaps with another bucket or key prefix, nil for c itself,
+// and c's credentials must be able to read it.
+//
+// Metadata is copied from the source, unless replaced by a content type or metadata in opts.
+func (c *Client) Copy(ctx context.Context, src *Client, srcObject, object string, opts ...PutOption) (err error) {
+
+	if src == nil {
+		src = c
+	}
+	if !c.SameEndpoint(src) {
+		err = errors.Errorf("cannot copy between endpoints %s and %s", src.Endpoint(), c.Endpoint())
+		return
+	}
+	if srcObject == "" || object == "" {
+		err = errors.Errorf("object cannot be blank")
+		return
+	}
+
+	c.logger.Info(ctx, "copying in S3", "source", src.bucket+"/"+src.prefix+srcObject, "object", object)
+
+	po := NewPutOptions(opts...)
+
+	hdr := po.header()
+	hdr.Set("X-Amz-Copy-Source", url.PathEscape(src.bucket)+"/"+escapeKey(src.prefix+srcObject))
+	if po.ContentType != "" || len(po.Metadata) > 0 {
+		hdr.Set("X-Amz-Metadata-Directive", "REPLACE")
+	}
+
+	req, err := c.buildRequest(ctx, "PUT", object, nil, hdr)
+	if err != nil {
+		return
+	}
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	// a copy can fail after the 200 has been sent, with the error in the body
+	result, err := readResult(resp, "copy")
+	if err != nil {
+		return
+	}
+
+	res := putResult(object, resp)
+	res.ETag = strings.Trim(result.ETag, `"`)
+	po.Record(res)
+
+	return
+}
+
+// Bucket is the client's bucket.
+func (c *Client) Bucket() string {
+
+	return c.bucket
+}
+
+// Endpoint is the client's scheme and host, as with "https://s3.us-east-1.amazonaws.com".
+func (c *Client) Endpoint() string {
+
+	return c.scheme + "://" + c.host
+}
+
+// SameEndpoint is true when other talks to the same endpoint and region, such that Copy can be used between them.
+func (c *Client) SameEndpoint(other *Client) bool {
+
+	return c.scheme == other.scheme && c.host == other.host && c.region == other.region
+}
+
+// unexported
+
+// readResult reads the result of a request that can fail after its 200 has been sent, as with copy
+// and complete upload, returning the error in the body if so.
+func readResult(resp *http.Response, what string) (result embeddedResult, err error) {
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*4))
+	if err != nil {
+		err = errors.Wrapf(err, "failed to read %s response", what)
+		return
+	}
+
+	err = xml.Unmarshal(body, &result)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to parse %s response: %s", what, string(body))
+		return
+	}
+	if result.XMLName.Local == "Error" {
+		ids := requestIDs(resp)
+		if result.RequestID != "" {
+			ids.RequestID = result.RequestID
+		}
+		err = errors.Wrapf(ErrRequestFailed, "s3 error, code: %s, request_id: %s, host_id: %s, message: %s",
+			result.Code, ids.RequestID, ids.HostID, result.Message)
+		err = &responseError{error: err, ids: ids}
+	}
+
+	return
+}
+
+type embeddedResult struct {
+	XMLName   xml.Name
+	ETag      string `xml:"ETag"`
+	Code      string `xml:"Code"`
+	Message   string `xml:"Message"`
+	RequestID string `xml:"RequestId"`
+}
+
+// escapeKey escapes each segment of key, leaving slashes be.
+func escapeKey(key string) string {
+
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,24 @@
+package objsto
+
+import "context"
+
+// Credentials are what a request is signed with.
+type Credentials struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+}
+
+// CredentialsProvider supplies Credentials, consulted for every request.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// StaticCredentials are fixed Credentials and the default provider.
+type StaticCredentials Credentials
+
+// Credentials returns the static credentials.
+func (sc StaticCredentials) Credentials(ctx context.Context) (Credentials, error) {
+
+	return Credentials(sc), nil
+}
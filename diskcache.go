@@ -0,0 +1,151 @@
+package objsto
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// diskCache holds objects as files named for key and ETag, evicting by mtime, touched on each hit.
+// Size is tallied from the directory on each fill, so that it's right across restarts.
+type diskCache struct {
+	dir      string
+	maxBytes int64
+	mu       sync.Mutex
+}
+
+// get opens the cached file for info, filling it from store on a miss.
+func (dc *diskCache) get(ctx context.Context, store ObjectStore, info ObjectInfo) (reader io.ReadCloser, hit bool, err error) {
+
+	path := dc.path(info.Key, info.ETag)
+
+	file, err := os.Open(path)
+	if err == nil {
+		now := time.Now()
+		os.Chtimes(path, now, now)
+		reader = file
+		hit = true
+		return
+	}
+
+	err = dc.fill(ctx, store, info.Key, path)
+	if err != nil {
+		return
+	}
+
+	reader, err = os.Open(path)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to open cached %q", info.Key)
+	}
+
+	return
+}
+
+func (dc *diskCache) path(key, etag string) string {
+
+	sum := sha256.Sum256([]byte(key + "\x00" + etag))
+	return filepath.Join(dc.dir, hex.EncodeToString(sum[:]))
+}
+
+// fill downloads to a temp file renamed into place, then evicts to make room.
+func (dc *diskCache) fill(ctx context.Context, store ObjectStore, key, path string) (err error) {
+
+	err = os.MkdirAll(dc.dir, 0755)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to create cache dir %q", dc.dir)
+		return
+	}
+
+	reader, err := store.Get(ctx, key)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	tmp, err := os.CreateTemp(dc.dir, ".fill-*")
+	if err != nil {
+		err = errors.Wrap(err, "failed to create cache file")
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, reader)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "failed to cache %q", key)
+		return
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to cache %q", key)
+		return
+	}
+
+	dc.evict(path)
+	return
+}
+
+// evict removes the least recently used files until under the limit, keeping keep.
+func (dc *diskCache) evict(keep string) {
+
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	entries, err := os.ReadDir(dc.dir)
+	if err != nil {
+		return
+	}
+
+	type cached struct {
+		path  string
+		size  int64
+		mtime time.Time
+	}
+
+	var files []cached
+	var total int64
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, cached{
+			path:  filepath.Join(dc.dir, entry.Name()),
+			size:  info.Size(),
+			mtime: info.ModTime(),
+		})
+		total += info.Size()
+	}
+
+	slices.SortFunc(files, func(a, b cached) int {
+		return a.mtime.Compare(b.mtime)
+	})
+
+	for _, file := range files {
+		if total <= dc.maxBytes {
+			return
+		}
+		if file.path == keep {
+			continue
+		}
+		if os.Remove(file.path) == nil {
+			total -= file.size
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package objsto
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/clarktrimble/launch"
+	"github.com/pkg/errors"
+)
+
+const defaultContinueSize = 8388608
+
+// ParseURL creates Config from a url such as "s3://access:secret@host:port/bucket?region=us-east-1",
+// letting connection info live in a single env var.
+//
+// Query parameters region, scheme, rate_limit, rate_burst, continue_size, and user_agent
+// set the like-named Config fields, with scheme defaulting to https.
+// Credentials containing reserved characters must be percent-encoded.
+func ParseURL(raw string) (cfg *Config, err error) {
+
+	uri, err := url.Parse(raw)
+	if err != nil {
+		// the url.Error quotes the url, credentials and all
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		err = errors.Wrap(err, "failed to parse url")
+		return
+	}
+	if uri.Scheme != "s3" {
+		err = errors.Errorf("url scheme must be s3, got %q", uri.Scheme)
+		return
+	}
+
+	cfg = &Config{
+		Scheme:       "https",
+		Host:         uri.Host,
+		ContinueSize: defaultContinueSize,
+	}
+
+	if uri.User != nil {
+		cfg.AccessKey = uri.User.Username()
+		secret, _ := uri.User.Password()
+		cfg.SecretKey = launch.Redact(secret)
+	}
+
+	cfg.Bucket, err = url.PathUnescape(strings.Trim(uri.EscapedPath(), "/"))
+	if err != nil {
+		err = errors.Wrap(err, "failed to unescape bucket")
+		return
+	}
+
+	for key, vals := range uri.Query() {
+		val := vals[len(vals)-1]
+
+		switch key {
+		case "region":
+			cfg.Region = val
+		case "scheme":
+			cfg.Scheme = val
+		case "user_agent":
+			cfg.UserAgent = val
+		case "rate_limit":
+			cfg.RateLimit, err = strconv.ParseInt(val, 10, 64)
+		case "rate_burst":
+			cfg.RateBurst, err = strconv.ParseInt(val, 10, 64)
+		case "continue_size":
+			cfg.ContinueSize, err = strconv.ParseInt(val, 10, 64)
+		default:
+			err = errors.Errorf("unknown url parameter %q", key)
+			return
+		}
+		if err != nil {
+			err = errors.Wrapf(err, "failed to parse url parameter %q", key)
+			return
+		}
+	}
+
+	switch {
+	case cfg.Host == "":
+		err = errors.Errorf("url must have a host")
+	case cfg.Bucket == "":
+		err = errors.Errorf("url must have a bucket")
+	case cfg.Scheme != "http" && cfg.Scheme != "https":
+		err = errors.Errorf("scheme must be http or https, got %q", cfg.Scheme)
+	}
+
+	return
+}
@@ -0,0 +1,75 @@
+package objsto_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var _ = Describe("ParseURL", func() {
+	var (
+		raw string
+		cfg *objsto.Config
+		err error
+	)
+
+	JustBeforeEach(func() {
+		cfg, err = objsto.ParseURL(raw)
+	})
+
+	When("the url is complete", func() {
+		BeforeEach(func() {
+			raw = "s3://AKID:se%2Fcret@garage.local:3900/my-bucket?region=garage&scheme=http&continue_size=0&user_agent=myapp"
+		})
+
+		It("fills in the config", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg).To(Equal(&objsto.Config{
+				Region:    "garage",
+				Scheme:    "http",
+				Host:      "garage.local:3900",
+				Bucket:    "my-bucket",
+				AccessKey: "AKID",
+				SecretKey: "se/cret",
+				UserAgent: "myapp",
+			}))
+		})
+	})
+
+	When("optional parts are left out", func() {
+		BeforeEach(func() {
+			raw = "s3://AKID:secret@s3.amazonaws.com/my-bucket/?region=us-east-1"
+		})
+
+		It("uses defaults", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.Scheme).To(Equal("https"))
+			Expect(cfg.Bucket).To(Equal("my-bucket"))
+			Expect(cfg.ContinueSize).To(Equal(int64(8388608)))
+		})
+	})
+
+	DescribeTable("bad urls",
+		func(raw, msg string) {
+			_, err := objsto.ParseURL(raw)
+			Expect(err).To(MatchError(ContainSubstring(msg)))
+		},
+		Entry("wrong scheme", "https://host/bucket", "scheme must be s3"),
+		Entry("no bucket", "s3://a:b@host", "must have a bucket"),
+		Entry("unknown param", "s3://a:b@host/bucket?regoin=x", "unknown url parameter"),
+		Entry("bad number", "s3://a:b@host/bucket?rate_limit=lots", "rate_limit"),
+		Entry("bad transport", "s3://a:b@host/bucket?scheme=ftp", "http or https"),
+	)
+
+	DescribeTable("unparseable urls, without the secret",
+		func(raw, msg string) {
+			_, err := objsto.ParseURL(raw)
+			Expect(err).To(MatchError(ContainSubstring(msg)))
+			Expect(err.Error()).ToNot(ContainSubstring("topsecret"))
+		},
+		Entry("bad port", "s3://ak:topsecret@host:bad/b", "invalid port"),
+		Entry("bad escape", "s3://ak:topsecret%zz@host/b", "invalid URL escape"),
+		Entry("control character", "s3://ak:topsecret@host/b\x7f", "invalid control character"),
+	)
+})
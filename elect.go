@@ -0,0 +1,155 @@
+package objsto
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ElectedFunc is called on becoming leader, with a context canceled on resigning
+// and the fencing token of the term.
+type ElectedFunc func(ctx context.Context, token int64)
+
+// ResignedFunc is called on ceasing to be leader, with the error that ended the term,
+// ErrLockLost, as when taken over or renewals fail until the lease would expire,
+// or that of the context given to Run.
+type ResignedFunc func(err error)
+
+// Elector campaigns for leadership with a Lock, for singleton workers running on several hosts.
+//
+// The leader renews its lease in the background, retrying through store trouble,
+// and resigns once the lease is lost or would expire, canceling the context given to OnElected
+// and waiting for it to return before releasing, so that terms don't overlap so long as the work heeds its context.
+// Pass the fencing token along to whatever the work touches to guard against a paused leader.
+type Elector struct {
+	lock       *Lock
+	interval   time.Duration
+	onElected  ElectedFunc
+	onResigned ResignedFunc
+	token      int64
+	mu         sync.Mutex
+}
+
+// ElectorOption sets an optional Elector setting.
+type ElectorOption func(*Elector)
+
+// WithCampaignInterval sets how often a follower tries for leadership, defaulting to a third of the lease TTL.
+func WithCampaignInterval(interval time.Duration) ElectorOption {
+
+	return func(el *Elector) {
+		el.interval = interval
+	}
+}
+
+// WithOnElected sets the function called in its own goroutine on becoming leader.
+func WithOnElected(fn ElectedFunc) ElectorOption {
+
+	return func(el *Elector) {
+		el.onElected = fn
+	}
+}
+
+// WithOnResigned sets the function called on ceasing to be leader.
+func WithOnResigned(fn ResignedFunc) ElectorOption {
+
+	return func(el *Elector) {
+		el.onResigned = fn
+	}
+}
+
+// NewElector creates an Elector campaigning with lock.
+func NewElector(lock *Lock, opts ...ElectorOption) *Elector {
+
+	el := &Elector{
+		lock:     lock,
+		interval: lock.ttl / 3,
+	}
+	for _, opt := range opts {
+		opt(el)
+	}
+
+	return el
+}
+
+// Run campaigns until ctx is done, serving a term whenever elected, and returns ctx.Err().
+func (el *Elector) Run(ctx context.Context) (err error) {
+
+	for {
+		err = el.lock.Acquire(ctx, el.interval)
+		if err != nil {
+			if ctx.Err() == nil {
+				// store trouble, try again next round
+				err = sleep(ctx, el.interval)
+			}
+			if err != nil {
+				err = ctx.Err()
+				return
+			}
+			continue
+		}
+
+		el.serve(ctx)
+
+		if ctx.Err() != nil {
+			err = ctx.Err()
+			return
+		}
+	}
+}
+
+// Leader reports whether a term is being served.
+func (el *Elector) Leader() bool {
+
+	return el.Token() != 0
+}
+
+// Token returns the fencing token of the term being served, or zero.
+func (el *Elector) Token() int64 {
+
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	return el.token
+}
+
+// unexported
+
+// serve runs a term, keeping the lease alive until lost or ctx is done.
+func (el *Elector) serve(ctx context.Context) {
+
+	token := el.lock.Token()
+	el.setToken(token)
+
+	term, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	if el.onElected != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			el.onElected(term, token)
+		}()
+	}
+
+	err := el.lock.KeepAlive(term)
+
+	cancel()
+	wg.Wait()
+	el.setToken(0)
+
+	// release even when ctx is done, sparing the next leader a wait for expiry
+	el.lock.Release(context.WithoutCancel(ctx))
+
+	if el.onResigned != nil {
+		el.onResigned(err)
+	}
+}
+
+func (el *Elector) setToken(token int64) {
+
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	el.token = token
+}
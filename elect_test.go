@@ -0,0 +1,142 @@
+package objsto_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+var _ = Describe("Elector", func() {
+	var (
+		ctx      = context.Background()
+		store    *memstore.Store
+		mu       sync.Mutex
+		events   []string
+		resigned error
+	)
+
+	BeforeEach(func() {
+		store = memstore.New()
+		events = nil
+		resigned = nil
+	})
+
+	elector := func(name string) *objsto.Elector {
+
+		lock := objsto.NewLock(store, "leader", objsto.WithHolder(name), objsto.WithLeaseTTL(60*time.Millisecond))
+
+		return objsto.NewElector(lock,
+			objsto.WithCampaignInterval(5*time.Millisecond),
+			objsto.WithOnElected(func(ctx context.Context, token int64) {
+				mu.Lock()
+				events = append(events, name+" elected")
+				mu.Unlock()
+				<-ctx.Done()
+			}),
+			objsto.WithOnResigned(func(err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				events = append(events, name+" resigned")
+				resigned = err
+			}),
+		)
+	}
+
+	seen := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, events...)
+	}
+
+	It("hands over leadership when the leader stops", func() {
+		one := elector("one")
+		oneCtx, stopOne := context.WithCancel(ctx)
+		oneDone := make(chan error)
+		go func() { oneDone <- one.Run(oneCtx) }()
+
+		Eventually(one.Leader).Should(BeTrue())
+		Expect(one.Token()).To(Equal(int64(1)))
+
+		two := elector("two")
+		twoCtx, stopTwo := context.WithCancel(ctx)
+		twoDone := make(chan error)
+		go func() { twoDone <- two.Run(twoCtx) }()
+
+		Consistently(two.Leader, 100*time.Millisecond).Should(BeFalse())
+
+		stopOne()
+		Expect(<-oneDone).To(MatchError(context.Canceled))
+
+		Eventually(two.Leader).Should(BeTrue())
+		Expect(two.Token()).To(Equal(int64(2)))
+		Expect(seen()).To(Equal([]string{"one elected", "one resigned", "two elected"}))
+
+		stopTwo()
+		Expect(<-twoDone).To(MatchError(context.Canceled))
+	})
+
+	It("rides out renewals failing for a moment", func() {
+		one := elector("one")
+		runCtx, stop := context.WithCancel(ctx)
+		done := make(chan error)
+		go func() { done <- one.Run(runCtx) }()
+
+		Eventually(one.Leader).Should(BeTrue())
+
+		failures := 2
+		store.SetFault(func(op memstore.Op, object string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if op == memstore.OpPut && failures > 0 {
+				failures--
+				return errors.New("bucket smoldering")
+			}
+			return nil
+		})
+
+		Eventually(func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return failures
+		}).Should(BeZero())
+		Consistently(one.Leader, 150*time.Millisecond).Should(BeTrue())
+		Expect(one.Token()).To(Equal(int64(1)))
+		Expect(seen()).To(Equal([]string{"one elected"}))
+
+		stop()
+		Expect(<-done).To(MatchError(context.Canceled))
+	})
+
+	It("resigns when renewal keeps failing until the lease would expire", func() {
+		one := elector("one")
+		runCtx, stop := context.WithCancel(ctx)
+		done := make(chan error)
+		go func() { done <- one.Run(runCtx) }()
+
+		Eventually(one.Leader).Should(BeTrue())
+
+		store.SetFault(func(op memstore.Op, object string) error {
+			if op == memstore.OpPut {
+				return errors.New("bucket on fire")
+			}
+			return nil
+		})
+
+		Eventually(one.Leader).Should(BeFalse())
+		Eventually(seen).Should(Equal([]string{"one elected", "one resigned"}))
+		mu.Lock()
+		Expect(errors.Is(resigned, objsto.ErrLockLost)).To(BeTrue())
+		Expect(resigned).To(MatchError(ContainSubstring("bucket on fire")))
+		mu.Unlock()
+
+		stop()
+		Expect(<-done).To(MatchError(context.Canceled))
+	})
+})
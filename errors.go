@@ -0,0 +1,69 @@
+package objsto
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNotFound is the cause of errors for missing objects, check with errors.Is.
+	ErrNotFound = errors.New("not found")
+	// ErrTooLarge is the cause of errors for objects exceeding a size guard.
+	ErrTooLarge = errors.New("object too large")
+	// ErrNotModified is the cause of errors for conditional gets of unchanged objects.
+	ErrNotModified = errors.New("not modified")
+	// ErrChecksum is the cause of errors for content not matching its expected checksum.
+	ErrChecksum = errors.New("checksum mismatch")
+	// ErrLockLost is the cause of errors for renewing or releasing a Lock whose lease is no longer held.
+	ErrLockLost = errors.New("lock lost")
+	// ErrQueueClosed is the cause of errors for puts to a QueueStore after Drain.
+	ErrQueueClosed = errors.New("queue closed")
+	// ErrPreconditionFailed is the cause of errors for conditional puts whose condition doesn't hold.
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrUnknownTenant is the cause of errors for a tenant not registered with a Router.
+	ErrUnknownTenant = errors.New("unknown tenant")
+	// ErrQuotaExceeded is the cause of errors for puts that would take a tenant over quota.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrConflict is the cause of errors for requests at odds with a bucket's state,
+	// as with creating one that exists or deleting one that isn't empty.
+	ErrConflict = errors.New("conflict")
+	// ErrRequestFailed is the cause of other errors reported by the server.
+	ErrRequestFailed = errors.New("request failed")
+)
+
+// Permanent reports whether err is one a retry would only repeat.
+func Permanent(err error) bool {
+
+	for _, perm := range []error{
+		ErrNotFound,
+		ErrPreconditionFailed,
+		ErrConflict,
+		ErrTooLarge,
+		ErrInvalidKey,
+	} {
+		if errors.Is(err, perm) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unexported
+
+func statusError(status int) error {
+
+	switch status {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusNotModified:
+		return ErrNotModified
+	case http.StatusPreconditionFailed:
+		return ErrPreconditionFailed
+	case http.StatusConflict:
+		return ErrConflict
+	}
+
+	return ErrRequestFailed
+}
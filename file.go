@@ -0,0 +1,70 @@
+package objsto
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const fileMode = 0644
+
+// GetToFile downloads an object to path.
+//
+// The download goes to a temp file in the same directory, renamed into place
+// on success, so a partial file never appears at path.
+func (c *Client) GetToFile(ctx context.Context, object, path string, opts ...GetOption) (info ObjectInfo, err error) {
+
+	gopts := NewGetOptions(opts...)
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		err = errors.Wrapf(err, "failed to create temp file for %q", path)
+		return
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	_, info, err = c.GetInto(ctx, object, tmp)
+	if err != nil {
+		return
+	}
+
+	err = tmp.Sync()
+	if err != nil {
+		err = errors.Wrapf(err, "failed to sync %q", tmp.Name())
+		return
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		err = errors.Wrapf(err, "failed to close %q", tmp.Name())
+		return
+	}
+
+	err = os.Chmod(tmp.Name(), fileMode)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to chmod %q", tmp.Name())
+		return
+	}
+
+	if gopts.PreserveMtime && !info.LastModified.IsZero() {
+		err = os.Chtimes(tmp.Name(), info.LastModified, info.LastModified)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to set mtime on %q", tmp.Name())
+			return
+		}
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to rename to %q", path)
+	}
+
+	return
+}
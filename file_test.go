@@ -0,0 +1,115 @@
+package objsto_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var _ = Describe("GetToFile", func() {
+	var (
+		ctx    context.Context
+		mock   *HttpDoerMock
+		client *objsto.Client
+		dir    string
+		path   string
+		opts   []objsto.GetOption
+		body   io.Reader
+		info   objsto.ObjectInfo
+		err    error
+	)
+
+	modified := time.Date(2026, 3, 2, 6, 0, 53, 0, time.UTC)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		dir = GinkgoT().TempDir()
+		path = filepath.Join(dir, "download.txt")
+		opts = nil
+		body = bytes.NewReader([]byte("test content"))
+
+		mock = &HttpDoerMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				header := http.Header{}
+				header.Set("Last-Modified", modified.Format(http.TimeFormat))
+				return &http.Response{
+					StatusCode: 200,
+					Header:     header,
+					Body:       io.NopCloser(body),
+				}, nil
+			},
+		}
+
+		client = objsto.New(&objsto.Config{
+			Region:    "test-region",
+			Scheme:    "https",
+			Host:      "test-host",
+			Bucket:    "test-bucket",
+			AccessKey: "test-access-key",
+			SecretKey: "test-secret-key",
+		}, objsto.WithHTTPClient(mock))
+	})
+
+	JustBeforeEach(func() {
+		info, err = client.GetToFile(ctx, "test-object.txt", path, opts...)
+	})
+
+	When("download succeeds", func() {
+		It("writes the file", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.LastModified).To(Equal(modified))
+
+			data, err := os.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("test content"))
+
+			stat, err := os.Stat(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stat.ModTime()).ToNot(Equal(modified))
+		})
+	})
+
+	When("preserving mtime", func() {
+		BeforeEach(func() {
+			opts = []objsto.GetOption{objsto.WithMtime()}
+		})
+
+		It("sets mtime from last modified", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			stat, err := os.Stat(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stat.ModTime().UTC()).To(Equal(modified))
+		})
+	})
+
+	When("download fails midway", func() {
+		BeforeEach(func() {
+			body = io.MultiReader(bytes.NewReader([]byte("test")), &failReader{})
+		})
+
+		It("leaves nothing behind", func() {
+			Expect(err).To(HaveOccurred())
+
+			entries, err := os.ReadDir(dir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+	})
+})
+
+type failReader struct{}
+
+func (fr *failReader) Read(buf []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
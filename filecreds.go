@@ -0,0 +1,100 @@
+package objsto
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultRecheck is how often FileCredentials stats its file by default.
+const DefaultRecheck = 10 * time.Second
+
+// FileCredentials reads the secret key from a file, picking up a rotated secret without restart,
+// as when a Kubernetes secret is updated in place.
+//
+// The file is re-statted at most once per recheck interval when credentials are wanted,
+// and re-read when its size or mtime have changed, so no goroutine is involved.
+// Should a re-read fail, the last good secret is used until the next check.
+type FileCredentials struct {
+	accessKey string
+	path      string
+	recheck   time.Duration
+
+	mu      sync.Mutex
+	secret  string
+	modTime time.Time
+	size    int64
+	checked time.Time
+}
+
+// NewFileCredentials creates FileCredentials, zero recheck for DefaultRecheck and negative for every time.
+func NewFileCredentials(accessKey, path string, recheck time.Duration) *FileCredentials {
+
+	if recheck == 0 {
+		recheck = DefaultRecheck
+	}
+
+	return &FileCredentials{
+		accessKey: accessKey,
+		path:      path,
+		recheck:   recheck,
+	}
+}
+
+// Credentials returns credentials with the current secret.
+func (fc *FileCredentials) Credentials(ctx context.Context) (creds Credentials, err error) {
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	now := time.Now()
+	if fc.secret == "" || now.Sub(fc.checked) >= fc.recheck {
+		err = fc.reload()
+		if err != nil && fc.secret == "" {
+			return
+		}
+		err = nil
+		fc.checked = now
+	}
+
+	creds = Credentials{
+		AccessKey: fc.accessKey,
+		SecretKey: fc.secret,
+	}
+	return
+}
+
+// unexported
+
+func (fc *FileCredentials) reload() (err error) {
+
+	info, err := os.Stat(fc.path)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to stat secret file")
+		return
+	}
+	if fc.secret != "" && info.ModTime().Equal(fc.modTime) && info.Size() == fc.size {
+		return
+	}
+
+	data, err := os.ReadFile(fc.path)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to read secret file")
+		return
+	}
+
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		err = errors.Errorf("secret file %q is empty", fc.path)
+		return
+	}
+
+	fc.secret = secret
+	fc.modTime = info.ModTime()
+	fc.size = info.Size()
+	return
+}
@@ -0,0 +1,66 @@
+package objsto_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var _ = Describe("FileCredentials", func() {
+	var (
+		ctx   = context.Background()
+		path  string
+		creds *objsto.FileCredentials
+	)
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "secret_key")
+		creds = objsto.NewFileCredentials("test-access-key", path, -1)
+	})
+
+	When("the file exists", func() {
+		BeforeEach(func() {
+			Expect(os.WriteFile(path, []byte("first-secret\n"), 0600)).To(Succeed())
+		})
+
+		It("reads the secret", func() {
+			got, err := creds.Credentials(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got).To(Equal(objsto.Credentials{AccessKey: "test-access-key", SecretKey: "first-secret"}))
+		})
+
+		It("picks up a rotated secret", func() {
+			_, err := creds.Credentials(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(os.WriteFile(path, []byte("second-secret-rotated"), 0600)).To(Succeed())
+
+			got, err := creds.Credentials(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got.SecretKey).To(Equal("second-secret-rotated"))
+		})
+
+		It("keeps the last good secret when the file goes missing", func() {
+			_, err := creds.Credentials(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(os.Remove(path)).To(Succeed())
+
+			got, err := creds.Credentials(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got.SecretKey).To(Equal("first-secret"))
+		})
+	})
+
+	When("the file does not exist", func() {
+		It("returns error", func() {
+			_, err := creds.Credentials(ctx)
+			Expect(err).To(MatchError(ContainSubstring("failed to stat secret file")))
+		})
+	})
+})
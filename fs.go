@@ -0,0 +1,330 @@
+package objsto
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BucketFS is a read-only fs.FS over objects under a prefix, with slashes in keys as directories.
+type BucketFS struct {
+	store  ObjectStore
+	prefix string
+}
+
+var (
+	_ fs.ReadDirFS = &BucketFS{}
+	_ fs.StatFS    = &BucketFS{}
+)
+
+// FS creates a BucketFS over store, rooted at prefix, which is taken to be a "directory".
+//
+// Being an fs.FS, there's no context to pass, so operations use context.Background.
+// Directories are synthesized from listings and file info is fetched lazily.
+func FS(store ObjectStore, prefix string) *BucketFS {
+
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	return &BucketFS{
+		store:  store,
+		prefix: prefix,
+	}
+}
+
+// Open opens the named file or directory.
+func (bfs *BucketFS) Open(name string) (file fs.File, err error) {
+
+	if !fs.ValidPath(name) {
+		err = &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+		return
+	}
+
+	info, err := bfs.stat(name)
+	if err != nil {
+		err = &fs.PathError{Op: "open", Path: name, Err: err}
+		return
+	}
+
+	if info.IsDir() {
+		file = &bucketDir{bfs: bfs, name: name, info: info}
+		return
+	}
+
+	file = &bucketFile{
+		objectReader: &objectReader{
+			ctx:   context.Background(),
+			store: bfs.store,
+			key:   bfs.key(name),
+			size:  info.Size(),
+		},
+		info: info,
+	}
+	return
+}
+
+// Stat returns info for the named file or directory.
+func (bfs *BucketFS) Stat(name string) (info fs.FileInfo, err error) {
+
+	if !fs.ValidPath(name) {
+		err = &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+		return
+	}
+
+	info, err = bfs.stat(name)
+	if err != nil {
+		err = &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+
+	return
+}
+
+// ReadDir reads the named directory, returning entries sorted by name.
+func (bfs *BucketFS) ReadDir(name string) (entries []fs.DirEntry, err error) {
+
+	if !fs.ValidPath(name) {
+		err = &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+		return
+	}
+
+	entries, err = bfs.readDir(name)
+	if err != nil {
+		err = &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+
+	return
+}
+
+// unexported
+
+func (bfs *BucketFS) key(name string) string {
+
+	if name == "." {
+		return bfs.prefix
+	}
+	return bfs.prefix + name
+}
+
+func (bfs *BucketFS) dirKey(name string) string {
+
+	if name == "." {
+		return bfs.prefix
+	}
+	return bfs.prefix + name + "/"
+}
+
+func (bfs *BucketFS) stat(name string) (info fs.FileInfo, err error) {
+
+	ctx := context.Background()
+
+	if name != "." {
+		var obj ObjectInfo
+		obj, err = bfs.store.Stat(ctx, bfs.key(name))
+		if err == nil {
+			info = fileInfo{name: path.Base(name), obj: obj}
+			return
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return
+		}
+	}
+
+	entries, err := bfs.list(ctx, name, 1)
+	if err != nil {
+		return
+	}
+	if len(entries) == 0 && name != "." {
+		err = fs.ErrNotExist
+		return
+	}
+
+	info = dirInfo{name: path.Base(name)}
+	return
+}
+
+func (bfs *BucketFS) readDir(name string) (entries []fs.DirEntry, err error) {
+
+	entries, err = bfs.list(context.Background(), name, 0)
+	if err != nil {
+		return
+	}
+	if len(entries) == 0 && name != "." {
+		err = fs.ErrNotExist
+		return
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return
+}
+
+// list lists up to limit entries of the named directory, zero for all, unsorted.
+// An ObjectLister lists with a delimiter, a page per directory level,
+// while other stores list the whole subtree to be rolled up here.
+func (bfs *BucketFS) list(ctx context.Context, name string, limit int) (entries []fs.DirEntry, err error) {
+
+	dirKey := bfs.dirKey(name)
+
+	lister, ok := bfs.store.(ObjectLister)
+	if ok {
+		for info, err := range lister.ListObjects(ctx, ListInput{Prefix: dirKey, Delimiter: "/", MaxKeys: limit}) {
+			if err != nil {
+				return nil, err
+			}
+
+			child := strings.TrimPrefix(info.Key, dirKey)
+			switch {
+			case child == "":
+				// a placeholder for the directory itself
+				continue
+			case strings.HasSuffix(child, "/"):
+				entries = append(entries, fs.FileInfoToDirEntry(dirInfo{name: strings.TrimSuffix(child, "/")}))
+			default:
+				// to the second, as Stat has it from Last-Modified
+				info.LastModified = info.LastModified.Truncate(time.Second)
+				entries = append(entries, fs.FileInfoToDirEntry(fileInfo{name: child, obj: info}))
+			}
+
+			if limit > 0 && len(entries) >= limit {
+				break
+			}
+		}
+		return
+	}
+
+	keys, err := bfs.store.List(ctx, dirKey)
+	if err != nil {
+		return
+	}
+
+	seen := map[string]bool{}
+	for _, key := range keys {
+		rest := strings.TrimPrefix(key, dirKey)
+		child, _, isDir := strings.Cut(rest, "/")
+		if child == "" || seen[child] {
+			continue
+		}
+		seen[child] = true
+
+		if isDir {
+			entries = append(entries, fs.FileInfoToDirEntry(dirInfo{name: child}))
+			continue
+		}
+		entries = append(entries, &lazyEntry{bfs: bfs, name: child, key: key})
+	}
+
+	return
+}
+
+type fileInfo struct {
+	name string
+	obj  ObjectInfo
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return fi.obj.Size }
+func (fi fileInfo) Mode() fs.FileMode  { return 0444 }
+func (fi fileInfo) ModTime() time.Time { return fi.obj.LastModified }
+func (fi fileInfo) IsDir() bool        { return false }
+func (fi fileInfo) Sys() any           { return fi.obj }
+
+type dirInfo struct {
+	name string
+}
+
+func (di dirInfo) Name() string       { return di.name }
+func (di dirInfo) Size() int64        { return 0 }
+func (di dirInfo) Mode() fs.FileMode  { return fs.ModeDir | 0555 }
+func (di dirInfo) ModTime() time.Time { return time.Time{} }
+func (di dirInfo) IsDir() bool        { return true }
+func (di dirInfo) Sys() any           { return nil }
+
+// lazyEntry stats on demand, sparing a request per entry when only names are wanted.
+type lazyEntry struct {
+	bfs  *BucketFS
+	name string
+	key  string
+}
+
+func (le *lazyEntry) Name() string      { return le.name }
+func (le *lazyEntry) IsDir() bool       { return false }
+func (le *lazyEntry) Type() fs.FileMode { return 0 }
+
+func (le *lazyEntry) Info() (fs.FileInfo, error) {
+
+	obj, err := le.bfs.store.Stat(context.Background(), le.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return fileInfo{name: le.name, obj: obj}, nil
+}
+
+// bucketFile reads an object, getting it on first read and again after a seek.
+type bucketFile struct {
+	*objectReader
+	info fs.FileInfo
+}
+
+func (bf *bucketFile) Stat() (fs.FileInfo, error) {
+
+	return bf.info, nil
+}
+
+type bucketDir struct {
+	bfs     *BucketFS
+	name    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (bd *bucketDir) Stat() (fs.FileInfo, error) {
+
+	return bd.info, nil
+}
+
+func (bd *bucketDir) Read(buf []byte) (int, error) {
+
+	return 0, &fs.PathError{Op: "read", Path: bd.name, Err: errors.New("is a directory")}
+}
+
+func (bd *bucketDir) Close() error {
+
+	return nil
+}
+
+func (bd *bucketDir) ReadDir(count int) (entries []fs.DirEntry, err error) {
+
+	if !bd.read {
+		bd.entries, err = bd.bfs.readDir(bd.name)
+		if err != nil {
+			return
+		}
+		bd.read = true
+	}
+
+	if count <= 0 {
+		entries = bd.entries
+		bd.entries = nil
+		return
+	}
+
+	if len(bd.entries) == 0 {
+		err = io.EOF
+		return
+	}
+
+	count = min(count, len(bd.entries))
+	entries = bd.entries[:count]
+	bd.entries = bd.entries[count:]
+	return
+}
@@ -0,0 +1,125 @@
+package objsto_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing/fstest"
+
+	"github.com/pkg/errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+	"github.com/clarktrimble/objsto/objstotest"
+)
+
+// delimitedOnly refuses to List, a walk of the whole subtree.
+type delimitedOnly struct {
+	*objsto.Client
+}
+
+func (do delimitedOnly) List(ctx context.Context, prefix string) ([]string, error) {
+	return nil, errors.New("listed the subtree")
+}
+
+var _ = Describe("BucketFS", func() {
+	var (
+		ctx   = context.Background()
+		store *memstore.Store
+		bfs   *objsto.BucketFS
+	)
+
+	BeforeEach(func() {
+		store = memstore.New()
+		for key, content := range map[string]string{
+			"site/index.html":        "<h1>hi</h1>",
+			"site/css/main.css":      "body {}",
+			"site/css/print/all.css": "@media print {}",
+			"site/robots.txt":        "User-agent: *",
+			"other/secret.txt":       "nope",
+		} {
+			err := store.Put(ctx, key, bytes.NewReader([]byte(content)))
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		bfs = objsto.FS(store, "site")
+	})
+
+	It("passes fstest", func() {
+		err := fstest.TestFS(bfs, "index.html", "robots.txt", "css/main.css", "css/print/all.css")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("reads directories", func() {
+		entries, err := fs.ReadDir(bfs, ".")
+		Expect(err).ToNot(HaveOccurred())
+
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		Expect(names).To(Equal([]string{"css", "index.html", "robots.txt"}))
+		Expect(entries[0].IsDir()).To(BeTrue())
+	})
+
+	It("does not see outside the prefix", func() {
+		_, err := fs.Stat(bfs, "../other/secret.txt")
+		Expect(err).To(HaveOccurred())
+
+		_, err = fs.Stat(bfs, "secret.txt")
+		Expect(err).To(MatchError(fs.ErrNotExist))
+	})
+
+	When("the store is an ObjectLister", func() {
+		var srv *objstotest.Server
+
+		BeforeEach(func() {
+			srv = objstotest.New()
+			DeferCleanup(srv.Close)
+
+			client := srv.Client("")
+			for _, key := range []string{"site/index.html", "site/css/main.css", "site/css/print/all.css", "other/secret.txt"} {
+				Expect(client.PutString(ctx, key, "content of "+key)).To(Succeed())
+			}
+
+			bfs = objsto.FS(delimitedOnly{Client: client}, "site")
+		})
+
+		It("lists a level at a time rather than the subtree", func() {
+			Expect(fstest.TestFS(bfs, "index.html", "css/main.css", "css/print/all.css")).To(Succeed())
+
+			entries, err := fs.ReadDir(bfs, "css")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[0].Name()).To(Equal("main.css"))
+			Expect(entries[1].Name()).To(Equal("print"))
+			Expect(entries[1].IsDir()).To(BeTrue())
+
+			info, err := entries[0].Info()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Size()).To(Equal(int64(len("content of site/css/main.css"))))
+
+			_, err = fs.Stat(bfs, "nope")
+			Expect(err).To(MatchError(fs.ErrNotExist))
+		})
+	})
+
+	It("serves via http.FS", func() {
+		srv := httptest.NewServer(http.FileServerFS(bfs))
+		defer srv.Close()
+
+		resp, err := http.Get(srv.URL + "/css/main.css")
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		Expect(resp.StatusCode).To(Equal(200))
+		Expect(string(body)).To(Equal("body {}"))
+	})
+})
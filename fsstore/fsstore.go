@@ -0,0 +1,364 @@
+// Package fsstore implements objsto.ObjectStore on a local directory,
+// handy for development without an S3 compatible server.
+//
+// Keys map to paths under the root directory, with metadata kept in
+// sidecar files under a hidden directory alongside.
+// Unlike S3, a key cannot be both an object and a prefix of others, as in "a" and "a/b".
+package fsstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+)
+
+const (
+	metaDir  = ".objsto-meta"
+	dirMode  = 0755
+	fileMode = 0644
+)
+
+// Config is Store configurables tagged for use with envconfig.
+type Config struct {
+	Root string `json:"root" desc:"directory to store objects under" required:"true"`
+}
+
+// Store is a filesystem backed ObjectStore.
+// Conditional puts are atomic among users of the same Store, not across processes.
+type Store struct {
+	root string
+	mu   sync.Mutex
+}
+
+var _ objsto.ObjectStore = &Store{}
+
+// New creates Store from Config, creating the root directory if needed.
+func (cfg *Config) New() (store *Store, err error) {
+
+	err = os.MkdirAll(cfg.Root, dirMode)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to create root %q", cfg.Root)
+		return
+	}
+
+	store = &Store{root: cfg.Root}
+	return
+}
+
+// Get gets an object.
+func (store *Store) Get(ctx context.Context, object string) (reader io.ReadCloser, err error) {
+
+	path, err := store.path(object)
+	if err != nil {
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		err = wrap(err, object)
+		return
+	}
+
+	// a directory holds objects rather than being one
+	stat, err := file.Stat()
+	if err == nil && stat.IsDir() {
+		err = fs.ErrNotExist
+	}
+	if err != nil {
+		file.Close()
+		err = wrap(err, object)
+		return
+	}
+
+	reader = file
+	return
+}
+
+// Put puts an object, writing to a temp file and renaming into place.
+func (store *Store) Put(ctx context.Context, object string, reader io.ReadSeeker, opts ...objsto.PutOption) (err error) {
+
+	path, err := store.path(object)
+	if err != nil {
+		return
+	}
+	po := objsto.NewPutOptions(opts...)
+
+	if po.IfMatch != "" || po.IfNoneMatch != "" {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+
+		err = po.Check(object, store.etag(ctx, object))
+		if err != nil {
+			return
+		}
+	}
+
+	hash := md5.New()
+	err = writeFile(path, io.TeeReader(reader, hash))
+	if err != nil {
+		err = errors.Wrapf(err, "failed to put %q", object)
+		return
+	}
+
+	etag := hex.EncodeToString(hash.Sum(nil))
+
+	data, err := json.Marshal(sidecar{
+		ETag:            etag,
+		ContentType:     po.ContentType,
+		ContentEncoding: po.ContentEncoding,
+		Metadata:        po.Metadata,
+		StorageClass:    po.StorageClass,
+		Tags:            po.Tags,
+	})
+	if err != nil {
+		err = errors.Wrapf(err, "failed to marshal metadata for %q", object)
+		return
+	}
+
+	err = writeFile(store.metaPath(object), bytes.NewReader(data))
+	if err != nil {
+		err = errors.Wrapf(err, "failed to put metadata for %q", object)
+		return
+	}
+
+	po.Record(objsto.PutResult{Key: object, ETag: etag})
+	return
+}
+
+// Delete deletes an object, pruning emptied directories.
+// As with S3, deleting an object that does not exist is not an error.
+func (store *Store) Delete(ctx context.Context, object string) (err error) {
+
+	path, err := store.path(object)
+	if err != nil {
+		return
+	}
+
+	for _, name := range []string{path, store.metaPath(object)} {
+		if stat, statErr := os.Stat(name); statErr == nil && stat.IsDir() {
+			// holding other objects, not one to delete
+			continue
+		}
+
+		err = os.Remove(name)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			err = errors.Wrapf(err, "failed to delete %q", object)
+			return
+		}
+		err = nil
+		store.prune(filepath.Dir(name))
+	}
+
+	return
+}
+
+// List returns object keys matching the given prefix, in order.
+func (store *Store) List(ctx context.Context, prefix string) (keys []string, err error) {
+
+	err = filepath.WalkDir(store.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == metaDir && filepath.Dir(path) == filepath.Clean(store.root) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") && strings.HasSuffix(entry.Name(), ".tmp") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(store.root, path)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		err = errors.Wrapf(err, "failed to list %q", prefix)
+		return
+	}
+
+	// byte order as with S3, walk order differs when names share a prefix
+	slices.Sort(keys)
+	return
+}
+
+// Stat gets an object's metadata.
+func (store *Store) Stat(ctx context.Context, object string) (info objsto.ObjectInfo, err error) {
+
+	path, err := store.path(object)
+	if err != nil {
+		return
+	}
+
+	stat, err := os.Stat(path)
+	if err == nil && stat.IsDir() {
+		err = fs.ErrNotExist
+	}
+	if err != nil {
+		err = wrap(err, object)
+		return
+	}
+
+	info = objsto.ObjectInfo{
+		Key:          object,
+		Size:         stat.Size(),
+		LastModified: stat.ModTime().UTC().Truncate(time.Second),
+	}
+
+	data, err := os.ReadFile(store.metaPath(object))
+	if errors.Is(err, fs.ErrNotExist) {
+		// dropped in by hand perhaps
+		err = nil
+		return
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "failed to read metadata for %q", object)
+		return
+	}
+
+	var meta sidecar
+	err = json.Unmarshal(data, &meta)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to unmarshal metadata for %q", object)
+		return
+	}
+
+	info.ETag = meta.ETag
+	info.ContentType = meta.ContentType
+	info.ContentEncoding = meta.ContentEncoding
+	info.Metadata = meta.Metadata
+	return
+}
+
+// unexported
+
+type sidecar struct {
+	ETag            string            `json:"etag"`
+	ContentType     string            `json:"content_type,omitempty"`
+	ContentEncoding string            `json:"content_encoding,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	StorageClass    string            `json:"storage_class,omitempty"`
+	Tags            map[string]string `json:"tags,omitempty"`
+}
+
+// etag is an object's current etag, blank when there's no object.
+func (store *Store) etag(ctx context.Context, object string) string {
+
+	info, err := store.Stat(ctx, object)
+	if err != nil {
+		return ""
+	}
+
+	// dropped in by hand perhaps, still exists
+	if info.ETag == "" {
+		return "-"
+	}
+	return info.ETag
+}
+
+func (store *Store) path(object string) (path string, err error) {
+
+	if object == "" {
+		err = errors.Errorf("object cannot be blank")
+		return
+	}
+
+	rel := filepath.FromSlash(object)
+	if !filepath.IsLocal(rel) || strings.HasSuffix(object, "/") {
+		err = errors.Errorf("object %q does not map to a file", object)
+		return
+	}
+	if strings.SplitN(object, "/", 2)[0] == metaDir {
+		err = errors.Errorf("object %q is reserved", object)
+		return
+	}
+
+	path = filepath.Join(store.root, rel)
+	return
+}
+
+func (store *Store) metaPath(object string) string {
+
+	return filepath.Join(store.root, metaDir, filepath.FromSlash(object)) + ".json"
+}
+
+// prune removes empty directories up to, but not including, root.
+func (store *Store) prune(dir string) {
+
+	root := filepath.Clean(store.root)
+	for dir != root && dir != filepath.Join(root, metaDir) && strings.HasPrefix(dir, root) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func writeFile(path string, reader io.Reader) (err error) {
+
+	dir := filepath.Dir(path)
+	err = os.MkdirAll(dir, dirMode)
+	if err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	_, err = io.Copy(tmp, reader)
+	if err != nil {
+		return
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return
+	}
+
+	err = os.Chmod(tmp.Name(), fileMode)
+	if err != nil {
+		return
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	return
+}
+
+func wrap(err error, object string) error {
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return errors.Wrapf(objsto.ErrNotFound, "no such object %q", object)
+	}
+
+	return errors.Wrapf(err, "failed to access %q", object)
+}
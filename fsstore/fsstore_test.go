@@ -0,0 +1,155 @@
+package fsstore_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/fsstore"
+)
+
+func TestFsStore(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "FsStore Suite")
+}
+
+var _ = Describe("Store", func() {
+	var (
+		ctx   = context.Background()
+		root  string
+		store *fsstore.Store
+	)
+
+	BeforeEach(func() {
+		root = filepath.Join(GinkgoT().TempDir(), "objects")
+
+		var err error
+		store, err = (&fsstore.Config{Root: root}).New()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	put := func(object, content string, opts ...objsto.PutOption) {
+		err := store.Put(ctx, object, bytes.NewReader([]byte(content)), opts...)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	Describe("Put and Get", func() {
+		BeforeEach(func() {
+			put("dir/test-object.txt", "test content")
+		})
+
+		It("round trips content in a file under root", func() {
+			reader, err := store.Get(ctx, "dir/test-object.txt")
+			Expect(err).ToNot(HaveOccurred())
+			defer reader.Close()
+
+			content, _ := io.ReadAll(reader)
+			Expect(string(content)).To(Equal("test content"))
+
+			_, err = os.Stat(filepath.Join(root, "dir", "test-object.txt"))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns not found for missing objects", func() {
+			_, err := store.Get(ctx, "dir/nope.txt")
+			Expect(errors.Is(err, objsto.ErrNotFound)).To(BeTrue())
+		})
+
+		It("returns not found for a directory", func() {
+			_, err := store.Get(ctx, "dir")
+			Expect(errors.Is(err, objsto.ErrNotFound)).To(BeTrue())
+		})
+
+		It("rejects keys escaping root", func() {
+			_, err := store.Get(ctx, "../outside.txt")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("does not map to a file"))
+		})
+	})
+
+	Describe("Stat", func() {
+		BeforeEach(func() {
+			put("test-object.txt", "test content",
+				objsto.WithContentType("text/plain"),
+				objsto.WithMetadata(map[string]string{"owner": "bob"}),
+			)
+		})
+
+		It("returns info with metadata from the sidecar", func() {
+			info, err := store.Stat(ctx, "test-object.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Key).To(Equal("test-object.txt"))
+			Expect(info.Size).To(Equal(int64(12)))
+			Expect(info.ETag).To(Equal("9473fdd0d880a43c21b7778d34872157"))
+			Expect(info.ContentType).To(Equal("text/plain"))
+			Expect(info.Metadata).To(Equal(map[string]string{"owner": "bob"}))
+			Expect(info.LastModified).ToNot(BeZero())
+		})
+
+		It("returns not found for missing objects", func() {
+			_, err := store.Stat(ctx, "nope.txt")
+			Expect(errors.Is(err, objsto.ErrNotFound)).To(BeTrue())
+		})
+
+		It("returns not found for a directory", func() {
+			put("dir/nested.txt", "nested")
+
+			_, err := store.Stat(ctx, "dir")
+			Expect(errors.Is(err, objsto.ErrNotFound)).To(BeTrue())
+		})
+	})
+
+	Describe("List", func() {
+		BeforeEach(func() {
+			put("export_1_b.json", "b")
+			put("export_1_a.json", "a")
+			put("export_1/nested.json", "n")
+			put("other.json", "o")
+		})
+
+		It("returns matching keys in byte order without sidecars", func() {
+			keys, err := store.List(ctx, "export_1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(keys).To(Equal([]string{
+				"export_1/nested.json",
+				"export_1_a.json",
+				"export_1_b.json",
+			}))
+		})
+	})
+
+	Describe("Delete", func() {
+		BeforeEach(func() {
+			put("dir/sub/test-object.txt", "test content")
+		})
+
+		It("removes the object and emptied directories", func() {
+			err := store.Delete(ctx, "dir/sub/test-object.txt")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = store.Stat(ctx, "dir/sub/test-object.txt")
+			Expect(errors.Is(err, objsto.ErrNotFound)).To(BeTrue())
+
+			_, err = os.Stat(filepath.Join(root, "dir"))
+			Expect(errors.Is(err, os.ErrNotExist)).To(BeTrue())
+		})
+
+		It("leaves a directory of other objects alone", func() {
+			Expect(store.Delete(ctx, "dir/sub")).To(Succeed())
+			Expect(store.List(ctx, "dir/")).To(Equal([]string{"dir/sub/test-object.txt"}))
+		})
+
+		It("does not mind missing objects", func() {
+			err := store.Delete(ctx, "nope.txt")
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,216 @@
+package objsto
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+	"testing"
+)
+
+// fuzzing inputs a caller or endpoint controls, as a key or path through Proxy and an error body back
+
+func FuzzURIEncode(f *testing.F) {
+
+	for _, seed := range []string{"", "a.txt", "my file.txt", "what?#now", "100%+tax", "ünï/ключ", "a//b/./../c", "\x00\xff"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, key string) {
+
+		encoded := uriEncode(key)
+		if !isEncoded(encoded, "/") {
+			t.Fatalf("unexpected characters in %q", encoded)
+		}
+
+		decoded, err := url.PathUnescape(encoded)
+		if err != nil || decoded != key {
+			t.Fatalf("%q decodes to %q, %v", encoded, decoded, err)
+		}
+	})
+}
+
+func FuzzCanonicalQuery(f *testing.F) {
+
+	f.Add("prefix", "a b+c", "delimiter", "/")
+	f.Add("Param1", "value2", "Param1", "Value1")
+	f.Add("[", "", "A", "=&;")
+	f.Add("ሴ", "\xff", "", "%zz")
+
+	f.Fuzz(func(t *testing.T, name1, val1, name2, val2 string) {
+
+		query := url.Values{}
+		query.Add(name1, val1)
+		query.Add(name2, val2)
+
+		canonical := canonicalQuery(query)
+		if !isEncoded(canonical, "=&") {
+			t.Fatalf("unexpected characters in %q", canonical)
+		}
+
+		parsed, err := url.ParseQuery(canonical)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", canonical, err)
+		}
+		if canonicalQuery(parsed) != canonical {
+			t.Fatalf("%q is not stable, got %q", canonical, canonicalQuery(parsed))
+		}
+
+		pairs := strings.Split(canonical, "&")
+		for idx := 1; idx < len(pairs); idx++ {
+			prevName, prevVal, _ := strings.Cut(pairs[idx-1], "=")
+			name, val, _ := strings.Cut(pairs[idx], "=")
+			if prevName > name || prevName == name && prevVal > val {
+				t.Fatalf("%q is out of order", canonical)
+			}
+		}
+	})
+}
+
+func FuzzNewRequest(f *testing.F) {
+
+	for _, seed := range []string{"a.txt", "my file.txt", "what?#now", "100%+tax", "ünï/ключ", "a//b/./../c", "\x00\xff", "%2F"} {
+		f.Add(seed)
+	}
+
+	cfg := &Config{Region: "us-east-1", Scheme: "https", Host: "test-host", Bucket: "test-bucket", AccessKey: "AK", SecretKey: "SK"}
+	client := New(cfg, WithClock(fixedClock(signTime)))
+
+	f.Fuzz(func(t *testing.T, key string) {
+
+		if key == "" {
+			return
+		}
+
+		query := url.Values{"prefix": {key}}
+		req, err := client.newRequest(context.Background(), "GET", key, query, nil, 0, emptyHash, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// what goes on the wire is what was signed
+		signed := "/test-bucket/" + uriEncode(key)
+		if req.URL.RequestURI() != signed+"?"+canonicalQuery(query) {
+			t.Fatalf("unexpected request uri %q for key %q", req.URL.RequestURI(), key)
+		}
+		if req.URL.Host != "test-host" || req.URL.Path != "/test-bucket/"+key || req.URL.Query().Get("prefix") != key {
+			t.Fatalf("unexpected url %q for key %q", req.URL, key)
+		}
+	})
+}
+
+func FuzzProxyPath(f *testing.F) {
+
+	for _, seed := range []string{"/test-bucket/a.txt", "/test-bucket", "/test-bucket/../other/a", "/test-bucket//a", "/other/a", "/test-bucket/a b?c", "/test-bucketx/a", ""} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, reqPath string) {
+
+		var sent *http.Request
+		doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+			sent = req
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Header: http.Header{}}, nil
+		})
+
+		cfg := &Config{Region: "us-east-1", Scheme: "https", Host: "test-host", Bucket: "test-bucket", AccessKey: "AK", SecretKey: "SK"}
+		px := NewProxy(New(cfg, WithHTTPClient(doer)))
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.URL.Path = reqPath
+		px.ServeHTTP(httptest.NewRecorder(), req)
+
+		if sent == nil {
+			return
+		}
+
+		cleaned := path.Clean(sent.URL.Path)
+		if cleaned != "/test-bucket" && !strings.HasPrefix(cleaned, "/test-bucket/") {
+			t.Fatalf("forwarded %q outside the bucket", sent.URL.Path)
+		}
+		if sent.URL.Path != reqPath {
+			t.Fatalf("forwarded %q for %q", sent.URL.Path, reqPath)
+		}
+		if sent.URL.EscapedPath() != uriEncode(reqPath) {
+			t.Fatalf("sent %q rather than the signed %q", sent.URL.EscapedPath(), uriEncode(reqPath))
+		}
+	})
+}
+
+func FuzzParseS3Error(f *testing.F) {
+
+	f.Add(404, []byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>gone</Message><RequestId>R1</RequestId></Error>`))
+	f.Add(403, []byte(`<Error><Code>SignatureDoesNotMatch</Code><Message>no`))
+	f.Add(500, []byte(`<Error><Code><Code>nested</Code></Code></Error>`))
+	f.Add(409, []byte("<Error>\x00\xff</Error>"))
+	f.Add(412, []byte(`<!DOCTYPE x [<!ENTITY a "aaaa">]><Error><Message>&a;&a;</Message></Error>`))
+	f.Add(503, []byte(strings.Repeat("<Error>", 2000)))
+	f.Add(304, []byte{})
+
+	f.Fuzz(func(t *testing.T, status int, body []byte) {
+
+		resp := &http.Response{
+			StatusCode: status,
+			Header: http.Header{
+				"Set-Cookie":       {"session=cookie-secret"},
+				"X-Amz-Request-Id": {"R0"},
+			},
+			Body: io.NopCloser(strings.NewReader(string(body))),
+		}
+
+		err := parseS3Error(resp)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if !errors.Is(err, statusError(status)) {
+			t.Fatalf("%v is not %v", err, statusError(status))
+		}
+
+		var rerr *responseError
+		if !errors.As(err, &rerr) || rerr.ids.RequestID == "" {
+			t.Fatalf("%v has no request id", err)
+		}
+
+		msg := err.Error()
+		if len(msg) > 16*1024 {
+			t.Fatalf("message of %d bytes", len(msg))
+		}
+		if strings.Contains(msg, "cookie-secret") && !strings.Contains(string(body), "cookie-secret") {
+			t.Fatalf("header secret in %q", msg)
+		}
+	})
+}
+
+// isEncoded is true when val has only unreserved characters, percent-encodings and those allowed.
+func isEncoded(val, allowed string) bool {
+
+	for idx := 0; idx < len(val); idx++ {
+		ch := val[idx]
+		switch {
+		case 'A' <= ch && ch <= 'Z', 'a' <= ch && ch <= 'z', '0' <= ch && ch <= '9',
+			ch == '-', ch == '.', ch == '_', ch == '~', strings.IndexByte(allowed, ch) >= 0:
+		case ch == '%' && idx+2 < len(val) && isUpperHex(val[idx+1]) && isUpperHex(val[idx+2]):
+			idx += 2
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func isUpperHex(ch byte) bool {
+
+	return '0' <= ch && ch <= '9' || 'A' <= ch && ch <= 'F'
+}
+
+// doerFunc is an HttpDoer from a func.
+type doerFunc func(*http.Request) (*http.Response, error)
+
+func (df doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return df(req)
+}
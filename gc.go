@@ -0,0 +1,155 @@
+package objsto
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultUploadTTL is how old an incomplete multipart upload gets before GC aborts it by default.
+const DefaultUploadTTL = 24 * time.Hour
+
+// DefaultTempPrefixes are where GC removes old objects by default.
+var DefaultTempPrefixes = []string{"tmp/"}
+
+// GCReport summarizes a collection.
+type GCReport struct {
+	Deleted  []string          `json:"deleted"`
+	Aborted  []Upload          `json:"aborted"`
+	Failures map[string]string `json:"failures,omitempty"`
+	Elapsed  time.Duration     `json:"elapsed"`
+}
+
+// GC removes leftovers: objects under temp prefixes older than a TTL, and,
+// when the store is an UploadAborter, incomplete multipart uploads older than the upload TTL.
+type GC struct {
+	store     ObjectStore
+	ttl       time.Duration
+	prefixes  []string
+	uploadTTL time.Duration
+	dryRun    bool
+	clock     Clock
+}
+
+// GCOption sets an optional GC setting.
+type GCOption func(*GC)
+
+// WithTempPrefixes sets where old objects are removed, defaulting to DefaultTempPrefixes.
+func WithTempPrefixes(prefixes ...string) GCOption {
+
+	return func(gc *GC) {
+		gc.prefixes = prefixes
+	}
+}
+
+// WithUploadTTL sets how old an incomplete upload gets before it's aborted, defaulting to DefaultUploadTTL.
+func WithUploadTTL(ttl time.Duration) GCOption {
+
+	return func(gc *GC) {
+		gc.uploadTTL = ttl
+	}
+}
+
+// WithGCDryRun reports what would be removed without removing it.
+func WithGCDryRun() GCOption {
+
+	return func(gc *GC) {
+		gc.dryRun = true
+	}
+}
+
+// WithGCClock sets the clock ages are judged by, as for testing.
+func WithGCClock(clock Clock) GCOption {
+
+	return func(gc *GC) {
+		gc.clock = clock
+	}
+}
+
+// NewGC creates a GC removing temp objects older than ttl from store.
+func NewGC(store ObjectStore, ttl time.Duration, opts ...GCOption) *GC {
+
+	gc := &GC{
+		store:     store,
+		ttl:       ttl,
+		prefixes:  DefaultTempPrefixes,
+		uploadTTL: DefaultUploadTTL,
+		clock:     systemClock{},
+	}
+	for _, opt := range opts {
+		opt(gc)
+	}
+
+	return gc
+}
+
+// Collect makes a pass over the temp prefixes and incomplete uploads.
+// Objects and uploads that fail to be removed are reported and left for the next pass.
+func (gc *GC) Collect(ctx context.Context) (report GCReport, err error) {
+
+	start := time.Now()
+	report.Deleted = []string{}
+	report.Aborted = []Upload{}
+
+	opts := []SweepOption{WithSweepClock(gc.clock)}
+	if gc.dryRun {
+		opts = append(opts, WithSweepDryRun())
+	}
+
+	for _, prefix := range gc.prefixes {
+		var swept SweepReport
+		swept, err = NewSweeper(gc.store, prefix, AgeExpiry(gc.ttl), opts...).Sweep(ctx)
+		report.Deleted = append(report.Deleted, swept.Deleted...)
+		for object, msg := range swept.Failures {
+			report.failed(object, msg)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	if ua, ok := gc.store.(UploadAborter); ok {
+		err = gc.abort(ctx, ua, &report)
+		if err != nil {
+			return
+		}
+	}
+
+	report.Elapsed = time.Since(start)
+	return
+}
+
+// unexported
+
+func (gc *GC) abort(ctx context.Context, ua UploadAborter, report *GCReport) (err error) {
+
+	uploads, err := ua.ListUploads(ctx, "")
+	if err != nil {
+		return
+	}
+
+	cutoff := gc.clock.Now().Add(-gc.uploadTTL)
+	for _, upl := range uploads {
+		if !upl.Initiated.Before(cutoff) {
+			continue
+		}
+
+		if !gc.dryRun {
+			err := ua.AbortUpload(ctx, upl.Key, upl.UploadID)
+			if err != nil {
+				report.failed(upl.Key+"?uploadId="+upl.UploadID, err.Error())
+				continue
+			}
+		}
+		report.Aborted = append(report.Aborted, upl)
+	}
+
+	return
+}
+
+func (report *GCReport) failed(object, msg string) {
+
+	if report.Failures == nil {
+		report.Failures = map[string]string{}
+	}
+	report.Failures[object] = msg
+}
@@ -0,0 +1,52 @@
+package objsto_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+var _ = Describe("GC", func() {
+	var (
+		ctx   = context.Background()
+		store *memstore.Store
+		clock *stepClock
+	)
+
+	BeforeEach(func() {
+		store = memstore.New()
+		clock = &stepClock{now: time.Now().Add(2 * time.Hour)}
+
+		for _, key := range []string{"tmp/a", "scratch/b", "keep/c"} {
+			Expect(store.Put(ctx, key, strings.NewReader("x"))).To(Succeed())
+		}
+	})
+
+	It("removes old objects under temp prefixes", func() {
+		gc := objsto.NewGC(store, time.Hour, objsto.WithTempPrefixes("tmp/", "scratch/"), objsto.WithGCClock(clock))
+
+		report, err := gc.Collect(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Deleted).To(Equal([]string{"tmp/a", "scratch/b"}))
+		Expect(store.List(ctx, "")).To(Equal([]string{"keep/c"}))
+	})
+
+	It("leaves young objects and reports only on a dry run", func() {
+		gc := objsto.NewGC(store, 3*time.Hour, objsto.WithGCClock(clock))
+		report, err := gc.Collect(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Deleted).To(BeEmpty())
+
+		gc = objsto.NewGC(store, time.Hour, objsto.WithGCDryRun(), objsto.WithGCClock(clock))
+		report, err = gc.Collect(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Deleted).To(Equal([]string{"tmp/a"}))
+		Expect(store.Len()).To(Equal(3))
+	})
+})
@@ -0,0 +1,116 @@
+package objsto
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Handler serves objects over HTTP, mapping request paths to keys under a prefix.
+//
+// Range, If-None-Match, and If-Modified-Since are honored via http.ServeContent,
+// with ranged gets when the store is a RangeGetter.
+type Handler struct {
+	store  ObjectStore
+	prefix string
+	index  string
+}
+
+// HandlerOption sets an optional setting for Handler.
+type HandlerOption func(*Handler)
+
+// WithIndex sets a key, such as "index.html", to serve for paths ending in a slash
+// or when no object is found at the path itself.
+func WithIndex(index string) HandlerOption {
+
+	return func(hdl *Handler) {
+		hdl.index = index
+	}
+}
+
+// NewHandler creates a Handler serving objects from store under prefix.
+func NewHandler(store ObjectStore, prefix string, opts ...HandlerOption) *Handler {
+
+	hdl := &Handler{
+		store:  store,
+		prefix: prefix,
+	}
+	for _, opt := range opts {
+		opt(hdl)
+	}
+
+	return hdl
+}
+
+// ServeHTTP serves the object for the request path.
+func (hdl *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		writer.Header().Set("Allow", "GET, HEAD")
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := request.Context()
+
+	name := strings.TrimPrefix(path.Clean("/"+request.URL.Path), "/")
+	if strings.HasSuffix(request.URL.Path, "/") && name != "" {
+		name += "/"
+	}
+
+	key, info, err := hdl.lookup(ctx, name)
+	if errors.Is(err, ErrNotFound) {
+		http.NotFound(writer, request)
+		return
+	}
+	if err != nil {
+		http.Error(writer, "failed to stat object", http.StatusBadGateway)
+		return
+	}
+
+	header := writer.Header()
+	if info.ContentType != "" {
+		header.Set("Content-Type", info.ContentType)
+	}
+	if info.ETag != "" {
+		header.Set("ETag", `"`+info.ETag+`"`)
+	}
+
+	reader := &objectReader{
+		ctx:   ctx,
+		store: hdl.store,
+		key:   key,
+		size:  info.Size,
+	}
+	defer reader.Close()
+
+	http.ServeContent(writer, request, path.Base(key), info.LastModified, reader)
+}
+
+// unexported
+
+func (hdl *Handler) lookup(ctx context.Context, name string) (key string, info ObjectInfo, err error) {
+
+	if name == "" || strings.HasSuffix(name, "/") {
+		if hdl.index == "" {
+			err = ErrNotFound
+			return
+		}
+		key = hdl.prefix + name + hdl.index
+		info, err = hdl.store.Stat(ctx, key)
+		return
+	}
+
+	key = hdl.prefix + name
+	info, err = hdl.store.Stat(ctx, key)
+	if !errors.Is(err, ErrNotFound) || hdl.index == "" {
+		return
+	}
+
+	key = hdl.prefix + name + "/" + hdl.index
+	info, err = hdl.store.Stat(ctx, key)
+	return
+}
@@ -0,0 +1,137 @@
+package objsto_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+// rangeStore adds GetRange to memstore, recording the offsets asked for.
+type rangeStore struct {
+	*memstore.Store
+	offsets []int64
+}
+
+func (rs *rangeStore) GetRange(ctx context.Context, object string, offset, length int64) (io.ReadCloser, error) {
+
+	rs.offsets = append(rs.offsets, offset)
+
+	reader, err := rs.Get(ctx, object)
+	if err != nil {
+		return nil, err
+	}
+	io.CopyN(io.Discard, reader, offset)
+	return reader, nil
+}
+
+var _ = Describe("Handler", func() {
+	var (
+		ctx   = context.Background()
+		store *rangeStore
+		opts  []objsto.HandlerOption
+		req   *http.Request
+		rec   *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		store = &rangeStore{Store: memstore.New()}
+
+		err := store.Put(ctx, "www/hello.txt", bytes.NewReader([]byte("hello world")), objsto.WithContentType("text/plain"))
+		Expect(err).ToNot(HaveOccurred())
+		err = store.Put(ctx, "www/docs/index.html", bytes.NewReader([]byte("<h1>docs</h1>")))
+		Expect(err).ToNot(HaveOccurred())
+
+		opts = nil
+		req = httptest.NewRequest("GET", "/hello.txt", nil)
+	})
+
+	JustBeforeEach(func() {
+		rec = httptest.NewRecorder()
+		objsto.NewHandler(store, "www/", opts...).ServeHTTP(rec, req)
+	})
+
+	When("getting an object", func() {
+		It("serves it with headers", func() {
+			Expect(rec.Code).To(Equal(200))
+			Expect(rec.Body.String()).To(Equal("hello world"))
+			Expect(rec.Header().Get("Content-Type")).To(Equal("text/plain"))
+			Expect(rec.Header().Get("ETag")).To(MatchRegexp(`^"[0-9a-f]+"$`))
+			Expect(rec.Header().Get("Last-Modified")).ToNot(BeEmpty())
+		})
+	})
+
+	When("the request has a range", func() {
+		BeforeEach(func() {
+			req.Header.Set("Range", "bytes=6-")
+		})
+
+		It("serves partial content with a ranged get", func() {
+			Expect(rec.Code).To(Equal(http.StatusPartialContent))
+			Expect(rec.Body.String()).To(Equal("world"))
+			Expect(store.offsets).To(Equal([]int64{6}))
+		})
+	})
+
+	When("the etag matches", func() {
+		BeforeEach(func() {
+			info, err := store.Stat(ctx, "www/hello.txt")
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("If-None-Match", `"`+info.ETag+`"`)
+		})
+
+		It("responds not modified", func() {
+			Expect(rec.Code).To(Equal(http.StatusNotModified))
+			Expect(rec.Body.Len()).To(BeZero())
+		})
+	})
+
+	When("the object is missing", func() {
+		BeforeEach(func() {
+			req = httptest.NewRequest("GET", "/nope.txt", nil)
+		})
+
+		It("responds not found", func() {
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	When("the path is a directory with an index", func() {
+		BeforeEach(func() {
+			opts = []objsto.HandlerOption{objsto.WithIndex("index.html")}
+			req = httptest.NewRequest("GET", "/docs", nil)
+		})
+
+		It("serves the index", func() {
+			Expect(rec.Code).To(Equal(200))
+			Expect(rec.Body.String()).To(Equal("<h1>docs</h1>"))
+		})
+	})
+
+	When("the path is a directory without an index", func() {
+		BeforeEach(func() {
+			req = httptest.NewRequest("GET", "/docs/", nil)
+		})
+
+		It("responds not found", func() {
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	When("the method is not allowed", func() {
+		BeforeEach(func() {
+			req = httptest.NewRequest("DELETE", "/hello.txt", nil)
+		})
+
+		It("responds method not allowed", func() {
+			Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+	})
+})
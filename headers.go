@@ -0,0 +1,41 @@
+package objsto
+
+import (
+	"context"
+	"net/http"
+)
+
+// WithHeaders returns a context adding hdr to requests made with it, such as provider extensions.
+// Headers named x-amz-* and Content-MD5 are signed, others are sent as is.
+// Headers set by an operation itself take precedence.
+func WithHeaders(ctx context.Context, hdr http.Header) context.Context {
+
+	merged := headersFrom(ctx).Clone()
+	if merged == nil {
+		merged = http.Header{}
+	}
+	for key, vals := range hdr {
+		merged[http.CanonicalHeaderKey(key)] = vals
+	}
+
+	return context.WithValue(ctx, headersKey{}, merged)
+}
+
+// unexported
+
+type headersKey struct{}
+
+func headersFrom(ctx context.Context) http.Header {
+
+	hdr, _ := ctx.Value(headersKey{}).(http.Header)
+	return hdr
+}
+
+func addHeaders(ctx context.Context, hdr http.Header) {
+
+	for key, vals := range headersFrom(ctx) {
+		if _, ok := hdr[key]; !ok {
+			hdr[key] = vals
+		}
+	}
+}
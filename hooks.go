@@ -0,0 +1,20 @@
+package objsto
+
+import (
+	"net/http"
+	"time"
+)
+
+// Hooks are called around each attempt at sending a request, retries included.
+// Either may be nil.
+//
+// BeforeSend sees the signed request, so headers it adds, such as for tracing,
+// are not signed and headers it changes that were signed will fail verification.
+// Returning an error abandons the request.
+//
+// AfterReceive sees the response, if any, and the error, if any, with an error
+// response's body already consumed.
+type Hooks struct {
+	BeforeSend   func(req *http.Request) error
+	AfterReceive func(req *http.Request, resp *http.Response, err error, elapsed time.Duration)
+}
@@ -0,0 +1,13 @@
+// Package integration holds an opt-in suite exercising objsto.Client against a running
+// S3-compatible endpoint, such as Garage or MinIO, rather than the in-memory objstotest.
+//
+// It is built only with the integration tag, and skipped unless OBJSTO_TEST_URL gives an
+// endpoint in the form objsto.ParseURL takes:
+//
+//	docker run -d -p 9000:9000 minio/minio server /data
+//	OBJSTO_TEST_URL='s3://minioadmin:minioadmin@localhost:9000/objsto-it?scheme=http&region=us-east-1' \
+//		go test -tags integration -count 1 ./integration/
+//
+// The bucket is created when missing and is otherwise left alone, each spec working under
+// a prefix of its own, deleted after.
+package integration
@@ -0,0 +1,292 @@
+//go:build integration
+
+package integration_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+func TestIntegration(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Integration Suite")
+}
+
+var base *objsto.Client
+
+var _ = BeforeSuite(func() {
+	raw := os.Getenv("OBJSTO_TEST_URL")
+	if raw == "" {
+		Skip("OBJSTO_TEST_URL not set")
+	}
+
+	cfg, err := objsto.ParseURL(raw)
+	Expect(err).ToNot(HaveOccurred())
+	base = objsto.New(cfg)
+	DeferCleanup(base.Close)
+
+	ctx := context.Background()
+	exists, err := base.BucketExists(ctx)
+	Expect(err).ToNot(HaveOccurred())
+	if !exists {
+		Expect(base.CreateBucket(ctx)).To(Succeed())
+	}
+})
+
+var _ = Describe("Client", func() {
+	var (
+		ctx    context.Context
+		client *objsto.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		client = base.Clone(objsto.WithKeyPrefix(fmt.Sprintf("objsto-it/%d/", time.Now().UnixNano())))
+
+		DeferCleanup(func() {
+			keys, err := client.List(ctx, "")
+			Expect(err).ToNot(HaveOccurred())
+			for batch := range slices.Chunk(keys, objsto.MaxBatchDelete) {
+				failed, err := client.DeleteObjects(ctx, batch)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(failed).To(BeEmpty())
+			}
+		})
+	})
+
+	read := func(object string) string {
+		data, err := client.GetBytes(ctx, object, 1<<30)
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	Describe("objects", func() {
+		It("puts, stats, gets and deletes", func() {
+			Expect(client.PutString(ctx, "a.txt", "ay", objsto.WithContentType("text/plain"),
+				objsto.WithMetadata(map[string]string{"color": "red"}))).To(Succeed())
+
+			info, err := client.Stat(ctx, "a.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Size).To(Equal(int64(2)))
+			Expect(info.ContentType).To(Equal("text/plain"))
+			Expect(info.Metadata).To(HaveKeyWithValue("color", "red"))
+			Expect(read("a.txt")).To(Equal("ay"))
+
+			Expect(client.Delete(ctx, "a.txt")).To(Succeed())
+			_, err = client.Stat(ctx, "a.txt")
+			Expect(err).To(MatchError(objsto.ErrNotFound))
+		})
+
+		It("gets a range", func() {
+			Expect(client.PutString(ctx, "digits", "0123456789")).To(Succeed())
+
+			reader, err := client.GetRange(ctx, "digits", 7, 3)
+			Expect(err).ToNot(HaveOccurred())
+			defer reader.Close()
+			data, err := io.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("789"))
+		})
+
+		It("copies", func() {
+			Expect(client.PutString(ctx, "src", "copied")).To(Succeed())
+			Expect(client.Copy(ctx, client, "src", "dst")).To(Succeed())
+			Expect(read("dst")).To(Equal("copied"))
+		})
+
+		It("serves a presigned url", func() {
+			Expect(client.PutString(ctx, "shared", "here you go")).To(Succeed())
+
+			uri, err := client.Presign(ctx, "GET", "shared", time.Minute)
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err := http.Get(uri)
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			data, err := io.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(string(data)).To(Equal("here you go"))
+		})
+	})
+
+	Describe("large uploads", func() {
+		It("streams in parts", func() {
+			data := make([]byte, objsto.MinPartSize*2+1234)
+			rand.Read(data)
+
+			Expect(client.PutStream(ctx, "big", bytes.NewReader(data), objsto.MinPartSize)).To(Succeed())
+
+			info, err := client.Stat(ctx, "big")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Size).To(Equal(int64(len(data))))
+			Expect(info.ETag).To(HaveSuffix("-3"))
+
+			// across a part boundary
+			reader, err := client.GetRange(ctx, "big", objsto.MinPartSize-10, 20)
+			Expect(err).ToNot(HaveOccurred())
+			defer reader.Close()
+			part, err := io.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(part).To(Equal(data[objsto.MinPartSize-10 : objsto.MinPartSize+10]))
+
+			got, err := client.GetBytes(ctx, "big", int64(len(data)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bytes.Equal(got, data)).To(BeTrue())
+
+			uploads, err := client.ListUploads(ctx, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uploads).To(BeEmpty())
+		})
+	})
+
+	Describe("listings", func() {
+		BeforeEach(func() {
+			for idx := range 25 {
+				Expect(client.PutString(ctx, fmt.Sprintf("flat/%02d", idx), "x")).To(Succeed())
+			}
+			for _, key := range []string{"tree/a", "tree/logs/1", "tree/logs/2", "tree/old/1", "tree/z"} {
+				Expect(client.PutString(ctx, key, "x")).To(Succeed())
+			}
+		})
+
+		It("pages through everything", func() {
+			keys := []string{}
+			for info, err := range client.ListObjects(ctx, objsto.ListInput{Prefix: "flat/", MaxKeys: 10}) {
+				Expect(err).ToNot(HaveOccurred())
+				keys = append(keys, info.Key)
+			}
+			Expect(keys).To(HaveLen(25))
+			Expect(slices.IsSorted(keys)).To(BeTrue())
+			Expect(keys[0]).To(Equal("flat/00"))
+		})
+
+		It("rolls up folders with a delimiter", func() {
+			page, err := client.NewPaginator(objsto.ListInput{Prefix: "tree/", Delimiter: "/"}).NextPage(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page.CommonPrefixes).To(Equal([]string{"tree/logs/", "tree/old/"}))
+			Expect(page.Objects).To(HaveLen(2))
+			Expect(page.Objects[0].Key).To(Equal("tree/a"))
+			Expect(page.Objects[1].Key).To(Equal("tree/z"))
+		})
+
+		It("starts after a key", func() {
+			page, err := client.NewPaginator(objsto.ListInput{Prefix: "tree/", StartAfter: "tree/logs/2"}).NextPage(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page.Objects).To(HaveLen(2))
+			Expect(page.Objects[0].Key).To(Equal("tree/old/1"))
+		})
+	})
+
+	Describe("odd keys", func() {
+		keys := []string{
+			"ünïcödé/日本語.txt",
+			"emoji/🪣.bin",
+			"space in the key",
+			"reserved?#%+&=;:@$,!'()*",
+			"tilde~and_under-dash.",
+			"double//slash",
+		}
+
+		It("round trips and lists keys as put", func() {
+			for _, key := range keys {
+				Expect(client.PutString(ctx, key, key)).To(Succeed(), key)
+				Expect(read(key)).To(Equal(key))
+
+				info, err := client.Stat(ctx, key)
+				Expect(err).ToNot(HaveOccurred(), key)
+				Expect(info.Size).To(Equal(int64(len(key))))
+			}
+
+			listed, err := client.List(ctx, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(listed).To(ConsistOf(keys))
+		})
+
+		It("copies and batch deletes them", func() {
+			for _, key := range keys {
+				Expect(client.PutString(ctx, key, key)).To(Succeed(), key)
+				Expect(client.Copy(ctx, client, key, "copy/"+key)).To(Succeed(), key)
+				Expect(read("copy/" + key)).To(Equal(key))
+			}
+
+			failed, err := client.DeleteObjects(ctx, keys)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(failed).To(BeEmpty())
+
+			listed, err := client.List(ctx, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(listed).To(HaveLen(len(keys)))
+		})
+	})
+
+	Describe("error paths", func() {
+		It("is not found for a missing object", func() {
+			_, err := client.Stat(ctx, "missing")
+			Expect(err).To(MatchError(objsto.ErrNotFound))
+
+			_, err = client.Get(ctx, "missing")
+			Expect(err).To(MatchError(objsto.ErrNotFound))
+
+			Expect(client.Delete(ctx, "missing")).To(Succeed())
+		})
+
+		It("is not found for a missing bucket", func() {
+			other := client.Clone(objsto.WithBucket(fmt.Sprintf("objsto-missing-%d", time.Now().UnixNano())))
+
+			exists, err := other.BucketExists(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(exists).To(BeFalse())
+
+			_, err = other.Get(ctx, "key")
+			Expect(err).To(MatchError(objsto.ErrNotFound))
+		})
+
+		It("is not modified for a matching etag", func() {
+			Expect(client.PutString(ctx, "etag", "tagged")).To(Succeed())
+			info, err := client.Stat(ctx, "etag")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, _, err = client.GetIfNoneMatch(ctx, "etag", info.ETag)
+			Expect(err).To(MatchError(objsto.ErrNotModified))
+		})
+
+		It("fails with the wrong secret", func() {
+			cfg, err := objsto.ParseURL(os.Getenv("OBJSTO_TEST_URL"))
+			Expect(err).ToNot(HaveOccurred())
+			cfg.SecretKey = "wrong"
+
+			err = objsto.New(cfg).PutString(ctx, "objsto-it/denied", "no")
+			Expect(err).To(MatchError(objsto.ErrRequestFailed))
+		})
+
+		It("leaves nothing behind when a stream fails", func() {
+			failing := io.MultiReader(bytes.NewReader(make([]byte, objsto.MinPartSize+1)), iotest.ErrReader(errors.New("source went away")))
+
+			err := client.PutStream(ctx, "broken", failing, objsto.MinPartSize)
+			Expect(err).To(HaveOccurred())
+
+			_, err = client.Stat(ctx, "broken")
+			Expect(err).To(MatchError(objsto.ErrNotFound))
+
+			uploads, err := client.ListUploads(ctx, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uploads).To(BeEmpty())
+		})
+	})
+})
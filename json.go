@@ -0,0 +1,27 @@
+package objsto
+
+import (
+	"context"
+)
+
+const jsonType = "application/json"
+
+// PutJSON puts val marshalled as JSON, with Content-Type set to match.
+func (c *Client) PutJSON(ctx context.Context, object string, val any, opts ...PutOption) (err error) {
+
+	err = PutValue(ctx, c, JSON, object, val, opts...)
+	return
+}
+
+// GetJSON gets an object, unmarshalling it into val.
+func (c *Client) GetJSON(ctx context.Context, object string, val any) (err error) {
+
+	reader, err := c.Get(ctx, object)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	err = decode(JSON, object, reader, val)
+	return
+}
@@ -0,0 +1,85 @@
+package objsto
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// MaxKeyLen is the longest key allowed by S3, in bytes.
+const MaxKeyLen = 1024
+
+// avoided are characters S3 suggests avoiding in keys, needing special handling by many tools.
+const avoided = "\\{^}%`]\">[~<#|"
+
+// ErrInvalidKey is returned for a key that cannot be stored.
+var ErrInvalidKey = errors.New("invalid key")
+
+// ValidateKey checks a key is non-blank, within MaxKeyLen, valid UTF-8, and free of control characters.
+func ValidateKey(key string) (err error) {
+
+	switch {
+	case key == "":
+		err = errors.Wrap(ErrInvalidKey, "key cannot be blank")
+	case len(key) > MaxKeyLen:
+		err = errors.Wrapf(ErrInvalidKey, "key is %d bytes, over %d", len(key), MaxKeyLen)
+	case !utf8.ValidString(key):
+		err = errors.Wrapf(ErrInvalidKey, "key %q is not valid utf-8", key)
+	case strings.ContainsFunc(key, unicode.IsControl):
+		err = errors.Wrapf(ErrInvalidKey, "key %q contains a control character", key)
+	}
+
+	return
+}
+
+// NormalizeKey trims leading slashes and collapses runs of slashes.
+func NormalizeKey(key string) string {
+
+	var bldr strings.Builder
+	bldr.Grow(len(key))
+
+	prev := byte('/')
+	for i := 0; i < len(key); i++ {
+		if key[i] == '/' && prev == '/' {
+			continue
+		}
+		bldr.WriteByte(key[i])
+		prev = key[i]
+	}
+
+	return bldr.String()
+}
+
+// KeyPolicy is applied to keys before requests leave the client, see WithKeyPolicy.
+type KeyPolicy struct {
+	// Normalize keys with NormalizeKey.
+	Normalize bool
+	// Replacement for characters S3 suggests avoiding, such as "\" and "#", when not blank.
+	Replacement string
+}
+
+// Apply normalizes and maps key as configured, then validates it.
+func (kp KeyPolicy) Apply(key string) (applied string, err error) {
+
+	applied = key
+	if kp.Normalize {
+		applied = NormalizeKey(applied)
+	}
+
+	if kp.Replacement != "" && strings.ContainsAny(applied, avoided) {
+		var bldr strings.Builder
+		for _, rn := range applied {
+			if strings.ContainsRune(avoided, rn) {
+				bldr.WriteString(kp.Replacement)
+				continue
+			}
+			bldr.WriteRune(rn)
+		}
+		applied = bldr.String()
+	}
+
+	err = ValidateKey(applied)
+	return
+}
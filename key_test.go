@@ -0,0 +1,55 @@
+package objsto_test
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var _ = Describe("Keys", func() {
+
+	DescribeTable("ValidateKey",
+		func(key string, valid bool) {
+			err := objsto.ValidateKey(key)
+			if valid {
+				Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			Expect(errors.Is(err, objsto.ErrInvalidKey)).To(BeTrue())
+		},
+		Entry("plain", "reports/2026/q3.pdf", true),
+		Entry("unicode", "café/naïve.txt", true),
+		Entry("blank", "", false),
+		Entry("too long", strings.Repeat("a", 1025), false),
+		Entry("bad utf-8", "bad\xff.txt", false),
+		Entry("control", "tab\there", false),
+	)
+
+	DescribeTable("NormalizeKey",
+		func(key, expected string) {
+			Expect(objsto.NormalizeKey(key)).To(Equal(expected))
+		},
+		Entry("clean", "a/b/c.txt", "a/b/c.txt"),
+		Entry("leading", "//a/b", "a/b"),
+		Entry("doubled", "a//b///c", "a/b/c"),
+	)
+
+	Describe("KeyPolicy", func() {
+		It("normalizes and replaces", func() {
+			policy := objsto.KeyPolicy{Normalize: true, Replacement: "_"}
+
+			key, err := policy.Apply("/uploads//report #3 {final}.pdf")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(key).To(Equal("uploads/report _3 _final_.pdf"))
+		})
+
+		It("validates", func() {
+			_, err := objsto.KeyPolicy{}.Apply("bell\a")
+			Expect(errors.Is(err, objsto.ErrInvalidKey)).To(BeTrue())
+		})
+	})
+})
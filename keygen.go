@@ -0,0 +1,125 @@
+package objsto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"path"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDateLayout = "2006/01/02"
+	defaultFanout     = 2
+	crockford         = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+// KeyGen generates keys with listing-friendly, collision-free layouts.
+// The zero value is ready to use, as with Keys.
+type KeyGen struct {
+	// DateLayout for Dated, defaulting to "2006/01/02".
+	DateLayout string
+	// Fanout is the number of two-character directories ahead of the hash in Hashed, defaulting to 2.
+	Fanout int
+}
+
+// Keys is a KeyGen with default settings.
+var Keys = KeyGen{}
+
+// Dated gives a key under prefix partitioned by date, in UTC, and ending with a ULID,
+// such as "events/2025/01/07/01JH0XKZ4E8Q7W9B6V3N2M1P0R".
+// Keys made in a partition list in order of time.
+func (kg KeyGen) Dated(prefix string, t time.Time) string {
+
+	layout := kg.DateLayout
+	if layout == "" {
+		layout = defaultDateLayout
+	}
+
+	return path.Join(prefix, t.UTC().Format(layout), ULID(t))
+}
+
+// Hashed gives a content-addressed key under prefix from the SHA-256 of data,
+// fanned out as with "blobs/ab/cd/abcd...", so identical data always lands on the same key.
+func (kg KeyGen) Hashed(prefix string, data []byte) string {
+
+	sum := sha256.Sum256(data)
+	return kg.fanout(prefix, hex.EncodeToString(sum[:]))
+}
+
+// ULID returns a new ULID for t, a lexically sortable unique id in Crockford base32.
+// Those made within the same millisecond increment the random part of the last, as in the
+// ULID spec, so they too sort in the order made.
+func ULID(t time.Time) string {
+
+	var id [16]byte
+	ms := t.UnixMilli()
+	binary.BigEndian.PutUint64(id[:8], uint64(ms)<<16)
+
+	entropy.mu.Lock()
+	if ms != entropy.ms || !increment(entropy.last[:]) {
+		rand.Read(entropy.last[:])
+	}
+	entropy.ms = ms
+	copy(id[6:], entropy.last[:])
+	entropy.mu.Unlock()
+
+	return encodeULID(id)
+}
+
+// unexported
+
+// entropy is the random part of the last ULID and its millisecond.
+var entropy struct {
+	ms   int64
+	last [10]byte
+	mu   sync.Mutex
+}
+
+// increment adds one to big-endian val, false on overflow.
+func increment(val []byte) bool {
+
+	for i := len(val) - 1; i >= 0; i-- {
+		val[i]++
+		if val[i] != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (kg KeyGen) fanout(prefix, hash string) string {
+
+	fanout := kg.Fanout
+	if fanout == 0 {
+		fanout = defaultFanout
+	}
+	fanout = min(fanout, len(hash)/2)
+
+	parts := []string{prefix}
+	for i := range fanout {
+		parts = append(parts, hash[i*2:i*2+2])
+	}
+	parts = append(parts, hash)
+
+	return path.Join(parts...)
+}
+
+// encodeULID encodes 128 bits as 26 base32 characters, 5 bits at a time from the top.
+func encodeULID(id [16]byte) string {
+
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(out[:])
+}
@@ -0,0 +1,64 @@
+package objsto_test
+
+import (
+	"slices"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var _ = Describe("KeyGen", func() {
+	var (
+		ts = time.Date(2025, 1, 7, 23, 30, 0, 0, time.FixedZone("EST", -5*3600))
+	)
+
+	Describe("Dated", func() {
+		It("partitions by utc date with a ulid", func() {
+			key := objsto.Keys.Dated("events", ts)
+			Expect(key).To(MatchRegexp(`^events/2025/01/08/[0-9A-HJKMNP-TV-Z]{26}$`))
+		})
+
+		It("honors the layout", func() {
+			key := objsto.KeyGen{DateLayout: "2006-01"}.Dated("events", ts)
+			Expect(key).To(HavePrefix("events/2025-01/"))
+		})
+	})
+
+	Describe("ULID", func() {
+		It("encodes the time in the leading characters", func() {
+			Expect(objsto.ULID(time.UnixMilli(1469918176385))).To(HavePrefix("01ARYZ6S41"))
+		})
+
+		It("sorts by time", func() {
+			early := objsto.ULID(ts)
+			late := objsto.ULID(ts.Add(time.Millisecond))
+			Expect(early < late).To(BeTrue())
+			Expect(objsto.ULID(ts)).ToNot(Equal(early))
+		})
+
+		It("sorts in the order made within a millisecond", func() {
+			ids := make([]string, 1000)
+			for idx := range ids {
+				ids[idx] = objsto.ULID(ts)
+			}
+			Expect(slices.IsSorted(ids)).To(BeTrue())
+			Expect(slices.Compact(slices.Clone(ids))).To(HaveLen(len(ids)))
+			Expect(ids[999]).To(HavePrefix(ids[0][:10]))
+		})
+	})
+
+	Describe("Hashed", func() {
+		It("fans out the content hash", func() {
+			key := objsto.Keys.Hashed("blobs", []byte("hello"))
+			Expect(key).To(Equal("blobs/2c/f2/2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"))
+		})
+
+		It("honors the fanout", func() {
+			key := objsto.KeyGen{Fanout: 1}.Hashed("blobs", []byte("hello"))
+			Expect(key).To(HavePrefix("blobs/2c/2cf2"))
+		})
+	})
+})
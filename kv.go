@@ -0,0 +1,125 @@
+package objsto
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// KV keeps small JSON records as objects under a prefix, for config and state living in the bucket.
+//
+// Each record's version is its ETag, returned by Get and Set, and SetIf puts only when the
+// version is unchanged, so that concurrent read-modify-write cycles don't clobber one another.
+// The store must support conditional puts for SetIf, as do Client, memstore, and fsstore.
+type KV struct {
+	store  ObjectStore
+	prefix string
+}
+
+// NewKV creates a KV keeping records under prefix in store.
+func NewKV(store ObjectStore, prefix string) *KV {
+
+	return &KV{store: store, prefix: prefix}
+}
+
+// Get unmarshals the record for key into val, returning its version.
+func (kv *KV) Get(ctx context.Context, key string, val any) (version string, err error) {
+
+	reader, version, err := getVersioned(ctx, kv.store, kv.prefix+key)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	err = decode(JSON, kv.prefix+key, reader, val)
+	return
+}
+
+// Set puts val as the record for key regardless of version, returning the new version.
+func (kv *KV) Set(ctx context.Context, key string, val any) (version string, err error) {
+
+	version, err = kv.set(ctx, key, val)
+	return
+}
+
+// SetIf puts val as the record for key only if its version is still version, or with a blank
+// version only if there's no record, returning the new version.
+// Otherwise it fails with an error satisfying errors.Is(err, ErrPreconditionFailed), time to Get and try again.
+func (kv *KV) SetIf(ctx context.Context, key string, val any, version string) (newVersion string, err error) {
+
+	cond := WithIfMatch(version)
+	if version == "" {
+		cond = WithIfNoneMatch("*")
+	}
+
+	newVersion, err = kv.set(ctx, key, val, cond)
+	return
+}
+
+// Delete deletes the record for key.
+func (kv *KV) Delete(ctx context.Context, key string) (err error) {
+
+	err = kv.store.Delete(ctx, kv.prefix+key)
+	return
+}
+
+// List lists record keys starting with prefix, without the KV's own prefix.
+func (kv *KV) List(ctx context.Context, prefix string) (keys []string, err error) {
+
+	objects, err := kv.store.List(ctx, kv.prefix+prefix)
+	if err != nil {
+		return
+	}
+
+	keys = make([]string, 0, len(objects))
+	for _, object := range objects {
+		keys = append(keys, strings.TrimPrefix(object, kv.prefix))
+	}
+
+	return
+}
+
+// unexported
+
+func (kv *KV) set(ctx context.Context, key string, val any, opts ...PutOption) (version string, err error) {
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	err = JSON.Encode(buf, val)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to encode %q", kv.prefix+key)
+		return
+	}
+
+	var res PutResult
+	opts = append(opts, WithContentType(JSON.ContentType()), WithResult(&res))
+
+	err = kv.store.Put(ctx, kv.prefix+key, bytes.NewReader(buf.Bytes()), opts...)
+	version = res.ETag
+	return
+}
+
+// getVersioned gets an object with its etag, in one request from a ConditionalGetter,
+// and otherwise stat'ing first so that a change meanwhile fails a conditional put to follow.
+func getVersioned(ctx context.Context, store ObjectStore, object string) (reader io.ReadCloser, etag string, err error) {
+
+	if cg, ok := store.(ConditionalGetter); ok {
+		var info ObjectInfo
+		reader, info, err = cg.GetIfNoneMatch(ctx, object, "")
+		etag = info.ETag
+		return
+	}
+
+	info, err := store.Stat(ctx, object)
+	if err != nil {
+		return
+	}
+
+	reader, err = store.Get(ctx, object)
+	etag = info.ETag
+	return
+}
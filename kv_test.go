@@ -0,0 +1,74 @@
+package objsto_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+var _ = Describe("KV", func() {
+	type config struct {
+		Replicas int    `json:"replicas"`
+		Mode     string `json:"mode"`
+	}
+
+	var (
+		ctx   = context.Background()
+		store *memstore.Store
+		kv    *objsto.KV
+	)
+
+	BeforeEach(func() {
+		store = memstore.New()
+		kv = objsto.NewKV(store, "state/")
+	})
+
+	It("sets, gets, lists, and deletes records", func() {
+		version, err := kv.Set(ctx, "svc/api", config{Replicas: 3, Mode: "active"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(version).ToNot(BeEmpty())
+		_, err = kv.Set(ctx, "svc/web", config{Replicas: 1})
+		Expect(err).ToNot(HaveOccurred())
+
+		var got config
+		gotVersion, err := kv.Get(ctx, "svc/api", &got)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got).To(Equal(config{Replicas: 3, Mode: "active"}))
+		Expect(gotVersion).To(Equal(version))
+
+		info, err := store.Stat(ctx, "state/svc/api")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.ContentType).To(Equal("application/json"))
+
+		Expect(kv.List(ctx, "svc/")).To(Equal([]string{"svc/api", "svc/web"}))
+
+		Expect(kv.Delete(ctx, "svc/api")).To(Succeed())
+		_, err = kv.Get(ctx, "svc/api", &got)
+		Expect(errors.Is(err, objsto.ErrNotFound)).To(BeTrue())
+	})
+
+	It("swaps only from the version read", func() {
+		created, err := kv.SetIf(ctx, "svc/api", config{Replicas: 1}, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = kv.SetIf(ctx, "svc/api", config{Replicas: 9}, "")
+		Expect(errors.Is(err, objsto.ErrPreconditionFailed)).To(BeTrue())
+
+		updated, err := kv.SetIf(ctx, "svc/api", config{Replicas: 2}, created)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updated).ToNot(Equal(created))
+
+		_, err = kv.SetIf(ctx, "svc/api", config{Replicas: 5}, created)
+		Expect(errors.Is(err, objsto.ErrPreconditionFailed)).To(BeTrue())
+
+		var got config
+		_, err = kv.Get(ctx, "svc/api", &got)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(got.Replicas).To(Equal(2))
+	})
+})
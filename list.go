@@ -0,0 +1,205 @@
+package objsto
+
+import (
+	"context"
+	"encoding/xml"
+	"iter"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ListInput are the settings for a listing.
+// Set ContinuationToken from a checkpoint to resume, or StartAfter to begin after a key.
+type ListInput struct {
+	Prefix            string
+	Delimiter         string
+	StartAfter        string
+	ContinuationToken string
+	MaxKeys           int
+}
+
+// ListPage is a page of a listing.
+// With a Delimiter, keys beyond it are rolled up into CommonPrefixes, as with folders.
+type ListPage struct {
+	Objects               []ObjectInfo
+	CommonPrefixes        []string
+	NextContinuationToken string
+	IsTruncated           bool
+}
+
+// Paginator pages through a listing, a page per request.
+type Paginator struct {
+	client *Client
+	input  ListInput
+	done   bool
+}
+
+// NewPaginator creates a Paginator for input.
+func (c *Client) NewPaginator(input ListInput) *Paginator {
+
+	return &Paginator{
+		client: c,
+		input:  input,
+	}
+}
+
+// HasMore is true until the last page has been got.
+func (pgr *Paginator) HasMore() bool {
+
+	return !pgr.done
+}
+
+// ContinuationToken is where the next page starts, to checkpoint and resume
+// with ListInput.ContinuationToken, blank before the first page.
+func (pgr *Paginator) ContinuationToken() string {
+
+	return pgr.input.ContinuationToken
+}
+
+// NextPage gets the next page, advancing only on success.
+func (pgr *Paginator) NextPage(ctx context.Context) (page ListPage, err error) {
+
+	if pgr.done {
+		err = errors.Errorf("no more pages")
+		return
+	}
+
+	page, err = pgr.client.listPage(ctx, pgr.input)
+	if err != nil {
+		return
+	}
+
+	pgr.input.ContinuationToken = page.NextContinuationToken
+	pgr.input.StartAfter = ""
+	pgr.done = !page.IsTruncated || page.NextContinuationToken == ""
+
+	return
+}
+
+// ListObjects iterates over the objects in a listing, getting pages as needed.
+// With a Delimiter, common prefixes are yielded among them in key order, as infos with only a Key.
+// Iteration stops after yielding an error.
+func (c *Client) ListObjects(ctx context.Context, input ListInput) iter.Seq2[ObjectInfo, error] {
+
+	return func(yield func(ObjectInfo, error) bool) {
+
+		pgr := c.NewPaginator(input)
+		for pgr.HasMore() {
+			page, err := pgr.NextPage(ctx)
+			if err != nil {
+				yield(ObjectInfo{}, err)
+				return
+			}
+
+			infos := page.Objects
+			if input.Delimiter != "" {
+				infos = page.withPrefixes()
+			}
+
+			for _, info := range infos {
+				if !yield(info, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// unexported
+
+// withPrefixes is the objects of a page with its common prefixes, in key order.
+func (page ListPage) withPrefixes() []ObjectInfo {
+
+	if len(page.CommonPrefixes) == 0 {
+		return page.Objects
+	}
+
+	infos := slices.Clone(page.Objects)
+	for _, prefix := range page.CommonPrefixes {
+		infos = append(infos, ObjectInfo{Key: prefix})
+	}
+	slices.SortFunc(infos, func(a, b ObjectInfo) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return infos
+}
+
+func (c *Client) listPage(ctx context.Context, input ListInput) (page ListPage, err error) {
+
+	c.logger.Info(ctx, "listing from S3", "prefix", input.Prefix, "token", input.ContinuationToken)
+
+	params := url.Values{}
+	params.Set("list-type", "2")
+	params.Set("prefix", c.prefix+input.Prefix)
+	if input.Delimiter != "" {
+		params.Set("delimiter", input.Delimiter)
+	}
+	if input.StartAfter != "" {
+		params.Set("start-after", c.prefix+input.StartAfter)
+	}
+	if input.ContinuationToken != "" {
+		params.Set("continuation-token", input.ContinuationToken)
+	}
+	if input.MaxKeys > 0 {
+		params.Set("max-keys", strconv.Itoa(input.MaxKeys))
+	}
+
+	req, err := c.newRequest(ctx, "GET", "", params, nil, 0, emptyHash, nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var result listBucketResult
+	err = xml.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		err = errors.Wrap(err, "failed to parse list response")
+		return
+	}
+
+	page = ListPage{
+		Objects:               make([]ObjectInfo, 0, len(result.Contents)),
+		NextContinuationToken: result.NextContinuationToken,
+		IsTruncated:           result.IsTruncated,
+	}
+	for _, obj := range result.Contents {
+		page.Objects = append(page.Objects, ObjectInfo{
+			Key:          strings.TrimPrefix(obj.Key, c.prefix),
+			Size:         obj.Size,
+			ETag:         strings.Trim(obj.ETag, `"`),
+			LastModified: obj.LastModified,
+			StorageClass: obj.StorageClass,
+		})
+	}
+	for _, cp := range result.CommonPrefixes {
+		page.CommonPrefixes = append(page.CommonPrefixes, strings.TrimPrefix(cp.Prefix, c.prefix))
+	}
+
+	return
+}
+
+type listBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		ETag         string    `xml:"ETag"`
+		LastModified time.Time `xml:"LastModified"`
+		StorageClass string    `xml:"StorageClass"`
+	} `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+}
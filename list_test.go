@@ -0,0 +1,122 @@
+package objsto_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var _ = Describe("Listing", func() {
+	var (
+		ctx    = context.Background()
+		mock   *HttpDoerMock
+		client *objsto.Client
+	)
+
+	pages := map[string]string{
+		"": `<ListBucketResult>
+  <IsTruncated>true</IsTruncated>
+  <NextContinuationToken>token-2</NextContinuationToken>
+  <Contents><Key>logs/a.txt</Key><Size>11</Size><ETag>"aaa"</ETag><LastModified>2026-03-01T12:00:00.000Z</LastModified><StorageClass>STANDARD</StorageClass></Contents>
+  <CommonPrefixes><Prefix>logs/2026/</Prefix></CommonPrefixes>
+</ListBucketResult>`,
+		"token-2": `<ListBucketResult>
+  <IsTruncated>false</IsTruncated>
+  <Contents><Key>logs/b.txt</Key><Size>22</Size></Contents>
+</ListBucketResult>`,
+	}
+
+	BeforeEach(func() {
+		mock = &HttpDoerMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				body := pages[req.URL.Query().Get("continuation-token")]
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+				}, nil
+			},
+		}
+
+		client = objsto.New(&objsto.Config{
+			Region:    "test-region",
+			Scheme:    "https",
+			Host:      "test-host",
+			Bucket:    "test-bucket",
+			AccessKey: "test-access-key",
+			SecretKey: "test-secret-key",
+		}, objsto.WithHTTPClient(mock))
+	})
+
+	Describe("Paginator", func() {
+		It("pages through with a resumable token", func() {
+			pgr := client.NewPaginator(objsto.ListInput{Prefix: "logs/", Delimiter: "/", MaxKeys: 1})
+			Expect(pgr.HasMore()).To(BeTrue())
+
+			page, err := pgr.NextPage(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page.Objects).To(HaveLen(1))
+			Expect(page.Objects[0].Key).To(Equal("logs/a.txt"))
+			Expect(page.Objects[0].Size).To(Equal(int64(11)))
+			Expect(page.Objects[0].ETag).To(Equal("aaa"))
+			Expect(page.Objects[0].StorageClass).To(Equal("STANDARD"))
+			Expect(page.Objects[0].LastModified.Year()).To(Equal(2026))
+			Expect(page.CommonPrefixes).To(Equal([]string{"logs/2026/"}))
+			Expect(pgr.ContinuationToken()).To(Equal("token-2"))
+
+			query := mock.DoCalls()[0].Request.URL.Query()
+			Expect(query.Get("delimiter")).To(Equal("/"))
+			Expect(query.Get("max-keys")).To(Equal("1"))
+
+			resumed := client.NewPaginator(objsto.ListInput{Prefix: "logs/", ContinuationToken: pgr.ContinuationToken()})
+			page, err = resumed.NextPage(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page.Objects[0].Key).To(Equal("logs/b.txt"))
+			Expect(resumed.HasMore()).To(BeFalse())
+
+			_, err = resumed.NextPage(ctx)
+			Expect(err).To(MatchError(ContainSubstring("no more pages")))
+		})
+	})
+
+	Describe("ListObjects", func() {
+		It("iterates across pages", func() {
+			var keys []string
+			for info, err := range client.ListObjects(ctx, objsto.ListInput{Prefix: "logs/"}) {
+				Expect(err).ToNot(HaveOccurred())
+				keys = append(keys, info.Key)
+			}
+			Expect(keys).To(Equal([]string{"logs/a.txt", "logs/b.txt"}))
+		})
+
+		It("yields common prefixes in key order given a delimiter", func() {
+			var keys []string
+			for info, err := range client.ListObjects(ctx, objsto.ListInput{Prefix: "logs/", Delimiter: "/"}) {
+				Expect(err).ToNot(HaveOccurred())
+				keys = append(keys, info.Key)
+			}
+			Expect(keys).To(Equal([]string{"logs/2026/", "logs/a.txt", "logs/b.txt"}))
+		})
+
+		It("stops early without getting more pages", func() {
+			for range client.ListObjects(ctx, objsto.ListInput{Prefix: "logs/"}) {
+				break
+			}
+			Expect(mock.DoCalls()).To(HaveLen(1))
+		})
+	})
+
+	Describe("List", func() {
+		It("gets all the keys", func() {
+			keys, err := client.List(ctx, "logs/")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(keys).To(Equal([]string{"logs/a.txt", "logs/b.txt"}))
+			Expect(mock.DoCalls()).To(HaveLen(2))
+		})
+	})
+})
@@ -0,0 +1,304 @@
+package objsto
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultLeaseTTL is how long a Lock's lease lasts unless renewed by default.
+const DefaultLeaseTTL = 30 * time.Second
+
+// Lease is the content of a lock object.
+type Lease struct {
+	Holder  string    `json:"holder"`
+	Token   int64     `json:"token"`
+	Expires time.Time `json:"expires"`
+}
+
+// Lock is a lease on an object, for coordinating across hosts with only object storage in common.
+//
+// Acquiring creates the object with If-None-Match: *, or takes over an expired or released lease
+// with If-Match, so that only one of those racing wins. Each acquisition increments a fencing token,
+// to be passed along to whatever the lock guards so that it can refuse a holder that's been superseded.
+// Renewals and release are also conditioned on the lease being unchanged since held.
+//
+// The store must support conditional puts, as do Client, memstore, and fsstore.
+// Expiry is judged by the clocks of the hosts involved, so keep the TTL well above any skew.
+type Lock struct {
+	store   ObjectStore
+	key     string
+	holder  string
+	ttl     time.Duration
+	clock   Clock
+	etag    string
+	token   int64
+	expires time.Time
+	mu      sync.Mutex
+}
+
+// LockOption sets an optional Lock setting.
+type LockOption func(*Lock)
+
+// WithLeaseTTL sets how long a lease lasts unless renewed, defaulting to DefaultLeaseTTL.
+func WithLeaseTTL(ttl time.Duration) LockOption {
+
+	return func(lk *Lock) {
+		lk.ttl = ttl
+	}
+}
+
+// WithHolder sets the name recorded in leases, defaulting to the hostname and a random suffix.
+func WithHolder(holder string) LockOption {
+
+	return func(lk *Lock) {
+		lk.holder = holder
+	}
+}
+
+// WithLockClock sets the clock used for expiry, as for testing.
+func WithLockClock(clock Clock) LockOption {
+
+	return func(lk *Lock) {
+		lk.clock = clock
+	}
+}
+
+// NewLock creates a Lock on key in store.
+func NewLock(store ObjectStore, key string, opts ...LockOption) *Lock {
+
+	lk := &Lock{
+		store: store,
+		key:   key,
+		ttl:   DefaultLeaseTTL,
+		clock: systemClock{},
+	}
+	for _, opt := range opts {
+		opt(lk)
+	}
+
+	if lk.holder == "" {
+		host, _ := os.Hostname()
+		suffix, _ := uploadID()
+		lk.holder = host + "-" + suffix
+	}
+
+	return lk
+}
+
+// TryAcquire takes the lease if free, reporting whether it did.
+func (lk *Lock) TryAcquire(ctx context.Context) (ok bool, err error) {
+
+	lk.mu.Lock()
+	defer lk.mu.Unlock()
+
+	lease := Lease{Holder: lk.holder, Token: 1, Expires: lk.clock.Now().Add(lk.ttl)}
+
+	err = lk.put(ctx, lease, WithIfNoneMatch("*"))
+	if err == nil {
+		ok = true
+		return
+	}
+	if !errors.Is(err, ErrPreconditionFailed) {
+		return
+	}
+
+	current, etag, err := lk.read(ctx)
+	if errors.Is(err, ErrNotFound) {
+		// gone since, try again later
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+	if current.Holder != "" && lk.clock.Now().Before(current.Expires) {
+		return
+	}
+
+	lease.Token = current.Token + 1
+
+	err = lk.put(ctx, lease, WithIfMatch(etag))
+	if errors.Is(err, ErrPreconditionFailed) {
+		err = nil
+		return
+	}
+
+	ok = err == nil
+	return
+}
+
+// Acquire waits for the lease, trying every poll, until ctx is done.
+func (lk *Lock) Acquire(ctx context.Context, poll time.Duration) (err error) {
+
+	for {
+		var ok bool
+		ok, err = lk.TryAcquire(ctx)
+		if err != nil || ok {
+			return
+		}
+
+		err = sleep(ctx, poll)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Renew extends the lease, failing with an error satisfying errors.Is(err, ErrLockLost) if no longer held.
+func (lk *Lock) Renew(ctx context.Context) (err error) {
+
+	lk.mu.Lock()
+	defer lk.mu.Unlock()
+
+	if lk.etag == "" {
+		err = errors.Wrapf(ErrLockLost, "lock %q not held", lk.key)
+		return
+	}
+
+	lease := Lease{Holder: lk.holder, Token: lk.token, Expires: lk.clock.Now().Add(lk.ttl)}
+
+	err = lk.put(ctx, lease, WithIfMatch(lk.etag))
+	if errors.Is(err, ErrPreconditionFailed) {
+		lk.etag = ""
+		err = errors.Wrapf(ErrLockLost, "lock %q taken over", lk.key)
+	}
+
+	return
+}
+
+// KeepAlive renews the lease every third of the TTL until ctx is done or the lease is lost,
+// returning ctx.Err() or an error satisfying errors.Is(err, ErrLockLost).
+// A renewal failing otherwise, as with store trouble, is retried every tenth of the TTL
+// for as long as the lease lasts. Run it in a goroutine, stopping the guarded work when it returns.
+func (lk *Lock) KeepAlive(ctx context.Context) (err error) {
+
+	interval := lk.ttl / 3
+	for {
+		err = sleep(ctx, interval)
+		if err != nil {
+			return
+		}
+
+		err = lk.Renew(ctx)
+		switch {
+		case err == nil:
+			interval = lk.ttl / 3
+		case ctx.Err() != nil:
+			err = ctx.Err()
+			return
+		case errors.Is(err, ErrLockLost):
+			return
+		default:
+			interval = lk.ttl / 10
+			err = lk.lapse(interval, err)
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Release gives up the lease by marking it expired, if still held.
+func (lk *Lock) Release(ctx context.Context) (err error) {
+
+	lk.mu.Lock()
+	defer lk.mu.Unlock()
+
+	if lk.etag == "" {
+		return
+	}
+
+	err = lk.put(ctx, Lease{Token: lk.token}, WithIfMatch(lk.etag))
+	if errors.Is(err, ErrPreconditionFailed) {
+		err = errors.Wrapf(ErrLockLost, "lock %q taken over", lk.key)
+	}
+	lk.etag = ""
+
+	return
+}
+
+// Token returns the fencing token of the lease held, or zero.
+func (lk *Lock) Token() int64 {
+
+	lk.mu.Lock()
+	defer lk.mu.Unlock()
+
+	if lk.etag == "" {
+		return 0
+	}
+	return lk.token
+}
+
+// Holder returns the name recorded in leases taken by the lock.
+func (lk *Lock) Holder() string {
+
+	return lk.holder
+}
+
+// unexported
+
+// put writes lease, holding it on success.
+func (lk *Lock) put(ctx context.Context, lease Lease, opts ...PutOption) (err error) {
+
+	data, err := json.Marshal(lease)
+	if err != nil {
+		err = errors.Wrap(err, "failed to encode lease")
+		return
+	}
+
+	var res PutResult
+	opts = append(opts, WithContentType(jsonType), WithResult(&res))
+
+	err = lk.store.Put(ctx, lk.key, bytes.NewReader(data), opts...)
+	if err != nil {
+		return
+	}
+	if res.ETag == "" {
+		err = errors.Errorf("no etag putting lease %q", lk.key)
+		return
+	}
+
+	lk.etag = res.ETag
+	lk.token = lease.Token
+	lk.expires = lease.Expires
+	return
+}
+
+// lapse gives up the lease when it would expire before another try at renewing, within retry,
+// returning ErrLockLost with the cause of the failed renewal.
+func (lk *Lock) lapse(retry time.Duration, cause error) (err error) {
+
+	lk.mu.Lock()
+	defer lk.mu.Unlock()
+
+	if lk.etag == "" {
+		err = errors.Wrapf(ErrLockLost, "lock %q not held", lk.key)
+		return
+	}
+	if lk.clock.Now().Add(retry).Before(lk.expires) {
+		return
+	}
+
+	lk.etag = ""
+	err = errors.Wrapf(ErrLockLost, "lock %q expiring, failed to renew: %s", lk.key, cause)
+	return
+}
+
+// read gets the current lease and its etag.
+func (lk *Lock) read(ctx context.Context) (lease Lease, etag string, err error) {
+
+	reader, etag, err := getVersioned(ctx, lk.store, lk.key)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	err = decode(JSON, lk.key, reader, &lease)
+	return
+}
@@ -0,0 +1,73 @@
+package objsto_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+var _ = Describe("Lock", func() {
+	var (
+		ctx   = context.Background()
+		store *memstore.Store
+		clock *stepClock
+		one   *objsto.Lock
+		two   *objsto.Lock
+	)
+
+	BeforeEach(func() {
+		store = memstore.New()
+		clock = &stepClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+
+		one = objsto.NewLock(store, "locks/nightly", objsto.WithHolder("one"), objsto.WithLockClock(clock))
+		two = objsto.NewLock(store, "locks/nightly", objsto.WithHolder("two"), objsto.WithLockClock(clock))
+	})
+
+	It("is held by one at a time", func() {
+		Expect(one.TryAcquire(ctx)).To(BeTrue())
+		Expect(two.TryAcquire(ctx)).To(BeFalse())
+		Expect(one.Token()).To(Equal(int64(1)))
+
+		Expect(one.Release(ctx)).To(Succeed())
+		Expect(one.Token()).To(BeZero())
+
+		Expect(two.TryAcquire(ctx)).To(BeTrue())
+		Expect(two.Token()).To(Equal(int64(2)))
+	})
+
+	It("is taken over when expired, the old holder learning on renewal", func() {
+		Expect(one.TryAcquire(ctx)).To(BeTrue())
+
+		clock.now = clock.now.Add(objsto.DefaultLeaseTTL / 2)
+		Expect(one.Renew(ctx)).To(Succeed())
+
+		clock.now = clock.now.Add(objsto.DefaultLeaseTTL / 2)
+		Expect(two.TryAcquire(ctx)).To(BeFalse())
+
+		clock.now = clock.now.Add(objsto.DefaultLeaseTTL)
+		Expect(two.TryAcquire(ctx)).To(BeTrue())
+		Expect(two.Token()).To(Equal(int64(2)))
+
+		err := one.Renew(ctx)
+		Expect(errors.Is(err, objsto.ErrLockLost)).To(BeTrue())
+		Expect(one.Release(ctx)).To(Succeed())
+		Expect(two.Renew(ctx)).To(Succeed())
+	})
+
+	It("waits to acquire", func() {
+		Expect(one.TryAcquire(ctx)).To(BeTrue())
+
+		short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		Expect(two.Acquire(short, time.Millisecond)).To(MatchError(context.DeadlineExceeded))
+
+		Expect(one.Release(ctx)).To(Succeed())
+		Expect(two.Acquire(ctx, time.Millisecond)).To(Succeed())
+	})
+})
@@ -0,0 +1,192 @@
+package objsto
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultLogPoll is the time a LogReader waits between looks for the next record by default.
+const DefaultLogPoll = time.Second
+
+// Record is an entry in a Log.
+type Record struct {
+	Offset int64  `json:"offset"`
+	Data   []byte `json:"data"`
+}
+
+// Log is an append-only log kept as an object per record, keyed by zero-padded offset under a prefix.
+//
+// Appends create the next object with If-None-Match: *, moving past offsets taken by other
+// writers, so that any number of Logs over the same prefix append without gaps or overwrites.
+// The store must support conditional puts, as do Client, memstore, and fsstore.
+type Log struct {
+	store  ObjectStore
+	prefix string
+	poll   time.Duration
+	next   int64
+	known  bool
+	mu     sync.Mutex
+}
+
+// LogOption sets an optional Log setting.
+type LogOption func(*Log)
+
+// WithLogPoll sets the time readers wait between looks for the next record, defaulting to DefaultLogPoll.
+func WithLogPoll(poll time.Duration) LogOption {
+
+	return func(lg *Log) {
+		lg.poll = poll
+	}
+}
+
+// NewLog creates a Log of records under prefix in store.
+func NewLog(store ObjectStore, prefix string, opts ...LogOption) *Log {
+
+	lg := &Log{
+		store:  store,
+		prefix: prefix,
+		poll:   DefaultLogPoll,
+	}
+	for _, opt := range opts {
+		opt(lg)
+	}
+
+	return lg
+}
+
+// Append writes a record, returning its offset.
+func (lg *Log) Append(ctx context.Context, data []byte) (offset int64, err error) {
+
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+
+	if !lg.known {
+		lg.next, err = lg.tail(ctx)
+		if err != nil {
+			return
+		}
+		lg.known = true
+	}
+
+	for {
+		err = lg.store.Put(ctx, lg.key(lg.next), bytes.NewReader(data), WithIfNoneMatch("*"))
+		if err == nil {
+			offset = lg.next
+			lg.next++
+			return
+		}
+		if !errors.Is(err, ErrPreconditionFailed) {
+			return
+		}
+
+		// taken by another writer, catch up
+		var next int64
+		next, err = lg.tail(ctx)
+		if err != nil {
+			return
+		}
+		lg.next = max(next, lg.next+1)
+	}
+}
+
+// Get reads the record at offset, failing with an error satisfying errors.Is(err, ErrNotFound)
+// if not yet appended.
+func (lg *Log) Get(ctx context.Context, offset int64) (rec Record, err error) {
+
+	reader, err := lg.store.Get(ctx, lg.key(offset))
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to read record %d", offset)
+		return
+	}
+
+	rec = Record{Offset: offset, Data: data}
+	return
+}
+
+// Next returns the offset the next append is expected to take, listing to find it.
+func (lg *Log) Next(ctx context.Context) (offset int64, err error) {
+
+	return lg.tail(ctx)
+}
+
+// Reader creates a LogReader starting at offset.
+func (lg *Log) Reader(offset int64) *LogReader {
+
+	return &LogReader{
+		log:    lg,
+		offset: offset,
+	}
+}
+
+// LogReader reads a Log in order, waiting for records yet to be appended.
+type LogReader struct {
+	log    *Log
+	offset int64
+}
+
+// Next returns the record at the reader's offset and advances, waiting until it's appended or ctx is done.
+func (lr *LogReader) Next(ctx context.Context) (rec Record, err error) {
+
+	for {
+		rec, err = lr.log.Get(ctx, lr.offset)
+		if err == nil {
+			lr.offset++
+			return
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return
+		}
+
+		err = sleep(ctx, lr.log.poll)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Offset returns the offset of the record Next returns.
+func (lr *LogReader) Offset() int64 {
+
+	return lr.offset
+}
+
+// unexported
+
+func (lg *Log) key(offset int64) string {
+
+	return fmt.Sprintf("%s%020d", lg.prefix, offset)
+}
+
+// tail lists the prefix for the offset after the last record.
+func (lg *Log) tail(ctx context.Context) (next int64, err error) {
+
+	keys, err := lg.store.List(ctx, lg.prefix)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to list log %q", lg.prefix)
+		return
+	}
+
+	for _, key := range keys {
+		offset, err := strconv.ParseInt(strings.TrimPrefix(key, lg.prefix), 10, 64)
+		if err != nil || offset < 0 {
+			continue
+		}
+		next = max(next, offset+1)
+	}
+
+	return
+}
@@ -0,0 +1,80 @@
+package objsto_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+var _ = Describe("Log", func() {
+	var (
+		ctx   = context.Background()
+		store *memstore.Store
+	)
+
+	BeforeEach(func() {
+		store = memstore.New()
+	})
+
+	It("appends in sequence across writers", func() {
+		one := objsto.NewLog(store, "events/")
+		two := objsto.NewLog(store, "events/")
+
+		Expect(one.Append(ctx, []byte("a"))).To(Equal(int64(0)))
+		Expect(two.Append(ctx, []byte("b"))).To(Equal(int64(1)))
+		Expect(one.Append(ctx, []byte("c"))).To(Equal(int64(2)))
+
+		var wg sync.WaitGroup
+		for _, lg := range []*objsto.Log{one, two} {
+			wg.Go(func() {
+				defer GinkgoRecover()
+				for range 10 {
+					_, err := lg.Append(ctx, []byte("x"))
+					Expect(err).ToNot(HaveOccurred())
+				}
+			})
+		}
+		wg.Wait()
+
+		Expect(one.Next(ctx)).To(Equal(int64(23)))
+		Expect(store.List(ctx, "events/")).To(ContainElement("events/00000000000000000022"))
+
+		rec, err := two.Get(ctx, 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec).To(Equal(objsto.Record{Offset: 1, Data: []byte("b")}))
+	})
+
+	It("tails from an offset", func() {
+		lg := objsto.NewLog(store, "events/", objsto.WithLogPoll(time.Millisecond))
+		for _, data := range []string{"a", "b"} {
+			_, err := lg.Append(ctx, []byte(data))
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		lr := lg.Reader(1)
+		rec, err := lr.Next(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(rec.Data)).To(Equal("b"))
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_, _ = lg.Append(ctx, []byte("c"))
+		}()
+
+		rec, err = lr.Next(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Offset).To(Equal(int64(2)))
+		Expect(lr.Offset()).To(Equal(int64(3)))
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err = lr.Next(ctx)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+})
@@ -9,3 +9,12 @@ type Logger interface {
 	Trace(ctx context.Context, msg string, kv ...any)
 	Error(ctx context.Context, msg string, err error, kv ...any)
 }
+
+// unexported
+
+type noopLogger struct{}
+
+func (nl noopLogger) Info(ctx context.Context, msg string, kv ...any)             {}
+func (nl noopLogger) Debug(ctx context.Context, msg string, kv ...any)            {}
+func (nl noopLogger) Trace(ctx context.Context, msg string, kv ...any)            {}
+func (nl noopLogger) Error(ctx context.Context, msg string, err error, kv ...any) {}
@@ -0,0 +1,259 @@
+// Package memstore implements objsto.ObjectStore in memory, for unit tests
+// of code built on objsto, with optional latency and error injection.
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+)
+
+// Op names an operation for fault injection.
+type Op string
+
+const (
+	OpGet    Op = "get"
+	OpPut    Op = "put"
+	OpDelete Op = "delete"
+	OpList   Op = "list"
+	OpStat   Op = "stat"
+)
+
+// FaultFunc returns an error to fail an operation with, nil to let it through.
+// For list, object is the prefix.
+type FaultFunc func(op Op, object string) error
+
+// Option sets an optional Store setting.
+type Option func(*Store)
+
+// WithLatency delays each operation, honoring context cancellation.
+func WithLatency(latency time.Duration) Option {
+
+	return func(store *Store) {
+		store.latency = latency
+	}
+}
+
+// WithFault injects errors via fault.
+func WithFault(fault FaultFunc) Option {
+
+	return func(store *Store) {
+		store.fault = fault
+	}
+}
+
+// Store is an in-memory ObjectStore, safe for concurrent use.
+type Store struct {
+	objects map[string]entry
+	latency time.Duration
+	fault   FaultFunc
+	mu      sync.RWMutex
+}
+
+var _ objsto.ObjectStore = &Store{}
+
+// New creates an empty Store.
+func New(opts ...Option) *Store {
+
+	store := &Store{
+		objects: map[string]entry{},
+	}
+
+	for _, opt := range opts {
+		opt(store)
+	}
+
+	return store
+}
+
+// SetFault replaces the fault func, nil for none, handy for failing partway through a test.
+func (store *Store) SetFault(fault FaultFunc) {
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.fault = fault
+}
+
+// Get gets an object.
+func (store *Store) Get(ctx context.Context, object string) (reader io.ReadCloser, err error) {
+
+	obj, err := store.lookup(ctx, OpGet, object)
+	if err != nil {
+		return
+	}
+
+	reader = io.NopCloser(bytes.NewReader(obj.data))
+	return
+}
+
+// Put puts an object.
+func (store *Store) Put(ctx context.Context, object string, reader io.ReadSeeker, opts ...objsto.PutOption) (err error) {
+
+	err = store.enter(ctx, OpPut, object)
+	if err != nil {
+		return
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to read %q", object)
+		return
+	}
+
+	po := objsto.NewPutOptions(opts...)
+	sum := md5.Sum(data)
+
+	obj := entry{
+		data: data,
+		info: objsto.ObjectInfo{
+			Key:             object,
+			Size:            int64(len(data)),
+			ETag:            hex.EncodeToString(sum[:]),
+			ContentType:     po.ContentType,
+			ContentEncoding: po.ContentEncoding,
+			LastModified:    time.Now().UTC().Truncate(time.Second),
+			Metadata:        maps.Clone(po.Metadata),
+		},
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	err = po.Check(object, store.objects[object].info.ETag)
+	if err != nil {
+		return
+	}
+
+	store.objects[object] = obj
+
+	po.Record(objsto.PutResult{Key: object, ETag: obj.info.ETag})
+	return
+}
+
+// Delete deletes an object.
+// As with S3, deleting an object that does not exist is not an error.
+func (store *Store) Delete(ctx context.Context, object string) (err error) {
+
+	err = store.enter(ctx, OpDelete, object)
+	if err != nil {
+		return
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.objects, object)
+	return
+}
+
+// List returns object keys matching the given prefix, in order.
+func (store *Store) List(ctx context.Context, prefix string) (keys []string, err error) {
+
+	err = store.enter(ctx, OpList, prefix)
+	if err != nil {
+		return
+	}
+
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	for key := range store.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+	return
+}
+
+// Stat gets an object's metadata.
+func (store *Store) Stat(ctx context.Context, object string) (info objsto.ObjectInfo, err error) {
+
+	obj, err := store.lookup(ctx, OpStat, object)
+	if err != nil {
+		return
+	}
+
+	info = obj.info
+	info.Metadata = maps.Clone(obj.info.Metadata)
+	return
+}
+
+// Len returns the number of objects stored.
+func (store *Store) Len() int {
+
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return len(store.objects)
+}
+
+// unexported
+
+type entry struct {
+	data []byte
+	info objsto.ObjectInfo
+}
+
+// enter checks for a valid object, waits out latency, and consults fault.
+func (store *Store) enter(ctx context.Context, op Op, object string) (err error) {
+
+	if object == "" && op != OpList {
+		err = errors.Errorf("object cannot be blank")
+		return
+	}
+
+	store.mu.RLock()
+	latency := store.latency
+	fault := store.fault
+	store.mu.RUnlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-timer.C:
+		}
+	}
+
+	if fault != nil {
+		err = fault(op, object)
+	}
+
+	return
+}
+
+func (store *Store) lookup(ctx context.Context, op Op, object string) (obj entry, err error) {
+
+	err = store.enter(ctx, op, object)
+	if err != nil {
+		return
+	}
+
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	obj, ok := store.objects[object]
+	if !ok {
+		err = errors.Wrapf(objsto.ErrNotFound, "no such object %q", object)
+	}
+
+	return
+}
@@ -0,0 +1,160 @@
+package memstore_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+func TestMemStore(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "MemStore Suite")
+}
+
+var _ = Describe("Store", func() {
+	var (
+		ctx   context.Context
+		opts  []memstore.Option
+		store *memstore.Store
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		opts = nil
+	})
+
+	JustBeforeEach(func() {
+		store = memstore.New(opts...)
+	})
+
+	put := func(object, content string, opts ...objsto.PutOption) error {
+		return store.Put(ctx, object, bytes.NewReader([]byte(content)), opts...)
+	}
+
+	Describe("basic operations", func() {
+		JustBeforeEach(func() {
+			Expect(put("b.txt", "bee", objsto.WithContentType("text/plain"))).To(Succeed())
+			Expect(put("a.txt", "ay")).To(Succeed())
+			Expect(put("other/c.txt", "sea")).To(Succeed())
+		})
+
+		It("gets what was put", func() {
+			reader, err := store.Get(ctx, "b.txt")
+			Expect(err).ToNot(HaveOccurred())
+
+			content, _ := io.ReadAll(reader)
+			Expect(string(content)).To(Equal("bee"))
+		})
+
+		It("stats what was put", func() {
+			info, err := store.Stat(ctx, "b.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Size).To(Equal(int64(3)))
+			Expect(info.ContentType).To(Equal("text/plain"))
+			Expect(info.ETag).ToNot(BeEmpty())
+		})
+
+		It("lists by prefix in order", func() {
+			keys, err := store.List(ctx, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(keys).To(Equal([]string{"a.txt", "b.txt", "other/c.txt"}))
+
+			keys, err = store.List(ctx, "other/")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(keys).To(Equal([]string{"other/c.txt"}))
+		})
+
+		It("puts conditionally", func() {
+			info, err := store.Stat(ctx, "a.txt")
+			Expect(err).ToNot(HaveOccurred())
+
+			err = put("a.txt", "again", objsto.WithIfNoneMatch("*"))
+			Expect(errors.Is(err, objsto.ErrPreconditionFailed)).To(BeTrue())
+			err = put("a.txt", "again", objsto.WithIfMatch("nope"))
+			Expect(errors.Is(err, objsto.ErrPreconditionFailed)).To(BeTrue())
+
+			Expect(put("a.txt", "again", objsto.WithIfMatch(info.ETag))).To(Succeed())
+			Expect(put("new.txt", "new", objsto.WithIfNoneMatch("*"))).To(Succeed())
+		})
+
+		It("deletes", func() {
+			Expect(store.Delete(ctx, "a.txt")).To(Succeed())
+			Expect(store.Len()).To(Equal(2))
+
+			_, err := store.Get(ctx, "a.txt")
+			Expect(errors.Is(err, objsto.ErrNotFound)).To(BeTrue())
+		})
+	})
+
+	Describe("concurrent use", func() {
+		It("is safe", func() {
+			var wg sync.WaitGroup
+			for i := range 50 {
+				wg.Go(func() {
+					defer GinkgoRecover()
+					key := fmt.Sprintf("key-%02d", i)
+					Expect(put(key, "data")).To(Succeed())
+					_, err := store.List(ctx, "key-")
+					Expect(err).ToNot(HaveOccurred())
+				})
+			}
+			wg.Wait()
+
+			Expect(store.Len()).To(Equal(50))
+		})
+	})
+
+	Describe("fault injection", func() {
+		BeforeEach(func() {
+			opts = []memstore.Option{
+				memstore.WithFault(func(op memstore.Op, object string) error {
+					if op == memstore.OpPut && object == "bad.txt" {
+						return errors.New("injected")
+					}
+					return nil
+				}),
+			}
+		})
+
+		It("fails matching operations", func() {
+			Expect(put("good.txt", "data")).To(Succeed())
+			Expect(put("bad.txt", "data")).To(MatchError("injected"))
+		})
+
+		It("can be changed midway", func() {
+			store.SetFault(nil)
+			Expect(put("bad.txt", "data")).To(Succeed())
+		})
+	})
+
+	Describe("latency injection", func() {
+		BeforeEach(func() {
+			opts = []memstore.Option{memstore.WithLatency(100 * time.Millisecond)}
+		})
+
+		It("delays operations", func() {
+			start := time.Now()
+			Expect(put("a.txt", "data")).To(Succeed())
+			Expect(time.Since(start)).To(BeNumerically(">=", 100*time.Millisecond))
+		})
+
+		It("honors cancellation", func() {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, 10*time.Millisecond)
+			defer cancel()
+
+			Expect(put("a.txt", "data")).To(MatchError(context.DeadlineExceeded))
+		})
+	})
+})
@@ -0,0 +1,91 @@
+package objsto
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Metrics records what's sent and received, see MetricsHooks.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveRequest records a request attempt, with status the response code or "error" if none.
+	ObserveRequest(operation, status string, elapsed time.Duration)
+	// ObserveBytes records bytes sent, direction "out", or received, "in", per content length.
+	ObserveBytes(operation, direction string, n int64)
+}
+
+// MetricsHooks returns hooks reporting each request attempt to metrics, for use with WithHooks.
+func MetricsHooks(metrics Metrics) Hooks {
+
+	return Hooks{
+		AfterReceive: func(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+
+			operation := Operation(req)
+
+			status := "error"
+			if resp != nil {
+				status = strconv.Itoa(resp.StatusCode)
+			}
+			metrics.ObserveRequest(operation, status, elapsed)
+
+			if req.ContentLength > 0 {
+				metrics.ObserveBytes(operation, "out", req.ContentLength)
+			}
+			if resp != nil && resp.ContentLength > 0 && req.Method != http.MethodHead {
+				metrics.ObserveBytes(operation, "in", resp.ContentLength)
+			}
+		},
+	}
+}
+
+// Operation names the S3 operation a request is for, such as "get" or "list", for labeling.
+func Operation(req *http.Request) string {
+
+	query := req.URL.Query()
+
+	switch req.Method {
+	case http.MethodGet:
+		switch {
+		case query.Has("list-type"):
+			return "list"
+		case query.Has("uploads"):
+			return "list_uploads"
+		case query.Has("tagging"):
+			return "get_tags"
+		}
+		return "get"
+	case http.MethodHead:
+		return "stat"
+	case http.MethodPut:
+		switch {
+		case query.Has("partNumber"):
+			return "upload_part"
+		case query.Has("tagging"):
+			return "put_tags"
+		case req.Header.Get("X-Amz-Copy-Source") != "":
+			return "copy"
+		}
+		return "put"
+	case http.MethodPost:
+		switch {
+		case query.Has("delete"):
+			return "delete_objects"
+		case query.Has("uploads"):
+			return "create_upload"
+		case query.Has("uploadId"):
+			return "complete_upload"
+		}
+		return "post"
+	case http.MethodDelete:
+		switch {
+		case query.Has("uploadId"):
+			return "abort_upload"
+		case query.Has("tagging"):
+			return "delete_tags"
+		}
+		return "delete"
+	}
+
+	return "other"
+}
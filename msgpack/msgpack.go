@@ -0,0 +1,79 @@
+// Package msgpack implements objsto.Codec with MessagePack, for use with objsto.PutValue and friends,
+// compact and quick for values read and written often.
+package msgpack
+
+import (
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/clarktrimble/objsto"
+)
+
+// ContentType is the Content-Type of values encoded with msgpack.
+const ContentType = "application/vnd.msgpack"
+
+// Codec is an objsto.Codec for msgpack, with struct fields named per "msgpack" tags.
+var Codec objsto.Codec = New()
+
+// Option sets an optional codec setting.
+type Option func(*codec)
+
+// WithStructTag names struct fields per tag, such as "json" for types already tagged for encoding/json.
+func WithStructTag(tag string) Option {
+
+	return func(cdc *codec) {
+		cdc.tag = tag
+	}
+}
+
+// WithCompactInts encodes integers in as few bytes as they fit, whatever their type.
+func WithCompactInts() Option {
+
+	return func(cdc *codec) {
+		cdc.compactInts = true
+	}
+}
+
+// New creates an objsto.Codec for msgpack.
+func New(opts ...Option) objsto.Codec {
+
+	cdc := codec{}
+	for _, opt := range opts {
+		opt(&cdc)
+	}
+
+	return cdc
+}
+
+// unexported
+
+type codec struct {
+	tag         string
+	compactInts bool
+}
+
+func (cdc codec) ContentType() string {
+	return ContentType
+}
+
+func (cdc codec) Encode(writer io.Writer, val any) error {
+
+	enc := msgpack.NewEncoder(writer)
+	if cdc.tag != "" {
+		enc.SetCustomStructTag(cdc.tag)
+	}
+	enc.UseCompactInts(cdc.compactInts)
+
+	return enc.Encode(val)
+}
+
+func (cdc codec) Decode(reader io.Reader, val any) error {
+
+	dec := msgpack.NewDecoder(reader)
+	if cdc.tag != "" {
+		dec.SetCustomStructTag(cdc.tag)
+	}
+
+	return dec.Decode(val)
+}
@@ -0,0 +1,82 @@
+package msgpack_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	vmsgpack "github.com/vmihailenco/msgpack/v5"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+	"github.com/clarktrimble/objsto/msgpack"
+)
+
+func TestMsgpack(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Msgpack Suite")
+}
+
+var _ = Describe("Codec", func() {
+	type record struct {
+		Name  string            `json:"name"`
+		Count int64             `json:"count"`
+		Tags  []string          `json:"tags"`
+		Attrs map[string]string `json:"attrs"`
+	}
+
+	var (
+		ctx   = context.Background()
+		store *memstore.Store
+		val   = record{Name: "bob", Count: 3, Tags: []string{"a", "b"}, Attrs: map[string]string{"k": "v"}}
+	)
+
+	BeforeEach(func() {
+		store = memstore.New()
+	})
+
+	DescribeTable("round tripping typed values",
+		func(codec objsto.Codec) {
+			Expect(objsto.PutValue(ctx, store, codec, "test-object", val)).To(Succeed())
+
+			info, err := store.Stat(ctx, "test-object")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.ContentType).To(Equal(msgpack.ContentType))
+
+			got, err := objsto.GetValue[record](ctx, store, codec, "test-object")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got).To(Equal(val))
+		},
+		Entry("by default", msgpack.Codec),
+		Entry("with json tags", msgpack.New(msgpack.WithStructTag("json"))),
+		Entry("with compact ints", msgpack.New(msgpack.WithCompactInts())),
+	)
+
+	It("names fields per the struct tag", func() {
+		buf := &bytes.Buffer{}
+		Expect(msgpack.New(msgpack.WithStructTag("json")).Encode(buf, val)).To(Succeed())
+
+		var fields map[string]any
+		Expect(vmsgpack.Unmarshal(buf.Bytes(), &fields)).To(Succeed())
+		Expect(fields).To(HaveKey("name"))
+		Expect(fields).ToNot(HaveKey("Name"))
+	})
+
+	It("encodes smaller than json", func() {
+		packed := &bytes.Buffer{}
+		Expect(msgpack.New(msgpack.WithCompactInts()).Encode(packed, val)).To(Succeed())
+
+		plain := &bytes.Buffer{}
+		Expect(objsto.JSON.Encode(plain, val)).To(Succeed())
+
+		Expect(packed.Len()).To(BeNumerically("<", plain.Len()))
+	})
+
+	It("fails to decode what isn't msgpack", func() {
+		var got record
+		err := msgpack.Codec.Decode(bytes.NewReader([]byte("{not msgpack")), &got)
+		Expect(err).To(HaveOccurred())
+	})
+})
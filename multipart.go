@@ -0,0 +1,309 @@
+package objsto
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MinPartSize is the smallest part S3 accepts in a multipart upload, other than the last.
+	MinPartSize = 5 << 20
+	// DefaultPartSize is the part size PutStream uses when given zero.
+	DefaultPartSize = 16 << 20
+	// MaxParts is the most parts S3 accepts in a multipart upload.
+	MaxParts = 10000
+)
+
+// Upload is an incomplete multipart upload.
+type Upload struct {
+	Key       string    `json:"key"`
+	UploadID  string    `json:"upload_id"`
+	Initiated time.Time `json:"initiated"`
+}
+
+// ListUploads lists incomplete multipart uploads of objects under prefix, getting as many pages as needed.
+// Their parts are stored, and billed, until completed or aborted.
+func (c *Client) ListUploads(ctx context.Context, prefix string) (uploads []Upload, err error) {
+
+	c.logger.Info(ctx, "listing uploads from S3", "prefix", prefix)
+
+	params := url.Values{}
+	params.Set("uploads", "")
+	params.Set("prefix", c.prefix+prefix)
+
+	for {
+		var result listUploadsResult
+		result, err = c.listUploads(ctx, params)
+		if err != nil {
+			return
+		}
+
+		for _, upl := range result.Uploads {
+			uploads = append(uploads, Upload{
+				Key:       strings.TrimPrefix(upl.Key, c.prefix),
+				UploadID:  upl.UploadID,
+				Initiated: upl.Initiated,
+			})
+		}
+
+		if !result.IsTruncated {
+			return
+		}
+		params.Set("key-marker", result.NextKeyMarker)
+		params.Set("upload-id-marker", result.NextUploadIDMarker)
+	}
+}
+
+// AbortUpload aborts an incomplete multipart upload, freeing its parts.
+func (c *Client) AbortUpload(ctx context.Context, object, uploadID string) (err error) {
+
+	c.logger.Info(ctx, "aborting upload in S3", "object", object, "upload_id", uploadID)
+
+	if object == "" || uploadID == "" {
+		err = errors.Errorf("object and upload id cannot be blank")
+		return
+	}
+
+	req, err := c.newRequest(ctx, "DELETE", object, url.Values{"uploadId": {uploadID}}, nil, 0, emptyHash, nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+
+	return
+}
+
+// PutStream puts an object of unknown size from a reader, such as a pipe, buffering a part at a time.
+// Objects no bigger than a part are put with a single request, and others as a multipart upload
+// of partSize parts, it being raised to MinPartSize and zero giving DefaultPartSize.
+// Objects are limited to MaxParts parts, so some 156GiB with the default.
+//
+// A failed multipart upload is aborted, sparing its parts being stored until GC.
+func (c *Client) PutStream(ctx context.Context, object string, reader io.Reader, partSize int64, opts ...PutOption) (err error) {
+
+	if partSize == 0 {
+		partSize = DefaultPartSize
+	}
+	partSize = max(partSize, MinPartSize)
+
+	buf := make([]byte, partSize)
+	n, err := io.ReadFull(reader, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return c.Put(ctx, object, bytes.NewReader(buf[:n]), opts...)
+	}
+	if err != nil {
+		err = errors.Wrap(err, "failed to read stream")
+		return
+	}
+
+	c.logger.Info(ctx, "streaming to S3", "object", object, "part_size", partSize)
+
+	po := NewPutOptions(opts...)
+
+	// conditions apply to completing rather than starting
+	hdr := po.header()
+	cond := http.Header{}
+	for _, name := range []string{"If-Match", "If-None-Match"} {
+		if val := hdr.Get(name); val != "" {
+			cond.Set(name, val)
+			hdr.Del(name)
+		}
+	}
+
+	uploadID, err := c.createUpload(ctx, object, hdr)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			// abort even when ctx is done
+			abortErr := c.AbortUpload(context.WithoutCancel(ctx), object, uploadID)
+			if abortErr != nil {
+				c.logger.Error(ctx, "failed to abort upload", abortErr, "object", object, "upload_id", uploadID)
+			}
+		}
+	}()
+
+	parts := []completedPart{}
+	for {
+		if len(parts) == MaxParts {
+			err = errors.Wrapf(ErrTooLarge, "more than %d parts of %d bytes", MaxParts, partSize)
+			return
+		}
+
+		var etag string
+		etag, err = c.uploadPart(ctx, object, uploadID, len(parts)+1, buf[:n])
+		if err != nil {
+			return
+		}
+		parts = append(parts, completedPart{PartNumber: len(parts) + 1, ETag: etag})
+
+		n, err = io.ReadFull(reader, buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			err = errors.Wrap(err, "failed to read stream")
+			return
+		}
+	}
+
+	res, err := c.completeUpload(ctx, object, uploadID, parts, cond)
+	if err != nil {
+		return
+	}
+	po.Record(res)
+
+	return
+}
+
+// unexported
+
+func (c *Client) createUpload(ctx context.Context, object string, hdr http.Header) (uploadID string, err error) {
+
+	req, err := c.newRequest(ctx, "POST", object, url.Values{"uploads": {""}}, nil, 0, emptyHash, hdr)
+	if err != nil {
+		return
+	}
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err = xml.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		err = errors.Wrap(err, "failed to parse create upload response")
+		return
+	}
+	if result.UploadID == "" {
+		err = errors.Errorf("no upload id in create upload response")
+		return
+	}
+
+	uploadID = result.UploadID
+	return
+}
+
+func (c *Client) uploadPart(ctx context.Context, object, uploadID string, number int, data []byte) (etag string, err error) {
+
+	pyld := bytes.NewReader(data)
+	hash, size, err := hashPayload(pyld)
+	if err != nil {
+		return
+	}
+
+	query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+	req, err := c.newRequest(ctx, "PUT", object, query, pyld, size, hash, nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+
+	etag = resp.Header.Get("ETag")
+	if etag == "" {
+		err = errors.Errorf("no etag for part %d", number)
+	}
+	return
+}
+
+func (c *Client) completeUpload(ctx context.Context, object, uploadID string, parts []completedPart, hdr http.Header) (res PutResult, err error) {
+
+	body, err := xml.Marshal(completeUpload{Parts: parts})
+	if err != nil {
+		err = errors.Wrap(err, "failed to marshal complete upload")
+		return
+	}
+
+	pyld := bytes.NewReader(body)
+	hash, size, err := hashPayload(pyld)
+	if err != nil {
+		return
+	}
+
+	req, err := c.newRequest(ctx, "POST", object, url.Values{"uploadId": {uploadID}}, pyld, size, hash, hdr)
+	if err != nil {
+		return
+	}
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	// completing can fail after the 200 has been sent, with the error in the body
+	result, err := readResult(resp, "complete upload")
+	if err != nil {
+		return
+	}
+
+	res = putResult(object, resp)
+	res.ETag = strings.Trim(result.ETag, `"`)
+	return
+}
+
+type completeUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+func (c *Client) listUploads(ctx context.Context, params url.Values) (result listUploadsResult, err error) {
+
+	req, err := c.newRequest(ctx, "GET", "", params, nil, 0, emptyHash, nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	err = xml.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		err = errors.Wrap(err, "failed to parse list uploads response")
+	}
+
+	return
+}
+
+type listUploadsResult struct {
+	IsTruncated        bool   `xml:"IsTruncated"`
+	NextKeyMarker      string `xml:"NextKeyMarker"`
+	NextUploadIDMarker string `xml:"NextUploadIdMarker"`
+	Uploads            []struct {
+		Key       string    `xml:"Key"`
+		UploadID  string    `xml:"UploadId"`
+		Initiated time.Time `xml:"Initiated"`
+	} `xml:"Upload"`
+}
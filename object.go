@@ -0,0 +1,127 @@
+package objsto
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+const metaPrefix = "X-Amz-Meta-"
+
+// ObjectInfo is metadata for a stored object.
+// EncodedSize is the size received when a get was decompressed, with Size that decompressed.
+type ObjectInfo struct {
+	Key             string            `json:"key"`
+	Size            int64             `json:"size"`
+	ETag            string            `json:"etag"`
+	ContentType     string            `json:"content_type,omitempty"`
+	ContentEncoding string            `json:"content_encoding,omitempty"`
+	EncodedSize     int64             `json:"encoded_size,omitempty"`
+	StorageClass    string            `json:"storage_class,omitempty"`
+	Checksum        string            `json:"checksum_sha256,omitempty"`
+	LastModified    time.Time         `json:"last_modified"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	Expiration      *Expiration       `json:"expiration,omitempty"`
+}
+
+// PutResult is what's known of an object just put, captured with WithResult.
+type PutResult struct {
+	Key        string      `json:"key"`
+	ETag       string      `json:"etag"`
+	VersionID  string      `json:"version_id,omitempty"`
+	Expiration *Expiration `json:"expiration,omitempty"`
+	RequestIDs
+}
+
+// Expiration is when a lifecycle rule will remove an object, from the x-amz-expiration header.
+type Expiration struct {
+	Date   time.Time `json:"date"`
+	RuleID string    `json:"rule_id"`
+}
+
+// unexported
+
+func objectInfo(key string, resp *http.Response) (info ObjectInfo) {
+
+	info = ObjectInfo{
+		Key:             key,
+		Size:            resp.ContentLength,
+		ETag:            strings.Trim(resp.Header.Get("ETag"), `"`),
+		ContentType:     resp.Header.Get("Content-Type"),
+		ContentEncoding: resp.Header.Get("Content-Encoding"),
+		StorageClass:    resp.Header.Get("X-Amz-Storage-Class"),
+		Checksum:        resp.Header.Get("X-Amz-Checksum-Sha256"),
+	}
+
+	info.Expiration = parseExpiration(resp.Header.Get("X-Amz-Expiration"))
+
+	modified, err := http.ParseTime(resp.Header.Get("Last-Modified"))
+	if err == nil {
+		info.LastModified = modified
+	}
+
+	for name, vals := range resp.Header {
+		if !strings.HasPrefix(name, metaPrefix) || len(vals) == 0 {
+			continue
+		}
+		if info.Metadata == nil {
+			info.Metadata = map[string]string{}
+		}
+		info.Metadata[strings.ToLower(name[len(metaPrefix):])] = vals[0]
+	}
+
+	return
+}
+
+func putResult(key string, resp *http.Response) PutResult {
+
+	return PutResult{
+		Key:        key,
+		ETag:       strings.Trim(resp.Header.Get("ETag"), `"`),
+		VersionID:  resp.Header.Get("X-Amz-Version-Id"),
+		Expiration: parseExpiration(resp.Header.Get("X-Amz-Expiration")),
+		RequestIDs: requestIDs(resp),
+	}
+}
+
+// parseExpiration parses a value like `expiry-date="Fri, 23 Dec 2012 00:00:00 GMT", rule-id="rule"`,
+// returning nil when absent or unparsable.
+func parseExpiration(val string) (exp *Expiration) {
+
+	if val == "" {
+		return
+	}
+
+	var parsed Expiration
+	for val != "" {
+		var name, quoted string
+		var ok bool
+
+		name, val, ok = strings.Cut(strings.TrimLeft(val, " ,"), `="`)
+		if !ok {
+			return
+		}
+		quoted, val, ok = strings.Cut(val, `"`)
+		if !ok {
+			return
+		}
+
+		switch name {
+		case "expiry-date":
+			date, err := http.ParseTime(quoted)
+			if err != nil {
+				return
+			}
+			parsed.Date = date
+		case "rule-id":
+			parsed.RuleID = quoted
+		}
+	}
+
+	if parsed.Date.IsZero() {
+		return
+	}
+
+	exp = &parsed
+	return
+}
@@ -1,15 +1,21 @@
 package objsto
 
 import (
+	"compress/gzip"
 	"context"
-	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/xml"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/clarktrimble/launch"
@@ -17,13 +23,21 @@ import (
 )
 
 // Config is Client configurables tagged for use with envconfig.
+//
+// One of SecretKey or SecretFile is needed, with SecretFile taking precedence.
+// ContinueSize relies on a transport with ExpectContinueTimeout set, as with DefaultTransport.
 type Config struct {
-	Region    string        `json:"region" desc:"provider region" required:"true"`
-	Scheme    string        `json:"scheme" desc:"http or https" default:"https"`
-	Host      string        `json:"host" desc:"endpoint hostname" required:"true"`
-	Bucket    string        `json:"bucket" desc:"bucket name" required:"true"`
-	AccessKey string        `json:"access_key" desc:"credential identifier" required:"true"`
-	SecretKey launch.Redact `json:"secret_key" desc:"credential secret or path to file" required:"true"`
+	Region       string        `json:"region" desc:"provider region" required:"true"`
+	Scheme       string        `json:"scheme" desc:"http or https" default:"https"`
+	Host         string        `json:"host" desc:"endpoint hostname" required:"true"`
+	Bucket       string        `json:"bucket" desc:"bucket name" required:"true"`
+	AccessKey    string        `json:"access_key" desc:"credential identifier" required:"true"`
+	SecretKey    launch.Redact `json:"secret_key" desc:"credential secret or path to file"`
+	SecretFile   string        `json:"secret_file" desc:"path to credential secret file, re-read when rotated"`
+	RateLimit    int64         `json:"rate_limit" desc:"transfer cap in bytes/sec, zero for none"`
+	RateBurst    int64         `json:"rate_burst" desc:"transfer burst in bytes, defaults to one second's worth"`
+	ContinueSize int64         `json:"continue_size" desc:"uploads of this size or more wait for 100-continue, zero for never" default:"8388608"`
+	UserAgent    string        `json:"user_agent" desc:"suffix for User-Agent, identifying the app"`
 }
 
 // HttpDoer performs HTTP requests. *http.Client satisfies this interface.
@@ -31,58 +45,235 @@ type HttpDoer interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
+// Clock tells the time. Handy for testing or when the local clock cannot be trusted.
+type Clock interface {
+	Now() time.Time
+}
+
 // Client is an S3 client.
 type Client struct {
-	region    string
-	scheme    string
-	host      string
-	bucket    string
-	accessKey string
-	secretKey string
-	client    HttpDoer
-	logger    Logger
+	region   string
+	scheme   string
+	host     string
+	bucket   string
+	prefix   string
+	policy   *KeyPolicy
+	timeout  time.Duration
+	creds    CredentialsProvider
+	limiter  *Limiter
+	contSize int64
+	agent    string
+	gzip     bool
+	wire     bool
+	debug    int
+	debugN   *atomic.Uint64
+	retry    RetryPolicy
+	clock    Clock
+	hooks    []Hooks
+	stats    *clientStats
+	window   int
+	client   HttpDoer
+	owned    bool
+	ownCreds bool
+	closed   *sync.Once
+	logger   Logger
+}
+
+// New creates Client from Config and options.
+func New(cfg *Config, opts ...ClientOption) *Client {
+
+	var limiter *Limiter
+	if cfg.RateLimit > 0 {
+		limiter = NewLimiter(cfg.RateLimit, cfg.RateBurst)
+	}
+
+	c := &Client{
+		region: cfg.Region,
+		scheme: cfg.Scheme,
+		host:   cfg.Host,
+		bucket: cfg.Bucket,
+		creds: StaticCredentials{
+			AccessKey: cfg.AccessKey,
+			SecretKey: string(cfg.SecretKey),
+		},
+		limiter:  limiter,
+		contSize: cfg.ContinueSize,
+		agent:    userAgent(cfg.UserAgent),
+		retry:    NoRetry{},
+		clock:    systemClock{},
+		window:   DefaultLatencyWindow,
+		debug:    1,
+		debugN:   &atomic.Uint64{},
+		logger:   noopLogger{},
+		ownCreds: true,
+	}
+
+	if cfg.SecretFile != "" {
+		c.creds = NewFileCredentials(cfg.AccessKey, cfg.SecretFile, 0)
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.client == nil {
+		c.client = NewHTTPClient(nil)
+		c.owned = true
+	}
+	c.closed = &sync.Once{}
+	c.stats = newClientStats(c.window)
+	if c.logger == nil {
+		c.logger = noopLogger{}
+	}
+
+	return c
+}
+
+// Clone returns a copy of the client with opts applied, sharing its HttpDoer unless overridden.
+// It's cheap, suiting derivation of a client per tenant with WithBucket or WithKeyPrefix.
+// The clone's Stats start from zero, and closing it leaves what it shares to the original.
+func (c *Client) Clone(opts ...ClientOption) *Client {
+
+	clone := *c
+	clone.hooks = slices.Clone(c.hooks)
+	clone.owned = false
+	clone.ownCreds = false
+	clone.closed = &sync.Once{}
+
+	for _, opt := range opts {
+		opt(&clone)
+	}
+	clone.stats = newClientStats(clone.window)
+
+	if clone.logger == nil {
+		clone.logger = noopLogger{}
+	}
+
+	return &clone
+}
+
+// Close releases resources, closing a credentials provider that is an io.Closer and idle
+// connections when the HttpDoer was created by New.
+// A clone closes only a provider it was given, resources shared with the original being left to it.
+// Subsequent calls do nothing.
+func (c *Client) Close() (err error) {
+
+	c.closed.Do(func() {
+		if closer, ok := c.creds.(io.Closer); ok && c.ownCreds {
+			err = closer.Close()
+			if err != nil {
+				err = errors.Wrap(err, "failed to close credentials provider")
+			}
+		}
+
+		if client, ok := c.client.(*http.Client); ok && c.owned {
+			client.CloseIdleConnections()
+		}
+	})
+
+	return
 }
 
 // New creates Client from Config.
+// A nil logger is fine, quietly discarding.
 func (cfg *Config) New(client HttpDoer, lgr Logger) *Client {
 
-	return &Client{
-		region:    cfg.Region,
-		scheme:    cfg.Scheme,
-		host:      cfg.Host,
-		bucket:    cfg.Bucket,
-		accessKey: cfg.AccessKey,
-		secretKey: string(cfg.SecretKey),
-		client:    client,
-		logger:    lgr,
-	}
+	return New(cfg, WithHTTPClient(client), WithLogger(lgr))
 }
 
 // Get gets an object.
 func (c *Client) Get(ctx context.Context, object string) (reader io.ReadCloser, err error) {
 
-	c.logger.Info(ctx, "getting from S3", "object", object)
+	resp, err := c.get(ctx, object, nil)
+	if err != nil {
+		return
+	}
+
+	reader = resp.Body
+	return
+}
 
-	req, err := c.buildRequest(ctx, "GET", object, nil)
+// GetRange gets length bytes of an object starting at offset, with a negative length reading to the end.
+func (c *Client) GetRange(ctx context.Context, object string, offset, length int64) (reader io.ReadCloser, err error) {
+
+	if offset < 0 {
+		err = errors.Errorf("offset cannot be negative")
+		return
+	}
+	if length == 0 {
+		reader = http.NoBody
+		return
+	}
+
+	rng := fmt.Sprintf("bytes=%d-", offset)
+	if length > 0 {
+		rng += strconv.FormatInt(offset+length-1, 10)
+	}
+
+	resp, err := c.get(ctx, object, http.Header{"Range": {rng}})
 	if err != nil {
 		return
 	}
 
-	resp, err := c.sendRequest(ctx, req)
+	reader = resp.Body
+	return
+}
+
+// GetIfNoneMatch gets an object with its info unless its ETag matches etag,
+// returning an error satisfying errors.Is(err, ErrNotModified) if so, or a plain get when etag is blank.
+func (c *Client) GetIfNoneMatch(ctx context.Context, object, etag string) (reader io.ReadCloser, info ObjectInfo, err error) {
+
+	var hdr http.Header
+	if etag != "" {
+		hdr = http.Header{"If-None-Match": {quote(etag)}}
+	}
+
+	resp, err := c.get(ctx, object, hdr)
 	if err != nil {
 		return
 	}
 
 	reader = resp.Body
+	info = objectInfo(object, resp)
+	return
+}
+
+// GetInto gets an object, streaming it into writer.
+// The copy is handed off to writer's ReadFrom when available, as with an *os.File.
+func (c *Client) GetInto(ctx context.Context, object string, writer io.Writer) (n int64, info ObjectInfo, err error) {
+
+	resp, err := c.get(ctx, object, nil)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	info = objectInfo(object, resp)
+
+	buf := getCopyBuf()
+	defer putCopyBuf(buf)
+
+	n, err = io.CopyBuffer(writer, resp.Body, *buf)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to copy %q", object)
+	}
+
+	if resp.Uncompressed {
+		info.EncodedSize = info.Size
+		info.Size = n
+	}
+
 	return
 }
 
 // Put puts an object.
-func (c *Client) Put(ctx context.Context, object string, reader io.ReadSeeker) (err error) {
+func (c *Client) Put(ctx context.Context, object string, reader io.ReadSeeker, opts ...PutOption) (err error) {
 
 	c.logger.Info(ctx, "putting to S3", "object", object)
 
-	req, err := c.buildRequest(ctx, "PUT", object, reader)
+	po := NewPutOptions(opts...)
+
+	req, err := c.buildRequest(ctx, "PUT", object, reader, po.header())
 	if err != nil {
 		return
 	}
@@ -93,233 +284,518 @@ func (c *Client) Put(ctx context.Context, object string, reader io.ReadSeeker) (
 	}
 	resp.Body.Close()
 
+	po.Record(putResult(object, resp))
+
 	return
 }
 
-// List returns object keys matching the given prefix.
-func (c *Client) List(ctx context.Context, prefix string) (keys []string, err error) {
-
-	c.logger.Info(ctx, "listing from S3", "prefix", prefix)
+// PutReader puts an object from a reader that cannot seek, such as a pipe or network stream.
+// The size must be known up front and is sent as Content-Length, avoiding chunked encoding.
+// The payload is not hashed, being sent as UNSIGNED-PAYLOAD, so rely on TLS for integrity.
+func (c *Client) PutReader(ctx context.Context, object string, reader io.Reader, size int64, opts ...PutOption) (err error) {
 
-	path := fmt.Sprintf("/%s", c.bucket)
-	now := time.Now().UTC()
+	c.logger.Info(ctx, "putting to S3", "object", object, "size", size)
 
-	params := url.Values{}
-	params.Set("list-type", "2")
-	params.Set("prefix", prefix)
-	query := params.Encode()
+	if object == "" {
+		err = errors.Errorf("object cannot be blank")
+		return
+	}
+	if size < 0 {
+		err = errors.Errorf("size cannot be negative")
+		return
+	}
+	if size == 0 {
+		reader = http.NoBody
+	}
 
-	uri := fmt.Sprintf("%s://%s%s?%s", c.scheme, c.host, path, query)
+	po := NewPutOptions(opts...)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
+	req, err := c.newRequest(ctx, "PUT", object, nil, reader, size, unsignedPayload, po.header())
 	if err != nil {
-		err = errors.Wrapf(err, "failed to create list request")
 		return
 	}
 
-	hash, _, err := hashPayload(nil)
+	resp, err := c.sendRequest(ctx, req)
 	if err != nil {
 		return
 	}
+	resp.Body.Close()
 
-	headers := signRequest("GET", c.region, c.host, path, c.accessKey, c.secretKey, hash, query, now)
+	po.Record(putResult(object, resp))
 
-	for k, v := range headers {
-		req.Header.Set(k, v)
+	return
+}
+
+// Delete deletes an object.
+// As with S3, deleting an object that does not exist is not an error.
+func (c *Client) Delete(ctx context.Context, object string) (err error) {
+
+	c.logger.Info(ctx, "deleting from S3", "object", object)
+
+	req, err := c.buildRequest(ctx, "DELETE", object, nil, nil)
+	if err != nil {
+		return
 	}
 
-	c.logger.Debug(ctx, "signed list request",
-		"url", req.URL.String(),
-		"headers", req.Header,
-	)
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+
+	return
+}
+
+// Stat gets an object's metadata without its content.
+func (c *Client) Stat(ctx context.Context, object string) (info ObjectInfo, err error) {
+
+	c.logger.Info(ctx, "statting in S3", "object", object)
+
+	// ask for the checksum, for objects uploaded with one
+	hdr := http.Header{"X-Amz-Checksum-Mode": {"ENABLED"}}
+
+	req, err := c.buildRequest(ctx, "HEAD", object, nil, hdr)
+	if err != nil {
+		return
+	}
 
 	resp, err := c.sendRequest(ctx, req)
 	if err != nil {
 		return
 	}
-	defer resp.Body.Close()
+	resp.Body.Close()
+
+	info = objectInfo(object, resp)
+	return
+}
+
+// List returns object keys matching the given prefix, getting as many pages as needed.
+func (c *Client) List(ctx context.Context, prefix string) (keys []string, err error) {
+
+	for info, err := range c.ListObjects(ctx, ListInput{Prefix: prefix}) {
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, info.Key)
+	}
+
+	return
+}
 
-	var result listBucketResult
-	err = xml.NewDecoder(resp.Body).Decode(&result)
+// Do builds, signs, and sends a request, for operations not otherwise supported.
+// A blank object addresses the bucket itself, as with query "lifecycle" or "uploads".
+// Headers named x-amz-* are signed, and responses other than 2xx are returned as errors.
+func (c *Client) Do(ctx context.Context, method, object string, query url.Values, body io.ReadSeeker, hdr http.Header) (resp *http.Response, err error) {
+
+	c.logger.Info(ctx, "sending to S3", "method", method, "object", object)
+
+	hash, size, err := hashPayload(body)
 	if err != nil {
-		err = errors.Wrap(err, "failed to parse list response")
 		return
 	}
 
-	for _, obj := range result.Contents {
-		keys = append(keys, obj.Key)
+	var reader io.Reader
+	if body != nil {
+		reader = body
 	}
 
+	req, err := c.newRequest(ctx, method, object, query, reader, size, hash, hdr)
+	if err != nil {
+		return
+	}
+
+	resp, err = c.sendRequest(ctx, req)
 	return
 }
 
 // unexported
 
-func (c *Client) buildRequest(ctx context.Context, method, object string, pyld io.ReadSeeker) (req *http.Request, err error) {
+func (c *Client) get(ctx context.Context, object string, hdr http.Header) (resp *http.Response, err error) {
+
+	c.logger.Info(ctx, "getting from S3", "object", object)
+
+	// a range of a compressed variant is no use, and a caller's choice stands
+	accept := c.gzip && hdr.Get("Range") == "" && headersFrom(ctx).Get("Accept-Encoding") == ""
+	if accept {
+		hdr = hdr.Clone()
+		if hdr == nil {
+			hdr = http.Header{}
+		}
+		hdr.Set("Accept-Encoding", "gzip")
+	}
+
+	req, err := c.buildRequest(ctx, "GET", object, nil, hdr)
+	if err != nil {
+		return
+	}
+
+	resp, err = c.sendRequest(ctx, req)
+	if err != nil || !accept || !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return
+	}
+
+	gzr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		err = errors.Wrapf(err, "failed to gunzip %q", object)
+		return
+	}
+
+	resp.Body = &decompressReader{ReadCloser: gzr, body: resp.Body}
+	resp.Uncompressed = true
+	return
+}
+
+func (c *Client) buildRequest(ctx context.Context, method, object string, pyld io.ReadSeeker, hdr http.Header) (req *http.Request, err error) {
 
 	if object == "" {
 		err = errors.Errorf("object cannot be blank")
 		return
 	}
 
-	// create request
+	hash, size, err := hashPayload(pyld)
+	if err != nil {
+		return
+	}
 
-	path := fmt.Sprintf("/%s/%s", c.bucket, object)
+	var body io.Reader
+	if pyld != nil {
+		body = pyld
+	}
+
+	req, err = c.newRequest(ctx, method, object, nil, body, size, hash, hdr)
+	return
+}
+
+func (c *Client) newRequest(ctx context.Context, method, object string, query url.Values, body io.Reader, size int64, hash string, hdr http.Header) (req *http.Request, err error) {
+
+	// create request, for the bucket itself when object is blank
+
+	if c.policy != nil && object != "" {
+		object, err = c.policy.Apply(object)
+		if err != nil {
+			return
+		}
+	}
+
+	path := "/" + uriEncode(c.bucket)
+	if object != "" {
+		path += "/" + uriEncode(c.prefix+object)
+	}
 	uri := fmt.Sprintf("%s://%s%s", c.scheme, c.host, path)
-	now := time.Now().UTC()
 
-	req, err = http.NewRequestWithContext(ctx, method, uri, pyld)
+	rawQuery := canonicalQuery(query)
+	if rawQuery != "" {
+		uri += "?" + rawQuery
+	}
+
+	req, err = http.NewRequestWithContext(ctx, method, uri, body)
 	if err != nil {
 		err = errors.Wrapf(err, "failed to create request to %q", uri)
 		return
 	}
+	// canonical keys, so mixed-case duplicates go as one header, signed as the server sees it
+	for _, key := range slices.Sorted(maps.Keys(hdr)) {
+		canon := http.CanonicalHeaderKey(key)
+		req.Header[canon] = append(req.Header[canon], hdr[key]...)
+	}
+	addHeaders(ctx, req.Header)
+	req.Header.Set("User-Agent", c.agent)
+	req.ContentLength = size
 
-	c.logger.Debug(ctx, "signing request",
-		"region", c.region,
-		"host", c.host,
-		"path", path,
-		"access_key", c.accessKey,
-		"now", now,
-	)
+	if seeker, ok := body.(io.Seeker); ok && req.GetBody == nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			_, err := seeker.Seek(0, io.SeekStart)
+			return io.NopCloser(body), err
+		}
+	}
 
 	// add signature headers
 
-	hash, size, err := hashPayload(pyld)
+	debug := c.sampled()
+
+	err = c.sign(ctx, req, path, rawQuery, hash, debug)
 	if err != nil {
 		return
 	}
 
-	headers := signRequest(method, c.region, c.host, path, c.accessKey, c.secretKey, hash, "", now)
-
-	req.ContentLength = size
-	for k, v := range headers {
-		req.Header.Set(k, v)
+	// let the server reject before a big body goes out
+	if c.contSize > 0 && size >= c.contSize {
+		req.Header.Set("Expect", "100-continue")
 	}
 
-	c.logger.Debug(ctx, "signed request",
-		"url", req.URL.String(),
-		"host", req.Host,
-		"headers", req.Header,
-	)
+	if debug {
+		c.logger.Debug(ctx, "signed request",
+			"url", req.URL.String(),
+			"host", req.Host,
+			"headers", redactHeader(req.Header),
+		)
+	}
 
 	return
 }
 
-func (c *Client) sendRequest(ctx context.Context, req *http.Request) (resp *http.Response, err error) {
+func (c *Client) sign(ctx context.Context, req *http.Request, path, query, hash string, debug bool) (err error) {
 
-	start := time.Now()
-	resp, err = c.client.Do(req)
-	elapsed := time.Since(start)
+	creds, err := c.creds.Credentials(ctx)
 	if err != nil {
-		err = errors.Wrapf(err, "failed request to %q", req.URL)
+		err = errors.Wrap(err, "failed to get credentials")
 		return
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		defer resp.Body.Close()
-		err = parseS3Error(resp)
-		return
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
 	}
 
-	// Todo: rejigger so we can haz request_id in ctx tying this to getting/putting
-	c.logger.Info(ctx, "S3 response", "status", resp.StatusCode, "elapsed", elapsed)
+	now := c.clock.Now().UTC()
+
+	if debug {
+		c.logger.Debug(ctx, "signing request",
+			"region", c.region,
+			"host", c.host,
+			"path", path,
+			"access_key", redactKey(creds.AccessKey),
+			"now", now,
+		)
+	}
+
+	sig := signRequest(req.Method, c.region, c.host, path, creds.AccessKey, creds.SecretKey, hash, query, req.Header, now)
+	sig.set(req.Header)
 
 	return
 }
 
-func hashPayload(body io.ReadSeeker) (hash string, size int64, err error) {
+func (c *Client) sendRequest(ctx context.Context, req *http.Request) (resp *http.Response, err error) {
 
-	h := sha256.New()
-	if body != nil {
-		size, err = io.Copy(h, body)
+	if req.Body != nil && req.Body != http.NoBody {
+		size := req.ContentLength
+		req.Body = c.counted(c.throttle(ctx, progress(ctx, req.Body, size)), &c.stats.up)
+		if getBody := req.GetBody; getBody != nil {
+			req.GetBody = func() (io.ReadCloser, error) {
+				body, err := getBody()
+				if err != nil {
+					return nil, err
+				}
+				return c.counted(c.throttle(ctx, progress(ctx, body, size)), &c.stats.up), nil
+			}
+		}
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		req = req.WithContext(ctx)
+		defer func() {
+			if err != nil {
+				cancel()
+				return
+			}
+			resp.Body = &cancelCloser{ReadCloser: resp.Body, cancel: cancel}
+		}()
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = c.beforeSend(req)
 		if err != nil {
-			err = errors.Wrap(err, "failed to hash body")
 			return
 		}
-		_, err = body.Seek(0, io.SeekStart)
+
+		if c.wire {
+			c.dumpRequest(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err = c.client.Do(req)
+		elapsed := time.Since(start)
+
+		if c.wire && resp != nil {
+			c.dumpResponse(ctx, resp)
+		}
+
+		switch {
+		case err != nil:
+			err = errors.Wrapf(err, "failed request to %q", req.URL)
+		case resp.StatusCode < 200 || resp.StatusCode >= 300:
+			err = parseS3Error(resp)
+			resp.Body.Close()
+		}
+		c.afterReceive(req, resp, err, elapsed)
+		c.stats.attempt(Operation(req), elapsed, err)
+		if resp != nil {
+			recordRequestIDs(ctx, requestIDs(resp))
+		}
+
+		if err == nil {
+			ids := requestIDs(resp)
+			c.logger.Info(ctx, "S3 response", "status", resp.StatusCode, "elapsed", elapsed,
+				"request_id", ids.RequestID, "host_id", ids.HostID)
+
+			resp.Body = c.counted(c.throttle(ctx, resp.Body), &c.stats.down)
+			if req.Method == "GET" {
+				resp.Body = progress(ctx, resp.Body, resp.ContentLength)
+			}
+			return
+		}
+
+		delay, ok := c.retry.Retry(attempt, resp, err)
+		if !ok {
+			return
+		}
+
+		retryReq, rewound := rewind(req)
+		if !rewound {
+			return
+		}
+
+		c.logger.Info(ctx, "retrying S3 request", "attempt", attempt, "delay", delay, "error", err.Error())
+
+		if sleepErr := sleep(ctx, delay); sleepErr != nil {
+			return
+		}
+		req = retryReq
+	}
+}
+
+func (c *Client) beforeSend(req *http.Request) (err error) {
+
+	for _, hooks := range c.hooks {
+		if hooks.BeforeSend == nil {
+			continue
+		}
+		err = hooks.BeforeSend(req)
 		if err != nil {
-			err = errors.Wrap(err, "failed to seek body")
+			err = errors.Wrap(err, "before send hook failed")
 			return
 		}
 	}
-	hash = hex.EncodeToString(h.Sum(nil))
 
 	return
 }
 
-type listBucketResult struct {
-	Contents []struct {
-		Key string `xml:"Key"`
-	} `xml:"Contents"`
+func (c *Client) afterReceive(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+
+	for _, hooks := range c.hooks {
+		if hooks.AfterReceive != nil {
+			hooks.AfterReceive(req, resp, err, elapsed)
+		}
+	}
 }
 
-type s3Error struct {
-	Code      string `xml:"Code"`
-	Message   string `xml:"Message"`
-	RequestID string `xml:"RequestId"`
+// sampled reports whether to log debug lines for a request, per WithDebugSampling.
+func (c *Client) sampled() bool {
+
+	switch {
+	case c.debug == 1:
+		return true
+	case c.debug <= 0:
+		return false
+	}
+
+	return (c.debugN.Add(1)-1)%uint64(c.debug) == 0
 }
 
-func parseS3Error(resp *http.Response) error {
+func (c *Client) counted(reader io.ReadCloser, count *atomic.Int64) io.ReadCloser {
 
-	bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024*4))
+	return &countReader{ReadCloser: reader, count: count}
+}
 
-	var s3Err s3Error
-	err := xml.Unmarshal(bodyBytes, &s3Err)
+func (c *Client) throttle(ctx context.Context, reader io.ReadCloser) io.ReadCloser {
+
+	reader = throttle(ctx, reader, c.limiter)
+	return throttle(ctx, reader, limiterFrom(ctx))
+}
+
+func rewind(req *http.Request) (*http.Request, bool) {
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return req.Clone(req.Context()), true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+
+	body, err := req.GetBody()
 	if err != nil {
-		return errors.Errorf("http error, status: %d, body: %s", resp.StatusCode, string(bodyBytes))
+		return nil, false
 	}
 
-	return errors.Errorf("s3 error, code: %s, request_id: %s, message: %s, headers: %s",
-		s3Err.Code, s3Err.RequestID, s3Err.Message, resp.Header)
-}
+	retryReq := req.Clone(req.Context())
+	retryReq.Body = body
 
-// vibe coded goodness
+	return retryReq, true
+}
 
-const (
-	service = "s3"
-)
+// cancelCloser cancels a context when closed, ending a timeout that covers reading the body.
+type cancelCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
 
-func signRequest(method, region, host, path, accessKey, secretKey, payloadHash, query string, t time.Time) map[string]string {
+func (cc *cancelCloser) Close() error {
 
-	amzDate := t.Format("20060102T150405Z")
-	dateStamp := t.Format("20060102")
+	err := cc.ReadCloser.Close()
+	cc.cancel()
+	return err
+}
 
-	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", host, payloadHash, amzDate)
-	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
-	canonicalRequest := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n%s", method, path, query, canonicalHeaders, signedHeaders, payloadHash)
+type systemClock struct{}
 
-	credentialScope := fmt.Sprintf("%s/%s/%s/aws4_request", dateStamp, region, service)
-	stringToSign := fmt.Sprintf("AWS4-HMAC-SHA256\n%s\n%s\n%s", amzDate, credentialScope, sha256Hash(canonicalRequest))
+func (sc systemClock) Now() time.Time {
+	return time.Now()
+}
 
-	signingKey := getSignatureKey(secretKey, dateStamp, region, service)
-	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+func hashPayload(body io.ReadSeeker) (hash string, size int64, err error) {
 
-	authHeader := fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
-		accessKey, credentialScope, signedHeaders, signature)
+	h := getHasher()
+	defer putHasher(h)
 
-	return map[string]string{
-		"Authorization":        authHeader,
-		"x-amz-date":           amzDate,
-		"x-amz-content-sha256": payloadHash,
+	if body != nil {
+		buf := getCopyBuf()
+		size, err = io.CopyBuffer(h, body, *buf)
+		putCopyBuf(buf)
+		if err != nil {
+			err = errors.Wrap(err, "failed to hash body")
+			return
+		}
+		_, err = body.Seek(0, io.SeekStart)
+		if err != nil {
+			err = errors.Wrap(err, "failed to seek body")
+			return
+		}
 	}
-}
+	var sum [sha256.Size]byte
+	hash = hex.EncodeToString(h.Sum(sum[:0]))
 
-func sha256Hash(data string) string {
-	h := sha256.New()
-	h.Write([]byte(data))
-	return hex.EncodeToString(h.Sum(nil))
+	return
 }
 
-func hmacSHA256(key []byte, data string) []byte {
-	h := hmac.New(sha256.New, key)
-	h.Write([]byte(data))
-	return h.Sum(nil)
+type s3Error struct {
+	Code      string `xml:"Code"`
+	Message   string `xml:"Message"`
+	RequestID string `xml:"RequestId"`
 }
 
-func getSignatureKey(secret, date, region, service string) []byte {
-	kDate := hmacSHA256([]byte("AWS4"+secret), date)
-	kRegion := hmacSHA256(kDate, region)
-	kService := hmacSHA256(kRegion, service)
-	kSigning := hmacSHA256(kService, "aws4_request")
-	return kSigning
+func parseS3Error(resp *http.Response) error {
+
+	bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024*4))
+
+	// HEAD responses have no body, so go with status
+	cause := statusError(resp.StatusCode)
+	ids := requestIDs(resp)
+
+	var s3Err s3Error
+	err := xml.Unmarshal(bodyBytes, &s3Err)
+	if err != nil {
+		err = errors.Wrapf(cause, "http error, status: %d, request_id: %s, host_id: %s, body: %s",
+			resp.StatusCode, ids.RequestID, ids.HostID, string(bodyBytes))
+		return &responseError{error: err, ids: ids}
+	}
+
+	if s3Err.RequestID != "" {
+		ids.RequestID = s3Err.RequestID
+	}
+
+	err = errors.Wrapf(cause, "s3 error, code: %s, request_id: %s, host_id: %s, message: %s, headers: %s",
+		s3Err.Code, ids.RequestID, ids.HostID, s3Err.Message, redactHeader(resp.Header))
+	return &responseError{error: err, ids: ids}
 }
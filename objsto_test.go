@@ -4,10 +4,18 @@ package objsto_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -50,15 +58,1363 @@ var _ = Describe("Client", func() {
 		client = cfg.New(mock, lgr)
 	})
 
+	Describe("New with options", func() {
+		BeforeEach(func() {
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			}
+
+			client = objsto.New(cfg,
+				objsto.WithHTTPClient(mock),
+				objsto.WithClock(fixedClock{time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}),
+				objsto.WithCredentialsProvider(objsto.StaticCredentials{
+					AccessKey:    "temp-access-key",
+					SecretKey:    "temp-secret-key",
+					SessionToken: "temp-token",
+				}),
+			)
+		})
+
+		It("signs with the clock and provided credentials", func() {
+			_, err := client.Get(ctx, "test-object.txt")
+			Expect(err).ToNot(HaveOccurred())
+
+			hdr := mock.DoCalls()[0].Request.Header
+			Expect(hdr.Get("x-amz-date")).To(Equal("20260301T120000Z"))
+			Expect(hdr.Get("x-amz-security-token")).To(Equal("temp-token"))
+			Expect(hdr.Get("Authorization")).To(ContainSubstring("Credential=temp-access-key/20260301/"))
+			Expect(hdr.Get("Authorization")).To(ContainSubstring("x-amz-security-token"))
+		})
+
+		It("sends the default user agent", func() {
+			_, err := client.Get(ctx, "test-object.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mock.DoCalls()[0].Request.Header.Get("User-Agent")).To(MatchRegexp(`^objsto/\S+$`))
+		})
+
+		When("a user agent suffix is set", func() {
+			BeforeEach(func() {
+				client = objsto.New(cfg, objsto.WithHTTPClient(mock), objsto.WithUserAgent("myapp/2.1"))
+			})
+
+			It("appends it", func() {
+				_, err := client.Get(ctx, "test-object.txt")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mock.DoCalls()[0].Request.Header.Get("User-Agent")).To(MatchRegexp(`^objsto/\S+ \(myapp/2\.1\)$`))
+			})
+		})
+	})
+
+	Describe("New with hooks", func() {
+		var (
+			statuses []int
+			hookErr  error
+			err      error
+		)
+
+		BeforeEach(func() {
+			statuses = nil
+			hookErr = nil
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 404,
+					Header:     req.Header,
+					Body:       io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			client = objsto.New(cfg,
+				objsto.WithHTTPClient(mock),
+				objsto.WithHooks(objsto.Hooks{
+					BeforeSend: func(req *http.Request) error {
+						req.Header.Set("Traceparent", "00-abc-def-01")
+						return hookErr
+					},
+				}),
+				objsto.WithHooks(objsto.Hooks{
+					AfterReceive: func(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+						statuses = append(statuses, resp.StatusCode)
+					},
+				}),
+			)
+
+			_, err = client.Get(ctx, "test-object.txt")
+		})
+
+		It("calls them around the request", func() {
+			Expect(errors.Is(err, objsto.ErrNotFound)).To(BeTrue())
+			Expect(mock.DoCalls()[0].Request.Header.Get("Traceparent")).To(Equal("00-abc-def-01"))
+			Expect(statuses).To(Equal([]int{404}))
+		})
+
+		When("before send fails", func() {
+			BeforeEach(func() {
+				hookErr = errors.New("nope")
+			})
+
+			It("does not send", func() {
+				Expect(err).To(MatchError(ContainSubstring("before send hook failed")))
+				Expect(mock.DoCalls()).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("New with wire trace", func() {
+		var (
+			dumps []string
+		)
+
+		BeforeEach(func() {
+			dumps = nil
+			lgr.TraceFunc = func(ctx context.Context, msg string, kv ...any) {
+				dumps = append(dumps, kv[1].(string))
+			}
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				hdr := http.Header{}
+				hdr.Set("X-Amz-Request-Id", "req-1")
+				return &http.Response{
+					ProtoMajor: 1,
+					ProtoMinor: 1,
+					StatusCode: 200,
+					Header:     hdr,
+					Body:       io.NopCloser(bytes.NewReader([]byte("secret body"))),
+				}, nil
+			}
+
+			cfg.AccessKey = "AKIDEXAMPLE"
+			client = objsto.New(cfg, objsto.WithHTTPClient(mock), objsto.WithLogger(lgr), objsto.WithWireTrace(),
+				objsto.WithCredentialsProvider(objsto.StaticCredentials{
+					AccessKey: "AKIDEXAMPLE", SecretKey: "test-secret-key", SessionToken: "session-token",
+				}))
+		})
+
+		It("dumps lines and headers, redacted", func() {
+			reader, err := client.Get(ctx, "test-object.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(io.ReadAll(reader)).To(Equal([]byte("secret body")))
+
+			Expect(dumps).To(HaveLen(2))
+			Expect(dumps[0]).To(HavePrefix("GET /test-bucket/test-object.txt HTTP/1.1\r\nHost: test-host\r\n"))
+			Expect(dumps[0]).To(MatchRegexp(`Authorization: AWS4-HMAC-SHA256 Credential=REDACTED/\d{8}/test-region/s3/aws4_request, ` +
+				`SignedHeaders=[a-z0-9;-]+, Signature=REDACTED\r\n`))
+			Expect(dumps[0]).To(ContainSubstring("X-Amz-Security-Token: REDACTED\r\n"))
+			Expect(dumps[0]).ToNot(ContainSubstring("AKIDEXAMPLE"))
+			Expect(dumps[0]).ToNot(ContainSubstring("session-token"))
+
+			Expect(dumps[1]).To(HavePrefix("HTTP/1.1 200 OK\r\n"))
+			Expect(dumps[1]).To(ContainSubstring("X-Amz-Request-Id: req-1"))
+			Expect(dumps[1]).ToNot(ContainSubstring("secret body"))
+		})
+	})
+
+	Describe("Debug logging", func() {
+		var (
+			logged []any
+		)
+
+		BeforeEach(func() {
+			logged = nil
+			lgr.DebugFunc = func(ctx context.Context, msg string, kv ...any) {
+				logged = append(logged, kv...)
+			}
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			}
+		})
+
+		It("redacts credentials", func() {
+			_, err := client.Get(ctx, "test-object.txt")
+			Expect(err).ToNot(HaveOccurred())
+
+			dump := fmt.Sprint(logged...)
+			Expect(dump).To(ContainSubstring("testREDACTED"))
+			Expect(dump).To(ContainSubstring("Signature=REDACTED"))
+			Expect(dump).ToNot(ContainSubstring("test-access-key"))
+
+			auth := mock.DoCalls()[0].Request.Header.Get("Authorization")
+			Expect(auth).To(ContainSubstring("Credential=test-access-key/"))
+		})
+
+		It("samples or disables", func() {
+			for every, lines := range map[int]int{0: 0, 2: 4} {
+				lgr = &LoggerMock{
+					InfoFunc:  func(ctx context.Context, msg string, kv ...any) {},
+					DebugFunc: func(ctx context.Context, msg string, kv ...any) {},
+				}
+				client = objsto.New(cfg, objsto.WithHTTPClient(mock), objsto.WithLogger(lgr), objsto.WithDebugSampling(every))
+
+				for range 3 {
+					_, err := client.Get(ctx, "test-object.txt")
+					Expect(err).ToNot(HaveOccurred())
+				}
+				Expect(lgr.DebugCalls()).To(HaveLen(lines))
+			}
+		})
+	})
+
+	Describe("Clone", func() {
+		var (
+			clone *objsto.Client
+		)
+
+		BeforeEach(func() {
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				body := `<ListBucketResult><Contents><Key>tenant-a/one.txt</Key></Contents></ListBucketResult>`
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+				}, nil
+			}
+
+			clone = client.Clone(objsto.WithBucket("other-bucket"), objsto.WithKeyPrefix("tenant-a/"))
+		})
+
+		It("adds the prefix to keys", func() {
+			_, err := clone.Get(ctx, "one.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mock.DoCalls()[0].Request.URL.Path).To(Equal("/other-bucket/tenant-a/one.txt"))
+		})
+
+		It("lists under the prefix, trimming it", func() {
+			keys, err := clone.List(ctx, "o")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(keys).To(Equal([]string{"one.txt"}))
+			Expect(mock.DoCalls()[0].Request.URL.Query().Get("prefix")).To(Equal("tenant-a/o"))
+		})
+
+		It("leaves the original alone", func() {
+			_, err := client.Get(ctx, "one.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mock.DoCalls()[0].Request.URL.Path).To(Equal("/test-bucket/one.txt"))
+		})
+
+		When("a key policy is set", func() {
+			BeforeEach(func() {
+				clone = client.Clone(objsto.WithKeyPolicy(objsto.KeyPolicy{Normalize: true}))
+			})
+
+			It("applies it before sending", func() {
+				_, err := clone.Get(ctx, "/a//b.txt")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mock.DoCalls()[0].Request.URL.Path).To(Equal("/test-bucket/a/b.txt"))
+
+				_, err = clone.Get(ctx, "nul\x00")
+				Expect(errors.Is(err, objsto.ErrInvalidKey)).To(BeTrue())
+				Expect(mock.DoCalls()).To(HaveLen(1))
+			})
+		})
+
+		When("a timeout is set", func() {
+			BeforeEach(func() {
+				mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+					<-req.Context().Done()
+					return nil, req.Context().Err()
+				}
+
+				clone = client.Clone(objsto.WithTimeout(10 * time.Millisecond))
+			})
+
+			It("gives up", func() {
+				_, err := clone.Get(ctx, "one.txt")
+				Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("Close", func() {
+		var (
+			creds *closingCredentials
+		)
+
+		BeforeEach(func() {
+			creds = &closingCredentials{}
+			client = objsto.New(cfg, objsto.WithHTTPClient(mock), objsto.WithCredentialsProvider(creds))
+		})
+
+		It("closes the credentials provider once", func() {
+			Expect(client.Close()).To(Succeed())
+			Expect(client.Clone().Close()).To(Succeed())
+			Expect(creds.closed).To(Equal(1))
+		})
+
+		It("leaves the provider open when a clone closes", func() {
+			Expect(client.Clone(objsto.WithBucket("other-bucket")).Close()).To(Succeed())
+			Expect(creds.closed).To(BeZero())
+
+			Expect(client.Close()).To(Succeed())
+			Expect(creds.closed).To(Equal(1))
+		})
+
+		It("closes a provider given to a clone", func() {
+			own := &closingCredentials{}
+			Expect(client.Clone(objsto.WithCredentialsProvider(own)).Close()).To(Succeed())
+			Expect(own.closed).To(Equal(1))
+			Expect(creds.closed).To(BeZero())
+		})
+	})
+
+	Describe("New without a logger", func() {
+		BeforeEach(func() {
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewReader([]byte("test content"))),
+				}, nil
+			}
+			client = cfg.New(mock, nil)
+		})
+
+		It("does not panic", func() {
+			_, err := client.Get(ctx, "test-object.txt")
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
 	Describe("Get", func() {
 		var (
 			object string
-			reader io.ReadCloser
+			reader io.ReadCloser
+			err    error
+		)
+
+		JustBeforeEach(func() {
+			reader, err = client.Get(ctx, object)
+		})
+
+		When("object is blank", func() {
+			BeforeEach(func() {
+				object = ""
+			})
+
+			It("returns error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("cannot be blank"))
+			})
+		})
+
+		When("request succeeds", func() {
+			BeforeEach(func() {
+				object = "test-object.txt"
+				mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(bytes.NewReader([]byte("test content"))),
+					}, nil
+				}
+			})
+
+			It("returns reader with body", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(reader).ToNot(BeNil())
+
+				content, _ := io.ReadAll(reader)
+				Expect(string(content)).To(Equal("test content"))
+			})
+
+			It("sends signed request to correct path", func() {
+				calls := mock.DoCalls()
+				Expect(calls).To(HaveLen(1))
+				Expect(calls[0].Request.URL.Path).To(Equal("/test-bucket/test-object.txt"))
+				Expect(calls[0].Request.Header.Get("Authorization")).To(ContainSubstring("AWS4-HMAC-SHA256"))
+			})
+		})
+	})
+
+	Describe("Get with context headers", func() {
+		BeforeEach(func() {
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			}
+		})
+
+		It("sends and signs them", func() {
+			hctx := objsto.WithHeaders(ctx, http.Header{"x-amz-request-payer": {"requester"}})
+			hctx = objsto.WithHeaders(hctx, http.Header{"X-Custom": {"yes"}})
+
+			_, err := client.Get(hctx, "test-object.txt")
+			Expect(err).ToNot(HaveOccurred())
+
+			hdr := mock.DoCalls()[0].Request.Header
+			Expect(hdr.Get("X-Amz-Request-Payer")).To(Equal("requester"))
+			Expect(hdr.Get("X-Custom")).To(Equal("yes"))
+			Expect(hdr.Get("Authorization")).To(ContainSubstring("x-amz-date;x-amz-request-payer,"))
+		})
+	})
+
+	Describe("GetRange", func() {
+		var (
+			length int64
+			err    error
+		)
+
+		BeforeEach(func() {
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 206,
+					Body:       io.NopCloser(bytes.NewReader([]byte("content"))),
+				}, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			_, err = client.GetRange(ctx, "test-object.txt", 5, length)
+		})
+
+		When("length is given", func() {
+			BeforeEach(func() {
+				length = 10
+			})
+
+			It("sends a bounded range", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mock.DoCalls()[0].Request.Header.Get("Range")).To(Equal("bytes=5-14"))
+			})
+		})
+
+		When("length is negative", func() {
+			BeforeEach(func() {
+				length = -1
+			})
+
+			It("sends an open range", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mock.DoCalls()[0].Request.Header.Get("Range")).To(Equal("bytes=5-"))
+			})
+		})
+	})
+
+	Describe("GetIfNoneMatch", func() {
+		var (
+			etag string
+			err  error
+		)
+
+		JustBeforeEach(func() {
+			_, _, err = client.GetIfNoneMatch(ctx, "test-object.txt", etag)
+		})
+
+		When("the etag matches", func() {
+			BeforeEach(func() {
+				etag = "abc123"
+				mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: 304,
+						Body:       io.NopCloser(bytes.NewReader(nil)),
+					}, nil
+				}
+			})
+
+			It("sends a quoted If-None-Match and returns not modified", func() {
+				Expect(mock.DoCalls()[0].Request.Header.Get("If-None-Match")).To(Equal(`"abc123"`))
+				Expect(errors.Is(err, objsto.ErrNotModified)).To(BeTrue())
+			})
+		})
+
+		When("the etag is blank", func() {
+			BeforeEach(func() {
+				etag = ""
+				mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: 200,
+						Header:     http.Header{"Etag": {`"def456"`}},
+						Body:       io.NopCloser(bytes.NewReader([]byte("content"))),
+					}, nil
+				}
+			})
+
+			It("gets plainly", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mock.DoCalls()[0].Request.Header.Values("If-None-Match")).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("GetInto", func() {
+		var (
+			object string
+			buf    *bytes.Buffer
+			n      int64
+			info   objsto.ObjectInfo
+			err    error
+		)
+
+		JustBeforeEach(func() {
+			buf = &bytes.Buffer{}
+			n, info, err = client.GetInto(ctx, object, buf)
+		})
+
+		When("request succeeds", func() {
+			BeforeEach(func() {
+				object = "test-object.txt"
+				mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+					header := http.Header{}
+					header.Set("ETag", `"abc123"`)
+					header.Set("Content-Type", "text/plain")
+					header.Set("Last-Modified", "Mon, 02 Mar 2026 06:00:53 GMT")
+					header.Set("X-Amz-Meta-Owner", "bob")
+					return &http.Response{
+						StatusCode:    200,
+						Header:        header,
+						ContentLength: 12,
+						Body:          io.NopCloser(bytes.NewReader([]byte("test content"))),
+					}, nil
+				}
+			})
+
+			It("writes the body and returns header info", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(n).To(Equal(int64(12)))
+				Expect(buf.String()).To(Equal("test content"))
+				Expect(info.Key).To(Equal("test-object.txt"))
+				Expect(info.Size).To(Equal(int64(12)))
+				Expect(info.ETag).To(Equal("abc123"))
+				Expect(info.ContentType).To(Equal("text/plain"))
+				Expect(info.LastModified).To(Equal(time.Date(2026, 3, 2, 6, 0, 53, 0, time.UTC)))
+				Expect(info.Metadata).To(Equal(map[string]string{"owner": "bob"}))
+			})
+		})
+
+		When("accepting gzip and served compressed", func() {
+			BeforeEach(func() {
+				object = "test-object.txt"
+
+				zipped := &bytes.Buffer{}
+				gzw := gzip.NewWriter(zipped)
+				_, err := gzw.Write([]byte(strings.Repeat("test content ", 10)))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(gzw.Close()).To(Succeed())
+
+				mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+					header := http.Header{}
+					header.Set("Content-Encoding", "gzip")
+					return &http.Response{
+						StatusCode:    200,
+						Header:        header,
+						ContentLength: int64(zipped.Len()),
+						Body:          io.NopCloser(bytes.NewReader(zipped.Bytes())),
+					}, nil
+				}
+
+				client = client.Clone(objsto.WithAcceptGzip())
+			})
+
+			It("asks for gzip and decompresses, with both sizes", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mock.DoCalls()[0].Request.Header.Get("Accept-Encoding")).To(Equal("gzip"))
+				Expect(buf.String()).To(Equal(strings.Repeat("test content ", 10)))
+				Expect(n).To(Equal(int64(130)))
+				Expect(info.Size).To(Equal(int64(130)))
+				Expect(info.EncodedSize).To(BeNumerically(">", 0))
+				Expect(info.EncodedSize).To(BeNumerically("<", 130))
+			})
+
+			It("leaves ranged gets alone", func() {
+				_, err := client.GetRange(ctx, object, 10, 5)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mock.DoCalls()[1].Request.Header.Get("Accept-Encoding")).To(BeEmpty())
+			})
+		})
+
+		When("object is blank", func() {
+			BeforeEach(func() {
+				object = ""
+			})
+
+			It("returns error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(n).To(BeZero())
+			})
+		})
+	})
+
+	Describe("Put", func() {
+		var (
+			object string
+			body   io.ReadSeeker
+			err    error
+		)
+
+		JustBeforeEach(func() {
+			err = client.Put(ctx, object, body)
+		})
+
+		When("object is blank", func() {
+			BeforeEach(func() {
+				object = ""
+				body = bytes.NewReader([]byte("data"))
+			})
+
+			It("returns error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("cannot be blank"))
+			})
+		})
+
+		When("request succeeds", func() {
+			BeforeEach(func() {
+				object = "test-object.txt"
+				body = bytes.NewReader([]byte("upload content"))
+				mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(bytes.NewReader(nil)),
+					}, nil
+				}
+			})
+
+			It("succeeds without error", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("sends PUT request with body hash", func() {
+				calls := mock.DoCalls()
+				Expect(calls).To(HaveLen(1))
+				Expect(calls[0].Request.Method).To(Equal("PUT"))
+				Expect(calls[0].Request.Header.Get("x-amz-content-sha256")).ToNot(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Put with options", func() {
+		var (
+			res objsto.PutResult
+			err error
+		)
+
+		BeforeEach(func() {
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				header := http.Header{}
+				header.Set("ETag", `"abc123"`)
+				header.Set("X-Amz-Version-Id", "v1")
+				return &http.Response{
+					StatusCode: 200,
+					Header:     header,
+					Body:       io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			err = client.Put(ctx, "test-object.txt", bytes.NewReader([]byte("upload content")),
+				objsto.WithContentType("text/plain"),
+				objsto.WithContentEncoding("gzip"),
+				objsto.WithMetadata(map[string]string{"owner": "bob"}),
+				objsto.WithStorageClass("STANDARD_IA"),
+				objsto.WithACL("private"),
+				objsto.WithSSE("AES256"),
+				objsto.WithTags(map[string]string{"env": "test", "team": "data"}),
+				objsto.WithResult(&res),
+			)
+		})
+
+		It("records the result", func() {
+			Expect(res).To(Equal(objsto.PutResult{Key: "test-object.txt", ETag: "abc123", VersionID: "v1"}))
+		})
+
+		It("sets headers on the request", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			hdr := mock.DoCalls()[0].Request.Header
+			Expect(hdr.Get("Content-Type")).To(Equal("text/plain"))
+			Expect(hdr.Get("Content-Encoding")).To(Equal("gzip"))
+			Expect(hdr.Get("x-amz-meta-owner")).To(Equal("bob"))
+			Expect(hdr.Get("x-amz-storage-class")).To(Equal("STANDARD_IA"))
+			Expect(hdr.Get("x-amz-acl")).To(Equal("private"))
+			Expect(hdr.Get("x-amz-server-side-encryption")).To(Equal("AES256"))
+			Expect(hdr.Get("x-amz-tagging")).To(Equal("env=test&team=data"))
+		})
+
+		It("signs the amz headers", func() {
+			auth := mock.DoCalls()[0].Request.Header.Get("Authorization")
+			Expect(auth).To(ContainSubstring("SignedHeaders=host;x-amz-acl;x-amz-content-sha256;x-amz-date;" +
+				"x-amz-meta-owner;x-amz-server-side-encryption;x-amz-storage-class;x-amz-tagging,"))
+		})
+	})
+
+	Describe("Put conditionally", func() {
+		BeforeEach(func() {
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 412,
+					Body:       io.NopCloser(strings.NewReader("<Error><Code>PreconditionFailed</Code></Error>")),
+				}, nil
+			}
+		})
+
+		It("sends quoted conditions and fails on 412", func() {
+			err := client.Put(ctx, "test-object.txt", strings.NewReader("content"),
+				objsto.WithIfMatch("abc123"), objsto.WithIfNoneMatch("*"))
+			Expect(errors.Is(err, objsto.ErrPreconditionFailed)).To(BeTrue())
+
+			hdr := mock.DoCalls()[0].Request.Header
+			Expect(hdr.Get("If-Match")).To(Equal(`"abc123"`))
+			Expect(hdr.Get("If-None-Match")).To(Equal("*"))
+		})
+	})
+
+	Describe("PutReader", func() {
+		var (
+			object string
+			size   int64
+			err    error
+		)
+
+		JustBeforeEach(func() {
+			reader := io.MultiReader(bytes.NewReader([]byte("upload content")))
+			err = client.PutReader(ctx, object, reader, size)
+		})
+
+		BeforeEach(func() {
+			object = "test-object.txt"
+			size = 14
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			}
+		})
+
+		When("size is given", func() {
+			It("sends unsigned payload with content length", func() {
+				Expect(err).ToNot(HaveOccurred())
+
+				calls := mock.DoCalls()
+				Expect(calls).To(HaveLen(1))
+				Expect(calls[0].Request.Method).To(Equal("PUT"))
+				Expect(calls[0].Request.ContentLength).To(Equal(int64(14)))
+				Expect(calls[0].Request.TransferEncoding).To(BeEmpty())
+				Expect(calls[0].Request.Header.Get("x-amz-content-sha256")).To(Equal("UNSIGNED-PAYLOAD"))
+			})
+		})
+
+		When("size is negative", func() {
+			BeforeEach(func() {
+				size = -1
+			})
+
+			It("returns error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(mock.DoCalls()).To(BeEmpty())
+			})
+		})
+
+		When("object is blank", func() {
+			BeforeEach(func() {
+				object = ""
+			})
+
+			It("returns error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("cannot be blank"))
+			})
+		})
+	})
+
+	Describe("Put with expect continue", func() {
+		var (
+			body io.ReadSeeker
+			err  error
+		)
+
+		BeforeEach(func() {
+			cfg.ContinueSize = 10
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			client = cfg.New(mock, lgr)
+			err = client.Put(ctx, "test-object.txt", body)
+		})
+
+		When("body is at least the threshold", func() {
+			BeforeEach(func() {
+				body = bytes.NewReader([]byte("upload content"))
+			})
+
+			It("sends expect header", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mock.DoCalls()[0].Request.Header.Get("Expect")).To(Equal("100-continue"))
+			})
+		})
+
+		When("body is under the threshold", func() {
+			BeforeEach(func() {
+				body = bytes.NewReader([]byte("upload"))
+			})
+
+			It("does not send expect header", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mock.DoCalls()[0].Request.Header.Get("Expect")).To(BeEmpty())
+			})
+		})
+
+		When("server rejects before the body is sent", func() {
+			var (
+				received int64
+			)
+
+			BeforeEach(func() {
+				body = bytes.NewReader(bytes.Repeat([]byte("x"), 1<<20))
+
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusForbidden)
+					_, _ = w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>nope</Message></Error>`))
+				}))
+				DeferCleanup(srv.Close)
+
+				cfg.Scheme = "http"
+				cfg.Host = srv.Listener.Addr().String()
+				mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+					resp, err := objsto.NewHTTPClient(nil).Do(req)
+					received, _ = body.Seek(0, io.SeekCurrent)
+					return resp, err
+				}
+			})
+
+			It("returns the error without sending the body", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("AccessDenied"))
+				Expect(received).To(BeZero())
+			})
+		})
+	})
+
+	Describe("PutJSON and GetJSON", func() {
+		type record struct {
+			Name  string `json:"name"`
+			Count int    `json:"count"`
+		}
+
+		var (
+			stored []byte
+		)
+
+		BeforeEach(func() {
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				if req.Method == "PUT" {
+					stored, _ = io.ReadAll(req.Body)
+				}
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewReader(stored)),
+				}, nil
+			}
+		})
+
+		It("round trips a value", func() {
+			err := client.PutJSON(ctx, "test-object.json", record{Name: "bob", Count: 3})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(stored)).To(Equal(`{"name":"bob","count":3}`))
+			Expect(mock.DoCalls()[0].Request.Header.Get("Content-Type")).To(Equal("application/json"))
+
+			var got record
+			err = client.GetJSON(ctx, "test-object.json", &got)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got).To(Equal(record{Name: "bob", Count: 3}))
+		})
+
+		It("returns error for a value that won't marshal", func() {
+			err := client.PutJSON(ctx, "test-object.json", make(chan int))
+			Expect(err).To(HaveOccurred())
+			Expect(mock.DoCalls()).To(BeEmpty())
+		})
+
+		It("returns error for a body that won't unmarshal", func() {
+			stored = []byte("not json")
+
+			var got record
+			err := client.GetJSON(ctx, "test-object.json", &got)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("failed to decode"))
+		})
+	})
+
+	Describe("Delete", func() {
+		var (
+			err error
+		)
+
+		BeforeEach(func() {
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 204,
+					Body:       io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			err = client.Delete(ctx, "test-object.txt")
+		})
+
+		It("sends signed delete", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			calls := mock.DoCalls()
+			Expect(calls).To(HaveLen(1))
+			Expect(calls[0].Request.Method).To(Equal("DELETE"))
+			Expect(calls[0].Request.URL.Path).To(Equal("/test-bucket/test-object.txt"))
+		})
+	})
+
+	Describe("Request ids", func() {
+		BeforeEach(func() {
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				hdr := http.Header{}
+				hdr.Set("X-Amz-Request-Id", "req-"+req.Method)
+				hdr.Set("X-Amz-Id-2", "host-"+req.Method)
+
+				status := 200
+				if req.Method == "HEAD" {
+					status = 404
+				}
+				return &http.Response{
+					StatusCode: status,
+					Header:     hdr,
+					Body:       io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			}
+		})
+
+		It("captures them in results, errors, and a recorder", func() {
+			var res objsto.PutResult
+			err := client.Put(ctx, "test-object.txt", bytes.NewReader([]byte("abc")), objsto.WithResult(&res))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.RequestIDs).To(Equal(objsto.RequestIDs{RequestID: "req-PUT", HostID: "host-PUT"}))
+
+			rec := &objsto.RequestIDRecorder{}
+			_, err = client.Stat(objsto.WithRequestIDs(ctx, rec), "missing.txt")
+			Expect(err).To(MatchError(objsto.ErrNotFound))
+			Expect(err).To(MatchError(ContainSubstring("request_id: req-HEAD, host_id: host-HEAD")))
+
+			ids, ok := objsto.RequestIDsFrom(err)
+			Expect(ok).To(BeTrue())
+			Expect(ids).To(Equal(objsto.RequestIDs{RequestID: "req-HEAD", HostID: "host-HEAD"}))
+			Expect(rec.Last()).To(Equal(ids))
+
+			_, ok = objsto.RequestIDsFrom(errors.New("nope"))
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("Stats", func() {
+		BeforeEach(func() {
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				if req.Body != nil {
+					_, _ = io.Copy(io.Discard, req.Body)
+				}
+
+				status := 200
+				if req.Method == "HEAD" {
+					status = 404
+				}
+				return &http.Response{
+					StatusCode: status,
+					Body:       io.NopCloser(bytes.NewReader([]byte("hello"))),
+				}, nil
+			}
+		})
+
+		It("counts requests, errors, and bytes", func() {
+			Expect(client.Put(ctx, "test-object.txt", bytes.NewReader([]byte("abc")))).To(Succeed())
+
+			reader, err := client.Get(ctx, "test-object.txt")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = io.Copy(io.Discard, reader)
+			Expect(err).ToNot(HaveOccurred())
+			reader.Close()
+
+			_, err = client.Stat(ctx, "missing.txt")
+			Expect(err).To(MatchError(objsto.ErrNotFound))
+
+			stats := client.Stats()
+			Expect(stats.Requests).To(Equal(int64(3)))
+			Expect(stats.Errors).To(Equal(int64(1)))
+			Expect(stats.BytesUp).To(Equal(int64(3)))
+			Expect(stats.BytesDown).To(Equal(int64(5)))
+			Expect(stats.Latency).To(HaveLen(3))
+			Expect(stats.Latency["get"].Count).To(Equal(int64(1)))
+
+			Expect(client.StatsVar().String()).To(HavePrefix(`{"requests":3,"errors":1,"bytes_up":3,"bytes_down":5,"latency":{`))
+			Expect(client.Clone().Stats()).To(Equal(objsto.ClientStats{}))
+		})
+
+		It("takes latency percentiles over a window", func() {
+			client = objsto.New(cfg, objsto.WithHTTPClient(mock), objsto.WithLatencyWindow(100))
+
+			delay := time.Duration(0)
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				delay += time.Millisecond
+				time.Sleep(delay % (5 * time.Millisecond))
+				return &http.Response{StatusCode: 404, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+			}
+			for range 120 {
+				_, err := client.Stat(ctx, "missing.txt")
+				Expect(err).To(MatchError(objsto.ErrNotFound))
+			}
+
+			latency := client.Stats().Latency["stat"]
+			Expect(latency.Count).To(Equal(int64(120)))
+			Expect(latency.P50).To(BeNumerically("<=", latency.P90))
+			Expect(latency.P90).To(BeNumerically("<=", latency.P99))
+			Expect(latency.P99).To(BeNumerically("<=", latency.Max))
+			Expect(latency.Max).To(BeNumerically(">=", 4*time.Millisecond))
+
+			recorder := httptest.NewRecorder()
+			client.StatsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/objsto", nil))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"stat":{"count":120,`))
+		})
+	})
+
+	Describe("DeleteObjects", func() {
+		var (
+			body   string
+			failed map[string]error
+			err    error
+		)
+
+		BeforeEach(func() {
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				data, _ := io.ReadAll(req.Body)
+				body = string(data)
+				return &http.Response{
+					StatusCode: 200,
+					Body: io.NopCloser(strings.NewReader(`<DeleteResult>` +
+						`<Error><Key>b.txt</Key><Code>AccessDenied</Code><Message>Access Denied</Message></Error>` +
+						`</DeleteResult>`)),
+				}, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			failed, err = client.DeleteObjects(ctx, []string{"a.txt", "b.txt"})
+		})
+
+		It("posts a quiet batch with its md5, returning failures by key", func() {
+			Expect(err).ToNot(HaveOccurred())
+
+			req := mock.DoCalls()[0].Request
+			Expect(req.Method).To(Equal("POST"))
+			Expect(req.URL.RawQuery).To(Equal("delete="))
+			Expect(req.Header.Get("Content-Md5")).ToNot(BeEmpty())
+			Expect(body).To(Equal("<Delete><Quiet>true</Quiet><Object><Key>a.txt</Key></Object><Object><Key>b.txt</Key></Object></Delete>"))
+
+			Expect(failed).To(HaveLen(1))
+			Expect(errors.Is(failed["b.txt"], objsto.ErrRequestFailed)).To(BeTrue())
+		})
+	})
+
+	Describe("ListUploads and AbortUpload", func() {
+		BeforeEach(func() {
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				body := ""
+				switch {
+				case req.Method == "DELETE":
+				case req.URL.Query().Get("key-marker") == "":
+					body = `<ListMultipartUploadsResult><IsTruncated>true</IsTruncated>` +
+						`<NextKeyMarker>a.bin</NextKeyMarker><NextUploadIdMarker>u1</NextUploadIdMarker>` +
+						`<Upload><Key>a.bin</Key><UploadId>u1</UploadId><Initiated>2026-03-01T00:00:00Z</Initiated></Upload>` +
+						`</ListMultipartUploadsResult>`
+				default:
+					body = `<ListMultipartUploadsResult><IsTruncated>false</IsTruncated>` +
+						`<Upload><Key>b.bin</Key><UploadId>u2</UploadId><Initiated>2026-03-02T00:00:00Z</Initiated></Upload>` +
+						`</ListMultipartUploadsResult>`
+				}
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(body)),
+				}, nil
+			}
+		})
+
+		It("lists across pages", func() {
+			uploads, err := client.ListUploads(ctx, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uploads).To(Equal([]objsto.Upload{
+				{Key: "a.bin", UploadID: "u1", Initiated: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+				{Key: "b.bin", UploadID: "u2", Initiated: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+			}))
+
+			calls := mock.DoCalls()
+			Expect(calls).To(HaveLen(2))
+			Expect(calls[1].Request.URL.Query().Get("upload-id-marker")).To(Equal("u1"))
+		})
+
+		It("aborts by upload id", func() {
+			Expect(client.AbortUpload(ctx, "a.bin", "u1")).To(Succeed())
+
+			req := mock.DoCalls()[0].Request
+			Expect(req.Method).To(Equal("DELETE"))
+			Expect(req.URL.Path).To(Equal("/test-bucket/a.bin"))
+			Expect(req.URL.Query().Get("uploadId")).To(Equal("u1"))
+		})
+	})
+
+	Describe("PutStream", func() {
+		var (
+			failPart string
+			bodies   map[string]int64
+		)
+
+		BeforeEach(func() {
+			failPart = ""
+			bodies = map[string]int64{}
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				query := req.URL.Query()
+				hdr := http.Header{}
+				body := ""
+				status := 200
+
+				n := int64(0)
+				if req.Body != nil {
+					n, _ = io.Copy(io.Discard, req.Body)
+				}
+				bodies[query.Get("partNumber")] = n
+
+				switch {
+				case query.Has("uploads"):
+					body = `<InitiateMultipartUploadResult><UploadId>u1</UploadId></InitiateMultipartUploadResult>`
+				case query.Has("partNumber") && query.Get("partNumber") == failPart:
+					status = 500
+				case query.Has("partNumber"):
+					hdr.Set("ETag", `"e`+query.Get("partNumber")+`"`)
+				case req.Method == "POST":
+					body = `<CompleteMultipartUploadResult><ETag>"abc-3"</ETag></CompleteMultipartUploadResult>`
+				}
+				return &http.Response{
+					StatusCode: status,
+					Header:     hdr,
+					Body:       io.NopCloser(strings.NewReader(body)),
+				}, nil
+			}
+		})
+
+		It("puts a small stream in one request", func() {
+			Expect(client.PutStream(ctx, "small.bin", strings.NewReader("hello"), 0)).To(Succeed())
+
+			calls := mock.DoCalls()
+			Expect(calls).To(HaveLen(1))
+			Expect(calls[0].Request.Method).To(Equal("PUT"))
+			Expect(calls[0].Request.URL.RawQuery).To(BeEmpty())
+		})
+
+		It("uploads parts and completes", func() {
+			var res objsto.PutResult
+			size := int64(objsto.MinPartSize*2 + 10)
+			reader := io.LimitReader(zeros{}, size)
+
+			err := client.PutStream(ctx, "big.bin", reader, 1, objsto.WithContentType("application/gzip"),
+				objsto.WithIfNoneMatch("*"), objsto.WithResult(&res))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.ETag).To(Equal("abc-3"))
+
+			calls := mock.DoCalls()
+			Expect(calls).To(HaveLen(5))
+			Expect(calls[0].Request.Header.Get("Content-Type")).To(Equal("application/gzip"))
+			Expect(calls[0].Request.Header.Get("If-None-Match")).To(BeEmpty())
+			Expect(bodies["1"]).To(Equal(int64(objsto.MinPartSize)))
+			Expect(bodies["3"]).To(Equal(int64(10)))
+
+			complete := calls[4].Request
+			Expect(complete.URL.Query().Get("uploadId")).To(Equal("u1"))
+			Expect(complete.Header.Get("If-None-Match")).To(Equal("*"))
+		})
+
+		It("aborts when a part fails", func() {
+			failPart = "2"
+			reader := io.LimitReader(zeros{}, objsto.MinPartSize*3)
+
+			err := client.PutStream(ctx, "big.bin", reader, objsto.MinPartSize)
+			Expect(err).To(HaveOccurred())
+
+			calls := mock.DoCalls()
+			last := calls[len(calls)-1].Request
+			Expect(last.Method).To(Equal("DELETE"))
+			Expect(last.URL.Query().Get("uploadId")).To(Equal("u1"))
+		})
+	})
+
+	Describe("Buckets", func() {
+		var status int
+
+		BeforeEach(func() {
+			status = 200
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: status,
+					Body:       io.NopCloser(strings.NewReader("")),
+				}, nil
+			}
+		})
+
+		It("creates in the region", func() {
+			Expect(client.CreateBucket(ctx)).To(Succeed())
+
+			req := mock.DoCalls()[0].Request
+			Expect(req.Method).To(Equal("PUT"))
+			Expect(req.URL.Path).To(Equal("/test-bucket"))
+
+			body, err := io.ReadAll(req.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).To(ContainSubstring("<LocationConstraint>test-region</LocationConstraint>"))
+		})
+
+		It("fails to create one that exists", func() {
+			status = 409
+			Expect(client.CreateBucket(ctx)).To(MatchError(objsto.ErrConflict))
+		})
+
+		It("deletes", func() {
+			Expect(client.DeleteBucket(ctx)).To(Succeed())
+
+			req := mock.DoCalls()[0].Request
+			Expect(req.Method).To(Equal("DELETE"))
+			Expect(req.URL.Path).To(Equal("/test-bucket"))
+		})
+
+		It("checks existence", func() {
+			Expect(client.BucketExists(ctx)).To(BeTrue())
+
+			status = 404
+			Expect(client.BucketExists(ctx)).To(BeFalse())
+
+			status = 403
+			_, err := client.BucketExists(ctx)
+			Expect(err).To(MatchError(objsto.ErrRequestFailed))
+		})
+	})
+
+	Describe("Tags", func() {
+		BeforeEach(func() {
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				body := ""
+				if req.Method == "GET" {
+					body = `<Tagging><TagSet><Tag><Key>team</Key><Value>ops</Value></Tag>` +
+						`<Tag><Key>tier</Key><Value>cold</Value></Tag></TagSet></Tagging>`
+				}
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(strings.NewReader(body)),
+				}, nil
+			}
+		})
+
+		It("gets them", func() {
+			tags, err := client.GetTags(ctx, "a.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tags).To(Equal(map[string]string{"team": "ops", "tier": "cold"}))
+
+			req := mock.DoCalls()[0].Request
+			Expect(req.URL.Path).To(Equal("/test-bucket/a.txt"))
+			Expect(req.URL.Query().Has("tagging")).To(BeTrue())
+		})
+
+		It("puts them with an md5", func() {
+			Expect(client.PutTags(ctx, "a.txt", map[string]string{"tier": "cold", "team": "ops"})).To(Succeed())
+
+			req := mock.DoCalls()[0].Request
+			Expect(req.Method).To(Equal("PUT"))
+			Expect(req.Header.Get("Content-Md5")).ToNot(BeEmpty())
+
+			body, err := io.ReadAll(req.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).To(Equal(`<Tagging><TagSet><Tag><Key>team</Key><Value>ops</Value></Tag>` +
+				`<Tag><Key>tier</Key><Value>cold</Value></Tag></TagSet></Tagging>`))
+		})
+
+		It("puts none as an empty set", func() {
+			Expect(client.PutTags(ctx, "a.txt", nil)).To(Succeed())
+
+			body, err := io.ReadAll(mock.DoCalls()[0].Request.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(body)).To(Equal(`<Tagging><TagSet></TagSet></Tagging>`))
+		})
+
+		It("refuses too many", func() {
+			tags := map[string]string{}
+			for idx := range objsto.MaxTags + 1 {
+				tags[strconv.Itoa(idx)] = "x"
+			}
+			Expect(client.PutTags(ctx, "a.txt", tags)).To(MatchError(ContainSubstring("more than the 10 allowed")))
+			Expect(mock.DoCalls()).To(BeEmpty())
+		})
+
+		It("deletes them", func() {
+			Expect(client.DeleteTags(ctx, "a.txt")).To(Succeed())
+
+			req := mock.DoCalls()[0].Request
+			Expect(req.Method).To(Equal("DELETE"))
+			Expect(req.URL.Query().Has("tagging")).To(BeTrue())
+		})
+	})
+
+	Describe("Presign", func() {
+		It("signs a url with the query", func() {
+			client = objsto.New(cfg, objsto.WithHTTPClient(mock),
+				objsto.WithClock(&stepClock{now: time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)}))
+
+			uri, err := client.Presign(ctx, "GET", "reports/q1 2026.pdf", time.Hour)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uri).To(HavePrefix("https://test-host/test-bucket/reports/q1%202026.pdf?X-Amz-Algorithm=AWS4-HMAC-SHA256&" +
+				"X-Amz-Credential=test-access-key%2F20260310%2Ftest-region%2Fs3%2Faws4_request&X-Amz-Date=20260310T120000Z&" +
+				"X-Amz-Expires=3600&X-Amz-SignedHeaders=host&X-Amz-Signature="))
+			Expect(mock.DoCalls()).To(BeEmpty())
+
+			_, err = client.Presign(ctx, "GET", "a.txt", 8*24*time.Hour)
+			Expect(err).To(MatchError(ContainSubstring("expiry must be positive")))
+		})
+	})
+
+	Describe("Do", func() {
+		var (
+			object string
+			hdr    http.Header
+			resp   *http.Response
 			err    error
 		)
 
+		BeforeEach(func() {
+			object = "test-object.txt"
+			hdr = http.Header{"X-Amz-Expected-Bucket-Owner": {"123"}}
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewReader([]byte("<Tagging/>"))),
+				}, nil
+			}
+		})
+
 		JustBeforeEach(func() {
-			reader, err = client.Get(ctx, object)
+			resp, err = client.Do(ctx, "PUT", object, url.Values{"tagging": {""}},
+				bytes.NewReader([]byte("<Tagging/>")), hdr)
+		})
+
+		It("sends a signed request with query and headers", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+
+			req := mock.DoCalls()[0].Request
+			Expect(req.URL.Path).To(Equal("/test-bucket/test-object.txt"))
+			Expect(req.URL.RawQuery).To(Equal("tagging="))
+			Expect(req.ContentLength).To(Equal(int64(10)))
+			Expect(req.Header.Get("Authorization")).To(ContainSubstring("x-amz-expected-bucket-owner"))
 		})
 
 		When("object is blank", func() {
@@ -66,84 +1422,131 @@ var _ = Describe("Client", func() {
 				object = ""
 			})
 
-			It("returns error", func() {
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("cannot be blank"))
+			It("addresses the bucket", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mock.DoCalls()[0].Request.URL.Path).To(Equal("/test-bucket"))
 			})
 		})
 
-		When("request succeeds", func() {
+		When("headers differ only in case", func() {
 			BeforeEach(func() {
-				object = "test-object.txt"
-				mock.DoFunc = func(req *http.Request) (*http.Response, error) {
-					return &http.Response{
-						StatusCode: 200,
-						Body:       io.NopCloser(bytes.NewReader([]byte("test content"))),
-					}, nil
-				}
+				hdr = http.Header{"x-amz-meta-a": {"1"}, "X-Amz-Meta-A": {"2"}}
 			})
 
-			It("returns reader with body", func() {
+			It("sends and signs them as one", func() {
 				Expect(err).ToNot(HaveOccurred())
-				Expect(reader).ToNot(BeNil())
-
-				content, _ := io.ReadAll(reader)
-				Expect(string(content)).To(Equal("test content"))
-			})
 
-			It("sends signed request to correct path", func() {
-				calls := mock.DoCalls()
-				Expect(calls).To(HaveLen(1))
-				Expect(calls[0].Request.URL.Path).To(Equal("/test-bucket/test-object.txt"))
-				Expect(calls[0].Request.Header.Get("Authorization")).To(ContainSubstring("AWS4-HMAC-SHA256"))
+				req := mock.DoCalls()[0].Request
+				Expect(req.Header).ToNot(HaveKey("x-amz-meta-a"))
+				Expect(req.Header["X-Amz-Meta-A"]).To(Equal([]string{"2", "1"}))
+				Expect(req.Header.Get("Authorization")).To(ContainSubstring("SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-meta-a,"))
 			})
 		})
 	})
 
-	Describe("Put", func() {
+	Describe("Copy", func() {
 		var (
-			object string
-			body   io.ReadSeeker
-			err    error
+			body string
+			res  objsto.PutResult
+			err  error
 		)
 
+		BeforeEach(func() {
+			body = `<CopyObjectResult><ETag>"abc123"</ETag></CopyObjectResult>`
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+				}, nil
+			}
+		})
+
 		JustBeforeEach(func() {
-			err = client.Put(ctx, object, body)
+			src := client.Clone(objsto.WithBucket("src-bucket"), objsto.WithKeyPrefix("pre/"))
+			err = client.Copy(ctx, src, "some file.txt", "copy.txt", objsto.WithContentType("text/plain"), objsto.WithResult(&res))
 		})
 
-		When("object is blank", func() {
+		It("sends a signed copy", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.ETag).To(Equal("abc123"))
+
+			req := mock.DoCalls()[0].Request
+			Expect(req.Method).To(Equal("PUT"))
+			Expect(req.URL.Path).To(Equal("/test-bucket/copy.txt"))
+			Expect(req.Header.Get("X-Amz-Copy-Source")).To(Equal("src-bucket/pre/some%20file.txt"))
+			Expect(req.Header.Get("X-Amz-Metadata-Directive")).To(Equal("REPLACE"))
+			Expect(req.Header.Get("Authorization")).To(ContainSubstring("x-amz-copy-source"))
+		})
+
+		When("the copy fails after the 200 is sent", func() {
 			BeforeEach(func() {
-				object = ""
-				body = bytes.NewReader([]byte("data"))
+				body = `<Error><Code>InternalError</Code><Message>oops</Message></Error>`
 			})
 
 			It("returns error", func() {
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("cannot be blank"))
+				Expect(err).To(MatchError(ContainSubstring("InternalError")))
+				Expect(errors.Is(err, objsto.ErrRequestFailed)).To(BeTrue())
 			})
 		})
+	})
 
-		When("request succeeds", func() {
-			BeforeEach(func() {
-				object = "test-object.txt"
-				body = bytes.NewReader([]byte("upload content"))
-				mock.DoFunc = func(req *http.Request) (*http.Response, error) {
-					return &http.Response{
-						StatusCode: 200,
-						Body:       io.NopCloser(bytes.NewReader(nil)),
-					}, nil
-				}
-			})
+	Describe("Stat", func() {
+		var (
+			status int
+			info   objsto.ObjectInfo
+			err    error
+		)
 
-			It("succeeds without error", func() {
+		BeforeEach(func() {
+			status = 200
+			mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+				header := http.Header{}
+				header.Set("ETag", `"abc123"`)
+				header.Set("Content-Type", "text/plain")
+				header.Set("X-Amz-Expiration", `expiry-date="Fri, 23 Dec 2012 00:00:00 GMT", rule-id="picture-deletion-rule"`)
+				header.Set("X-Amz-Checksum-Sha256", "jtP2rWhblZ6tcCJRjhr3bNgW+OjsfM3aHtQBjo8iI/g=")
+				return &http.Response{
+					StatusCode:    status,
+					Header:        header,
+					ContentLength: 42,
+					Body:          io.NopCloser(bytes.NewReader(nil)),
+				}, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			info, err = client.Stat(ctx, "test-object.txt")
+		})
+
+		When("object exists", func() {
+			It("returns info from a head request", func() {
 				Expect(err).ToNot(HaveOccurred())
+				Expect(mock.DoCalls()[0].Request.Method).To(Equal("HEAD"))
+				Expect(info.Size).To(Equal(int64(42)))
+				Expect(info.ETag).To(Equal("abc123"))
+				Expect(info.ContentType).To(Equal("text/plain"))
 			})
 
-			It("sends PUT request with body hash", func() {
-				calls := mock.DoCalls()
-				Expect(calls).To(HaveLen(1))
-				Expect(calls[0].Request.Method).To(Equal("PUT"))
-				Expect(calls[0].Request.Header.Get("x-amz-content-sha256")).ToNot(BeEmpty())
+			It("asks for and returns the checksum", func() {
+				Expect(mock.DoCalls()[0].Request.Header.Get("X-Amz-Checksum-Mode")).To(Equal("ENABLED"))
+				Expect(info.Checksum).To(Equal("jtP2rWhblZ6tcCJRjhr3bNgW+OjsfM3aHtQBjo8iI/g="))
+			})
+
+			It("parses the expiration", func() {
+				Expect(info.Expiration).To(Equal(&objsto.Expiration{
+					Date:   time.Date(2012, 12, 23, 0, 0, 0, 0, time.UTC),
+					RuleID: "picture-deletion-rule",
+				}))
+			})
+		})
+
+		When("object does not exist", func() {
+			BeforeEach(func() {
+				status = 404
+			})
+
+			It("returns not found", func() {
+				Expect(errors.Is(err, objsto.ErrNotFound)).To(BeTrue())
 			})
 		})
 	})
@@ -212,3 +1615,29 @@ var _ = Describe("Client", func() {
 		})
 	})
 })
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (fc fixedClock) Now() time.Time {
+	return fc.now
+}
+
+type closingCredentials struct {
+	objsto.StaticCredentials
+	closed int
+}
+
+func (cc *closingCredentials) Close() error {
+	cc.closed++
+	return nil
+}
+
+type zeros struct{}
+
+func (zeros) Read(buf []byte) (n int, err error) {
+
+	clear(buf)
+	return len(buf), nil
+}
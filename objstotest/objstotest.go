@@ -0,0 +1,882 @@
+// Package objstotest provides an in-memory S3-compatible server, for integration tests
+// of code built on objsto without docker or a cloud account.
+//
+// Buckets, objects and multipart uploads are kept in memory, served over httptest
+// with path-style addressing as objsto.Client uses.
+// Supported are bucket create, delete and head, object get (with ranges), head, put
+// (conditional, and copy), delete and batch delete, v2 listings with delimiters,
+// and multipart uploads. Other requests are refused with NotImplemented.
+//
+// Signatures are only verified given WithCredentials, as SigV4 in the Authorization header
+// or a presigned url.
+//
+// ObjectStoreMock is a moq of objsto.ObjectStore for unit tests wanting canned results
+// rather than a server, generated here so consumers need not run moq against objsto.
+//
+// Recorder is an objsto.HttpDoer recording a real provider's responses to a fixture,
+// scrubbed of credentials, and replaying them in tests run without the provider.
+package objstotest
+
+//go:generate moq -out mock.go -pkg objstotest .. ObjectStore:ObjectStoreMock
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clarktrimble/launch"
+
+	"github.com/clarktrimble/objsto"
+)
+
+// DefaultBucket is created unless WithBuckets says otherwise.
+const DefaultBucket = "test"
+
+// xmlns is the S3 namespace for responses.
+const xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+// Option sets an optional Server setting.
+type Option func(*Server)
+
+// WithCredentials verifies requests are signed with accessKey and secretKey.
+func WithCredentials(accessKey, secretKey string) Option {
+
+	return func(srv *Server) {
+		srv.accessKey = accessKey
+		srv.secretKey = secretKey
+		srv.verify = true
+	}
+}
+
+// WithRegion sets the region of the server, checked with signatures, defaulting to us-east-1.
+func WithRegion(region string) Option {
+
+	return func(srv *Server) {
+		srv.region = region
+	}
+}
+
+// WithBuckets creates buckets up front, in place of DefaultBucket.
+func WithBuckets(names ...string) Option {
+
+	return func(srv *Server) {
+		srv.initial = names
+	}
+}
+
+// Server is an in-memory S3 server, safe for concurrent use.
+type Server struct {
+	// URL is the base url of the server, as with http://127.0.0.1:40423.
+	URL string
+
+	region    string
+	accessKey string
+	secretKey string
+	verify    bool
+	initial   []string
+	buckets   map[string]*bucket
+	uploads   map[string]*upload
+	requests  int
+	mu        sync.Mutex
+	server    *httptest.Server
+}
+
+// New starts a Server, to be closed when done.
+func New(opts ...Option) *Server {
+
+	srv := &Server{
+		region:    "us-east-1",
+		accessKey: "test",
+		secretKey: "test",
+		initial:   []string{DefaultBucket},
+		buckets:   map[string]*bucket{},
+		uploads:   map[string]*upload{},
+	}
+	for _, opt := range opts {
+		opt(srv)
+	}
+
+	for _, name := range srv.initial {
+		srv.buckets[name] = newBucket()
+	}
+
+	srv.server = httptest.NewServer(srv)
+	srv.URL = srv.server.URL
+	return srv
+}
+
+// Close shuts down the server.
+func (srv *Server) Close() {
+
+	srv.server.Close()
+}
+
+// Config is configuration for a client of bucket on the server, DefaultBucket when blank.
+func (srv *Server) Config(bucket string) *objsto.Config {
+
+	if bucket == "" {
+		bucket = DefaultBucket
+	}
+
+	return &objsto.Config{
+		Region:    srv.region,
+		Scheme:    "http",
+		Host:      strings.TrimPrefix(srv.URL, "http://"),
+		Bucket:    bucket,
+		AccessKey: srv.accessKey,
+		SecretKey: launch.Redact(srv.secretKey),
+	}
+}
+
+// Client is a client of bucket on the server, DefaultBucket when blank.
+func (srv *Server) Client(bucket string, opts ...objsto.ClientOption) *objsto.Client {
+
+	return objsto.New(srv.Config(bucket), opts...)
+}
+
+// Keys are the keys of objects in bucket, sorted.
+func (srv *Server) Keys(bucket string) []string {
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	bkt, ok := srv.buckets[bucket]
+	if !ok {
+		return nil
+	}
+
+	return slices.Sorted(maps.Keys(bkt.objects))
+}
+
+// Requests is the count of requests served.
+func (srv *Server) Requests() int {
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	return srv.requests
+}
+
+// ServeHTTP serves an S3 request.
+func (srv *Server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+
+	srv.mu.Lock()
+	srv.requests++
+	reqID := fmt.Sprintf("%016X", srv.requests)
+	srv.mu.Unlock()
+
+	writer.Header().Set("X-Amz-Request-Id", reqID)
+
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		srv.fail(writer, reqID, errIncompleteBody)
+		return
+	}
+
+	if srv.verify {
+		s3e := srv.authenticate(request, body)
+		if s3e != nil {
+			srv.fail(writer, reqID, s3e)
+			return
+		}
+	}
+
+	name, key, _ := strings.Cut(strings.TrimPrefix(request.URL.Path, "/"), "/")
+	query := request.URL.Query()
+	for param := range query {
+		// presigned url auth rather than subresources
+		if strings.HasPrefix(param, "X-Amz-") {
+			query.Del(param)
+		}
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	var s3e *s3Error
+	switch {
+	case name == "":
+		s3e = errNotImplemented
+	case key == "":
+		s3e = srv.serveBucket(writer, request, name, query, body)
+	default:
+		s3e = srv.serveObject(writer, request, name, key, query, body)
+	}
+	if s3e != nil {
+		srv.fail(writer, reqID, s3e)
+	}
+}
+
+// unexported
+
+type bucket struct {
+	objects map[string]*object
+}
+
+func newBucket() *bucket {
+
+	return &bucket{objects: map[string]*object{}}
+}
+
+type object struct {
+	data     []byte
+	etag     string
+	modified time.Time
+	header   http.Header
+}
+
+type upload struct {
+	bucket    string
+	key       string
+	header    http.Header
+	parts     map[int]*object
+	initiated time.Time
+}
+
+// s3Error is an error response, its code and message going out as xml.
+type s3Error struct {
+	status  int
+	code    string
+	message string
+}
+
+var (
+	errNotImplemented      = &s3Error{http.StatusNotImplemented, "NotImplemented", "not implemented by objstotest"}
+	errIncompleteBody      = &s3Error{http.StatusBadRequest, "IncompleteBody", "failed to read the request body"}
+	errMalformedXML        = &s3Error{http.StatusBadRequest, "MalformedXML", "the xml provided was not well-formed"}
+	errBadDigest           = &s3Error{http.StatusBadRequest, "BadDigest", "the Content-MD5 does not match the body"}
+	errInvalidRange        = &s3Error{http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "the requested range is not satisfiable"}
+	errPreconditionFailed  = &s3Error{http.StatusPreconditionFailed, "PreconditionFailed", "a precondition does not hold"}
+	errNoSuchBucket        = &s3Error{http.StatusNotFound, "NoSuchBucket", "the bucket does not exist"}
+	errNoSuchKey           = &s3Error{http.StatusNotFound, "NoSuchKey", "the key does not exist"}
+	errNoSuchUpload        = &s3Error{http.StatusNotFound, "NoSuchUpload", "the upload does not exist"}
+	errBucketExists        = &s3Error{http.StatusConflict, "BucketAlreadyOwnedByYou", "the bucket already exists"}
+	errBucketNotEmpty      = &s3Error{http.StatusConflict, "BucketNotEmpty", "the bucket is not empty"}
+	errInvalidPart         = &s3Error{http.StatusBadRequest, "InvalidPart", "a part was not found or its etag does not match"}
+	errInvalidPartOrder    = &s3Error{http.StatusBadRequest, "InvalidPartOrder", "parts are not in ascending order"}
+	errEntityTooSmall      = &s3Error{http.StatusBadRequest, "EntityTooSmall", "a part other than the last is under the minimum size"}
+	errInvalidArgument     = &s3Error{http.StatusBadRequest, "InvalidArgument", "an argument is invalid"}
+	errAccessDenied        = &s3Error{http.StatusForbidden, "AccessDenied", "the request is not signed"}
+	errInvalidAccessKey    = &s3Error{http.StatusForbidden, "InvalidAccessKeyId", "the access key is not known"}
+	errSignatureMismatch   = &s3Error{http.StatusForbidden, "SignatureDoesNotMatch", "the signature does not match"}
+	errMalformedAuth       = &s3Error{http.StatusBadRequest, "AuthorizationHeaderMalformed", "the authorization header is malformed"}
+	errContentHashMismatch = &s3Error{http.StatusBadRequest, "XAmzContentSHA256Mismatch", "the content sha256 does not match the body"}
+	errExpired             = &s3Error{http.StatusForbidden, "AccessDenied", "the presigned url has expired"}
+)
+
+func (srv *Server) fail(writer http.ResponseWriter, reqID string, s3e *s3Error) {
+
+	writer.Header().Set("Content-Type", "application/xml")
+	writer.WriteHeader(s3e.status)
+
+	xml.NewEncoder(writer).Encode(struct {
+		XMLName   xml.Name `xml:"Error"`
+		Code      string   `xml:"Code"`
+		Message   string   `xml:"Message"`
+		RequestID string   `xml:"RequestId"`
+	}{Code: s3e.code, Message: s3e.message, RequestID: reqID})
+}
+
+func (srv *Server) serveBucket(writer http.ResponseWriter, request *http.Request, name string, query url.Values, body []byte) *s3Error {
+
+	bkt, exists := srv.buckets[name]
+
+	switch {
+	case request.Method == http.MethodPut && len(query) == 0:
+		if exists {
+			return errBucketExists
+		}
+		srv.buckets[name] = newBucket()
+		writer.Header().Set("Location", "/"+name)
+		return nil
+	case !exists:
+		return errNoSuchBucket
+	}
+
+	switch {
+	case request.Method == http.MethodHead && len(query) == 0:
+		return nil
+	case request.Method == http.MethodDelete && len(query) == 0:
+		if len(bkt.objects) > 0 {
+			return errBucketNotEmpty
+		}
+		delete(srv.buckets, name)
+		writer.WriteHeader(http.StatusNoContent)
+		return nil
+	case request.Method == http.MethodGet && query.Has("uploads"):
+		return srv.listUploads(writer, name, query)
+	case request.Method == http.MethodGet && query.Get("list-type") == "2":
+		return srv.list(writer, bkt, query)
+	case request.Method == http.MethodPost && query.Has("delete"):
+		return srv.deleteObjects(writer, request, bkt, body)
+	}
+
+	return errNotImplemented
+}
+
+func (srv *Server) serveObject(writer http.ResponseWriter, request *http.Request, name, key string, query url.Values, body []byte) *s3Error {
+
+	bkt, ok := srv.buckets[name]
+	if !ok {
+		return errNoSuchBucket
+	}
+
+	switch {
+	case request.Method == http.MethodPost && query.Has("uploads"):
+		return srv.createUpload(writer, request, name, key)
+	case request.Method == http.MethodPut && query.Has("uploadId"):
+		return srv.uploadPart(writer, request, name, key, query, body)
+	case request.Method == http.MethodPost && query.Has("uploadId"):
+		return srv.completeUpload(writer, request, name, bkt, key, query, body)
+	case request.Method == http.MethodDelete && query.Has("uploadId"):
+		if srv.lookupUpload(name, key, query.Get("uploadId")) == nil {
+			return errNoSuchUpload
+		}
+		delete(srv.uploads, query.Get("uploadId"))
+		writer.WriteHeader(http.StatusNoContent)
+		return nil
+	case len(query) > 0:
+		// subresources such as tagging and acl
+		return errNotImplemented
+	case request.Method == http.MethodGet || request.Method == http.MethodHead:
+		return srv.get(writer, request, bkt, key)
+	case request.Method == http.MethodPut && request.Header.Get("X-Amz-Copy-Source") != "":
+		return srv.copyObject(writer, request, bkt, key)
+	case request.Method == http.MethodPut:
+		return srv.put(writer, request, bkt, key, body)
+	case request.Method == http.MethodDelete:
+		delete(bkt.objects, key)
+		writer.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+
+	return errNotImplemented
+}
+
+func (srv *Server) get(writer http.ResponseWriter, request *http.Request, bkt *bucket, key string) *s3Error {
+
+	obj, ok := bkt.objects[key]
+	if !ok {
+		return errNoSuchKey
+	}
+
+	hdr := writer.Header()
+	maps.Copy(hdr, obj.header)
+	hdr.Set("ETag", quote(obj.etag))
+	hdr.Set("Last-Modified", obj.modified.Format(http.TimeFormat))
+	hdr.Set("Accept-Ranges", "bytes")
+
+	match := request.Header.Get("If-Match")
+	if match != "" && !etagMatch(match, obj.etag) {
+		return errPreconditionFailed
+	}
+	noneMatch := request.Header.Get("If-None-Match")
+	if noneMatch != "" && etagMatch(noneMatch, obj.etag) {
+		writer.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	data := obj.data
+	status := http.StatusOK
+	if rng := request.Header.Get("Range"); rng != "" {
+		start, end, ok := parseRange(rng, int64(len(data)))
+		if !ok {
+			return errInvalidRange
+		}
+		hdr.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		data = data[start : end+1]
+		status = http.StatusPartialContent
+	}
+
+	hdr.Set("Content-Length", strconv.Itoa(len(data)))
+	writer.WriteHeader(status)
+	if request.Method == http.MethodGet {
+		writer.Write(data)
+	}
+
+	return nil
+}
+
+func (srv *Server) put(writer http.ResponseWriter, request *http.Request, bkt *bucket, key string, body []byte) *s3Error {
+
+	s3e := checkMD5(request, body)
+	if s3e != nil {
+		return s3e
+	}
+	s3e = precondition(request, bkt.objects[key])
+	if s3e != nil {
+		return s3e
+	}
+
+	sum := md5.Sum(body)
+	obj := &object{
+		data:     body,
+		etag:     hex.EncodeToString(sum[:]),
+		modified: time.Now().UTC(),
+		header:   stored(request.Header),
+	}
+	bkt.objects[key] = obj
+
+	writer.Header().Set("ETag", quote(obj.etag))
+	return nil
+}
+
+func (srv *Server) copyObject(writer http.ResponseWriter, request *http.Request, bkt *bucket, key string) *s3Error {
+
+	source, err := url.PathUnescape(request.Header.Get("X-Amz-Copy-Source"))
+	if err != nil {
+		return errInvalidArgument
+	}
+	srcName, srcKey, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+
+	srcBkt, ok := srv.buckets[srcName]
+	if !ok {
+		return errNoSuchBucket
+	}
+	src, ok := srcBkt.objects[srcKey]
+	if !ok {
+		return errNoSuchKey
+	}
+	s3e := precondition(request, bkt.objects[key])
+	if s3e != nil {
+		return s3e
+	}
+
+	header := src.header
+	if strings.EqualFold(request.Header.Get("X-Amz-Metadata-Directive"), "REPLACE") {
+		header = stored(request.Header)
+	}
+	obj := &object{
+		data:     bytes.Clone(src.data),
+		etag:     src.etag,
+		modified: time.Now().UTC(),
+		header:   header.Clone(),
+	}
+	bkt.objects[key] = obj
+
+	return respond(writer, struct {
+		XMLName      xml.Name `xml:"CopyObjectResult"`
+		ETag         string   `xml:"ETag"`
+		LastModified string   `xml:"LastModified"`
+	}{ETag: quote(obj.etag), LastModified: obj.modified.Format(time.RFC3339)})
+}
+
+func (srv *Server) deleteObjects(writer http.ResponseWriter, request *http.Request, bkt *bucket, body []byte) *s3Error {
+
+	s3e := checkMD5(request, body)
+	if s3e != nil {
+		return s3e
+	}
+
+	var del struct {
+		Quiet   bool `xml:"Quiet"`
+		Objects []struct {
+			Key string `xml:"Key"`
+		} `xml:"Object"`
+	}
+	err := xml.Unmarshal(body, &del)
+	if err != nil || len(del.Objects) > objsto.MaxBatchDelete {
+		return errMalformedXML
+	}
+
+	type deleted struct {
+		Key string `xml:"Key"`
+	}
+	result := struct {
+		XMLName xml.Name  `xml:"DeleteResult"`
+		Xmlns   string    `xml:"xmlns,attr"`
+		Deleted []deleted `xml:"Deleted"`
+	}{Xmlns: xmlns}
+
+	for _, obj := range del.Objects {
+		delete(bkt.objects, obj.Key)
+		if !del.Quiet {
+			result.Deleted = append(result.Deleted, deleted{Key: obj.Key})
+		}
+	}
+
+	return respond(writer, result)
+}
+
+func (srv *Server) list(writer http.ResponseWriter, bkt *bucket, query url.Values) *s3Error {
+
+	prefix := query.Get("prefix")
+	delimiter := query.Get("delimiter")
+
+	maxKeys := 1000
+	if val := query.Get("max-keys"); val != "" {
+		num, err := strconv.Atoi(val)
+		if err != nil || num < 0 {
+			return errInvalidArgument
+		}
+		maxKeys = min(num, 1000)
+	}
+
+	after := query.Get("start-after")
+	if token := query.Get("continuation-token"); token != "" {
+		decoded, err := base64.StdEncoding.DecodeString(token)
+		if err != nil {
+			return errInvalidArgument
+		}
+		after = string(decoded)
+	}
+
+	type content struct {
+		Key          string `xml:"Key"`
+		LastModified string `xml:"LastModified"`
+		ETag         string `xml:"ETag"`
+		Size         int    `xml:"Size"`
+		StorageClass string `xml:"StorageClass"`
+	}
+	type commonPrefix struct {
+		Prefix string `xml:"Prefix"`
+	}
+	result := struct {
+		XMLName               xml.Name       `xml:"ListBucketResult"`
+		Xmlns                 string         `xml:"xmlns,attr"`
+		Prefix                string         `xml:"Prefix"`
+		Delimiter             string         `xml:"Delimiter,omitempty"`
+		MaxKeys               int            `xml:"MaxKeys"`
+		KeyCount              int            `xml:"KeyCount"`
+		IsTruncated           bool           `xml:"IsTruncated"`
+		ContinuationToken     string         `xml:"ContinuationToken,omitempty"`
+		NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+		StartAfter            string         `xml:"StartAfter,omitempty"`
+		Contents              []content      `xml:"Contents"`
+		CommonPrefixes        []commonPrefix `xml:"CommonPrefixes"`
+	}{
+		Xmlns:             xmlns,
+		Prefix:            prefix,
+		Delimiter:         delimiter,
+		MaxKeys:           maxKeys,
+		ContinuationToken: query.Get("continuation-token"),
+		StartAfter:        query.Get("start-after"),
+	}
+
+	last := ""
+	for _, key := range slices.Sorted(maps.Keys(bkt.objects)) {
+		if !strings.HasPrefix(key, prefix) || key <= after {
+			continue
+		}
+
+		entry := key
+		if delimiter != "" {
+			if idx := strings.Index(key[len(prefix):], delimiter); idx >= 0 {
+				entry = key[:len(prefix)+idx+len(delimiter)]
+			}
+		}
+		if entry == last {
+			continue
+		}
+
+		if result.KeyCount == maxKeys {
+			result.IsTruncated = true
+			result.NextContinuationToken = base64.StdEncoding.EncodeToString([]byte(lastKey(last, delimiter)))
+			break
+		}
+		result.KeyCount++
+		last = entry
+
+		if entry != key {
+			result.CommonPrefixes = append(result.CommonPrefixes, commonPrefix{Prefix: entry})
+			continue
+		}
+		obj := bkt.objects[key]
+		result.Contents = append(result.Contents, content{
+			Key:          key,
+			LastModified: obj.modified.Format(time.RFC3339Nano),
+			ETag:         quote(obj.etag),
+			Size:         len(obj.data),
+			StorageClass: "STANDARD",
+		})
+	}
+
+	return respond(writer, result)
+}
+
+func (srv *Server) createUpload(writer http.ResponseWriter, request *http.Request, name, key string) *s3Error {
+
+	var raw [12]byte
+	rand.Read(raw[:])
+	uploadID := hex.EncodeToString(raw[:])
+
+	srv.uploads[uploadID] = &upload{
+		bucket:    name,
+		key:       key,
+		header:    stored(request.Header),
+		parts:     map[int]*object{},
+		initiated: time.Now().UTC(),
+	}
+
+	return respond(writer, struct {
+		XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+		Xmlns    string   `xml:"xmlns,attr"`
+		Bucket   string   `xml:"Bucket"`
+		Key      string   `xml:"Key"`
+		UploadID string   `xml:"UploadId"`
+	}{Xmlns: xmlns, Bucket: name, Key: key, UploadID: uploadID})
+}
+
+func (srv *Server) uploadPart(writer http.ResponseWriter, request *http.Request, name, key string, query url.Values, body []byte) *s3Error {
+
+	upl := srv.lookupUpload(name, key, query.Get("uploadId"))
+	if upl == nil {
+		return errNoSuchUpload
+	}
+
+	number, err := strconv.Atoi(query.Get("partNumber"))
+	if err != nil || number < 1 || number > objsto.MaxParts {
+		return errInvalidArgument
+	}
+	s3e := checkMD5(request, body)
+	if s3e != nil {
+		return s3e
+	}
+
+	sum := md5.Sum(body)
+	part := &object{data: body, etag: hex.EncodeToString(sum[:])}
+	upl.parts[number] = part
+
+	writer.Header().Set("ETag", quote(part.etag))
+	return nil
+}
+
+func (srv *Server) completeUpload(writer http.ResponseWriter, request *http.Request, name string, bkt *bucket, key string, query url.Values, body []byte) *s3Error {
+
+	uploadID := query.Get("uploadId")
+	upl := srv.lookupUpload(name, key, uploadID)
+	if upl == nil {
+		return errNoSuchUpload
+	}
+
+	var complete struct {
+		Parts []struct {
+			PartNumber int    `xml:"PartNumber"`
+			ETag       string `xml:"ETag"`
+		} `xml:"Part"`
+	}
+	err := xml.Unmarshal(body, &complete)
+	if err != nil || len(complete.Parts) == 0 {
+		return errMalformedXML
+	}
+
+	data := []byte{}
+	sums := []byte{}
+	for idx, ref := range complete.Parts {
+		part, ok := upl.parts[ref.PartNumber]
+		switch {
+		case idx > 0 && ref.PartNumber <= complete.Parts[idx-1].PartNumber:
+			return errInvalidPartOrder
+		case !ok || strings.Trim(ref.ETag, `"`) != part.etag:
+			return errInvalidPart
+		case idx < len(complete.Parts)-1 && len(part.data) < objsto.MinPartSize:
+			return errEntityTooSmall
+		}
+
+		data = append(data, part.data...)
+		sum, _ := hex.DecodeString(part.etag)
+		sums = append(sums, sum...)
+	}
+
+	s3e := precondition(request, bkt.objects[key])
+	if s3e != nil {
+		return s3e
+	}
+
+	sum := md5.Sum(sums)
+	obj := &object{
+		data:     data,
+		etag:     fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), len(complete.Parts)),
+		modified: time.Now().UTC(),
+		header:   upl.header,
+	}
+	bkt.objects[key] = obj
+	delete(srv.uploads, uploadID)
+
+	return respond(writer, struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		Xmlns   string   `xml:"xmlns,attr"`
+		Key     string   `xml:"Key"`
+		ETag    string   `xml:"ETag"`
+	}{Xmlns: xmlns, Key: key, ETag: quote(obj.etag)})
+}
+
+func (srv *Server) listUploads(writer http.ResponseWriter, name string, query url.Values) *s3Error {
+
+	prefix := query.Get("prefix")
+
+	type listed struct {
+		Key       string `xml:"Key"`
+		UploadID  string `xml:"UploadId"`
+		Initiated string `xml:"Initiated"`
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"ListMultipartUploadsResult"`
+		Xmlns       string   `xml:"xmlns,attr"`
+		Bucket      string   `xml:"Bucket"`
+		Prefix      string   `xml:"Prefix"`
+		IsTruncated bool     `xml:"IsTruncated"`
+		Uploads     []listed `xml:"Upload"`
+	}{Xmlns: xmlns, Bucket: name, Prefix: prefix}
+
+	for uploadID, upl := range srv.uploads {
+		if upl.bucket == name && strings.HasPrefix(upl.key, prefix) {
+			result.Uploads = append(result.Uploads, listed{
+				Key:       upl.key,
+				UploadID:  uploadID,
+				Initiated: upl.initiated.Format(time.RFC3339Nano),
+			})
+		}
+	}
+	slices.SortFunc(result.Uploads, func(a, b listed) int {
+		return strings.Compare(a.Key+"\x00"+a.UploadID, b.Key+"\x00"+b.UploadID)
+	})
+
+	return respond(writer, result)
+}
+
+func (srv *Server) lookupUpload(name, key, uploadID string) *upload {
+
+	upl, ok := srv.uploads[uploadID]
+	if !ok || upl.bucket != name || upl.key != key {
+		return nil
+	}
+
+	return upl
+}
+
+func respond(writer http.ResponseWriter, val any) *s3Error {
+
+	writer.Header().Set("Content-Type", "application/xml")
+	writer.Write([]byte(xml.Header))
+	xml.NewEncoder(writer).Encode(val)
+
+	return nil
+}
+
+// stored are the headers of a put kept with an object.
+func stored(hdr http.Header) http.Header {
+
+	keep := http.Header{}
+	for name, vals := range hdr {
+		switch {
+		case strings.HasPrefix(name, "X-Amz-Meta-"),
+			name == "Content-Type", name == "Content-Encoding", name == "Content-Disposition",
+			name == "Content-Language", name == "Cache-Control", name == "Expires",
+			name == "X-Amz-Storage-Class", name == "X-Amz-Checksum-Sha256":
+			keep[name] = slices.Clone(vals)
+		}
+	}
+	if keep.Get("Content-Type") == "" {
+		keep.Set("Content-Type", "binary/octet-stream")
+	}
+
+	return keep
+}
+
+// checkMD5 checks body against any Content-MD5.
+func checkMD5(request *http.Request, body []byte) *s3Error {
+
+	want := request.Header.Get("Content-Md5")
+	if want == "" {
+		return nil
+	}
+
+	sum := md5.Sum(body)
+	if base64.StdEncoding.EncodeToString(sum[:]) != want {
+		return errBadDigest
+	}
+
+	return nil
+}
+
+// precondition checks If-Match and If-None-Match of a write against the object there, if any.
+func precondition(request *http.Request, existing *object) *s3Error {
+
+	match := request.Header.Get("If-Match")
+	noneMatch := request.Header.Get("If-None-Match")
+
+	switch {
+	case match != "" && (existing == nil || !etagMatch(match, existing.etag)):
+		return errPreconditionFailed
+	case noneMatch != "" && existing != nil && etagMatch(noneMatch, existing.etag):
+		return errPreconditionFailed
+	}
+
+	return nil
+}
+
+// etagMatch is true when cond, as from If-Match, is "*" or lists etag.
+func etagMatch(cond, etag string) bool {
+
+	for _, val := range strings.Split(cond, ",") {
+		val = strings.TrimSpace(val)
+		if val == "*" || strings.Trim(strings.TrimPrefix(val, "W/"), `"`) == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseRange parses a single bytes=start-end, start- or -suffix range, inclusive of end.
+func parseRange(rng string, size int64) (start, end int64, ok bool) {
+
+	spec, found := strings.CutPrefix(rng, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return
+	}
+	first, last, found := strings.Cut(spec, "-")
+	if !found {
+		return
+	}
+
+	var err error
+	switch {
+	case first == "":
+		var suffix int64
+		suffix, err = strconv.ParseInt(last, 10, 64)
+		start, end = max(size-suffix, 0), size-1
+	case last == "":
+		start, err = strconv.ParseInt(first, 10, 64)
+		end = size - 1
+	default:
+		start, err = strconv.ParseInt(first, 10, 64)
+		if err == nil {
+			end, err = strconv.ParseInt(last, 10, 64)
+		}
+		end = min(end, size-1)
+	}
+
+	ok = err == nil && start >= 0 && start <= end && start < size
+	return
+}
+
+// lastKey is the key to continue a listing after, past all keys rolled up into entry.
+func lastKey(entry, delimiter string) string {
+
+	if delimiter != "" && strings.HasSuffix(entry, delimiter) {
+		return entry + "\U0010FFFF"
+	}
+
+	return entry
+}
+
+func quote(etag string) string {
+
+	return `"` + etag + `"`
+}
@@ -0,0 +1,403 @@
+package objstotest_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/objstotest"
+)
+
+func TestObjstoTest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ObjstoTest Suite")
+}
+
+var _ = Describe("Server", func() {
+	var (
+		ctx    context.Context
+		opts   []objstotest.Option
+		srv    *objstotest.Server
+		client *objsto.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		opts = nil
+	})
+
+	JustBeforeEach(func() {
+		srv = objstotest.New(opts...)
+		DeferCleanup(srv.Close)
+		client = srv.Client("")
+	})
+
+	put := func(object, content string, opts ...objsto.PutOption) error {
+		return client.PutString(ctx, object, content, opts...)
+	}
+
+	read := func(object string) string {
+		data, err := client.GetBytes(ctx, object, 1<<30)
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	Describe("objects", func() {
+		JustBeforeEach(func() {
+			Expect(put("a.txt", "ay", objsto.WithContentType("text/plain"), objsto.WithMetadata(map[string]string{"color": "red"}))).To(Succeed())
+		})
+
+		It("gets what was put", func() {
+			Expect(read("a.txt")).To(Equal("ay"))
+		})
+
+		It("stats with headers kept", func() {
+			info, err := client.Stat(ctx, "a.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Size).To(Equal(int64(2)))
+			Expect(info.ContentType).To(Equal("text/plain"))
+			Expect(info.Metadata).To(Equal(map[string]string{"color": "red"}))
+			Expect(info.ETag).To(HaveLen(32))
+			Expect(info.LastModified).To(BeTemporally("~", time.Now(), 2*time.Second))
+		})
+
+		It("gets a range", func() {
+			Expect(put("digits", "0123456789")).To(Succeed())
+
+			reader, err := client.GetRange(ctx, "digits", 2, 3)
+			Expect(err).ToNot(HaveOccurred())
+			data, err := io.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("234"))
+		})
+
+		It("is not found once deleted", func() {
+			Expect(client.Delete(ctx, "a.txt")).To(Succeed())
+
+			_, err := client.Stat(ctx, "a.txt")
+			Expect(err).To(MatchError(objsto.ErrNotFound))
+			Expect(client.Delete(ctx, "a.txt")).To(Succeed())
+		})
+
+		It("honors an if-none-match put", func() {
+			err := put("a.txt", "again", objsto.WithIfNoneMatch("*"))
+			Expect(err).To(MatchError(objsto.ErrPreconditionFailed))
+			Expect(read("a.txt")).To(Equal("ay"))
+		})
+
+		It("copies", func() {
+			Expect(client.Copy(ctx, client, "a.txt", "b.txt")).To(Succeed())
+			Expect(read("b.txt")).To(Equal("ay"))
+		})
+
+		It("batch deletes", func() {
+			Expect(put("b.txt", "bee")).To(Succeed())
+
+			failed, err := client.DeleteObjects(ctx, []string{"a.txt", "b.txt", "none"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(failed).To(BeEmpty())
+			Expect(srv.Keys(objstotest.DefaultBucket)).To(BeEmpty())
+		})
+
+		It("refuses what it doesn't implement", func() {
+			_, err := client.GetTags(ctx, "a.txt")
+			Expect(err).To(MatchError(ContainSubstring("NotImplemented")))
+		})
+	})
+
+	Describe("listing", func() {
+		JustBeforeEach(func() {
+			for _, key := range []string{"a", "logs/1", "logs/2", "logs/3", "logs/old/1", "z"} {
+				Expect(put(key, key)).To(Succeed())
+			}
+		})
+
+		It("lists everything, across pages", func() {
+			keys := []string{}
+			for info, err := range client.ListObjects(ctx, objsto.ListInput{MaxKeys: 2}) {
+				Expect(err).ToNot(HaveOccurred())
+				keys = append(keys, info.Key)
+			}
+			Expect(keys).To(Equal([]string{"a", "logs/1", "logs/2", "logs/3", "logs/old/1", "z"}))
+		})
+
+		It("rolls up folders with a delimiter", func() {
+			page, err := client.NewPaginator(objsto.ListInput{Delimiter: "/"}).NextPage(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page.CommonPrefixes).To(Equal([]string{"logs/"}))
+			Expect(page.Objects).To(HaveLen(2))
+		})
+
+		It("continues past a folder rolled up at the end of a page", func() {
+			pgr := client.NewPaginator(objsto.ListInput{Delimiter: "/", MaxKeys: 2})
+
+			page, err := pgr.NextPage(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page.Objects[0].Key).To(Equal("a"))
+			Expect(page.CommonPrefixes).To(Equal([]string{"logs/"}))
+			Expect(page.IsTruncated).To(BeTrue())
+
+			page, err = pgr.NextPage(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page.Objects).To(HaveLen(1))
+			Expect(page.Objects[0].Key).To(Equal("z"))
+			Expect(page.CommonPrefixes).To(BeEmpty())
+		})
+	})
+
+	Describe("multipart", func() {
+		It("assembles parts", func() {
+			data := bytes.Repeat([]byte("0123456789abcdef"), (objsto.MinPartSize*2+100)/16)
+
+			Expect(client.PutStream(ctx, "big", bytes.NewReader(data), objsto.MinPartSize)).To(Succeed())
+
+			info, err := client.Stat(ctx, "big")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Size).To(Equal(int64(len(data))))
+			Expect(info.ETag).To(HaveSuffix("-3"))
+			Expect(read("big")).To(Equal(string(data)))
+
+			uploads, err := client.ListUploads(ctx, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uploads).To(BeEmpty())
+		})
+	})
+
+	Describe("buckets", func() {
+		BeforeEach(func() {
+			opts = append(opts, objstotest.WithBuckets("one"))
+		})
+
+		It("creates, checks and deletes", func() {
+			two := srv.Client("two")
+
+			exists, err := two.BucketExists(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(exists).To(BeFalse())
+
+			Expect(two.CreateBucket(ctx)).To(Succeed())
+			Expect(two.CreateBucket(ctx)).To(MatchError(objsto.ErrConflict))
+
+			Expect(two.PutString(ctx, "key", "val")).To(Succeed())
+			Expect(two.DeleteBucket(ctx)).To(MatchError(objsto.ErrConflict))
+			Expect(two.Delete(ctx, "key")).To(Succeed())
+			Expect(two.DeleteBucket(ctx)).To(Succeed())
+
+			_, err = srv.Client("two").Stat(ctx, "key")
+			Expect(err).To(MatchError(objsto.ErrNotFound))
+		})
+	})
+
+	Describe("signatures", func() {
+		BeforeEach(func() {
+			opts = append(opts, objstotest.WithCredentials("AKID", "sekrit"), objstotest.WithRegion("eu-west-2"))
+		})
+
+		It("accepts a signed request", func() {
+			Expect(put("signed/odd key?#.txt", "yes")).To(Succeed())
+
+			page, err := client.NewPaginator(objsto.ListInput{Prefix: "signed/", Delimiter: "/"}).NextPage(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(page.Objects).To(HaveLen(1))
+			Expect(page.Objects[0].Key).To(Equal("signed/odd key?#.txt"))
+		})
+
+		DescribeTable("round trips keys with characters needing encoding",
+			func(key string) {
+				Expect(put(key, key)).To(Succeed())
+				Expect(read(key)).To(Equal(key))
+
+				keys, err := client.List(ctx, "")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(keys).To(ConsistOf(key))
+
+				keys, err = client.List(ctx, key)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(keys).To(ConsistOf(key))
+			},
+			Entry("space", "a key"),
+			Entry("plus", "a+key"),
+			Entry("question mark", "a?key"),
+			Entry("hash", "a#key"),
+			Entry("percent", "a%20key"),
+			Entry("ampersand and equals", "a&b=key"),
+			Entry("unicode", "ünï/ключ"),
+		)
+
+		It("refuses the wrong secret", func() {
+			cfg := srv.Config("")
+			cfg.SecretKey = "wrong"
+
+			err := objsto.New(cfg).PutString(ctx, "key", "no")
+			Expect(err).To(MatchError(ContainSubstring("SignatureDoesNotMatch")))
+		})
+
+		It("refuses an unsigned request", func() {
+			resp, err := http.Get(srv.URL + "/test/key")
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+		})
+
+		It("accepts a presigned url, refusing one tampered with", func() {
+			Expect(put("shared", "here you go")).To(Succeed())
+
+			uri, err := client.Presign(ctx, "GET", "shared", time.Minute)
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err := http.Get(uri)
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			data, err := io.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal("here you go"))
+
+			tampered := strings.Replace(uri, "shared", "other", 1)
+			resp, err = http.Get(tampered)
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+		})
+
+		It("refuses an expired presigned url", func() {
+			past := srv.Client("", objsto.WithClock(clock(time.Now().Add(-time.Hour))))
+
+			uri, err := past.Presign(ctx, "GET", "shared", time.Minute)
+			Expect(err).ToNot(HaveOccurred())
+
+			resp, err := http.Get(uri)
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+		})
+	})
+})
+
+var _ = Describe("ObjectStoreMock", func() {
+
+	It("stands in for a store", func() {
+		mock := &objstotest.ObjectStoreMock{
+			ListFunc: func(ctx context.Context, prefix string) ([]string, error) {
+				return []string{"kv/one", "kv/two"}, nil
+			},
+			DeleteFunc: func(ctx context.Context, object string) error {
+				return objsto.ErrNotFound
+			},
+		}
+		kv := objsto.NewKV(mock, "kv/")
+
+		keys, err := kv.List(context.Background(), "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keys).To(Equal([]string{"one", "two"}))
+
+		err = kv.Delete(context.Background(), "one")
+		Expect(err).To(MatchError(objsto.ErrNotFound))
+
+		Expect(mock.ListCalls()[0].Prefix).To(Equal("kv/"))
+		Expect(mock.DeleteCalls()[0].Object).To(Equal("kv/one"))
+	})
+})
+
+var _ = Describe("Recorder", func() {
+	var (
+		ctx     context.Context
+		fixture string
+		live    *objsto.Config
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fixture = filepath.Join(GinkgoT().TempDir(), "testdata", "fixture.json")
+
+		srv := objstotest.New(objstotest.WithCredentials("AKIDLIVE", "live-secret"), objstotest.WithBuckets("acct-12345"))
+		DeferCleanup(srv.Close)
+		live = srv.Config("acct-12345")
+
+		rec, err := objstotest.NewRecorder(fixture, objstotest.WithRecording(http.DefaultClient), objstotest.WithReplacement("acct-12345", "bucket"))
+		Expect(err).ToNot(HaveOccurred())
+
+		client := objsto.New(live, objsto.WithHTTPClient(rec))
+		Expect(client.PutString(ctx, "greeting", "hello")).To(Succeed())
+		Expect(client.PutString(ctx, "noise", "\xff\xfe\x00")).To(Succeed())
+		Expect(client.PutString(ctx, "greeting", "hello again")).To(Succeed())
+
+		data, err := client.GetBytes(ctx, "greeting", 1024)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("hello again"))
+
+		_, err = client.GetBytes(ctx, "noise", 1024)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(rec.Close()).To(Succeed())
+	})
+
+	replayer := func() (*objstotest.Recorder, *objsto.Client) {
+		rec, err := objstotest.NewRecorder(fixture)
+		Expect(err).ToNot(HaveOccurred())
+
+		cfg := *live
+		cfg.Host = "nowhere.invalid"
+		cfg.Bucket = "bucket"
+		cfg.AccessKey = "other"
+		cfg.SecretKey = "other"
+		return rec, objsto.New(&cfg, objsto.WithHTTPClient(rec))
+	}
+
+	It("scrubs credentials and replaced values from the fixture", func() {
+		data, err := os.ReadFile(fixture)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(data)).To(ContainSubstring(objstotest.Scrubbed))
+		Expect(string(data)).ToNot(ContainSubstring("AKIDLIVE"))
+		Expect(string(data)).ToNot(ContainSubstring("Signature="))
+		Expect(string(data)).ToNot(ContainSubstring("acct-12345"))
+	})
+
+	It("replays in order, without the server or its credentials", func() {
+		rec, client := replayer()
+
+		Expect(client.PutString(ctx, "greeting", "hello")).To(Succeed())
+		Expect(client.PutString(ctx, "noise", "\xff\xfe\x00")).To(Succeed())
+		Expect(client.PutString(ctx, "greeting", "hello again")).To(Succeed())
+
+		data, err := client.GetBytes(ctx, "greeting", 1024)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("hello again"))
+
+		data, err = client.GetBytes(ctx, "noise", 1024)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal([]byte("\xff\xfe\x00")))
+
+		Expect(rec.Unreplayed()).To(BeZero())
+	})
+
+	It("fails a request not recorded", func() {
+		rec, client := replayer()
+
+		_, err := client.Stat(ctx, "greeting")
+		Expect(err).To(MatchError(ContainSubstring("no recorded response for HEAD /bucket/greeting")))
+		Expect(rec.Unreplayed()).To(Equal(5))
+	})
+
+	It("fails without a fixture", func() {
+		_, err := objstotest.NewRecorder(filepath.Join(GinkgoT().TempDir(), "missing.json"))
+		Expect(err).To(MatchError(ContainSubstring("record it with WithRecording")))
+	})
+})
+
+// clock is stuck at a time.
+type clock time.Time
+
+func (clk clock) Now() time.Time {
+	return time.Time(clk)
+}
@@ -0,0 +1,338 @@
+package objstotest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+)
+
+// Scrubbed replaces secrets in recorded fixtures.
+const Scrubbed = "SCRUBBED"
+
+var (
+	// scrubbedHeaders are headers whose values are scrubbed before recording.
+	scrubbedHeaders = []string{"Authorization", "X-Amz-Security-Token", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+	// scrubbedParams are presigned query params scrubbed before recording.
+	scrubbedParams = []string{"X-Amz-Credential", "X-Amz-Signature", "X-Amz-Security-Token"}
+)
+
+// RecorderOption sets an optional Recorder setting.
+type RecorderOption func(*Recorder)
+
+// WithRecording records requests sent through doer, such as objsto.NewHTTPClient(nil)
+// configured for a real provider, rather than replaying them.
+func WithRecording(doer objsto.HttpDoer) RecorderOption {
+
+	return func(rec *Recorder) {
+		rec.doer = doer
+	}
+}
+
+// WithReplacement replaces secret with placeholder throughout what's recorded, and in requests
+// before they are matched on replay, for an account id in a host or bucket name, say.
+func WithReplacement(secret, placeholder string) RecorderOption {
+
+	return func(rec *Recorder) {
+		rec.replacements = append(rec.replacements, secret, placeholder)
+	}
+}
+
+// Interaction is a request and the response to it, as kept in a fixture.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request, scrubbed of credentials.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response, scrubbed of cookies.
+type RecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// Body is content kept as text when valid utf-8, base64 encoded otherwise.
+type Body []byte
+
+// MarshalJSON marshals body as text, or base64 prefixed with "base64:".
+func (body Body) MarshalJSON() ([]byte, error) {
+
+	text := string(body)
+	if !utf8.Valid(body) || strings.HasPrefix(text, "base64:") {
+		text = "base64:" + base64.StdEncoding.EncodeToString(body)
+	}
+
+	return json.Marshal(text)
+}
+
+// UnmarshalJSON unmarshals body from text or base64 prefixed with "base64:".
+func (body *Body) UnmarshalJSON(data []byte) (err error) {
+
+	var text string
+	err = json.Unmarshal(data, &text)
+	if err != nil {
+		return
+	}
+
+	encoded, found := strings.CutPrefix(text, "base64:")
+	if !found {
+		*body = Body(text)
+		return
+	}
+
+	*body, err = base64.StdEncoding.DecodeString(encoded)
+	return
+}
+
+// Recorder is an objsto.HttpDoer replaying responses from a fixture, or recording them to it
+// given WithRecording, so tests of a real provider's quirks run without its credentials.
+//
+// Requests are matched on method, path and query, the host and any presigning aside, each
+// recorded response being replayed once and in the order recorded.
+// Credentials are scrubbed from what's recorded.
+type Recorder struct {
+	path         string
+	doer         objsto.HttpDoer
+	replacements []string
+	interactions []Interaction
+	replayed     []bool
+	mu           sync.Mutex
+}
+
+// NewRecorder returns a Recorder replaying the fixture at path, or recording to it
+// given WithRecording, to be closed when done.
+func NewRecorder(path string, opts ...RecorderOption) (rec *Recorder, err error) {
+
+	rec = &Recorder{path: path}
+	for _, opt := range opts {
+		opt(rec)
+	}
+
+	if rec.doer != nil {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to read fixture, record it with WithRecording")
+		return
+	}
+
+	err = json.Unmarshal(data, &rec.interactions)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to unmarshal fixture %q", path)
+		return
+	}
+	rec.replayed = make([]bool, len(rec.interactions))
+
+	return
+}
+
+// Do replays a response recorded for request, or sends and records it.
+func (rec *Recorder) Do(request *http.Request) (resp *http.Response, err error) {
+
+	if rec.doer != nil {
+		return rec.record(request)
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	method, uri := request.Method, rec.replace(matchable(request.URL))
+	for idx, ia := range rec.interactions {
+		if rec.replayed[idx] || ia.Request.Method != method || ia.Request.URL != uri {
+			continue
+		}
+		rec.replayed[idx] = true
+
+		resp = ia.Response.response(request)
+		return
+	}
+
+	err = errors.Errorf("no recorded response for %s %s", method, uri)
+	return
+}
+
+// Unreplayed is the count of recorded interactions not yet replayed.
+func (rec *Recorder) Unreplayed() (count int) {
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	for _, done := range rec.replayed {
+		if !done {
+			count++
+		}
+	}
+
+	return
+}
+
+// Close writes the fixture when recording.
+func (rec *Recorder) Close() (err error) {
+
+	if rec.doer == nil {
+		return
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	data, err := json.MarshalIndent(rec.interactions, "", "  ")
+	if err != nil {
+		err = errors.Wrapf(err, "failed to marshal fixture")
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(rec.path), 0o755)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to create fixture dir")
+		return
+	}
+
+	err = os.WriteFile(rec.path, append(data, '\n'), 0o644)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to write fixture")
+	}
+	return
+}
+
+// unexported
+
+func (rec *Recorder) record(request *http.Request) (resp *http.Response, err error) {
+
+	var reqBody []byte
+	if request.Body != nil && request.Body != http.NoBody {
+		reqBody, err = io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			err = errors.Wrapf(err, "failed to read request body")
+			return
+		}
+		request = request.Clone(request.Context())
+		request.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err = rec.doer.Do(request)
+	if err != nil {
+		return
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		err = errors.Wrapf(err, "failed to read response body")
+		return
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	ia := Interaction{
+		Request: RecordedRequest{
+			Method: request.Method,
+			URL:    rec.replace(matchable(request.URL)),
+			Header: rec.scrub(request.Header),
+			Body:   Body(rec.replace(string(reqBody))),
+		},
+		Response: RecordedResponse{
+			Status: resp.StatusCode,
+			Header: rec.scrub(resp.Header),
+			Body:   Body(rec.replace(string(respBody))),
+		},
+	}
+
+	rec.mu.Lock()
+	rec.interactions = append(rec.interactions, ia)
+	rec.mu.Unlock()
+
+	return
+}
+
+// scrub copies hdr with credentials and replacements scrubbed.
+func (rec *Recorder) scrub(hdr http.Header) http.Header {
+
+	out := http.Header{}
+	for key, vals := range hdr {
+		for _, val := range vals {
+			out.Add(key, rec.replace(val))
+		}
+	}
+
+	for _, key := range scrubbedHeaders {
+		if _, ok := out[key]; ok {
+			out[key] = []string{Scrubbed}
+		}
+	}
+
+	return out
+}
+
+func (rec *Recorder) replace(val string) string {
+
+	if len(rec.replacements) == 0 {
+		return val
+	}
+
+	return strings.NewReplacer(rec.replacements...).Replace(val)
+}
+
+// matchable is the path and canonical query of uri with presigning scrubbed,
+// the same whatever the host or when it was signed.
+func matchable(uri *url.URL) string {
+
+	query := uri.Query()
+	for _, name := range scrubbedParams {
+		if query.Has(name) {
+			query.Set(name, Scrubbed)
+		}
+	}
+	query.Del("X-Amz-Date")
+
+	out := uri.EscapedPath()
+	if len(query) > 0 {
+		out += "?" + strings.ReplaceAll(query.Encode(), "+", "%20")
+	}
+
+	return out
+}
+
+func (rr RecordedResponse) response(request *http.Request) *http.Response {
+
+	hdr := rr.Header.Clone()
+	if len(rr.Body) > 0 {
+		// a replacement may have changed the length
+		hdr.Set("Content-Length", strconv.Itoa(len(rr.Body)))
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rr.Status, http.StatusText(rr.Status)),
+		StatusCode:    rr.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        hdr,
+		Body:          io.NopCloser(bytes.NewReader(rr.Body)),
+		ContentLength: int64(len(rr.Body)),
+		Request:       request,
+	}
+}
@@ -0,0 +1,217 @@
+package objstotest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	algorithm       = "AWS4-HMAC-SHA256"
+	amzDateFormat   = "20060102T150405Z"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// authenticate verifies a request's signature, from its Authorization header or a presigned query.
+func (srv *Server) authenticate(request *http.Request, body []byte) *s3Error {
+
+	query := request.URL.Query()
+	if query.Has("X-Amz-Signature") {
+		return srv.authenticateQuery(request, query)
+	}
+
+	auth := request.Header.Get("Authorization")
+	if auth == "" {
+		return errAccessDenied
+	}
+
+	cred, signed, signature, ok := parseAuthorization(auth)
+	if !ok {
+		return errMalformedAuth
+	}
+	amzDate := request.Header.Get("X-Amz-Date")
+	s3e := srv.checkScope(cred, amzDate)
+	if s3e != nil {
+		return s3e
+	}
+
+	payloadHash := request.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		return errMalformedAuth
+	}
+	if payloadHash != unsignedPayload {
+		sum := sha256.Sum256(body)
+		if hex.EncodeToString(sum[:]) != payloadHash {
+			return errContentHashMismatch
+		}
+	}
+
+	want := srv.signature(request, query, signed, amzDate, payloadHash)
+	if !hmac.Equal([]byte(want), []byte(signature)) {
+		return errSignatureMismatch
+	}
+
+	return nil
+}
+
+func (srv *Server) authenticateQuery(request *http.Request, query url.Values) *s3Error {
+
+	if query.Get("X-Amz-Algorithm") != algorithm {
+		return errMalformedAuth
+	}
+
+	amzDate := query.Get("X-Amz-Date")
+	s3e := srv.checkScope(query.Get("X-Amz-Credential"), amzDate)
+	if s3e != nil {
+		return s3e
+	}
+
+	signed, err := time.Parse(amzDateFormat, amzDate)
+	if err != nil {
+		return errMalformedAuth
+	}
+	expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil {
+		return errMalformedAuth
+	}
+	if time.Now().After(signed.Add(time.Duration(expires) * time.Second)) {
+		return errExpired
+	}
+
+	signature := query.Get("X-Amz-Signature")
+	query.Del("X-Amz-Signature")
+
+	want := srv.signature(request, query, strings.Split(query.Get("X-Amz-SignedHeaders"), ";"), amzDate, unsignedPayload)
+	if !hmac.Equal([]byte(want), []byte(signature)) {
+		return errSignatureMismatch
+	}
+
+	return nil
+}
+
+// checkScope checks a credential, as with AKID/20260101/us-east-1/s3/aws4_request, is for the server
+// and dated as amzDate is.
+func (srv *Server) checkScope(cred, amzDate string) *s3Error {
+
+	parts := strings.Split(cred, "/")
+	switch {
+	case len(parts) != 5 || parts[3] != "s3" || parts[4] != "aws4_request" || len(amzDate) != len(amzDateFormat):
+		return errMalformedAuth
+	case parts[0] != srv.accessKey:
+		return errInvalidAccessKey
+	case parts[1] != amzDate[:8] || parts[2] != srv.region:
+		return errMalformedAuth
+	}
+
+	return nil
+}
+
+// signature is what the request should be signed with, per SigV4.
+func (srv *Server) signature(request *http.Request, query url.Values, signed []string, amzDate, payloadHash string) string {
+
+	headers := make([]string, len(signed))
+	for idx, name := range signed {
+		val := request.Header.Values(name)
+		if name == "host" {
+			val = []string{request.Host}
+		}
+		trimmed := make([]string, len(val))
+		for jdx, each := range val {
+			trimmed[jdx] = strings.Join(strings.Fields(each), " ")
+		}
+		headers[idx] = name + ":" + strings.Join(trimmed, ",")
+	}
+
+	canonical := strings.Join([]string{
+		request.Method,
+		uriEncode(request.URL.Path, false),
+		canonicalQuery(query),
+		strings.Join(headers, "\n") + "\n",
+		strings.Join(signed, ";"),
+		payloadHash,
+	}, "\n")
+	sum := sha256.Sum256([]byte(canonical))
+
+	scope := amzDate[:8] + "/" + srv.region + "/s3/aws4_request"
+	toSign := strings.Join([]string{algorithm, amzDate, scope, hex.EncodeToString(sum[:])}, "\n")
+
+	key := hmacSum([]byte("AWS4"+srv.secretKey), amzDate[:8])
+	key = hmacSum(key, srv.region)
+	key = hmacSum(key, "s3")
+	key = hmacSum(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSum(key, toSign))
+}
+
+// parseAuthorization parses an AWS4-HMAC-SHA256 Authorization header.
+func parseAuthorization(auth string) (cred string, signed []string, signature string, ok bool) {
+
+	rest, found := strings.CutPrefix(auth, algorithm+" ")
+	if !found {
+		return
+	}
+
+	for _, field := range strings.Split(rest, ",") {
+		name, val, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch name {
+		case "Credential":
+			cred = val
+		case "SignedHeaders":
+			signed = strings.Split(val, ";")
+		case "Signature":
+			signature = val
+		}
+	}
+
+	ok = cred != "" && len(signed) > 0 && signature != "" && slices.Contains(signed, "host")
+	return
+}
+
+// canonicalQuery is the query sorted by name then value, each strictly encoded.
+func canonicalQuery(query url.Values) string {
+
+	byEncoded := func(a, b string) int {
+		return strings.Compare(uriEncode(a, true), uriEncode(b, true))
+	}
+
+	pairs := []string{}
+	for _, name := range slices.SortedFunc(maps.Keys(query), byEncoded) {
+		for _, val := range slices.SortedFunc(slices.Values(query[name]), byEncoded) {
+			pairs = append(pairs, uriEncode(name, true)+"="+uriEncode(val, true))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes all but unreserved characters, and slashes unless encodeSlash.
+func uriEncode(val string, encodeSlash bool) string {
+
+	var bldr strings.Builder
+	for idx := 0; idx < len(val); idx++ {
+		ch := val[idx]
+		switch {
+		case 'A' <= ch && ch <= 'Z', 'a' <= ch && ch <= 'z', '0' <= ch && ch <= '9',
+			ch == '-', ch == '.', ch == '_', ch == '~', ch == '/' && !encodeSlash:
+			bldr.WriteByte(ch)
+		default:
+			bldr.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{ch})))
+		}
+	}
+
+	return bldr.String()
+}
+
+func hmacSum(key []byte, data string) []byte {
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
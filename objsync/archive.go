@@ -0,0 +1,381 @@
+package objsync
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+)
+
+const (
+	paxContentType = "OBJSTO.content_type"
+	paxMetaPrefix  = "OBJSTO.meta."
+)
+
+// Pack writes objects under prefix to writer as an archive, as with Snapshot,
+// but with entries named relative to prefix, the reverse of ExtractArchive.
+func Pack(ctx context.Context, store objsto.ObjectStore, prefix string, writer io.Writer, opts ...Option) (report Report, err error) {
+
+	prefix = dirPrefix(prefix)
+
+	report, err = pack(ctx, store, prefix, prefix, writer, newOptions(opts))
+	return
+}
+
+// ExtractArchive puts each file in a tar stream, gzipped or not, to an object under prefix,
+// restoring content type and metadata from PAX records written by Pack or Snapshot.
+// Options from WithPutOptions apply to each object.
+//
+// Entries are streamed to a ReaderPutter, and otherwise buffered in memory, one at a time.
+// Zip can't be read as a stream, see ExtractZip.
+func ExtractArchive(ctx context.Context, store objsto.ObjectStore, reader io.Reader, prefix string, opts ...Option) (report Report, err error) {
+
+	start := time.Now()
+	o := newOptions(opts)
+	prefix = dirPrefix(prefix)
+
+	buffered := bufio.NewReader(reader)
+	magic, _ := buffered.Peek(4)
+
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")):
+		err = errors.Errorf("zip archive cannot be streamed, use ExtractZip")
+		return
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		var gzr *gzip.Reader
+		gzr, err = gzip.NewReader(buffered)
+		if err != nil {
+			err = errors.Wrap(err, "failed to read gzip header")
+			return
+		}
+		defer gzr.Close()
+		reader = gzr
+	default:
+		reader = buffered
+	}
+
+	tl := &tally{}
+	tr := tar.NewReader(reader)
+	for {
+		var hdr *tar.Header
+		hdr, err = tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			err = errors.Wrap(err, "failed to read tar")
+			return
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		key, ok := entryKey(prefix, hdr.Name)
+		if !ok {
+			tl.failed(hdr.Name, errors.Errorf("entry name escapes the archive"))
+			continue
+		}
+
+		putOpts := append(paxOptions(hdr.PAXRecords), o.putOpts...)
+
+		// the stream can't be rewound, so a failed entry is not retried and is the end
+		err = putEntry(ctx, store, key, tr, hdr.Size, putOpts)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to put %q", key)
+			return
+		}
+		tl.transferred(key, hdr.Size)
+	}
+
+	report, err = tl.done(start, nil)
+	return
+}
+
+// ExtractZip puts each file in a zip to an object under prefix, as with ExtractArchive.
+func ExtractZip(ctx context.Context, store objsto.ObjectStore, reader io.ReaderAt, size int64, prefix string, opts ...Option) (report Report, err error) {
+
+	start := time.Now()
+	o := newOptions(opts)
+	prefix = dirPrefix(prefix)
+
+	zr, err := zip.NewReader(reader, size)
+	if err != nil {
+		err = errors.Wrap(err, "failed to read zip")
+		return
+	}
+
+	tl := &tally{}
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		key, ok := entryKey(prefix, file.Name)
+		if !ok {
+			tl.failed(file.Name, errors.Errorf("entry name escapes the archive"))
+			continue
+		}
+
+		putOpts := append(commentOptions(file.Comment), o.putOpts...)
+
+		err := o.attempt(ctx, func() error {
+			entry, err := file.Open()
+			if err != nil {
+				return err
+			}
+			defer entry.Close()
+
+			return putEntry(ctx, store, key, entry, int64(file.UncompressedSize64), putOpts)
+		})
+		if err != nil {
+			tl.failed(key, err)
+			continue
+		}
+		tl.transferred(key, int64(file.UncompressedSize64))
+	}
+
+	report, err = tl.done(start, nil)
+	return
+}
+
+// unexported
+
+type entryMeta struct {
+	ContentType string            `json:"content_type,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// archiver writes objects to an archive.
+type archiver interface {
+	add(name string, info objsto.ObjectInfo, reader io.Reader) (int64, error)
+	close() error
+}
+
+func newArchiver(writer io.Writer, o options) archiver {
+
+	if o.zip {
+		return &zipArchiver{zw: zip.NewWriter(writer)}
+	}
+
+	ta := &tarArchiver{}
+	if o.gzip {
+		ta.gzw = gzip.NewWriter(writer)
+		writer = ta.gzw
+	}
+	ta.tw = tar.NewWriter(writer)
+
+	return ta
+}
+
+// pack writes objects under prefix to writer, naming entries for keys without trim.
+func pack(ctx context.Context, store objsto.ObjectStore, prefix, trim string, writer io.Writer, o options) (report Report, err error) {
+
+	start := time.Now()
+
+	infos, err := listInfos(ctx, store, prefix)
+	if err != nil {
+		return
+	}
+
+	arc := newArchiver(writer, o)
+
+	tl := &tally{}
+	for _, key := range sortedKeys(infos) {
+		var size int64
+		size, err = addObject(ctx, arc, store, key, strings.TrimPrefix(key, trim))
+		if errors.Is(err, objsto.ErrNotFound) {
+			err = nil
+			tl.skipped()
+			continue
+		}
+		if err != nil {
+			err = errors.Wrapf(err, "failed to add %q to archive", key)
+			return
+		}
+		tl.transferred(key, size)
+	}
+
+	err = arc.close()
+	if err != nil {
+		err = errors.Wrap(err, "failed to finish archive")
+		return
+	}
+
+	report, err = tl.done(start, nil)
+	return
+}
+
+func addObject(ctx context.Context, arc archiver, store objsto.ObjectStore, key, name string) (size int64, err error) {
+
+	info, err := store.Stat(ctx, key)
+	if err != nil {
+		return
+	}
+
+	reader, err := store.Get(ctx, key)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	size, err = arc.add(name, info, reader)
+	return
+}
+
+type tarArchiver struct {
+	tw  *tar.Writer
+	gzw *gzip.Writer
+}
+
+func (ta *tarArchiver) add(name string, info objsto.ObjectInfo, reader io.Reader) (size int64, err error) {
+
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     info.Size,
+		Mode:     0644,
+		ModTime:  info.LastModified,
+		Format:   tar.FormatPAX,
+	}
+
+	if info.ContentType != "" || len(info.Metadata) > 0 {
+		hdr.PAXRecords = map[string]string{}
+	}
+	if info.ContentType != "" {
+		hdr.PAXRecords[paxContentType] = info.ContentType
+	}
+	for meta, val := range info.Metadata {
+		hdr.PAXRecords[paxMetaPrefix+meta] = val
+	}
+
+	err = ta.tw.WriteHeader(hdr)
+	if err != nil {
+		return
+	}
+
+	size, err = io.Copy(ta.tw, reader)
+	return
+}
+
+func (ta *tarArchiver) close() (err error) {
+
+	err = ta.tw.Close()
+	if err == nil && ta.gzw != nil {
+		err = ta.gzw.Close()
+	}
+
+	return
+}
+
+type zipArchiver struct {
+	zw *zip.Writer
+}
+
+func (za *zipArchiver) add(name string, info objsto.ObjectInfo, reader io.Reader) (size int64, err error) {
+
+	hdr := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: info.LastModified,
+	}
+
+	if info.ContentType != "" || len(info.Metadata) > 0 {
+		var comment []byte
+		comment, err = json.Marshal(entryMeta{ContentType: info.ContentType, Metadata: info.Metadata})
+		if err != nil {
+			return
+		}
+		hdr.Comment = string(comment)
+	}
+
+	writer, err := za.zw.CreateHeader(hdr)
+	if err != nil {
+		return
+	}
+
+	size, err = io.Copy(writer, reader)
+	return
+}
+
+func (za *zipArchiver) close() error {
+
+	return za.zw.Close()
+}
+
+// entryKey joins prefix and a cleaned entry name, refusing names that climb out.
+func entryKey(prefix, name string) (key string, ok bool) {
+
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return
+	}
+
+	key = prefix + name
+	ok = true
+	return
+}
+
+func paxOptions(records map[string]string) (putOpts []objsto.PutOption) {
+
+	metadata := map[string]string{}
+	for name, val := range records {
+		if name == paxContentType {
+			putOpts = append(putOpts, objsto.WithContentType(val))
+			continue
+		}
+		if meta, ok := strings.CutPrefix(name, paxMetaPrefix); ok {
+			metadata[meta] = val
+		}
+	}
+
+	if len(metadata) > 0 {
+		putOpts = append(putOpts, objsto.WithMetadata(metadata))
+	}
+	return
+}
+
+func commentOptions(comment string) (putOpts []objsto.PutOption) {
+
+	var meta entryMeta
+	err := json.Unmarshal([]byte(comment), &meta)
+	if err != nil {
+		return
+	}
+
+	if meta.ContentType != "" {
+		putOpts = append(putOpts, objsto.WithContentType(meta.ContentType))
+	}
+	if len(meta.Metadata) > 0 {
+		putOpts = append(putOpts, objsto.WithMetadata(meta.Metadata))
+	}
+	return
+}
+
+// putEntry puts from a reader of known size, streaming to a ReaderPutter and buffering otherwise.
+func putEntry(ctx context.Context, store objsto.ObjectStore, key string, reader io.Reader, size int64, opts []objsto.PutOption) (err error) {
+
+	if rp, ok := store.(objsto.ReaderPutter); ok {
+		err = rp.PutReader(ctx, key, reader, size, opts...)
+		return
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to read %q", key)
+		return
+	}
+
+	err = store.Put(ctx, key, bytes.NewReader(data), opts...)
+	return
+}
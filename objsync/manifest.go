@@ -0,0 +1,143 @@
+package objsync
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+)
+
+const metaColumn = "meta:"
+
+// Entry is a file to upload to a key, with optional content type and metadata.
+type Entry struct {
+	Path        string            `json:"path"`
+	Key         string            `json:"key"`
+	ContentType string            `json:"content_type,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// Manifest is a batch of files to upload.
+type Manifest []Entry
+
+// ReadManifestJSON reads a manifest from a JSON array of entries.
+func ReadManifestJSON(reader io.Reader) (manifest Manifest, err error) {
+
+	err = json.NewDecoder(reader).Decode(&manifest)
+	if err != nil {
+		err = errors.Wrap(err, "failed to decode manifest")
+		return
+	}
+
+	err = manifest.validate()
+	return
+}
+
+// ReadManifestCSV reads a manifest from CSV with a header row.
+// Columns "path" and "key" are required, "content_type" is optional,
+// and columns named "meta:<name>" become metadata, with blank values left out.
+func ReadManifestCSV(reader io.Reader) (manifest Manifest, err error) {
+
+	rows, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		err = errors.Wrap(err, "failed to read manifest csv")
+		return
+	}
+	if len(rows) == 0 {
+		err = errors.Errorf("manifest csv has no header")
+		return
+	}
+
+	header := rows[0]
+	for _, required := range []string{"path", "key"} {
+		if !slices.Contains(header, required) {
+			err = errors.Errorf("manifest csv has no %q column", required)
+			return
+		}
+	}
+
+	for _, row := range rows[1:] {
+		var entry Entry
+		for i, val := range row {
+			switch name := header[i]; {
+			case name == "path":
+				entry.Path = val
+			case name == "key":
+				entry.Key = val
+			case name == "content_type":
+				entry.ContentType = val
+			case strings.HasPrefix(name, metaColumn) && val != "":
+				if entry.Metadata == nil {
+					entry.Metadata = map[string]string{}
+				}
+				entry.Metadata[name[len(metaColumn):]] = val
+			}
+		}
+		manifest = append(manifest, entry)
+	}
+
+	err = manifest.validate()
+	return
+}
+
+// Upload puts each entry in the manifest, with WithConcurrency and WithRetry honored.
+// Relative paths are relative to the working directory.
+func Upload(ctx context.Context, store objsto.ObjectStore, manifest Manifest, opts ...Option) (report Report, err error) {
+
+	start := time.Now()
+	o := newOptions(opts)
+	tl := &tally{}
+
+	stopped := each(ctx, o.concurrency, manifest, func(entry Entry) {
+		info, err := os.Stat(entry.Path)
+		if err != nil {
+			tl.failed(entry.Key, errors.Wrapf(err, "failed to stat %q", entry.Path))
+			return
+		}
+
+		if !o.dryRun {
+			putOpts := slices.Clone(o.putOpts)
+			if entry.ContentType != "" {
+				putOpts = append(putOpts, objsto.WithContentType(entry.ContentType))
+			}
+			if len(entry.Metadata) > 0 {
+				putOpts = append(putOpts, objsto.WithMetadata(entry.Metadata))
+			}
+
+			local := localFile{path: entry.Path, key: entry.Key}
+			err = o.attempt(ctx, func() error {
+				return upload(ctx, store, local, putOpts)
+			})
+			if err != nil {
+				tl.failed(entry.Key, err)
+				return
+			}
+		}
+		tl.transferred(entry.Key, info.Size())
+	})
+
+	report, err = tl.done(start, stopped)
+	return
+}
+
+// unexported
+
+func (manifest Manifest) validate() (err error) {
+
+	for i, entry := range manifest {
+		if entry.Path == "" || entry.Key == "" {
+			err = errors.Errorf("manifest entry %d needs both path and key", i+1)
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,174 @@
+package objsync
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+)
+
+// Mirror copies new and changed objects under prefix from src to dst, as from Garage to AWS.
+// With WithDelete, objects under prefix in dst but not src are deleted, and with WithDryRun only the diff is reported.
+//
+// When both are Clients on the same endpoint, objects are copied server-side.
+// Otherwise they're streamed through, with dst a ReaderPutter, or spooled to a temp file.
+// Objects are judged the same by size and ETag, or by size and dst being newer
+// when either ETag is from a multipart upload, or by stored checksums with CompareChecksum.
+func Mirror(ctx context.Context, src, dst objsto.ObjectStore, prefix string, opts ...Option) (report Report, err error) {
+
+	start := time.Now()
+	o := newOptions(opts)
+
+	srcInfos, err := listInfos(ctx, src, prefix)
+	if err != nil {
+		return
+	}
+	dstInfos, err := listInfos(ctx, dst, prefix)
+	if err != nil {
+		return
+	}
+
+	tl := &tally{}
+	var todo []objsto.ObjectInfo
+	for _, key := range sortedKeys(srcInfos) {
+		info := srcInfos[key]
+
+		have, ok := dstInfos[key]
+		delete(dstInfos, key)
+
+		if ok && o.mirrored(ctx, src, dst, info, have) {
+			tl.skipped()
+			continue
+		}
+		todo = append(todo, info)
+	}
+
+	copier := newCopier(src, dst)
+
+	stopped := each(ctx, o.concurrency, todo, func(info objsto.ObjectInfo) {
+		if !o.dryRun {
+			err := o.attempt(ctx, func() error {
+				return copier(ctx, info)
+			})
+			if err != nil {
+				tl.failed(info.Key, err)
+				return
+			}
+		}
+		tl.transferred(info.Key, info.Size)
+	})
+
+	if o.delete && stopped == nil {
+		stopped = each(ctx, o.concurrency, sortedKeys(dstInfos), func(key string) {
+			if !o.dryRun {
+				err := o.attempt(ctx, func() error {
+					return dst.Delete(ctx, key)
+				})
+				if err != nil {
+					tl.failed(key, err)
+					return
+				}
+			}
+			tl.deleted(key)
+		})
+	}
+
+	report, err = tl.done(start, stopped)
+	return
+}
+
+// unexported
+
+type copyFunc func(ctx context.Context, info objsto.ObjectInfo) error
+
+func newCopier(src, dst objsto.ObjectStore) copyFunc {
+
+	srcClient, srcOk := src.(*objsto.Client)
+	dstClient, dstOk := dst.(*objsto.Client)
+	if srcOk && dstOk && dstClient.SameEndpoint(srcClient) {
+		return func(ctx context.Context, info objsto.ObjectInfo) error {
+			return dstClient.Copy(ctx, srcClient, info.Key, info.Key)
+		}
+	}
+
+	return func(ctx context.Context, info objsto.ObjectInfo) error {
+		return stream(ctx, src, dst, info.Key)
+	}
+}
+
+func stream(ctx context.Context, src, dst objsto.ObjectStore, key string) (err error) {
+
+	info, err := src.Stat(ctx, key)
+	if err != nil {
+		return
+	}
+	opts := []objsto.PutOption{objsto.WithMetadata(info.Metadata)}
+	if info.ContentType != "" {
+		opts = append(opts, objsto.WithContentType(info.ContentType))
+	}
+
+	reader, err := src.Get(ctx, key)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	if rp, ok := dst.(objsto.ReaderPutter); ok {
+		err = rp.PutReader(ctx, key, reader, info.Size, opts...)
+		return
+	}
+
+	err = putSpooled(ctx, dst, key, reader, opts)
+	return
+}
+
+// putSpooled puts from a reader of unknown size by way of a temp file.
+func putSpooled(ctx context.Context, dst objsto.ObjectStore, key string, reader io.Reader, opts []objsto.PutOption) (err error) {
+
+	tmp, err := os.CreateTemp("", "objsync-*")
+	if err != nil {
+		err = errors.Wrap(err, "failed to create temp file")
+		return
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	_, err = io.Copy(tmp, reader)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "failed to spool %q", key)
+		return
+	}
+
+	err = dst.Put(ctx, key, tmp, opts...)
+	return
+}
+
+func (o options) mirrored(ctx context.Context, srcStore, dstStore objsto.ObjectStore, src, dst objsto.ObjectInfo) bool {
+
+	if src.Size != dst.Size {
+		return false
+	}
+
+	if o.compare == CompareChecksum {
+		srcSum, srcOk := storedSum(ctx, srcStore, src.Key)
+		dstSum, dstOk := storedSum(ctx, dstStore, dst.Key)
+		return srcOk && dstOk && srcSum == dstSum
+	}
+
+	multipart := strings.Contains(src.ETag, "-") || strings.Contains(dst.ETag, "-")
+	if src.ETag != "" && dst.ETag != "" && !multipart {
+		return src.ETag == dst.ETag
+	}
+
+	return !dst.LastModified.Before(src.LastModified.Truncate(time.Second))
+}
@@ -0,0 +1,356 @@
+// Package objsync synchronizes local directories and other stores with object storage prefixes,
+// and snapshots prefixes to archives.
+package objsync
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+)
+
+// DefaultConcurrency is the number of transfers in flight by default.
+const DefaultConcurrency = 4
+
+// Compare is how a local file and an object are judged the same, sparing a transfer.
+type Compare int
+
+const (
+	// CompareSizeMtime compares size and modification time, the default.
+	CompareSizeMtime Compare = iota
+	// CompareETag compares size and the local MD5 with a non-multipart ETag, reading each local file.
+	CompareETag
+	// CompareChecksum compares size and the local SHA-256 with that stored for the object,
+	// as x-amz-checksum-sha256 or "sha256" metadata, reading each local file and statting each object.
+	// Uploads store the metadata so later syncs can compare. Objects without either are taken to differ.
+	CompareChecksum
+)
+
+// ChecksumKey is the metadata key for a hex SHA-256 stored with CompareChecksum.
+const ChecksumKey = "sha256"
+
+// Option sets an optional sync setting.
+type Option func(*options)
+
+// WithConcurrency sets the number of transfers in flight, defaulting to DefaultConcurrency.
+func WithConcurrency(n int) Option {
+
+	return func(opts *options) {
+		opts.concurrency = n
+	}
+}
+
+// WithCompare sets how files and objects are compared.
+func WithCompare(compare Compare) Option {
+
+	return func(opts *options) {
+		opts.compare = compare
+	}
+}
+
+// WithDelete removes what's in the destination but not the source.
+func WithDelete() Option {
+
+	return func(opts *options) {
+		opts.delete = true
+	}
+}
+
+// WithDryRun reports what would be done without doing it.
+func WithDryRun() Option {
+
+	return func(opts *options) {
+		opts.dryRun = true
+	}
+}
+
+// WithPutOptions are applied when putting objects.
+func WithPutOptions(putOpts ...objsto.PutOption) Option {
+
+	return func(opts *options) {
+		opts.putOpts = append(opts.putOpts, putOpts...)
+	}
+}
+
+// WithRetry retries each transfer that fails per policy, such as objsto.Backoff.
+// This is on top of any retries made by a Client for individual requests.
+func WithRetry(policy objsto.RetryPolicy) Option {
+
+	return func(opts *options) {
+		opts.retry = policy
+	}
+}
+
+// Report summarizes a sync.
+// It's returned even when the sync errors, as when anything failed or ctx was done partway.
+type Report struct {
+	Transferred []string      `json:"transferred"`
+	Deleted     []string      `json:"deleted,omitempty"`
+	Skipped     int           `json:"skipped"`
+	Bytes       int64         `json:"bytes"`
+	Failures    []Failure     `json:"failures,omitempty"`
+	Elapsed     time.Duration `json:"elapsed"`
+}
+
+// Failure is a key that could not be synced.
+type Failure struct {
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// unexported
+
+type options struct {
+	concurrency int
+	compare     Compare
+	delete      bool
+	dryRun      bool
+	putOpts     []objsto.PutOption
+	retry       objsto.RetryPolicy
+	interval    time.Duration
+	passFunc    func(Report, error)
+	gzip        bool
+	zip         bool
+}
+
+func newOptions(opts []Option) (o options) {
+
+	o.concurrency = DefaultConcurrency
+	o.retry = objsto.NoRetry{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.concurrency = max(o.concurrency, 1)
+
+	return
+}
+
+// attempt calls fn until it succeeds or the retry policy gives up,
+// not retrying once ctx is done or when another try would fail the same.
+func (o options) attempt(ctx context.Context, fn func() error) (err error) {
+
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || ctx.Err() != nil || objsto.Permanent(err) {
+			return
+		}
+
+		delay, ok := o.retry.Retry(attempt, nil, err)
+		if !ok {
+			return
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// matches judges whether local and remote are the same per compare mode, sparing a transfer,
+// falling back to mtimeOk.
+func (o options) matches(ctx context.Context, store objsto.ObjectStore, local localFile, remote objsto.ObjectInfo, mtimeOk func() bool) bool {
+
+	if local.size != remote.Size {
+		return false
+	}
+
+	switch o.compare {
+	case CompareETag:
+		if remote.ETag != "" && !strings.Contains(remote.ETag, "-") {
+			sum, err := fileSum(local.path, md5.New())
+			return err == nil && sum == remote.ETag
+		}
+	case CompareChecksum:
+		stored, ok := storedSum(ctx, store, remote.Key)
+		if !ok {
+			return false
+		}
+		sum, err := fileSum(local.path, sha256.New())
+		return err == nil && sum == stored
+	}
+
+	return mtimeOk()
+}
+
+// tally collects results from concurrent transfers.
+type tally struct {
+	report Report
+	mu     sync.Mutex
+}
+
+func (tl *tally) transferred(key string, size int64) {
+
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.report.Transferred = append(tl.report.Transferred, key)
+	tl.report.Bytes += size
+}
+
+func (tl *tally) deleted(key string) {
+
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.report.Deleted = append(tl.report.Deleted, key)
+}
+
+func (tl *tally) skipped() {
+
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.report.Skipped++
+}
+
+func (tl *tally) failed(key string, err error) {
+
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.report.Failures = append(tl.report.Failures, Failure{Key: key, Error: err.Error()})
+}
+
+// done finishes the report, erroring when stopped before all were attempted or anything failed.
+func (tl *tally) done(start time.Time, stopped error) (report Report, err error) {
+
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	report = tl.report
+	report.Elapsed = time.Since(start)
+	slices.Sort(report.Transferred)
+	slices.Sort(report.Deleted)
+
+	switch {
+	case stopped != nil:
+		err = errors.Wrapf(stopped, "stopped before all were attempted, %d failed", len(report.Failures))
+	case len(report.Failures) > 0:
+		err = errors.Errorf("%d failed, first: %s: %s", len(report.Failures), report.Failures[0].Key, report.Failures[0].Error)
+	}
+
+	return
+}
+
+// each calls fn for items with up to n in flight, stopping early with ctx's error when it's done.
+func each[T any](ctx context.Context, n int, items []T, fn func(T)) (err error) {
+
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+
+	for _, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		// checked apart from the select, which picks at random when both are ready
+		if ctx.Err() != nil {
+			wg.Wait()
+			err = ctx.Err()
+			return
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(item)
+		}()
+	}
+
+	wg.Wait()
+	return
+}
+
+// listInfos lists objects under prefix keyed by key, with ObjectLister when available and Stat otherwise.
+func listInfos(ctx context.Context, store objsto.ObjectStore, prefix string) (infos map[string]objsto.ObjectInfo, err error) {
+
+	infos = map[string]objsto.ObjectInfo{}
+
+	lister, ok := store.(objsto.ObjectLister)
+	if ok {
+		for info, err := range lister.ListObjects(ctx, objsto.ListInput{Prefix: prefix}) {
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to list %q", prefix)
+			}
+			infos[info.Key] = info
+		}
+		return
+	}
+
+	keys, err := store.List(ctx, prefix)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to list %q", prefix)
+		return
+	}
+
+	for _, key := range keys {
+		var info objsto.ObjectInfo
+		info, err = store.Stat(ctx, key)
+		if errors.Is(err, objsto.ErrNotFound) {
+			err = nil
+			continue
+		}
+		if err != nil {
+			err = errors.Wrapf(err, "failed to stat %q", key)
+			return
+		}
+		infos[key] = info
+	}
+
+	return
+}
+
+// storedSum finds the hex SHA-256 stored for an object, if any.
+func storedSum(ctx context.Context, store objsto.ObjectStore, key string) (sum string, ok bool) {
+
+	info, err := store.Stat(ctx, key)
+	if err != nil {
+		return
+	}
+
+	if info.Checksum != "" {
+		raw, err := base64.StdEncoding.DecodeString(info.Checksum)
+		if err == nil {
+			return hex.EncodeToString(raw), true
+		}
+	}
+
+	sum, ok = info.Metadata[ChecksumKey]
+	return
+}
+
+func fileSum(path string, hash hash.Hash) (sum string, err error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return
+	}
+
+	sum = hex.EncodeToString(hash.Sum(nil))
+	return
+}
@@ -0,0 +1,631 @@
+package objsync_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+	"github.com/clarktrimble/objsto/objsync"
+)
+
+func TestObjSync(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ObjSync Suite")
+}
+
+func writeFile(dir, rel, content string, mtime time.Time) {
+
+	path := filepath.Join(dir, rel)
+	Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+	Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+	Expect(os.Chtimes(path, mtime, mtime)).To(Succeed())
+}
+
+func getString(store *memstore.Store, key string) string {
+
+	reader, err := store.Get(context.Background(), key)
+	Expect(err).ToNot(HaveOccurred())
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	Expect(err).ToNot(HaveOccurred())
+	return string(data)
+}
+
+var _ = Describe("Push", func() {
+	var (
+		ctx   = context.Background()
+		dir   string
+		store *memstore.Store
+		past  = time.Now().Add(-time.Hour)
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		store = memstore.New()
+
+		writeFile(dir, "a.txt", "alpha", past)
+		writeFile(dir, "sub/b.txt", "bravo", past)
+	})
+
+	It("uploads everything the first time", func() {
+		report, err := objsync.Push(ctx, store, dir, "site")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(Equal([]string{"site/a.txt", "site/sub/b.txt"}))
+		Expect(report.Bytes).To(Equal(int64(10)))
+		Expect(getString(store, "site/sub/b.txt")).To(Equal("bravo"))
+	})
+
+	It("uploads only what changed the second time", func() {
+		_, err := objsync.Push(ctx, store, dir, "site")
+		Expect(err).ToNot(HaveOccurred())
+
+		writeFile(dir, "a.txt", "alpha, revised", time.Now().Add(time.Hour))
+
+		report, err := objsync.Push(ctx, store, dir, "site")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(Equal([]string{"site/a.txt"}))
+		Expect(report.Skipped).To(Equal(1))
+		Expect(getString(store, "site/a.txt")).To(Equal("alpha, revised"))
+	})
+
+	It("compares etags when asked", func() {
+		err := store.Put(ctx, "site/a.txt", bytes.NewReader([]byte("alpha")))
+		Expect(err).ToNot(HaveOccurred())
+		err = store.Put(ctx, "site/sub/b.txt", bytes.NewReader([]byte("BRAVO")))
+		Expect(err).ToNot(HaveOccurred())
+
+		report, err := objsync.Push(ctx, store, dir, "site", objsync.WithCompare(objsync.CompareETag))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(Equal([]string{"site/sub/b.txt"}))
+	})
+
+	It("compares checksums when asked, whatever the timestamps", func() {
+		_, err := objsync.Push(ctx, store, dir, "site", objsync.WithCompare(objsync.CompareChecksum))
+		Expect(err).ToNot(HaveOccurred())
+
+		info, err := store.Stat(ctx, "site/a.txt")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Metadata).To(HaveKeyWithValue(objsync.ChecksumKey,
+			"8ed3f6ad685b959ead7022518e1af76cd816f8e8ec7ccdda1ed4018e8f2223f8"))
+
+		writeFile(dir, "a.txt", "alpha", time.Now().Add(time.Hour))
+		writeFile(dir, "sub/b.txt", "BRAVO", past)
+
+		report, err := objsync.Push(ctx, store, dir, "site", objsync.WithCompare(objsync.CompareChecksum))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(Equal([]string{"site/sub/b.txt"}))
+		Expect(report.Skipped).To(Equal(1))
+	})
+
+	It("deletes remote extras when asked", func() {
+		err := store.Put(ctx, "site/gone.txt", bytes.NewReader([]byte("old")))
+		Expect(err).ToNot(HaveOccurred())
+
+		report, err := objsync.Push(ctx, store, dir, "site", objsync.WithDelete())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Deleted).To(Equal([]string{"site/gone.txt"}))
+		Expect(store.Len()).To(Equal(2))
+	})
+
+	It("changes nothing on a dry run", func() {
+		report, err := objsync.Push(ctx, store, dir, "site", objsync.WithDryRun())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(HaveLen(2))
+		Expect(store.Len()).To(BeZero())
+	})
+
+	It("reports failures", func() {
+		store.SetFault(func(op memstore.Op, object string) error {
+			if op == memstore.OpPut && object == "site/a.txt" {
+				return errors.New("boom")
+			}
+			return nil
+		})
+
+		report, err := objsync.Push(ctx, store, dir, "site", objsync.WithConcurrency(1))
+		Expect(err).To(MatchError(ContainSubstring("1 failed")))
+		Expect(report.Transferred).To(Equal([]string{"site/sub/b.txt"}))
+		Expect(report.Failures).To(HaveLen(1))
+		Expect(report.Failures[0].Key).To(Equal("site/a.txt"))
+	})
+
+	It("errors when canceled partway", func() {
+		pushCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		store.SetFault(func(op memstore.Op, object string) error {
+			if op == memstore.OpPut {
+				cancel()
+			}
+			return nil
+		})
+
+		report, err := objsync.Push(pushCtx, store, dir, "site", objsync.WithConcurrency(1))
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		Expect(report.Transferred).To(Equal([]string{"site/a.txt"}))
+	})
+})
+
+var _ = Describe("Pull", func() {
+	var (
+		ctx   = context.Background()
+		dir   string
+		store *memstore.Store
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		store = memstore.New()
+
+		for key, content := range map[string]string{
+			"site/a.txt":     "alpha",
+			"site/sub/b.txt": "bravo",
+			"other/c.txt":    "charlie",
+		} {
+			Expect(store.Put(ctx, key, bytes.NewReader([]byte(content)))).To(Succeed())
+		}
+	})
+
+	It("downloads with the object's mtime", func() {
+		report, err := objsync.Pull(ctx, store, "site/", dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(Equal([]string{"site/a.txt", "site/sub/b.txt"}))
+
+		data, err := os.ReadFile(filepath.Join(dir, "sub", "b.txt"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("bravo"))
+
+		info, err := store.Stat(ctx, "site/a.txt")
+		Expect(err).ToNot(HaveOccurred())
+		fi, err := os.Stat(filepath.Join(dir, "a.txt"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fi.ModTime().Equal(info.LastModified)).To(BeTrue())
+	})
+
+	It("skips what's current the second time", func() {
+		_, err := objsync.Pull(ctx, store, "site", dir)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(store.Put(ctx, "site/a.txt", bytes.NewReader([]byte("alpha, revised")))).To(Succeed())
+
+		report, err := objsync.Pull(ctx, store, "site", dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(Equal([]string{"site/a.txt"}))
+		Expect(report.Skipped).To(Equal(1))
+	})
+
+	It("leaves partial downloads alone", func() {
+		writeFile(dir, ".a.txt.123.tmp", "alp", time.Now())
+
+		report, err := objsync.Pull(ctx, store, "site", dir, objsync.WithDelete())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Deleted).To(BeEmpty())
+
+		_, err = os.Stat(filepath.Join(dir, ".a.txt.123.tmp"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("deletes local extras when asked", func() {
+		writeFile(dir, "stale.txt", "old", time.Now())
+
+		report, err := objsync.Pull(ctx, store, "site", dir, objsync.WithDelete())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Deleted).To(Equal([]string{"site/stale.txt"}))
+		Expect(filepath.Join(dir, "stale.txt")).ToNot(BeAnExistingFile())
+	})
+
+	It("refuses keys escaping the directory", func() {
+		Expect(store.Put(ctx, "site/../../evil.txt", bytes.NewReader([]byte("x")))).To(Succeed())
+
+		report, err := objsync.Pull(ctx, store, "site", dir)
+		Expect(err).To(HaveOccurred())
+		Expect(report.Failures[0].Key).To(Equal("site/../../evil.txt"))
+	})
+})
+
+var _ = Describe("Mirror", func() {
+	var (
+		ctx = context.Background()
+		src *memstore.Store
+		dst *memstore.Store
+	)
+
+	BeforeEach(func() {
+		src = memstore.New()
+		dst = memstore.New()
+
+		Expect(src.Put(ctx, "data/a.txt", bytes.NewReader([]byte("alpha")), objsto.WithContentType("text/plain"))).To(Succeed())
+		Expect(src.Put(ctx, "data/b.txt", bytes.NewReader([]byte("bravo")))).To(Succeed())
+		Expect(dst.Put(ctx, "data/b.txt", bytes.NewReader([]byte("bravo")))).To(Succeed())
+		Expect(dst.Put(ctx, "data/extra.txt", bytes.NewReader([]byte("extra")))).To(Succeed())
+	})
+
+	It("streams what differs", func() {
+		report, err := objsync.Mirror(ctx, src, dst, "data/")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(Equal([]string{"data/a.txt"}))
+		Expect(report.Skipped).To(Equal(1))
+
+		Expect(getString(dst, "data/a.txt")).To(Equal("alpha"))
+		info, err := dst.Stat(ctx, "data/a.txt")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.ContentType).To(Equal("text/plain"))
+	})
+
+	It("copies what lacks a checksum when comparing checksums", func() {
+		sum := map[string]string{objsync.ChecksumKey: "abc123"}
+		Expect(src.Put(ctx, "data/b.txt", bytes.NewReader([]byte("bravo")), objsto.WithMetadata(sum))).To(Succeed())
+
+		report, err := objsync.Mirror(ctx, src, dst, "data/", objsync.WithCompare(objsync.CompareChecksum))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(Equal([]string{"data/a.txt", "data/b.txt"}))
+
+		report, err = objsync.Mirror(ctx, src, dst, "data/", objsync.WithCompare(objsync.CompareChecksum))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(Equal([]string{"data/a.txt"}))
+		Expect(report.Skipped).To(Equal(1))
+	})
+
+	It("reports the diff only on a dry run", func() {
+		report, err := objsync.Mirror(ctx, src, dst, "data/", objsync.WithDryRun(), objsync.WithDelete())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(Equal([]string{"data/a.txt"}))
+		Expect(report.Deleted).To(Equal([]string{"data/extra.txt"}))
+		Expect(dst.Len()).To(Equal(2))
+	})
+
+	It("retries failed transfers", func() {
+		failures := 1
+		dst.SetFault(func(op memstore.Op, object string) error {
+			if op == memstore.OpPut && failures > 0 {
+				failures--
+				return errors.New("flaky")
+			}
+			return nil
+		})
+
+		report, err := objsync.Mirror(ctx, src, dst, "data/", objsync.WithRetry(objsto.Backoff{MaxAttempts: 3}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(Equal([]string{"data/a.txt"}))
+	})
+
+	It("does not retry what would fail the same", func() {
+		puts := 0
+		dst.SetFault(func(op memstore.Op, object string) error {
+			if op == memstore.OpPut {
+				puts++
+				return errors.Wrap(objsto.ErrPreconditionFailed, "if-none-match")
+			}
+			return nil
+		})
+
+		_, err := objsync.Mirror(ctx, src, dst, "data/", objsync.WithRetry(objsto.Backoff{MaxAttempts: 3}))
+		Expect(err).To(MatchError(ContainSubstring("precondition failed")))
+		Expect(puts).To(Equal(1))
+	})
+
+	When("both are clients on the same endpoint", func() {
+		It("copies server-side", func() {
+			var requests []*http.Request
+			doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+				requests = append(requests, req)
+
+				body := ""
+				switch {
+				case req.Method == "GET" && req.URL.Path == "/src-bucket":
+					body = `<ListBucketResult><Contents><Key>data/a.txt</Key><Size>5</Size><ETag>"aaa"</ETag></Contents></ListBucketResult>`
+				case req.Method == "GET":
+					body = `<ListBucketResult></ListBucketResult>`
+				case req.Method == "PUT":
+					body = `<CopyObjectResult><ETag>"aaa"</ETag></CopyObjectResult>`
+				}
+				return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body))}, nil
+			})
+
+			cfg := &objsto.Config{Region: "r", Scheme: "https", Host: "h", Bucket: "src-bucket", AccessKey: "a", SecretKey: "s"}
+			srcClient := objsto.New(cfg, objsto.WithHTTPClient(doer))
+			dstClient := srcClient.Clone(objsto.WithBucket("dst-bucket"))
+
+			report, err := objsync.Mirror(ctx, srcClient, dstClient, "data/")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(report.Transferred).To(Equal([]string{"data/a.txt"}))
+
+			put := requests[len(requests)-1]
+			Expect(put.URL.Path).To(Equal("/dst-bucket/data/a.txt"))
+			Expect(put.Header.Get("X-Amz-Copy-Source")).To(Equal("src-bucket/data/a.txt"))
+		})
+	})
+})
+
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (df doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return df(req)
+}
+
+var _ = Describe("Manifest", func() {
+	var (
+		ctx   = context.Background()
+		dir   string
+		store *memstore.Store
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		store = memstore.New()
+
+		writeFile(dir, "one.csv", "a,b\n1,2\n", time.Now())
+		writeFile(dir, "two.json", `{"x":1}`, time.Now())
+	})
+
+	It("reads csv with metadata columns", func() {
+		manifest, err := objsync.ReadManifestCSV(strings.NewReader(
+			"path,key,content_type,meta:owner,meta:team\n" +
+				"one.csv,pub/one.csv,text/csv,bob,\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest).To(Equal(objsync.Manifest{{
+			Path:        "one.csv",
+			Key:         "pub/one.csv",
+			ContentType: "text/csv",
+			Metadata:    map[string]string{"owner": "bob"},
+		}}))
+	})
+
+	It("rejects csv without a key column", func() {
+		_, err := objsync.ReadManifestCSV(strings.NewReader("path\none.csv\n"))
+		Expect(err).To(MatchError(ContainSubstring(`no "key" column`)))
+	})
+
+	It("rejects json entries missing a key", func() {
+		_, err := objsync.ReadManifestJSON(strings.NewReader(`[{"path": "one.csv"}]`))
+		Expect(err).To(MatchError(ContainSubstring("entry 1 needs both")))
+	})
+
+	It("uploads the entries", func() {
+		manifest, err := objsync.ReadManifestJSON(strings.NewReader(`[
+			{"path": "` + filepath.Join(dir, "one.csv") + `", "key": "pub/one.csv", "content_type": "text/csv"},
+			{"path": "` + filepath.Join(dir, "two.json") + `", "key": "pub/two.json", "metadata": {"owner": "bob"}},
+			{"path": "` + filepath.Join(dir, "missing") + `", "key": "pub/missing"}
+		]`))
+		Expect(err).ToNot(HaveOccurred())
+
+		report, err := objsync.Upload(ctx, store, manifest)
+		Expect(err).To(MatchError(ContainSubstring("1 failed")))
+		Expect(report.Transferred).To(Equal([]string{"pub/one.csv", "pub/two.json"}))
+		Expect(report.Failures[0].Key).To(Equal("pub/missing"))
+
+		info, err := store.Stat(ctx, "pub/two.json")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Metadata).To(Equal(map[string]string{"owner": "bob"}))
+	})
+})
+
+var _ = Describe("Replicator", func() {
+	var (
+		ctx        = context.Background()
+		src        *memstore.Store
+		dst        *memstore.Store
+		checkpoint objsync.StoreCheckpoint
+		rp         *objsync.Replicator
+	)
+
+	BeforeEach(func() {
+		src = memstore.New()
+		dst = memstore.New()
+		checkpoint = objsync.StoreCheckpoint{Store: dst, Key: "_replica/checkpoint"}
+
+		Expect(src.Put(ctx, "data/a.txt", bytes.NewReader([]byte("alpha")))).To(Succeed())
+		Expect(src.Put(ctx, "data/b.txt", bytes.NewReader([]byte("bravo")))).To(Succeed())
+
+		rp = objsync.NewReplicator(src, dst, "data/", checkpoint)
+	})
+
+	It("copies what's new since the checkpoint", func() {
+		report, err := rp.Pass(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(Equal([]string{"data/a.txt", "data/b.txt"}))
+
+		mark, err := checkpoint.Load(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mark).ToNot(BeZero())
+
+		Expect(src.Put(ctx, "data/c.txt", bytes.NewReader([]byte("charlie")))).To(Succeed())
+
+		report, err = rp.Pass(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(Equal([]string{"data/c.txt"}))
+		Expect(report.Skipped).To(Equal(2))
+		Expect(getString(dst, "data/c.txt")).To(Equal("charlie"))
+	})
+
+	It("picks up failures on the next pass", func() {
+		dst.SetFault(func(op memstore.Op, object string) error {
+			if op == memstore.OpPut && object == "data/b.txt" {
+				return errors.New("boom")
+			}
+			return nil
+		})
+
+		_, err := rp.Pass(ctx)
+		Expect(err).To(MatchError(ContainSubstring("1 failed")))
+
+		dst.SetFault(nil)
+
+		report, err := rp.Pass(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(Equal([]string{"data/b.txt"}))
+	})
+
+	It("runs until canceled", func() {
+		runCtx, cancel := context.WithCancel(ctx)
+		passes := 0
+
+		rp = objsync.NewReplicator(src, dst, "data/", checkpoint,
+			objsync.WithInterval(time.Millisecond),
+			objsync.WithPassFunc(func(report objsync.Report, err error) {
+				Expect(err).ToNot(HaveOccurred())
+				passes++
+				if passes == 2 {
+					cancel()
+				}
+			}),
+		)
+
+		err := rp.Run(runCtx)
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		Expect(passes).To(Equal(2))
+		Expect(dst.Len()).To(Equal(3))
+	})
+})
+
+var _ = Describe("Snapshot", func() {
+	var (
+		ctx   = context.Background()
+		store *memstore.Store
+	)
+
+	BeforeEach(func() {
+		store = memstore.New()
+
+		meta := objsto.WithMetadata(map[string]string{"owner": "bob"})
+		Expect(store.Put(ctx, "data/a.txt", bytes.NewReader([]byte("alpha")), objsto.WithContentType("text/plain"), meta)).To(Succeed())
+		Expect(store.Put(ctx, "data/sub/b.txt", bytes.NewReader([]byte("bravo")))).To(Succeed())
+		Expect(store.Put(ctx, "other/c.txt", bytes.NewReader([]byte("charlie")))).To(Succeed())
+	})
+
+	readTar := func(reader io.Reader) (hdrs []*tar.Header, contents []string) {
+
+		tr := tar.NewReader(reader)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return
+			}
+			Expect(err).ToNot(HaveOccurred())
+
+			data, err := io.ReadAll(tr)
+			Expect(err).ToNot(HaveOccurred())
+			hdrs = append(hdrs, hdr)
+			contents = append(contents, string(data))
+		}
+	}
+
+	It("writes a gzipped tar with keys and metadata", func() {
+		buf := &bytes.Buffer{}
+		report, err := objsync.Snapshot(ctx, store, "data/", buf, objsync.WithGzip())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(Equal([]string{"data/a.txt", "data/sub/b.txt"}))
+		Expect(report.Bytes).To(Equal(int64(10)))
+
+		gzr, err := gzip.NewReader(buf)
+		Expect(err).ToNot(HaveOccurred())
+
+		hdrs, contents := readTar(gzr)
+		Expect(hdrs).To(HaveLen(2))
+		Expect(hdrs[0].Name).To(Equal("data/a.txt"))
+		Expect(hdrs[0].PAXRecords).To(HaveKeyWithValue("OBJSTO.content_type", "text/plain"))
+		Expect(hdrs[0].PAXRecords).To(HaveKeyWithValue("OBJSTO.meta.owner", "bob"))
+		Expect(hdrs[1].Name).To(Equal("data/sub/b.txt"))
+		Expect(contents).To(Equal([]string{"alpha", "bravo"}))
+	})
+
+	It("puts the snapshot as an object", func() {
+		dst := memstore.New()
+
+		report, err := objsync.SnapshotTo(ctx, store, "data/", dst, "backup/data.tar")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(HaveLen(2))
+
+		info, err := dst.Stat(ctx, "backup/data.tar")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.ContentType).To(Equal("application/x-tar"))
+
+		_, contents := readTar(strings.NewReader(getString(dst, "backup/data.tar")))
+		Expect(contents).To(Equal([]string{"alpha", "bravo"}))
+	})
+})
+
+var _ = Describe("Archive", func() {
+	var (
+		ctx   = context.Background()
+		src   *memstore.Store
+		dst   *memstore.Store
+		stamp = time.Now().UTC().Truncate(time.Second)
+	)
+
+	BeforeEach(func() {
+		src = memstore.New()
+		dst = memstore.New()
+
+		meta := objsto.WithMetadata(map[string]string{"owner": "bob"})
+		Expect(src.Put(ctx, "data/a.txt", bytes.NewReader([]byte("alpha")), objsto.WithContentType("text/plain"), meta)).To(Succeed())
+		Expect(src.Put(ctx, "data/sub/b.txt", bytes.NewReader([]byte("bravo")))).To(Succeed())
+	})
+
+	expectRestored := func() {
+
+		Expect(getString(dst, "restored/a.txt")).To(Equal("alpha"))
+		Expect(getString(dst, "restored/sub/b.txt")).To(Equal("bravo"))
+
+		info, err := dst.Stat(ctx, "restored/a.txt")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.ContentType).To(Equal("text/plain"))
+		Expect(info.Metadata).To(Equal(map[string]string{"owner": "bob"}))
+	}
+
+	It("round trips a gzipped tar to another prefix", func() {
+		buf := &bytes.Buffer{}
+		_, err := objsync.Pack(ctx, src, "data", buf, objsync.WithGzip())
+		Expect(err).ToNot(HaveOccurred())
+
+		report, err := objsync.ExtractArchive(ctx, dst, buf, "restored")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(Equal([]string{"restored/a.txt", "restored/sub/b.txt"}))
+		expectRestored()
+	})
+
+	It("round trips a zip to another prefix", func() {
+		buf := &bytes.Buffer{}
+		_, err := objsync.Pack(ctx, src, "data/", buf, objsync.WithZip())
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = objsync.ExtractArchive(ctx, dst, bytes.NewReader(buf.Bytes()), "restored")
+		Expect(err).To(MatchError(ContainSubstring("use ExtractZip")))
+
+		report, err := objsync.ExtractZip(ctx, dst, bytes.NewReader(buf.Bytes()), int64(buf.Len()), "restored/")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Transferred).To(HaveLen(2))
+		expectRestored()
+	})
+
+	It("refuses entries escaping the prefix", func() {
+		buf := &bytes.Buffer{}
+		tw := tar.NewWriter(buf)
+		for _, name := range []string{"ok.txt", "../evil.txt"} {
+			Expect(tw.WriteHeader(&tar.Header{Name: name, Size: 2, Mode: 0644, ModTime: stamp})).To(Succeed())
+			_, err := tw.Write([]byte("hi"))
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(tw.Close()).To(Succeed())
+
+		report, err := objsync.ExtractArchive(ctx, dst, buf, "restored")
+		Expect(err).To(MatchError(ContainSubstring("1 failed")))
+		Expect(report.Transferred).To(Equal([]string{"restored/ok.txt"}))
+		Expect(dst.Len()).To(Equal(1))
+	})
+})
@@ -0,0 +1,167 @@
+package objsync
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+)
+
+// Pull downloads new and changed objects under prefix, taken to be a "directory", into dir.
+// Downloaded files get the object's Last-Modified as mtime, which is how they're later known to be current.
+// With WithDelete, local files having no object are deleted.
+//
+// Keys that would land outside dir are reported as failures.
+func Pull(ctx context.Context, store objsto.ObjectStore, prefix, dir string, opts ...Option) (report Report, err error) {
+
+	start := time.Now()
+	o := newOptions(opts)
+	prefix = dirPrefix(prefix)
+
+	remote, err := listInfos(ctx, store, prefix)
+	if err != nil {
+		return
+	}
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to create %q", dir)
+		return
+	}
+
+	locals, err := walk(dir, prefix)
+	if err != nil {
+		return
+	}
+	byKey := map[string]localFile{}
+	for _, local := range locals {
+		byKey[local.key] = local
+	}
+
+	tl := &tally{}
+	var todo []objsto.ObjectInfo
+	for _, key := range sortedKeys(remote) {
+		info := remote[key]
+		if strings.HasSuffix(key, "/") {
+			continue
+		}
+
+		local, ok := byKey[key]
+		delete(byKey, key)
+
+		// downloads get the object's mtime
+		mtimeOk := func() bool {
+			return local.mtime.Truncate(time.Second).Equal(info.LastModified.Truncate(time.Second))
+		}
+
+		if ok && o.matches(ctx, store, local, info, mtimeOk) {
+			tl.skipped()
+			continue
+		}
+		todo = append(todo, info)
+	}
+
+	stopped := each(ctx, o.concurrency, todo, func(info objsto.ObjectInfo) {
+		rel := strings.TrimPrefix(info.Key, prefix)
+		if !filepath.IsLocal(filepath.FromSlash(rel)) {
+			tl.failed(info.Key, errors.Errorf("key is outside of %q", dir))
+			return
+		}
+
+		if !o.dryRun {
+			err := o.attempt(ctx, func() error {
+				return download(ctx, store, info, filepath.Join(dir, filepath.FromSlash(rel)))
+			})
+			if err != nil {
+				tl.failed(info.Key, err)
+				return
+			}
+		}
+		tl.transferred(info.Key, info.Size)
+	})
+
+	if o.delete && stopped == nil {
+		for _, local := range byKey {
+			if !o.dryRun {
+				err := os.Remove(local.path)
+				if err != nil {
+					tl.failed(local.key, errors.Wrapf(err, "failed to remove %q", local.path))
+					continue
+				}
+			}
+			tl.deleted(local.key)
+		}
+	}
+
+	report, err = tl.done(start, stopped)
+	return
+}
+
+// unexported
+
+// download writes to a temp file renamed into place, so a partial file never appears at path.
+func download(ctx context.Context, store objsto.ObjectStore, info objsto.ObjectInfo, path string) (err error) {
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to create directory for %q", path)
+		return
+	}
+
+	reader, err := store.Get(ctx, info.Key)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		err = errors.Wrapf(err, "failed to create temp file for %q", path)
+		return
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	_, err = io.Copy(tmp, reader)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to download %q", info.Key)
+		return
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		err = errors.Wrapf(err, "failed to close %q", tmp.Name())
+		return
+	}
+
+	err = os.Chmod(tmp.Name(), 0644)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to chmod %q", tmp.Name())
+		return
+	}
+
+	if !info.LastModified.IsZero() {
+		err = os.Chtimes(tmp.Name(), info.LastModified, info.LastModified)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to set mtime on %q", tmp.Name())
+			return
+		}
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to rename into %q", path)
+	}
+
+	return
+}
@@ -0,0 +1,178 @@
+package objsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+)
+
+// Push uploads new and changed files under dir to prefix, taken to be a "directory".
+// With WithDelete, objects under prefix having no local file are deleted.
+func Push(ctx context.Context, store objsto.ObjectStore, dir, prefix string, opts ...Option) (report Report, err error) {
+
+	start := time.Now()
+	o := newOptions(opts)
+	prefix = dirPrefix(prefix)
+
+	remote, err := listInfos(ctx, store, prefix)
+	if err != nil {
+		return
+	}
+
+	locals, err := walk(dir, prefix)
+	if err != nil {
+		return
+	}
+
+	tl := &tally{}
+	var todo []localFile
+	for _, local := range locals {
+		info, ok := remote[local.key]
+		delete(remote, local.key)
+
+		// an upload is stamped when it lands, so unchanged files are never newer
+		mtimeOk := func() bool { return !local.mtime.Truncate(time.Second).After(info.LastModified) }
+
+		if ok && o.matches(ctx, store, local, info, mtimeOk) {
+			tl.skipped()
+			continue
+		}
+		todo = append(todo, local)
+	}
+
+	stopped := each(ctx, o.concurrency, todo, func(local localFile) {
+		if !o.dryRun {
+			putOpts, err := o.withSum(local)
+			if err != nil {
+				tl.failed(local.key, err)
+				return
+			}
+
+			err = o.attempt(ctx, func() error {
+				return upload(ctx, store, local, putOpts)
+			})
+			if err != nil {
+				tl.failed(local.key, err)
+				return
+			}
+		}
+		tl.transferred(local.key, local.size)
+	})
+
+	if o.delete && stopped == nil {
+		stopped = each(ctx, o.concurrency, sortedKeys(remote), func(key string) {
+			if !o.dryRun {
+				err := store.Delete(ctx, key)
+				if err != nil {
+					tl.failed(key, err)
+					return
+				}
+			}
+			tl.deleted(key)
+		})
+	}
+
+	report, err = tl.done(start, stopped)
+	return
+}
+
+// unexported
+
+type localFile struct {
+	path  string
+	key   string
+	size  int64
+	mtime time.Time
+}
+
+func walk(dir, prefix string) (locals []localFile, err error) {
+
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		// partial downloads, left by an interrupted pull
+		if strings.HasPrefix(entry.Name(), ".") && strings.HasSuffix(entry.Name(), ".tmp") {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		locals = append(locals, localFile{
+			path:  path,
+			key:   prefix + filepath.ToSlash(rel),
+			size:  info.Size(),
+			mtime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		err = errors.Wrapf(err, "failed to walk %q", dir)
+	}
+
+	return
+}
+
+func upload(ctx context.Context, store objsto.ObjectStore, local localFile, opts []objsto.PutOption) (err error) {
+
+	file, err := os.Open(local.path)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to open %q", local.path)
+		return
+	}
+	defer file.Close()
+
+	err = store.Put(ctx, local.key, file, opts...)
+	return
+}
+
+// withSum adds the checksum metadata when comparing checksums.
+func (o options) withSum(local localFile) (putOpts []objsto.PutOption, err error) {
+
+	putOpts = o.putOpts
+	if o.compare != CompareChecksum {
+		return
+	}
+
+	sum, err := fileSum(local.path, sha256.New())
+	if err != nil {
+		err = errors.Wrapf(err, "failed to checksum %q", local.path)
+		return
+	}
+
+	putOpts = append(slices.Clone(putOpts), objsto.WithMetadata(map[string]string{ChecksumKey: sum}))
+	return
+}
+
+func dirPrefix(prefix string) string {
+
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+func sortedKeys(infos map[string]objsto.ObjectInfo) []string {
+
+	return slices.Sorted(maps.Keys(infos))
+}
@@ -0,0 +1,237 @@
+package objsync
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+)
+
+// DefaultInterval is the time between replication passes by default.
+const DefaultInterval = time.Minute
+
+// WithInterval sets the time between replication passes, defaulting to DefaultInterval.
+func WithInterval(interval time.Duration) Option {
+
+	return func(opts *options) {
+		opts.interval = interval
+	}
+}
+
+// WithPassFunc is called with the report and error of each replication pass, as for logging.
+func WithPassFunc(fn func(report Report, err error)) Option {
+
+	return func(opts *options) {
+		opts.passFunc = fn
+	}
+}
+
+// Checkpoint persists how far a Replicator has gotten, so that it survives restarts.
+type Checkpoint interface {
+	// Load returns the saved mark, zero when there is none.
+	Load(ctx context.Context) (mark time.Time, err error)
+	// Save saves the mark.
+	Save(ctx context.Context, mark time.Time) (err error)
+}
+
+// StoreCheckpoint is a Checkpoint kept as an object, such as in the destination.
+type StoreCheckpoint struct {
+	Store objsto.ObjectStore
+	Key   string
+}
+
+// Load gets and parses the checkpoint object.
+func (sc StoreCheckpoint) Load(ctx context.Context) (mark time.Time, err error) {
+
+	reader, err := sc.Store.Get(ctx, sc.Key)
+	if errors.Is(err, objsto.ErrNotFound) {
+		err = nil
+		return
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "failed to get checkpoint %q", sc.Key)
+		return
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to read checkpoint %q", sc.Key)
+		return
+	}
+
+	mark, err = time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+	if err != nil {
+		err = errors.Wrapf(err, "failed to parse checkpoint %q", sc.Key)
+	}
+
+	return
+}
+
+// Save puts the checkpoint object.
+func (sc StoreCheckpoint) Save(ctx context.Context, mark time.Time) (err error) {
+
+	data := []byte(mark.UTC().Format(time.RFC3339Nano))
+
+	err = sc.Store.Put(ctx, sc.Key, bytes.NewReader(data), objsto.WithContentType("text/plain"))
+	if err != nil {
+		err = errors.Wrapf(err, "failed to save checkpoint %q", sc.Key)
+	}
+
+	return
+}
+
+// Replicator repeatedly copies new and changed objects under a prefix from src to dst.
+//
+// Each pass lists src and copies objects modified since the checkpoint's mark,
+// then advances the mark past what was copied, holding it at the first failure so that's retried.
+// Objects modified at the mark are checked against dst, as the same second can hold more than one.
+// Deletes are not replicated, and WithDelete is ignored.
+type Replicator struct {
+	src        objsto.ObjectStore
+	dst        objsto.ObjectStore
+	prefix     string
+	checkpoint Checkpoint
+	opts       options
+	mu         sync.Mutex
+}
+
+// NewReplicator creates a Replicator, with checkpoint kept perhaps as a StoreCheckpoint in dst.
+func NewReplicator(src, dst objsto.ObjectStore, prefix string, checkpoint Checkpoint, opts ...Option) *Replicator {
+
+	o := newOptions(opts)
+	if o.interval <= 0 {
+		o.interval = DefaultInterval
+	}
+
+	return &Replicator{
+		src:        src,
+		dst:        dst,
+		prefix:     prefix,
+		checkpoint: checkpoint,
+		opts:       o,
+	}
+}
+
+// Run makes passes until ctx is done, returning its error.
+// A failed pass is reported to the pass func and tried again after the interval.
+func (rp *Replicator) Run(ctx context.Context) (err error) {
+
+	for {
+		report, passErr := rp.Pass(ctx)
+		if rp.opts.passFunc != nil {
+			rp.opts.passFunc(report, passErr)
+		}
+
+		timer := time.NewTimer(rp.opts.interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			err = ctx.Err()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Pass makes a single replication pass, saving the checkpoint unless a dry run.
+func (rp *Replicator) Pass(ctx context.Context) (report Report, err error) {
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	start := time.Now()
+	o := rp.opts
+
+	since, err := rp.checkpoint.Load(ctx)
+	if err != nil {
+		return
+	}
+
+	infos, err := listInfos(ctx, rp.src, rp.prefix)
+	if err != nil {
+		return
+	}
+
+	tl := &tally{}
+	mark := since
+	var todo []objsto.ObjectInfo
+	for _, key := range sortedKeys(infos) {
+		info := infos[key]
+
+		if info.LastModified.After(mark) {
+			mark = info.LastModified
+		}
+		if info.LastModified.Before(since) || rp.replicated(ctx, since, info) {
+			tl.skipped()
+			continue
+		}
+		todo = append(todo, info)
+	}
+
+	copier := newCopier(rp.src, rp.dst)
+
+	var failedMu sync.Mutex
+	failedMark := time.Time{}
+
+	stopped := each(ctx, o.concurrency, todo, func(info objsto.ObjectInfo) {
+		if !o.dryRun {
+			err := o.attempt(ctx, func() error {
+				return copier(ctx, info)
+			})
+			if err != nil {
+				tl.failed(info.Key, err)
+
+				failedMu.Lock()
+				if failedMark.IsZero() || info.LastModified.Before(failedMark) {
+					failedMark = info.LastModified
+				}
+				failedMu.Unlock()
+				return
+			}
+		}
+		tl.transferred(info.Key, info.Size)
+	})
+
+	if !failedMark.IsZero() {
+		mark = failedMark
+	}
+	if stopped != nil {
+		// not all were attempted
+		mark = since
+	}
+
+	report, err = tl.done(start, stopped)
+
+	if !o.dryRun && mark.After(since) {
+		saveErr := rp.checkpoint.Save(ctx, mark)
+		if err == nil {
+			err = saveErr
+		}
+	}
+
+	return
+}
+
+// unexported
+
+// replicated checks dst for an object modified at the mark, as it may have been copied last pass.
+func (rp *Replicator) replicated(ctx context.Context, since time.Time, info objsto.ObjectInfo) bool {
+
+	if since.IsZero() || info.LastModified.After(since) {
+		return false
+	}
+
+	have, err := rp.dst.Stat(ctx, info.Key)
+	if err != nil {
+		return false
+	}
+
+	return rp.opts.mirrored(ctx, rp.src, rp.dst, info, have)
+}
@@ -0,0 +1,65 @@
+package objsync
+
+import (
+	"context"
+	"io"
+
+	"github.com/clarktrimble/objsto"
+)
+
+// WithGzip compresses tar archives.
+func WithGzip() Option {
+
+	return func(opts *options) {
+		opts.gzip = true
+	}
+}
+
+// WithZip writes zip rather than tar archives.
+func WithZip() Option {
+
+	return func(opts *options) {
+		opts.zip = true
+	}
+}
+
+// Snapshot writes objects under prefix to writer as a tar, gzipped with WithGzip, or a zip with WithZip.
+// Entries are named for keys, with content type and metadata kept as PAX records,
+// or as JSON in entry comments for zip.
+//
+// Objects are read one at a time, in key order, and objects gone by the time they're read are skipped.
+func Snapshot(ctx context.Context, store objsto.ObjectStore, prefix string, writer io.Writer, opts ...Option) (report Report, err error) {
+
+	report, err = pack(ctx, store, prefix, "", writer, newOptions(opts))
+	return
+}
+
+// SnapshotTo puts a snapshot of objects under prefix in src to key in dst, by way of a temp file.
+// Options from WithPutOptions apply to the snapshot object.
+func SnapshotTo(ctx context.Context, src objsto.ObjectStore, prefix string, dst objsto.ObjectStore, key string, opts ...Option) (report Report, err error) {
+
+	o := newOptions(opts)
+
+	contentType := "application/x-tar"
+	switch {
+	case o.zip:
+		contentType = "application/zip"
+	case o.gzip:
+		contentType = "application/gzip"
+	}
+	putOpts := append([]objsto.PutOption{objsto.WithContentType(contentType)}, o.putOpts...)
+
+	reader, writer := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var snapErr error
+		report, snapErr = pack(ctx, src, prefix, "", writer, o)
+		writer.CloseWithError(snapErr)
+	}()
+
+	err = putSpooled(ctx, dst, key, reader, putOpts)
+	reader.CloseWithError(err)
+	<-done
+	return
+}
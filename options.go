@@ -0,0 +1,358 @@
+package objsto
+
+import (
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PutOptions are optional settings for putting an object.
+// ObjectStore implementations other than Client can read them via NewPutOptions.
+type PutOptions struct {
+	ContentType     string
+	ContentEncoding string
+	Metadata        map[string]string
+	StorageClass    string
+	ACL             string
+	SSE             string
+	SSEKMSKeyID     string
+	Tags            map[string]string
+	IfMatch         string
+	IfNoneMatch     string
+	Result          *PutResult
+}
+
+// PutOption sets an optional setting for putting an object.
+type PutOption func(*PutOptions)
+
+// NewPutOptions applies opts to a zero PutOptions.
+func NewPutOptions(opts ...PutOption) (po PutOptions) {
+
+	for _, opt := range opts {
+		opt(&po)
+	}
+
+	return
+}
+
+// WithContentType sets the Content-Type of the object.
+func WithContentType(contentType string) PutOption {
+
+	return func(po *PutOptions) {
+		po.ContentType = contentType
+	}
+}
+
+// WithContentEncoding sets the Content-Encoding of the object, such as "gzip".
+func WithContentEncoding(encoding string) PutOption {
+
+	return func(po *PutOptions) {
+		po.ContentEncoding = encoding
+	}
+}
+
+// WithMetadata adds user metadata, sent as x-amz-meta-* headers.
+func WithMetadata(metadata map[string]string) PutOption {
+
+	return func(po *PutOptions) {
+		if po.Metadata == nil {
+			po.Metadata = map[string]string{}
+		}
+		maps.Copy(po.Metadata, metadata)
+	}
+}
+
+// WithStorageClass sets the storage class, such as "STANDARD_IA".
+func WithStorageClass(class string) PutOption {
+
+	return func(po *PutOptions) {
+		po.StorageClass = class
+	}
+}
+
+// WithACL sets a canned ACL, such as "public-read".
+func WithACL(acl string) PutOption {
+
+	return func(po *PutOptions) {
+		po.ACL = acl
+	}
+}
+
+// WithSSE sets server side encryption, such as "AES256".
+func WithSSE(algorithm string) PutOption {
+
+	return func(po *PutOptions) {
+		po.SSE = algorithm
+	}
+}
+
+// WithSSEKMS sets server side encryption with the given KMS key.
+func WithSSEKMS(keyID string) PutOption {
+
+	return func(po *PutOptions) {
+		po.SSE = "aws:kms"
+		po.SSEKMSKeyID = keyID
+	}
+}
+
+// WithTags adds object tags.
+func WithTags(tags map[string]string) PutOption {
+
+	return func(po *PutOptions) {
+		if po.Tags == nil {
+			po.Tags = map[string]string{}
+		}
+		maps.Copy(po.Tags, tags)
+	}
+}
+
+// WithIfMatch puts only if the object's current ETag is etag,
+// failing with an error satisfying errors.Is(err, ErrPreconditionFailed) otherwise.
+func WithIfMatch(etag string) PutOption {
+
+	return func(po *PutOptions) {
+		po.IfMatch = etag
+	}
+}
+
+// WithIfNoneMatch puts only if the object's current ETag is not etag, or with "*" only if there is none,
+// failing with an error satisfying errors.Is(err, ErrPreconditionFailed) otherwise.
+func WithIfNoneMatch(etag string) PutOption {
+
+	return func(po *PutOptions) {
+		po.IfNoneMatch = etag
+	}
+}
+
+// WithResult captures the result of a successful put into res.
+func WithResult(res *PutResult) PutOption {
+
+	return func(po *PutOptions) {
+		po.Result = res
+	}
+}
+
+// Record stores res for a caller having passed WithResult, for use by ObjectStore implementations.
+func (po PutOptions) Record(res PutResult) {
+
+	if po.Result != nil {
+		*po.Result = res
+	}
+}
+
+// Check returns an error satisfying errors.Is(err, ErrPreconditionFailed) if the conditions set with
+// WithIfMatch and WithIfNoneMatch don't hold for the current etag, blank when there's no object,
+// for use by ObjectStore implementations.
+func (po PutOptions) Check(object, etag string) (err error) {
+
+	switch {
+	case po.IfMatch != "" && (etag == "" || (po.IfMatch != "*" && unquote(po.IfMatch) != etag)):
+		err = errors.Wrapf(ErrPreconditionFailed, "%q does not match %s", object, po.IfMatch)
+	case po.IfNoneMatch == "*" && etag != "":
+		err = errors.Wrapf(ErrPreconditionFailed, "%q exists", object)
+	case po.IfNoneMatch != "" && po.IfNoneMatch != "*" && unquote(po.IfNoneMatch) == etag:
+		err = errors.Wrapf(ErrPreconditionFailed, "%q matches %s", object, po.IfNoneMatch)
+	}
+
+	return
+}
+
+// GetOptions are optional settings for getting an object.
+type GetOptions struct {
+	PreserveMtime bool
+}
+
+// GetOption sets an optional setting for getting an object.
+type GetOption func(*GetOptions)
+
+// NewGetOptions applies opts to a zero GetOptions.
+func NewGetOptions(opts ...GetOption) (gopts GetOptions) {
+
+	for _, opt := range opts {
+		opt(&gopts)
+	}
+
+	return
+}
+
+// WithMtime sets a downloaded file's mtime to the object's Last-Modified.
+func WithMtime() GetOption {
+
+	return func(gopts *GetOptions) {
+		gopts.PreserveMtime = true
+	}
+}
+
+// ClientOption sets an optional Client setting.
+type ClientOption func(*Client)
+
+// WithLogger sets the logger, nil for none.
+func WithLogger(lgr Logger) ClientOption {
+
+	return func(c *Client) {
+		c.logger = lgr
+	}
+}
+
+// WithHTTPClient sets the HttpDoer, defaulting to NewHTTPClient(nil).
+func WithHTTPClient(client HttpDoer) ClientOption {
+
+	return func(c *Client) {
+		c.client = client
+		c.owned = false
+	}
+}
+
+// WithRetryPolicy sets the retry policy, defaulting to NoRetry.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithClock sets the clock used for signing.
+func WithClock(clock Clock) ClientOption {
+
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// WithBucket sets the bucket, replacing that from Config.
+func WithBucket(bucket string) ClientOption {
+
+	return func(c *Client) {
+		c.bucket = bucket
+	}
+}
+
+// WithKeyPrefix sets a prefix added to object keys and list prefixes, and trimmed from listed keys.
+func WithKeyPrefix(prefix string) ClientOption {
+
+	return func(c *Client) {
+		c.prefix = prefix
+	}
+}
+
+// WithKeyPolicy applies policy to object keys as requests are made, failing those with invalid keys.
+func WithKeyPolicy(policy KeyPolicy) ClientOption {
+
+	return func(c *Client) {
+		c.policy = &policy
+	}
+}
+
+// WithTimeout limits each operation, including reading a response body, zero for no limit.
+func WithTimeout(timeout time.Duration) ClientOption {
+
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithUserAgent sets a suffix for the User-Agent, such as "myapp/2.1", replacing any from Config.
+func WithUserAgent(suffix string) ClientOption {
+
+	return func(c *Client) {
+		c.agent = userAgent(suffix)
+	}
+}
+
+// WithAcceptGzip sends Accept-Encoding: gzip with gets, other than ranged, and gunzips responses served compressed,
+// as by a CDN. GetInto reports both the size received and the size decompressed.
+func WithAcceptGzip() ClientOption {
+
+	return func(c *Client) {
+		c.gzip = true
+	}
+}
+
+// WithDebugSampling logs the debug lines of one request in every, such as 100 at high volume,
+// or none when less than one. All are logged by default.
+func WithDebugSampling(every int) ClientOption {
+
+	return func(c *Client) {
+		c.debug = every
+	}
+}
+
+// WithHooks adds request hooks, called in the order added.
+func WithHooks(hooks Hooks) ClientOption {
+
+	return func(c *Client) {
+		c.hooks = append(c.hooks, hooks)
+	}
+}
+
+// WithCredentialsProvider sets the credentials provider, replacing keys from Config.
+func WithCredentialsProvider(creds CredentialsProvider) ClientOption {
+
+	return func(c *Client) {
+		c.creds = creds
+		c.ownCreds = true
+	}
+}
+
+// unexported
+
+func (po PutOptions) header() (hdr http.Header) {
+
+	hdr = http.Header{}
+
+	if po.ContentType != "" {
+		hdr.Set("Content-Type", po.ContentType)
+	}
+	if po.ContentEncoding != "" {
+		hdr.Set("Content-Encoding", po.ContentEncoding)
+	}
+	for _, key := range slices.Sorted(maps.Keys(po.Metadata)) {
+		hdr.Set(metaPrefix+key, po.Metadata[key])
+	}
+	if po.StorageClass != "" {
+		hdr.Set("X-Amz-Storage-Class", po.StorageClass)
+	}
+	if po.ACL != "" {
+		hdr.Set("X-Amz-Acl", po.ACL)
+	}
+	if po.SSE != "" {
+		hdr.Set("X-Amz-Server-Side-Encryption", po.SSE)
+	}
+	if po.SSEKMSKeyID != "" {
+		hdr.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", po.SSEKMSKeyID)
+	}
+	if po.IfMatch != "" {
+		hdr.Set("If-Match", quote(po.IfMatch))
+	}
+	if po.IfNoneMatch != "" {
+		hdr.Set("If-None-Match", quote(po.IfNoneMatch))
+	}
+	if len(po.Tags) > 0 {
+		tags := url.Values{}
+		for key, val := range po.Tags {
+			tags.Set(key, val)
+		}
+		hdr.Set("X-Amz-Tagging", tags.Encode())
+	}
+
+	return
+}
+
+// quote quotes an etag for a conditional header, leaving "*" be.
+func quote(etag string) string {
+
+	if etag == "*" {
+		return etag
+	}
+	return `"` + unquote(etag) + `"`
+}
+
+func unquote(etag string) string {
+
+	return strings.Trim(etag, `"`)
+}
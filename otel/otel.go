@@ -0,0 +1,250 @@
+// Package otel traces objsto operations with OpenTelemetry, a span per operation,
+// with hooks propagating trace context to the object store and noting each attempt.
+package otel
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/clarktrimble/objsto"
+)
+
+// ScopeName is the instrumentation scope of tracers obtained from a provider.
+const ScopeName = "github.com/clarktrimble/objsto/otel"
+
+// Attribute keys set on spans.
+const (
+	BucketKey = attribute.Key("objsto.bucket")
+	ObjectKey = attribute.Key("objsto.key")
+	PrefixKey = attribute.Key("objsto.prefix")
+	SizeKey   = attribute.Key("objsto.size")
+	CountKey  = attribute.Key("objsto.count")
+)
+
+// Option sets an optional setting for Store or Hooks.
+type Option func(*config)
+
+// WithTracerProvider sets the provider of tracers, defaulting to the global provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+
+	return func(cfg *config) {
+		cfg.provider = provider
+	}
+}
+
+// WithPropagator sets the propagator injecting trace context, defaulting to the global propagator.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+
+	return func(cfg *config) {
+		cfg.propagator = propagator
+	}
+}
+
+// WithBucket sets the bucket attribute, defaulting to that of a store with a Bucket method, such as Client.
+func WithBucket(bucket string) Option {
+
+	return func(cfg *config) {
+		cfg.bucket = bucket
+	}
+}
+
+// Store is an objsto.ObjectStore starting a span per operation.
+// Wrapping hides optional interfaces of the store, such as objsto.RangeGetter.
+type Store struct {
+	objsto.ObjectStore
+	tracer trace.Tracer
+	bucket attribute.KeyValue
+}
+
+var _ objsto.ObjectStore = &Store{}
+
+// New creates a Store tracing operations on store.
+func New(store objsto.ObjectStore, opts ...Option) *Store {
+
+	cfg := newConfig(opts)
+	if cfg.bucket == "" {
+		if bucketer, ok := store.(interface{ Bucket() string }); ok {
+			cfg.bucket = bucketer.Bucket()
+		}
+	}
+
+	return &Store{
+		ObjectStore: store,
+		tracer:      cfg.provider.Tracer(ScopeName),
+		bucket:      BucketKey.String(cfg.bucket),
+	}
+}
+
+// Get gets an object, the span ending when the reader is closed, sized by bytes read.
+func (st *Store) Get(ctx context.Context, object string) (reader io.ReadCloser, err error) {
+
+	ctx, span := st.start(ctx, "objsto.Get", ObjectKey.String(object))
+
+	reader, err = st.ObjectStore.Get(ctx, object)
+	if err != nil {
+		end(span, err)
+		return
+	}
+
+	reader = &spanReader{ReadCloser: reader, span: span}
+	return
+}
+
+// Put puts an object.
+func (st *Store) Put(ctx context.Context, object string, reader io.ReadSeeker, opts ...objsto.PutOption) (err error) {
+
+	ctx, span := st.start(ctx, "objsto.Put", ObjectKey.String(object))
+	defer func() { end(span, err) }()
+
+	pos, err := reader.Seek(0, io.SeekCurrent)
+	if err == nil {
+		var size int64
+		size, err = reader.Seek(0, io.SeekEnd)
+		if err == nil {
+			span.SetAttributes(SizeKey.Int64(size - pos))
+			_, err = reader.Seek(pos, io.SeekStart)
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	err = st.ObjectStore.Put(ctx, object, reader, opts...)
+	return
+}
+
+// Delete deletes an object.
+func (st *Store) Delete(ctx context.Context, object string) (err error) {
+
+	ctx, span := st.start(ctx, "objsto.Delete", ObjectKey.String(object))
+	defer func() { end(span, err) }()
+
+	err = st.ObjectStore.Delete(ctx, object)
+	return
+}
+
+// List returns object keys matching prefix.
+func (st *Store) List(ctx context.Context, prefix string) (keys []string, err error) {
+
+	ctx, span := st.start(ctx, "objsto.List", PrefixKey.String(prefix))
+	defer func() { end(span, err) }()
+
+	keys, err = st.ObjectStore.List(ctx, prefix)
+	span.SetAttributes(CountKey.Int(len(keys)))
+	return
+}
+
+// Stat gets an object's info.
+func (st *Store) Stat(ctx context.Context, object string) (info objsto.ObjectInfo, err error) {
+
+	ctx, span := st.start(ctx, "objsto.Stat", ObjectKey.String(object))
+	defer func() { end(span, err) }()
+
+	info, err = st.ObjectStore.Stat(ctx, object)
+	if err == nil {
+		span.SetAttributes(SizeKey.Int64(info.Size))
+	}
+	return
+}
+
+// Hooks returns hooks injecting trace context into each request attempt and noting
+// the attempt on the current span, for use with objsto.WithHooks.
+// Injected headers go unsigned, so they're free to differ between attempts.
+func Hooks(opts ...Option) objsto.Hooks {
+
+	cfg := newConfig(opts)
+
+	return objsto.Hooks{
+		BeforeSend: func(req *http.Request) error {
+
+			cfg.propagator.Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+			return nil
+		},
+		AfterReceive: func(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+
+			attrs := []attribute.KeyValue{
+				attribute.String("http.request.method", req.Method),
+				attribute.Int64("objsto.elapsed_ms", elapsed.Milliseconds()),
+			}
+			if resp != nil {
+				attrs = append(attrs, attribute.Int("http.response.status_code", resp.StatusCode))
+			}
+			if err != nil {
+				attrs = append(attrs, attribute.String("error.message", err.Error()))
+			}
+
+			trace.SpanFromContext(req.Context()).AddEvent("objsto.attempt", trace.WithAttributes(attrs...))
+		},
+	}
+}
+
+// unexported
+
+type config struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+	bucket     string
+}
+
+func newConfig(opts []Option) *config {
+
+	cfg := &config{
+		provider:   otel.GetTracerProvider(),
+		propagator: otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+func (st *Store) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+
+	attrs = append(attrs, st.bucket)
+	return st.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+func end(span trace.Span, err error) {
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// spanReader ends its span when closed.
+type spanReader struct {
+	io.ReadCloser
+	span trace.Span
+	size int64
+	err  error
+}
+
+func (sr *spanReader) Read(buf []byte) (n int, err error) {
+
+	n, err = sr.ReadCloser.Read(buf)
+	sr.size += int64(n)
+	if err != nil && err != io.EOF {
+		sr.err = err
+	}
+	return
+}
+
+func (sr *spanReader) Close() (err error) {
+
+	err = sr.ReadCloser.Close()
+
+	sr.span.SetAttributes(SizeKey.Int64(sr.size))
+	end(sr.span, sr.err)
+	return
+}
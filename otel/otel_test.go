@@ -0,0 +1,103 @@
+package otel_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/clarktrimble/objsto"
+	objotel "github.com/clarktrimble/objsto/otel"
+)
+
+func TestOtel(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Otel Suite")
+}
+
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (fn doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+var _ = Describe("Store", func() {
+	var (
+		ctx      = context.Background()
+		recorder *tracetest.SpanRecorder
+		headers  []http.Header
+		store    *objotel.Store
+	)
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		headers = nil
+
+		doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+			headers = append(headers, req.Header.Clone())
+			if req.Method == "HEAD" {
+				return &http.Response{StatusCode: 404, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+			return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("hello"))}, nil
+		})
+
+		client := objsto.New(&objsto.Config{
+			Region:    "test-region",
+			Scheme:    "https",
+			Host:      "test-host",
+			Bucket:    "test-bucket",
+			AccessKey: "test-access-key",
+			SecretKey: "test-secret-key",
+		}, objsto.WithHTTPClient(doer), objsto.WithHooks(objotel.Hooks(objotel.WithPropagator(propagation.TraceContext{}))))
+
+		store = objotel.New(client, objotel.WithTracerProvider(provider))
+	})
+
+	It("spans each operation, propagating trace context", func() {
+		Expect(store.Put(ctx, "a.txt", strings.NewReader("abc"))).To(Succeed())
+
+		reader, err := store.Get(ctx, "a.txt")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(io.ReadAll(reader)).To(Equal([]byte("hello")))
+		Expect(reader.Close()).To(Succeed())
+
+		_, err = store.Stat(ctx, "b.txt")
+		Expect(err).To(MatchError(objsto.ErrNotFound))
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(3))
+
+		attrs := func(span sdktrace.ReadOnlySpan) map[string]string {
+			got := map[string]string{}
+			for _, kv := range span.Attributes() {
+				got[string(kv.Key)] = kv.Value.Emit()
+			}
+			return got
+		}
+
+		Expect(spans[0].Name()).To(Equal("objsto.Put"))
+		Expect(attrs(spans[0])).To(Equal(map[string]string{
+			"objsto.key": "a.txt", "objsto.bucket": "test-bucket", "objsto.size": "3",
+		}))
+		Expect(spans[1].Name()).To(Equal("objsto.Get"))
+		Expect(attrs(spans[1])).To(HaveKeyWithValue("objsto.size", "5"))
+		Expect(spans[2].Status().Code).To(Equal(codes.Error))
+
+		Expect(spans[0].Events()).To(HaveLen(1))
+		Expect(spans[0].Events()[0].Name).To(Equal("objsto.attempt"))
+
+		for i, span := range spans {
+			traceparent := headers[i].Get("Traceparent")
+			Expect(traceparent).To(ContainSubstring(span.SpanContext().TraceID().String()))
+		}
+	})
+})
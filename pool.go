@@ -0,0 +1,68 @@
+package objsto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"hash"
+	"sync"
+)
+
+// pools for the per-request hot path, avoiding allocs when signing and hashing
+
+const (
+	copyBufSize  = 32 * 1024
+	maxPooledBuf = 64 * 1024
+)
+
+var (
+	hasherPool = sync.Pool{
+		New: func() any { return sha256.New() },
+	}
+	bufferPool = sync.Pool{
+		New: func() any { return new(bytes.Buffer) },
+	}
+	copyBufPool = sync.Pool{
+		New: func() any {
+			buf := make([]byte, copyBufSize)
+			return &buf
+		},
+	}
+)
+
+func getHasher() hash.Hash {
+
+	hsh := hasherPool.Get().(hash.Hash)
+	hsh.Reset()
+	return hsh
+}
+
+func putHasher(hsh hash.Hash) {
+
+	hasherPool.Put(hsh)
+}
+
+func getBuffer() *bytes.Buffer {
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putBuffer(buf *bytes.Buffer) {
+
+	// don't hang on to the odd giant
+	if buf.Cap() > maxPooledBuf {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+func getCopyBuf() *[]byte {
+
+	return copyBufPool.Get().(*[]byte)
+}
+
+func putCopyBuf(buf *[]byte) {
+
+	copyBufPool.Put(buf)
+}
@@ -0,0 +1,96 @@
+package objsto
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MaxPresignExpiry is the longest a presigned url may last.
+const MaxPresignExpiry = 7 * 24 * time.Hour
+
+// Presign returns a url allowing method on object, such as GET or PUT, to whoever holds it
+// until expires has passed, for a browser or other client without credentials to use directly.
+func (c *Client) Presign(ctx context.Context, method, object string, expires time.Duration) (uri string, err error) {
+
+	if object == "" {
+		err = errors.Errorf("object cannot be blank")
+		return
+	}
+	if expires <= 0 || expires > MaxPresignExpiry {
+		err = errors.Errorf("expiry must be positive and at most %s, got %s", MaxPresignExpiry, expires)
+		return
+	}
+
+	if c.policy != nil {
+		object, err = c.policy.Apply(object)
+		if err != nil {
+			return
+		}
+	}
+
+	creds, err := c.creds.Credentials(ctx)
+	if err != nil {
+		err = errors.Wrap(err, "failed to get credentials")
+		return
+	}
+
+	path := "/" + uriEncode(c.bucket+"/"+c.prefix+object)
+	query := presignQuery(method, c.region, c.host, path, creds, expires, c.clock.Now().UTC())
+
+	uri = c.scheme + "://" + c.host + path + "?" + query
+	return
+}
+
+// unexported
+
+// presignQuery returns the canonical query of a presigned url, signature last.
+func presignQuery(method, region, host, path string, creds Credentials, expires time.Duration, t time.Time) string {
+
+	amzDate := t.Format(amzDateFormat)
+	scope := amzDate[:8] + "/" + region + "/" + service + "/aws4_request"
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", algorithm)
+	query.Set("X-Amz-Credential", creds.AccessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if creds.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	canonical := canonicalQuery(query)
+
+	request := strings.Join([]string{method, path, canonical, "host:" + host, "", "host", unsignedPayload}, "\n")
+	sum := sha256.Sum256([]byte(request))
+
+	toSign := strings.Join([]string{algorithm, amzDate, scope, hex.EncodeToString(sum[:])}, "\n")
+	key := signingKey(creds.SecretKey, []byte(amzDate[:8]), region, service)
+	sig := hmacSum(key[:], []byte(toSign))
+
+	return canonical + "&X-Amz-Signature=" + hex.EncodeToString(sig[:])
+}
+
+// uriEncode percent-encodes all but unreserved characters and slashes, as SigV4 expects of paths.
+func uriEncode(path string) string {
+
+	var bldr strings.Builder
+	for i := 0; i < len(path); i++ {
+		ch := path[i]
+		switch {
+		case 'A' <= ch && ch <= 'Z', 'a' <= ch && ch <= 'z', '0' <= ch && ch <= '9',
+			ch == '-', ch == '.', ch == '_', ch == '~', ch == '/':
+			bldr.WriteByte(ch)
+		default:
+			bldr.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{ch})))
+		}
+	}
+
+	return bldr.String()
+}
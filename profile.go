@@ -0,0 +1,66 @@
+package objsto
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Profiles are named endpoint configs, such as "prod", "staging", or "local-garage".
+//
+// Being a map of Config, they load from JSON as is.
+// From the environment, envconfig uses Decode, taking space separated name=url pairs, with urls as for ParseURL.
+type Profiles map[string]*Config
+
+// Decode populates profiles from name=url pairs, satisfying envconfig's Decoder.
+func (prf *Profiles) Decode(value string) (err error) {
+
+	profiles := Profiles{}
+
+	for _, pair := range strings.Fields(value) {
+		name, raw, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			err = errors.Errorf("profile must be name=url, got %q", pair)
+			return
+		}
+
+		profiles[name], err = ParseURL(raw)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to parse profile %q", name)
+			return
+		}
+	}
+
+	*prf = profiles
+	return
+}
+
+// Select returns the named profile.
+func (prf Profiles) Select(name string) (cfg *Config, err error) {
+
+	cfg, ok := prf[name]
+	if !ok || cfg == nil {
+		names := make([]string, 0, len(prf))
+		for key := range prf {
+			names = append(names, key)
+		}
+		slices.Sort(names)
+
+		err = errors.Errorf("no profile %q, have: %s", name, strings.Join(names, ", "))
+	}
+
+	return
+}
+
+// ProfileConfig is a selection of one of several profiles, tagged for use with envconfig.
+type ProfileConfig struct {
+	Profile  string   `json:"profile" desc:"name of the profile to use" required:"true"`
+	Profiles Profiles `json:"profiles" desc:"space separated name=url pairs, as for ParseURL" required:"true"`
+}
+
+// Config returns the selected profile.
+func (pc *ProfileConfig) Config() (*Config, error) {
+
+	return pc.Profiles.Select(pc.Profile)
+}
@@ -0,0 +1,60 @@
+package objsto_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var _ = Describe("Profiles", func() {
+
+	Describe("Decode", func() {
+		It("parses name=url pairs", func() {
+			var prf objsto.Profiles
+			err := prf.Decode("prod=s3://a:b@s3.amazonaws.com/prod-bucket?region=us-east-1  local-garage=s3://c:d@localhost:3900/dev?region=garage&scheme=http")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(prf).To(HaveLen(2))
+			Expect(prf["prod"].Bucket).To(Equal("prod-bucket"))
+			Expect(prf["local-garage"].Scheme).To(Equal("http"))
+		})
+
+		It("returns error for a malformed pair", func() {
+			var prf objsto.Profiles
+			err := prf.Decode("prod")
+			Expect(err).To(MatchError(ContainSubstring("name=url")))
+		})
+	})
+
+	Describe("ProfileConfig", func() {
+		var (
+			pc *objsto.ProfileConfig
+		)
+
+		BeforeEach(func() {
+			pc = &objsto.ProfileConfig{}
+			err := json.Unmarshal([]byte(`{
+				"profile": "staging",
+				"profiles": {
+					"prod": {"region": "us-east-1", "host": "s3.amazonaws.com", "bucket": "prod-bucket"},
+					"staging": {"region": "us-west-2", "host": "s3.amazonaws.com", "bucket": "staging-bucket"}
+				}
+			}`), pc)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("selects the profile", func() {
+			cfg, err := pc.Config()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.Bucket).To(Equal("staging-bucket"))
+		})
+
+		It("returns error for an unknown profile", func() {
+			pc.Profile = "qa"
+			_, err := pc.Config()
+			Expect(err).To(MatchError(ContainSubstring(`no profile "qa", have: prod, staging`)))
+		})
+	})
+})
@@ -0,0 +1,67 @@
+package objsto
+
+import (
+	"context"
+	"io"
+)
+
+// ProgressFunc is called as a transfer proceeds with bytes so far and the total, -1 when unknown.
+type ProgressFunc func(transferred, total int64)
+
+// WithProgress returns a context reporting progress of transfers made with it,
+// uploads as the request body is sent and downloads as the response body is read.
+// A retry starts the count over.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// unexported
+
+type progressKey struct{}
+
+func progressFrom(ctx context.Context) ProgressFunc {
+
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+type progressReader struct {
+	reader io.ReadCloser
+	fn     ProgressFunc
+	done   int64
+	total  int64
+}
+
+func progress(ctx context.Context, reader io.ReadCloser, total int64) io.ReadCloser {
+
+	fn := progressFrom(ctx)
+	if fn == nil {
+		return reader
+	}
+	if total <= 0 {
+		total = -1
+	}
+
+	return &progressReader{
+		reader: reader,
+		fn:     fn,
+		total:  total,
+	}
+}
+
+func (pr *progressReader) Read(buf []byte) (n int, err error) {
+
+	n, err = pr.reader.Read(buf)
+	if n > 0 {
+		pr.done += int64(n)
+		pr.fn(pr.done, pr.total)
+	}
+
+	return
+}
+
+func (pr *progressReader) Close() error {
+
+	return pr.reader.Close()
+}
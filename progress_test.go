@@ -0,0 +1,77 @@
+package objsto_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var _ = Describe("Progress", func() {
+	var (
+		ctx     context.Context
+		mock    *HttpDoerMock
+		client  *objsto.Client
+		data    []byte
+		reports [][2]int64
+	)
+
+	BeforeEach(func() {
+		data = bytes.Repeat([]byte("x"), 100000)
+		reports = nil
+
+		ctx = objsto.WithProgress(context.Background(), func(transferred, total int64) {
+			reports = append(reports, [2]int64{transferred, total})
+		})
+
+		mock = &HttpDoerMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if req.Body != nil {
+					io.Copy(io.Discard, req.Body)
+				}
+				return &http.Response{
+					StatusCode:    200,
+					ContentLength: int64(len(data)),
+					Body:          io.NopCloser(bytes.NewReader(data)),
+				}, nil
+			},
+		}
+
+		client = objsto.New(&objsto.Config{
+			Region:    "test-region",
+			Scheme:    "https",
+			Host:      "test-host",
+			Bucket:    "test-bucket",
+			AccessKey: "test-access-key",
+			SecretKey: "test-secret-key",
+		}, objsto.WithHTTPClient(mock))
+	})
+
+	It("reports upload progress", func() {
+		err := client.Put(ctx, "big.bin", bytes.NewReader(data))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(len(reports)).To(BeNumerically(">", 1))
+		Expect(reports[len(reports)-1]).To(Equal([2]int64{100000, 100000}))
+	})
+
+	It("reports download progress", func() {
+		reader, err := client.Get(ctx, "big.bin")
+		Expect(err).ToNot(HaveOccurred())
+		io.Copy(io.Discard, reader)
+		reader.Close()
+
+		Expect(reports[len(reports)-1]).To(Equal([2]int64{100000, 100000}))
+	})
+
+	It("leaves other contexts alone", func() {
+		_, err := client.GetBytes(context.Background(), "big.bin", 1<<20)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reports).To(BeEmpty())
+	})
+})
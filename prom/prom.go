@@ -0,0 +1,127 @@
+// Package prom implements objsto.Metrics with Prometheus counters and histograms,
+// labeled by operation and status.
+package prom
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/clarktrimble/objsto"
+)
+
+// Collector is an objsto.Metrics and a prometheus.Collector, to be registered as any other.
+type Collector struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	bytes    *prometheus.CounterVec
+}
+
+var (
+	_ objsto.Metrics       = &Collector{}
+	_ prometheus.Collector = &Collector{}
+)
+
+// Option sets an optional Collector setting.
+type Option func(*options)
+
+// WithNamespace sets the namespace prefixed to metric names, defaulting to "objsto".
+func WithNamespace(namespace string) Option {
+
+	return func(opts *options) {
+		opts.namespace = namespace
+	}
+}
+
+// WithBuckets sets the request duration histogram buckets, in seconds, defaulting to prometheus.DefBuckets.
+func WithBuckets(buckets []float64) Option {
+
+	return func(opts *options) {
+		opts.buckets = buckets
+	}
+}
+
+// WithConstLabels sets labels added to every metric, such as for the bucket.
+func WithConstLabels(labels prometheus.Labels) Option {
+
+	return func(opts *options) {
+		opts.labels = labels
+	}
+}
+
+// New creates a Collector.
+func New(opts ...Option) *Collector {
+
+	cfg := &options{
+		namespace: "objsto",
+		buckets:   prometheus.DefBuckets,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   cfg.namespace,
+			Name:        "requests_total",
+			Help:        "Requests sent, retries included, by operation and response status.",
+			ConstLabels: cfg.labels,
+		}, []string{"operation", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   cfg.namespace,
+			Name:        "request_duration_seconds",
+			Help:        "Time from sending a request to receiving its response headers, by operation and response status.",
+			Buckets:     cfg.buckets,
+			ConstLabels: cfg.labels,
+		}, []string{"operation", "status"}),
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   cfg.namespace,
+			Name:        "bytes_transferred_total",
+			Help:        "Bytes sent and received per content length, by operation and direction.",
+			ConstLabels: cfg.labels,
+		}, []string{"operation", "direction"}),
+	}
+}
+
+// Hooks returns hooks reporting to the collector, for use with objsto.WithHooks.
+func (col *Collector) Hooks() objsto.Hooks {
+
+	return objsto.MetricsHooks(col)
+}
+
+// ObserveRequest records a request attempt.
+func (col *Collector) ObserveRequest(operation, status string, elapsed time.Duration) {
+
+	col.requests.WithLabelValues(operation, status).Inc()
+	col.duration.WithLabelValues(operation, status).Observe(elapsed.Seconds())
+}
+
+// ObserveBytes records bytes sent or received.
+func (col *Collector) ObserveBytes(operation, direction string, n int64) {
+
+	col.bytes.WithLabelValues(operation, direction).Add(float64(n))
+}
+
+// Describe sends metric descriptions to ch.
+func (col *Collector) Describe(ch chan<- *prometheus.Desc) {
+
+	col.requests.Describe(ch)
+	col.duration.Describe(ch)
+	col.bytes.Describe(ch)
+}
+
+// Collect sends metrics to ch.
+func (col *Collector) Collect(ch chan<- prometheus.Metric) {
+
+	col.requests.Collect(ch)
+	col.duration.Collect(ch)
+	col.bytes.Collect(ch)
+}
+
+// unexported
+
+type options struct {
+	namespace string
+	buckets   []float64
+	labels    prometheus.Labels
+}
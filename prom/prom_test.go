@@ -0,0 +1,92 @@
+package prom_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/prom"
+)
+
+func TestProm(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Prom Suite")
+}
+
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (fn doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+var _ = Describe("Collector", func() {
+	var (
+		ctx    = context.Background()
+		col    *prom.Collector
+		client *objsto.Client
+	)
+
+	BeforeEach(func() {
+		col = prom.New()
+
+		doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method == "HEAD" {
+				return &http.Response{StatusCode: 404, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+			return &http.Response{
+				StatusCode:    200,
+				ContentLength: 5,
+				Body:          io.NopCloser(strings.NewReader("hello")),
+			}, nil
+		})
+
+		client = objsto.New(&objsto.Config{
+			Region:    "test-region",
+			Scheme:    "https",
+			Host:      "test-host",
+			Bucket:    "test-bucket",
+			AccessKey: "test-access-key",
+			SecretKey: "test-secret-key",
+		}, objsto.WithHTTPClient(doer), objsto.WithHooks(col.Hooks()))
+	})
+
+	It("counts requests and bytes by operation and status", func() {
+		Expect(client.Put(ctx, "a.txt", strings.NewReader("abc"))).To(Succeed())
+
+		reader, err := client.Get(ctx, "a.txt")
+		Expect(err).ToNot(HaveOccurred())
+		reader.Close()
+
+		_, err = client.Stat(ctx, "b.txt")
+		Expect(err).To(MatchError(objsto.ErrNotFound))
+
+		registry := prometheus.NewPedanticRegistry()
+		Expect(registry.Register(col)).To(Succeed())
+
+		expected := `
+# HELP objsto_requests_total Requests sent, retries included, by operation and response status.
+# TYPE objsto_requests_total counter
+objsto_requests_total{operation="get",status="200"} 1
+objsto_requests_total{operation="put",status="200"} 1
+objsto_requests_total{operation="stat",status="404"} 1
+# HELP objsto_bytes_transferred_total Bytes sent and received per content length, by operation and direction.
+# TYPE objsto_bytes_transferred_total counter
+objsto_bytes_transferred_total{direction="in",operation="get"} 5
+objsto_bytes_transferred_total{direction="in",operation="put"} 5
+objsto_bytes_transferred_total{direction="out",operation="put"} 3
+`
+		err = testutil.GatherAndCompare(registry, strings.NewReader(expected),
+			"objsto_requests_total", "objsto_bytes_transferred_total")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(testutil.CollectAndCount(col, "objsto_request_duration_seconds")).To(Equal(3))
+	})
+})
@@ -0,0 +1,95 @@
+package objsto
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Proxy is a signing reverse proxy, forwarding requests to the endpoint with the Client's credentials.
+//
+// Callers are trusted rather than authenticated, so keep it on an internal network.
+// Any signature they send is dropped and the request re-signed, with the payload
+// streamed through as UNSIGNED-PAYLOAD. Only paths within the Client's bucket are forwarded,
+// dot segments resolved, though that includes the bucket itself, so its sub-resources such as
+// ?policy and ?acl, and a bucket DELETE, are forwarded too.
+// Requests are sent once, without retry or throttling.
+type Proxy struct {
+	client  *Client
+	reverse *httputil.ReverseProxy
+}
+
+// NewProxy creates a Proxy for client.
+func NewProxy(client *Client) *Proxy {
+
+	return &Proxy{
+		client: client,
+		reverse: &httputil.ReverseProxy{
+			Rewrite: func(pr *httputil.ProxyRequest) {
+				pr.Out.URL.Scheme = client.scheme
+				pr.Out.URL.Host = client.host
+				pr.Out.Host = ""
+
+				for _, key := range []string{"Authorization", "X-Amz-Security-Token", "X-Amz-Date", "X-Amz-Content-Sha256"} {
+					pr.Out.Header.Del(key)
+				}
+			},
+			Transport: signingTransport{client: client},
+		},
+	}
+}
+
+// ServeHTTP signs and forwards the request.
+func (px *Proxy) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+
+	// cleaned for the check only, so dot segments can't climb out to another bucket
+	// where an endpoint resolves them, while keys with runs of slashes still pass
+	bucket := "/" + px.client.bucket
+	cleaned := path.Clean(request.URL.Path)
+	if cleaned != bucket && !strings.HasPrefix(cleaned, bucket+"/") {
+		http.Error(writer, "bucket not allowed", http.StatusForbidden)
+		return
+	}
+
+	px.reverse.ServeHTTP(writer, request)
+}
+
+// unexported
+
+type signingTransport struct {
+	client *Client
+}
+
+func (st signingTransport) RoundTrip(request *http.Request) (resp *http.Response, err error) {
+
+	ctx := request.Context()
+
+	req := request.Clone(ctx)
+	req.RequestURI = ""
+	req.URL.RawPath = uriEncode(req.URL.Path)
+	req.URL.RawQuery = canonicalQuery(req.URL.Query())
+
+	debug := st.client.sampled()
+
+	err = st.client.sign(ctx, req, req.URL.RawPath, req.URL.RawQuery, unsignedPayload, debug)
+	if err != nil {
+		return
+	}
+
+	if debug {
+		st.client.logger.Debug(ctx, "signed proxy request",
+			"method", req.Method,
+			"url", req.URL.String(),
+		)
+	}
+
+	resp, err = st.client.client.Do(req)
+	if err != nil {
+		err = errors.Wrapf(err, "failed proxy request to %q", req.URL)
+	}
+
+	return
+}
@@ -0,0 +1,97 @@
+package objsto_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var _ = Describe("Proxy", func() {
+	var (
+		upstream *httptest.Server
+		received *http.Request
+		body     string
+		req      *http.Request
+		rec      *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		received = nil
+		upstream = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			data, _ := io.ReadAll(request.Body)
+			received = request
+			body = string(data)
+			writer.Header().Set("ETag", `"abc123"`)
+			writer.Write([]byte("upstream says hi"))
+		}))
+		DeferCleanup(upstream.Close)
+
+		req = httptest.NewRequest("PUT", "/test-bucket/some%20key.txt?b=2&a=x+y", strings.NewReader("payload"))
+		req.Header.Set("Authorization", "Bearer caller-token")
+		req.Header.Set("X-Amz-Meta-Owner", "bob")
+	})
+
+	JustBeforeEach(func() {
+		uri, err := url.Parse(upstream.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		client := objsto.New(&objsto.Config{
+			Region:    "test-region",
+			Scheme:    "http",
+			Host:      uri.Host,
+			Bucket:    "test-bucket",
+			AccessKey: "test-access-key",
+			SecretKey: "test-secret-key",
+		})
+
+		rec = httptest.NewRecorder()
+		objsto.NewProxy(client).ServeHTTP(rec, req)
+	})
+
+	When("the request is for the bucket", func() {
+		It("re-signs and forwards it", func() {
+			Expect(rec.Code).To(Equal(200))
+			Expect(rec.Body.String()).To(Equal("upstream says hi"))
+			Expect(rec.Header().Get("ETag")).To(Equal(`"abc123"`))
+
+			Expect(received.Method).To(Equal("PUT"))
+			Expect(received.URL.EscapedPath()).To(Equal("/test-bucket/some%20key.txt"))
+			Expect(received.URL.RawQuery).To(Equal("a=x%20y&b=2"))
+			Expect(body).To(Equal("payload"))
+
+			auth := received.Header.Get("Authorization")
+			Expect(auth).To(HavePrefix("AWS4-HMAC-SHA256 Credential=test-access-key/"))
+			Expect(auth).To(ContainSubstring("x-amz-meta-owner"))
+			Expect(received.Header.Get("X-Amz-Content-Sha256")).To(Equal("UNSIGNED-PAYLOAD"))
+		})
+	})
+
+	When("the request is for another bucket", func() {
+		BeforeEach(func() {
+			req = httptest.NewRequest("GET", "/other-bucket/a.txt", nil)
+		})
+
+		It("is forbidden", func() {
+			Expect(rec.Code).To(Equal(http.StatusForbidden))
+			Expect(received).To(BeNil())
+		})
+	})
+
+	When("the request climbs out of the bucket", func() {
+		BeforeEach(func() {
+			req = httptest.NewRequest("GET", "/test-bucket/%2E%2E/other-bucket/a.txt", nil)
+		})
+
+		It("is forbidden", func() {
+			Expect(rec.Code).To(Equal(http.StatusForbidden))
+			Expect(received).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,507 @@
+package objsto
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultQueueDepth is the number of puts a QueueStore buffers by default.
+	DefaultQueueDepth = 64
+	// DefaultQueueWorkers is the number of uploads a QueueStore has in flight by default.
+	DefaultQueueWorkers = 2
+)
+
+// DefaultQueueRetry is how a QueueStore retries uploads by default, patient with a flaky link.
+var DefaultQueueRetry = Backoff{MaxAttempts: 10, Base: time.Second, Max: time.Minute}
+
+// CompletionFunc is called when a queued put is done, with the error when it failed for good.
+type CompletionFunc func(object string, err error)
+
+// WithCompletion returns a context whose puts to a QueueStore call fn when done,
+// in addition to any set with WithCompletionFunc.
+func WithCompletion(ctx context.Context, fn CompletionFunc) context.Context {
+
+	return context.WithValue(ctx, completionKey{}, fn)
+}
+
+// QueueStore wraps an ObjectStore, returning from Put once an object is buffered
+// and uploading it in the background, for writers with intermittent connectivity.
+//
+// Puts block while the buffer is full. Workers retry each upload per policy and then report
+// to any CompletionFunc, a failed object being dropped. Uploads keep the values of the
+// context given to Put, such as headers, but not its cancelation.
+//
+// With WithQueueDir, objects are buffered as files rather than in memory, and those left
+// by a previous run, or cut short by Drain, are uploaded on start.
+// Get and the rest pass through, not seeing objects still queued.
+type QueueStore struct {
+	ObjectStore
+	depth   int
+	workers int
+	retry   RetryPolicy
+	dir     string
+	done    CompletionFunc
+	slots   chan struct{}
+	items   chan *queuedPut
+	stop    context.CancelFunc
+	running sync.WaitGroup
+	mu      sync.RWMutex
+	closed  bool
+	pending pending
+	seq     atomic.Uint64
+}
+
+var _ ObjectStore = &QueueStore{}
+
+// QueueOption sets an optional QueueStore setting.
+type QueueOption func(*QueueStore)
+
+// WithQueueDepth sets the number of puts buffered before Put blocks, defaulting to DefaultQueueDepth.
+func WithQueueDepth(depth int) QueueOption {
+
+	return func(qs *QueueStore) {
+		qs.depth = depth
+	}
+}
+
+// WithQueueWorkers sets the number of uploads in flight, defaulting to DefaultQueueWorkers.
+func WithQueueWorkers(n int) QueueOption {
+
+	return func(qs *QueueStore) {
+		qs.workers = n
+	}
+}
+
+// WithQueueRetry sets how uploads are retried, defaulting to DefaultQueueRetry.
+// This is on top of any retries made by a Client for individual requests.
+func WithQueueRetry(policy RetryPolicy) QueueOption {
+
+	return func(qs *QueueStore) {
+		qs.retry = policy
+	}
+}
+
+// WithQueueDir buffers objects as files in dir, surviving restarts.
+func WithQueueDir(dir string) QueueOption {
+
+	return func(qs *QueueStore) {
+		qs.dir = dir
+	}
+}
+
+// WithCompletionFunc sets a CompletionFunc called for every queued put.
+func WithCompletionFunc(fn CompletionFunc) QueueOption {
+
+	return func(qs *QueueStore) {
+		qs.done = fn
+	}
+}
+
+// NewQueueStore creates a QueueStore wrapping store and starts its workers,
+// queueing any objects left in the queue dir first.
+func NewQueueStore(store ObjectStore, opts ...QueueOption) (qs *QueueStore, err error) {
+
+	qs = &QueueStore{
+		ObjectStore: store,
+		depth:       DefaultQueueDepth,
+		workers:     DefaultQueueWorkers,
+		retry:       DefaultQueueRetry,
+	}
+	for _, opt := range opts {
+		opt(qs)
+	}
+	qs.depth = max(qs.depth, 1)
+	qs.workers = max(qs.workers, 1)
+
+	var left []*queuedPut
+	if qs.dir != "" {
+		left, err = qs.recover()
+		if err != nil {
+			return
+		}
+	}
+
+	qs.slots = make(chan struct{}, qs.depth)
+	qs.items = make(chan *queuedPut, qs.depth+len(left))
+	for _, item := range left {
+		qs.pending.add()
+		qs.items <- item
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	qs.stop = stop
+
+	qs.running.Add(qs.workers)
+	for range qs.workers {
+		go qs.work(ctx)
+	}
+
+	return
+}
+
+// Put buffers an object for upload, blocking while the queue is full.
+// An error means the object was not queued, upload errors go to any CompletionFunc.
+func (qs *QueueStore) Put(ctx context.Context, object string, reader io.ReadSeeker, opts ...PutOption) (err error) {
+
+	qs.mu.RLock()
+	defer qs.mu.RUnlock()
+
+	if qs.closed {
+		err = errors.Wrapf(ErrQueueClosed, "failed to queue %q", object)
+		return
+	}
+
+	select {
+	case qs.slots <- struct{}{}:
+	case <-ctx.Done():
+		err = errors.Wrapf(ctx.Err(), "failed to queue %q", object)
+		return
+	}
+
+	item := &queuedPut{
+		ctx:    context.WithoutCancel(ctx),
+		object: object,
+		opts:   NewPutOptions(opts...),
+		done:   completionFrom(ctx),
+		slot:   true,
+	}
+
+	if qs.dir != "" {
+		err = qs.spool(item, reader)
+	} else {
+		item.data, err = io.ReadAll(reader)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to read %q", object)
+		}
+	}
+	if err != nil {
+		<-qs.slots
+		return
+	}
+
+	qs.pending.add()
+	qs.items <- item
+	return
+}
+
+// Flush waits until the puts queued so far are done, or ctx is done.
+func (qs *QueueStore) Flush(ctx context.Context) (err error) {
+
+	select {
+	case <-qs.pending.idle():
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	return
+}
+
+// Drain stops taking puts, waits for those queued to be done, and stops the workers.
+// When ctx is done first, uploads in flight are canceled, and with a queue dir,
+// those not done are left for the next start.
+func (qs *QueueStore) Drain(ctx context.Context) (err error) {
+
+	qs.mu.Lock()
+	if qs.closed {
+		qs.mu.Unlock()
+		return
+	}
+	qs.closed = true
+	qs.mu.Unlock()
+
+	err = qs.Flush(ctx)
+	if err != nil {
+		qs.stop()
+	}
+
+	close(qs.items)
+	qs.running.Wait()
+	qs.stop()
+	return
+}
+
+// Len returns the number of puts queued or in flight.
+func (qs *QueueStore) Len() int {
+
+	return qs.pending.len()
+}
+
+// unexported
+
+type completionKey struct{}
+
+func completionFrom(ctx context.Context) CompletionFunc {
+
+	fn, _ := ctx.Value(completionKey{}).(CompletionFunc)
+	return fn
+}
+
+type queuedPut struct {
+	ctx    context.Context
+	object string
+	opts   PutOptions
+	data   []byte
+	path   string
+	done   CompletionFunc
+	slot   bool
+}
+
+// queuedMeta is what's saved alongside a spooled object.
+type queuedMeta struct {
+	Object  string     `json:"object"`
+	Options PutOptions `json:"options"`
+}
+
+func (qs *QueueStore) work(ctx context.Context) {
+
+	defer qs.running.Done()
+
+	for item := range qs.items {
+		err := qs.upload(ctx, item)
+		qs.finish(ctx, item, err)
+	}
+}
+
+// upload puts an item until it succeeds, fails for good, the retry policy gives up, or ctx is done.
+func (qs *QueueStore) upload(ctx context.Context, item *queuedPut) (err error) {
+
+	if ctx.Err() != nil {
+		err = ctx.Err()
+		return
+	}
+
+	ictx, cancel := context.WithCancel(item.ctx)
+	defer cancel()
+	unhook := context.AfterFunc(ctx, cancel)
+	defer unhook()
+
+	for attempt := 1; ; attempt++ {
+		err = qs.put(ictx, item)
+		if err == nil || Permanent(err) {
+			return
+		}
+
+		delay, ok := qs.retry.Retry(attempt, nil, err)
+		if !ok {
+			return
+		}
+
+		if sleep(ictx, delay) != nil {
+			return
+		}
+	}
+}
+
+func (qs *QueueStore) put(ctx context.Context, item *queuedPut) (err error) {
+
+	var reader io.ReadSeeker = bytes.NewReader(item.data)
+	if item.path != "" {
+		var file *os.File
+		file, err = os.Open(item.path)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to open queued %q", item.object)
+			return
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	err = qs.ObjectStore.Put(ctx, item.object, reader, func(po *PutOptions) { *po = item.opts })
+	return
+}
+
+// finish reports on an item and frees its place, keeping its files when stopped short.
+func (qs *QueueStore) finish(ctx context.Context, item *queuedPut, err error) {
+
+	if item.path != "" && ctx.Err() == nil {
+		os.Remove(metaPath(item.path))
+		os.Remove(item.path)
+	}
+
+	if item.done != nil {
+		item.done(item.object, err)
+	}
+	if qs.done != nil {
+		qs.done(item.object, err)
+	}
+
+	if item.slot {
+		<-qs.slots
+	}
+	qs.pending.done()
+}
+
+// spool writes an item's object and options to the queue dir, renaming each into place.
+func (qs *QueueStore) spool(item *queuedPut, reader io.Reader) (err error) {
+
+	err = os.MkdirAll(qs.dir, 0755)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to create queue dir %q", qs.dir)
+		return
+	}
+
+	name := fmt.Sprintf("%020d-%06d", time.Now().UnixNano(), qs.seq.Add(1)%1000000)
+	item.path = filepath.Join(qs.dir, name+".data")
+
+	err = writeRenamed(item.path, reader)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to spool %q", item.object)
+		return
+	}
+
+	saved := item.opts
+	saved.Result = nil
+	meta, err := json.Marshal(queuedMeta{Object: item.object, Options: saved})
+	if err == nil {
+		err = writeRenamed(metaPath(item.path), bytes.NewReader(meta))
+	}
+	if err != nil {
+		os.Remove(item.path)
+		err = errors.Wrapf(err, "failed to spool %q", item.object)
+	}
+
+	return
+}
+
+// recover finds items spooled by a previous run, oldest first, removing leftovers without options.
+func (qs *QueueStore) recover() (items []*queuedPut, err error) {
+
+	err = os.MkdirAll(qs.dir, 0755)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to create queue dir %q", qs.dir)
+		return
+	}
+
+	entries, err := os.ReadDir(qs.dir)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to read queue dir %q", qs.dir)
+		return
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			os.Remove(filepath.Join(qs.dir, name))
+			continue
+		}
+		if strings.HasSuffix(name, ".data") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		path := filepath.Join(qs.dir, name)
+
+		var meta queuedMeta
+		data, readErr := os.ReadFile(metaPath(path))
+		if readErr == nil {
+			readErr = json.Unmarshal(data, &meta)
+		}
+		if readErr != nil {
+			os.Remove(metaPath(path))
+			os.Remove(path)
+			continue
+		}
+
+		items = append(items, &queuedPut{
+			ctx:    context.Background(),
+			object: meta.Object,
+			opts:   meta.Options,
+			path:   path,
+		})
+	}
+
+	return
+}
+
+func metaPath(path string) string {
+
+	return strings.TrimSuffix(path, ".data") + ".json"
+}
+
+// writeRenamed writes to a dot-named temp file beside path and renames it into place.
+func writeRenamed(path string, reader io.Reader) (err error) {
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".spool-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, reader)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	return
+}
+
+// pending counts items not yet done, with a channel closed whenever there are none.
+type pending struct {
+	count  int
+	idleCh chan struct{}
+	mu     sync.Mutex
+}
+
+func (pd *pending) add() {
+
+	pd.mu.Lock()
+	defer pd.mu.Unlock()
+
+	if pd.count == 0 {
+		pd.idleCh = make(chan struct{})
+	}
+	pd.count++
+}
+
+func (pd *pending) done() {
+
+	pd.mu.Lock()
+	defer pd.mu.Unlock()
+
+	pd.count--
+	if pd.count == 0 {
+		close(pd.idleCh)
+	}
+}
+
+func (pd *pending) idle() <-chan struct{} {
+
+	pd.mu.Lock()
+	defer pd.mu.Unlock()
+
+	if pd.count == 0 {
+		ch := make(chan struct{})
+		close(ch)
+		return ch
+	}
+	return pd.idleCh
+}
+
+func (pd *pending) len() int {
+
+	pd.mu.Lock()
+	defer pd.mu.Unlock()
+
+	return pd.count
+}
@@ -0,0 +1,176 @@
+package objsto_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+var _ = Describe("QueueStore", func() {
+	var (
+		ctx     = context.Background()
+		inner   *memstore.Store
+		offline bool
+		puts    int
+		mu      sync.Mutex
+		done    map[string]error
+		qs      *objsto.QueueStore
+		retry   = objsto.Backoff{MaxAttempts: 3, Base: time.Millisecond, Max: time.Millisecond}
+	)
+
+	BeforeEach(func() {
+		offline = false
+		puts = 0
+		inner = memstore.New(memstore.WithFault(func(op memstore.Op, object string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if op == memstore.OpPut {
+				puts++
+			}
+			if offline && op == memstore.OpPut {
+				return errors.New("no signal")
+			}
+			if object == "refused.txt" && op == memstore.OpPut {
+				return objsto.ErrPreconditionFailed
+			}
+			return nil
+		}))
+		done = map[string]error{}
+	})
+
+	record := func(object string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		done[object] = err
+	}
+
+	setOffline := func(off bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		offline = off
+	}
+
+	read := func(object string) string {
+
+		reader, err := inner.Get(ctx, object)
+		Expect(err).ToNot(HaveOccurred())
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	When("buffering in memory", func() {
+		BeforeEach(func() {
+			var err error
+			qs, err = objsto.NewQueueStore(inner, objsto.WithQueueRetry(retry), objsto.WithCompletionFunc(record))
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(qs.Drain(ctx)).To(Succeed())
+		})
+
+		It("uploads in the background, reporting each", func() {
+			var mine error = errors.New("not called")
+			cctx := objsto.WithCompletion(ctx, func(object string, err error) { mine = err })
+
+			Expect(qs.Put(cctx, "a.txt", strings.NewReader("aaa"), objsto.WithContentType("text/plain"))).To(Succeed())
+			Expect(qs.Put(ctx, "b.txt", strings.NewReader("bbb"))).To(Succeed())
+			Expect(qs.Flush(ctx)).To(Succeed())
+
+			Expect(read("a.txt")).To(Equal("aaa"))
+			Expect(read("b.txt")).To(Equal("bbb"))
+			info, err := inner.Stat(ctx, "a.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.ContentType).To(Equal("text/plain"))
+
+			Expect(mine).ToNot(HaveOccurred())
+			Expect(done).To(HaveLen(2))
+			Expect(qs.Len()).To(BeZero())
+		})
+
+		It("reports an upload failing for good", func() {
+			setOffline(true)
+
+			Expect(qs.Put(ctx, "a.txt", strings.NewReader("aaa"))).To(Succeed())
+			Expect(qs.Flush(ctx)).To(Succeed())
+
+			Expect(done["a.txt"]).To(MatchError("no signal"))
+		})
+
+		It("gives up on an upload failing permanently without retrying", func() {
+			Expect(qs.Put(ctx, "refused.txt", strings.NewReader("aaa"))).To(Succeed())
+			Expect(qs.Flush(ctx)).To(Succeed())
+
+			Expect(errors.Is(done["refused.txt"], objsto.ErrPreconditionFailed)).To(BeTrue())
+			Expect(puts).To(Equal(1))
+		})
+
+		It("refuses puts once drained", func() {
+			Expect(qs.Drain(ctx)).To(Succeed())
+
+			err := qs.Put(ctx, "a.txt", strings.NewReader("aaa"))
+			Expect(errors.Is(err, objsto.ErrQueueClosed)).To(BeTrue())
+		})
+	})
+
+	When("buffering on disk", func() {
+		var dir string
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+		})
+
+		It("picks up where a previous run left off", func() {
+			setOffline(true)
+			patient := objsto.Backoff{MaxAttempts: 1000, Base: time.Millisecond, Max: time.Millisecond}
+
+			qs, err := objsto.NewQueueStore(inner, objsto.WithQueueDir(dir), objsto.WithQueueRetry(patient))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(qs.Put(ctx, "a.txt", strings.NewReader("aaa"), objsto.WithMetadata(map[string]string{"k": "v"}))).To(Succeed())
+
+			short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+			Expect(qs.Drain(short)).To(MatchError(context.DeadlineExceeded))
+
+			setOffline(false)
+			qs, err = objsto.NewQueueStore(inner, objsto.WithQueueDir(dir), objsto.WithCompletionFunc(record))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(qs.Drain(ctx)).To(Succeed())
+
+			Expect(read("a.txt")).To(Equal("aaa"))
+			info, err := inner.Stat(ctx, "a.txt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Metadata).To(HaveKeyWithValue("k", "v"))
+			Expect(done).To(HaveKeyWithValue("a.txt", BeNil()))
+		})
+	})
+
+	It("blocks puts while full", func() {
+		setOffline(true)
+		patient := objsto.Backoff{MaxAttempts: 1000, Base: time.Millisecond, Max: time.Millisecond}
+
+		qs, err := objsto.NewQueueStore(inner, objsto.WithQueueDepth(1), objsto.WithQueueRetry(patient))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(qs.Put(ctx, "a.txt", strings.NewReader("aaa"))).To(Succeed())
+
+		short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		Expect(qs.Put(short, "b.txt", strings.NewReader("bbb"))).To(MatchError(context.DeadlineExceeded))
+
+		setOffline(false)
+		Expect(qs.Drain(ctx)).To(Succeed())
+		Expect(read("a.txt")).To(Equal("aaa"))
+	})
+})
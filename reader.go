@@ -0,0 +1,106 @@
+package objsto
+
+import (
+	"context"
+	"io"
+	"io/fs"
+)
+
+// objectReader is a lazy io.ReadSeeker over an object of known size.
+// It gets the object on first read and again after a seek, ranged when the store is a RangeGetter.
+type objectReader struct {
+	ctx    context.Context
+	store  ObjectStore
+	key    string
+	size   int64
+	body   io.ReadCloser
+	offset int64
+	closed bool
+}
+
+func (or *objectReader) Read(buf []byte) (n int, err error) {
+
+	if or.closed {
+		err = fs.ErrClosed
+		return
+	}
+	if or.offset >= or.size {
+		err = io.EOF
+		return
+	}
+
+	if or.body == nil {
+		err = or.open()
+		if err != nil {
+			return
+		}
+	}
+
+	n, err = or.body.Read(buf)
+	or.offset += int64(n)
+	return
+}
+
+func (or *objectReader) Seek(offset int64, whence int) (pos int64, err error) {
+
+	if or.closed {
+		err = fs.ErrClosed
+		return
+	}
+
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = or.offset + offset
+	case io.SeekEnd:
+		pos = or.size + offset
+	}
+	if pos < 0 {
+		err = fs.ErrInvalid
+		return
+	}
+
+	if pos != or.offset && or.body != nil {
+		or.body.Close()
+		or.body = nil
+	}
+	or.offset = pos
+
+	return
+}
+
+func (or *objectReader) Close() error {
+
+	if or.closed {
+		return fs.ErrClosed
+	}
+	or.closed = true
+
+	if or.body != nil {
+		return or.body.Close()
+	}
+	return nil
+}
+
+func (or *objectReader) open() (err error) {
+
+	rg, ok := or.store.(RangeGetter)
+	if ok && or.offset > 0 {
+		or.body, err = rg.GetRange(or.ctx, or.key, or.offset, -1)
+		return
+	}
+
+	or.body, err = or.store.Get(or.ctx, or.key)
+	if err != nil {
+		return
+	}
+
+	// no ranged get, so skip ahead to the offset
+	_, err = io.CopyN(io.Discard, or.body, or.offset)
+	if err == io.EOF {
+		err = nil
+	}
+
+	return
+}
@@ -0,0 +1,68 @@
+package objsto
+
+import (
+	"net/http"
+	"strings"
+)
+
+// redacted replaces secrets in what's logged.
+const redacted = "REDACTED"
+
+// sensitive are headers whose values are redacted whole.
+var sensitive = []string{"X-Amz-Security-Token", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// redactHeader returns a copy of hdr with credentials masked, keeping the credential scope
+// and signed headers of an Authorization for troubleshooting signature mismatches.
+func redactHeader(hdr http.Header) http.Header {
+
+	out := hdr.Clone()
+	if out == nil {
+		return out
+	}
+
+	for _, key := range sensitive {
+		if _, ok := out[key]; ok {
+			out[key] = []string{redacted}
+		}
+	}
+	if vals, ok := out["Authorization"]; ok {
+		out["Authorization"] = []string{redactAuthorization(strings.Join(vals, ", "))}
+	}
+
+	return out
+}
+
+// redactKey masks all but the first four characters of an access key, enough to tell keys apart.
+func redactKey(key string) string {
+
+	if len(key) <= 4 {
+		return redacted
+	}
+	return key[:4] + redacted
+}
+
+// redactAuthorization masks the access key and signature of a SigV4 Authorization,
+// or all but the scheme of another kind.
+func redactAuthorization(auth string) string {
+
+	scheme, params, ok := strings.Cut(auth, " ")
+	if !ok || scheme != algorithm {
+		return scheme + " " + redacted
+	}
+
+	parts := strings.Split(params, ",")
+	for i, part := range parts {
+		name, val, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "Credential":
+			_, scope, _ := strings.Cut(val, "/")
+			parts[i] = "Credential=" + redacted + "/" + scope
+		case "Signature":
+			parts[i] = "Signature=" + redacted
+		default:
+			parts[i] = strings.TrimSpace(part)
+		}
+	}
+
+	return scheme + " " + strings.Join(parts, ", ")
+}
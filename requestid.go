@@ -0,0 +1,84 @@
+package objsto
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// RequestIDs identify a response, from x-amz-request-id and x-amz-id-2, for quoting to provider support.
+type RequestIDs struct {
+	RequestID string `json:"request_id,omitempty"`
+	HostID    string `json:"host_id,omitempty"`
+}
+
+// RequestIDRecorder keeps the ids of the last response to requests made with a context from WithRequestIDs.
+type RequestIDRecorder struct {
+	ids RequestIDs
+	mu  sync.Mutex
+}
+
+// Last returns the ids of the last response recorded.
+func (rec *RequestIDRecorder) Last() RequestIDs {
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	return rec.ids
+}
+
+// WithRequestIDs returns a context recording ids of responses to requests made with it, errors included.
+func WithRequestIDs(ctx context.Context, rec *RequestIDRecorder) context.Context {
+
+	return context.WithValue(ctx, requestIDsKey{}, rec)
+}
+
+// RequestIDsFrom returns the ids of the response an error came from, ok false if none.
+func RequestIDsFrom(err error) (ids RequestIDs, ok bool) {
+
+	var rerr *responseError
+	if errors.As(err, &rerr) {
+		ids = rerr.ids
+		ok = true
+	}
+
+	return
+}
+
+// unexported
+
+type requestIDsKey struct{}
+
+func requestIDs(resp *http.Response) RequestIDs {
+
+	return RequestIDs{
+		RequestID: resp.Header.Get("X-Amz-Request-Id"),
+		HostID:    resp.Header.Get("X-Amz-Id-2"),
+	}
+}
+
+func recordRequestIDs(ctx context.Context, ids RequestIDs) {
+
+	rec, _ := ctx.Value(requestIDsKey{}).(*RequestIDRecorder)
+	if rec == nil {
+		return
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.ids = ids
+}
+
+// responseError carries the ids of the response it came from.
+type responseError struct {
+	error
+	ids RequestIDs
+}
+
+func (rerr *responseError) Unwrap() error {
+
+	return rerr.error
+}
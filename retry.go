@@ -0,0 +1,84 @@
+package objsto
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy decides whether a failed request is tried again.
+type RetryPolicy interface {
+	// Retry is called after the numbered attempt fails with resp and err,
+	// returning the delay before another attempt or false to give up.
+	// Resp is nil when the request did not complete and its body is already closed.
+	Retry(attempt int, resp *http.Response, err error) (delay time.Duration, ok bool)
+}
+
+// NoRetry never retries and is the default policy.
+type NoRetry struct{}
+
+// Retry gives up.
+func (nr NoRetry) Retry(attempt int, resp *http.Response, err error) (delay time.Duration, ok bool) {
+
+	return
+}
+
+// Backoff retries transport errors, throttling, and server errors with
+// exponential backoff and full jitter.
+type Backoff struct {
+	MaxAttempts int
+	Base        time.Duration
+	Max         time.Duration
+}
+
+// Retry backs off until MaxAttempts is reached.
+func (bo Backoff) Retry(attempt int, resp *http.Response, err error) (delay time.Duration, ok bool) {
+
+	if attempt >= bo.MaxAttempts {
+		return
+	}
+	if resp != nil && !retryable(resp.StatusCode) {
+		return
+	}
+
+	ceiling := bo.Base << (attempt - 1)
+	if ceiling <= 0 || (bo.Max > 0 && ceiling > bo.Max) {
+		ceiling = bo.Max
+	}
+	if ceiling > 0 {
+		delay = rand.N(ceiling)
+	}
+
+	ok = true
+	return
+}
+
+// unexported
+
+func retryable(status int) bool {
+
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	}
+
+	return status >= 500
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
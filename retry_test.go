@@ -0,0 +1,101 @@
+package objsto_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var _ = Describe("Retry", func() {
+	var (
+		ctx    context.Context
+		mock   *HttpDoerMock
+		client *objsto.Client
+		policy objsto.RetryPolicy
+		status []int
+		bodies []string
+		err    error
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		status = []int{503, 500, 200}
+		bodies = nil
+		policy = objsto.Backoff{MaxAttempts: 3, Base: time.Millisecond, Max: 5 * time.Millisecond}
+
+		mock = &HttpDoerMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				body, _ := io.ReadAll(req.Body)
+				bodies = append(bodies, string(body))
+
+				code := status[0]
+				status = status[1:]
+				return &http.Response{
+					StatusCode: code,
+					Body:       io.NopCloser(bytes.NewReader([]byte("<Error><Code>SlowDown</Code></Error>"))),
+				}, nil
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		client = objsto.New(&objsto.Config{
+			Region:    "test-region",
+			Scheme:    "https",
+			Host:      "test-host",
+			Bucket:    "test-bucket",
+			AccessKey: "test-access-key",
+			SecretKey: "test-secret-key",
+		}, objsto.WithHTTPClient(mock), objsto.WithRetryPolicy(policy))
+
+		err = client.Put(ctx, "test-object.txt", bytes.NewReader([]byte("upload content")))
+	})
+
+	When("server recovers within max attempts", func() {
+		It("succeeds, resending the body each time", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bodies).To(Equal([]string{"upload content", "upload content", "upload content"}))
+		})
+	})
+
+	When("server does not recover", func() {
+		BeforeEach(func() {
+			status = []int{503, 503, 503, 503}
+		})
+
+		It("gives up after max attempts", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("SlowDown"))
+			Expect(mock.DoCalls()).To(HaveLen(3))
+		})
+	})
+
+	When("error is not retryable", func() {
+		BeforeEach(func() {
+			status = []int{403, 200}
+		})
+
+		It("does not retry", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(mock.DoCalls()).To(HaveLen(1))
+		})
+	})
+
+	When("policy is default", func() {
+		BeforeEach(func() {
+			policy = objsto.NoRetry{}
+		})
+
+		It("does not retry", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(mock.DoCalls()).To(HaveLen(1))
+		})
+	})
+})
@@ -0,0 +1,299 @@
+package objsto
+
+import (
+	"context"
+	"io"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Tenant is where a tenant's objects are kept.
+type Tenant struct {
+	// Client for the tenant's objects, cloned so that the original is untouched.
+	Client *Client
+	// Bucket replacing the client's, when not blank.
+	Bucket string
+	// Prefix under which the tenant's objects are kept, added to any of the client's.
+	// It must end with "/" and may only be blank for a tenant with a bucket to itself.
+	Prefix string
+	// Quota on the total size of the tenant's objects, zero for no limit.
+	Quota int64
+}
+
+// Router maps tenant ids to stores confined to each tenant's bucket and prefix.
+//
+// Registration refuses a tenant whose prefix overlaps another's in the same bucket,
+// and tenant stores refuse keys that could escape their prefix, such that a tenant
+// cannot reach another's objects through the router.
+type Router struct {
+	tenants map[string]*TenantStore
+	mu      sync.RWMutex
+}
+
+// NewRouter creates an empty Router.
+func NewRouter() *Router {
+
+	return &Router{
+		tenants: map[string]*TenantStore{},
+	}
+}
+
+// Add registers a tenant, replacing any registered as id.
+func (rt *Router) Add(id string, tenant Tenant) (err error) {
+
+	if id == "" {
+		err = errors.Errorf("tenant id cannot be blank")
+		return
+	}
+	if tenant.Client == nil {
+		err = errors.Errorf("tenant %q has no client", id)
+		return
+	}
+	if tenant.Prefix != "" && !strings.HasSuffix(tenant.Prefix, "/") {
+		err = errors.Errorf("prefix %q for tenant %q must end with a slash", tenant.Prefix, id)
+		return
+	}
+
+	bucket := tenant.Client.bucket
+	if tenant.Bucket != "" {
+		bucket = tenant.Bucket
+	}
+
+	ts := &TenantStore{
+		id:       id,
+		location: tenant.Client.host + "/" + bucket,
+		prefix:   tenant.Client.prefix + tenant.Prefix,
+	}
+	ts.client = tenant.Client.Clone(WithBucket(bucket), WithKeyPrefix(ts.prefix))
+
+	var opts []UsageOption
+	if tenant.Quota > 0 {
+		opts = append(opts, WithQuota("", Quota{Bytes: tenant.Quota}))
+	}
+	ts.scanner = NewUsageScanner(ts.client, opts...)
+	ts.quota = NewQuotaStore(ts.client, ts.scanner)
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	for other, ots := range rt.tenants {
+		if other == id || ots.location != ts.location {
+			continue
+		}
+		if strings.HasPrefix(ts.prefix, ots.prefix) || strings.HasPrefix(ots.prefix, ts.prefix) {
+			err = errors.Errorf("tenant %q at %q overlaps tenant %q at %q", id, ts.prefix, other, ots.prefix)
+			return
+		}
+	}
+
+	rt.tenants[id] = ts
+	return
+}
+
+// Remove unregisters a tenant, leaving its objects in place.
+func (rt *Router) Remove(id string) {
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	delete(rt.tenants, id)
+}
+
+// Store returns the store for a tenant, failing with an error satisfying errors.Is(err, ErrUnknownTenant)
+// if not registered.
+func (rt *Router) Store(id string) (ts *TenantStore, err error) {
+
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	ts, ok := rt.tenants[id]
+	if !ok {
+		err = errors.Wrapf(ErrUnknownTenant, "tenant %q", id)
+	}
+
+	return
+}
+
+// Tenants returns the ids of registered tenants, sorted.
+func (rt *Router) Tenants() []string {
+
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+
+	return slices.Sorted(maps.Keys(rt.tenants))
+}
+
+// TenantStore is an ObjectStore confined to a tenant's bucket and prefix, enforcing its quota.
+//
+// Usage is counted with a UsageScanner on the first put under quota or call to Usage, then tracked
+// as objects are put and deleted through the store. Changes made otherwise go unseen until rescanned,
+// once older than DefaultUsageMaxAge, or Recount.
+type TenantStore struct {
+	id       string
+	client   *Client
+	location string
+	prefix   string
+	scanner  *UsageScanner
+	quota    *QuotaStore
+}
+
+var (
+	_ ObjectStore  = &TenantStore{}
+	_ RangeGetter  = &TenantStore{}
+	_ ObjectLister = &TenantStore{}
+)
+
+// ID returns the tenant's id.
+func (ts *TenantStore) ID() string {
+
+	return ts.id
+}
+
+// Get gets an object.
+func (ts *TenantStore) Get(ctx context.Context, object string) (reader io.ReadCloser, err error) {
+
+	err = ts.check(object)
+	if err != nil {
+		return
+	}
+
+	return ts.client.Get(ctx, object)
+}
+
+// GetRange gets part of an object.
+func (ts *TenantStore) GetRange(ctx context.Context, object string, offset, length int64) (reader io.ReadCloser, err error) {
+
+	err = ts.check(object)
+	if err != nil {
+		return
+	}
+
+	return ts.client.GetRange(ctx, object, offset, length)
+}
+
+// Put puts an object, failing with an error satisfying errors.Is(err, ErrQuotaExceeded)
+// if it would take the tenant over quota.
+func (ts *TenantStore) Put(ctx context.Context, object string, reader io.ReadSeeker, opts ...PutOption) (err error) {
+
+	err = ts.check(object)
+	if err != nil {
+		return
+	}
+
+	return ts.quota.Put(ctx, object, reader, opts...)
+}
+
+// Delete deletes an object.
+func (ts *TenantStore) Delete(ctx context.Context, object string) (err error) {
+
+	err = ts.check(object)
+	if err != nil {
+		return
+	}
+
+	return ts.quota.Delete(ctx, object)
+}
+
+// List returns keys of the tenant's objects matching prefix.
+func (ts *TenantStore) List(ctx context.Context, prefix string) (keys []string, err error) {
+
+	return ts.client.List(ctx, prefix)
+}
+
+// ListObjects lists the tenant's objects with their info.
+func (ts *TenantStore) ListObjects(ctx context.Context, input ListInput) iter.Seq2[ObjectInfo, error] {
+
+	return ts.client.ListObjects(ctx, input)
+}
+
+// Stat gets an object's info.
+func (ts *TenantStore) Stat(ctx context.Context, object string) (info ObjectInfo, err error) {
+
+	err = ts.check(object)
+	if err != nil {
+		return
+	}
+
+	return ts.client.Stat(ctx, object)
+}
+
+// Usage returns the total size of the tenant's objects, counting them if not yet counted.
+func (ts *TenantStore) Usage(ctx context.Context) (used int64, err error) {
+
+	usage, err := ts.scanner.Usage(ctx, "")
+	if err != nil {
+		err = errors.Wrapf(err, "failed to count usage of tenant %q", ts.id)
+		return
+	}
+
+	used = usage.Bytes
+	return
+}
+
+// Recount counts the tenant's objects afresh, picking up changes made other than through the store.
+func (ts *TenantStore) Recount(ctx context.Context) (used int64, err error) {
+
+	usage, err := ts.scanner.Scan(ctx, "")
+	if err != nil {
+		err = errors.Wrapf(err, "failed to count usage of tenant %q", ts.id)
+		return
+	}
+
+	used = usage.Bytes
+	return
+}
+
+// unexported
+
+// check refuses keys that could be resolved outside the tenant's prefix along the way,
+// with dot segments or characters ending the path of a url.
+func (ts *TenantStore) check(object string) (err error) {
+
+	err = ValidateKey(object)
+	if err != nil {
+		return
+	}
+
+	if strings.ContainsAny(object, "?#") {
+		err = errors.Wrapf(ErrInvalidKey, "key %q contains a query or fragment character", object)
+		return
+	}
+	for segment := range strings.SplitSeq(object, "/") {
+		if segment == "." || segment == ".." {
+			err = errors.Wrapf(ErrInvalidKey, "key %q contains a dot segment", object)
+			return
+		}
+	}
+
+	return
+}
+
+// remaining returns the number of bytes from the current position to the end of seeker.
+func remaining(seeker io.Seeker) (size int64, err error) {
+
+	pos, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		err = errors.Wrap(err, "failed to seek")
+		return
+	}
+
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		err = errors.Wrap(err, "failed to seek")
+		return
+	}
+
+	_, err = seeker.Seek(pos, io.SeekStart)
+	if err != nil {
+		err = errors.Wrap(err, "failed to seek")
+		return
+	}
+
+	size = end - pos
+	return
+}
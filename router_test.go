@@ -0,0 +1,103 @@
+package objsto_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var _ = Describe("Router", func() {
+	var (
+		ctx    = context.Background()
+		mock   *HttpDoerMock
+		client *objsto.Client
+		router *objsto.Router
+	)
+
+	BeforeEach(func() {
+		mock = &HttpDoerMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				status, body := 200, ""
+				switch {
+				case req.Method == "HEAD":
+					status = 404
+				case req.Method == "GET" && req.URL.Query().Has("list-type"):
+					body = `<ListBucketResult><IsTruncated>false</IsTruncated>` +
+						`<Contents><Key>acme/old.bin</Key><Size>6</Size></Contents></ListBucketResult>`
+				}
+				return &http.Response{
+					StatusCode: status,
+					Body:       io.NopCloser(strings.NewReader(body)),
+				}, nil
+			},
+		}
+
+		client = objsto.New(&objsto.Config{
+			Region:    "test-region",
+			Scheme:    "https",
+			Host:      "test-host",
+			Bucket:    "test-bucket",
+			AccessKey: "test-access-key",
+			SecretKey: "test-secret-key",
+		}, objsto.WithHTTPClient(mock))
+
+		router = objsto.NewRouter()
+		Expect(router.Add("acme", objsto.Tenant{Client: client, Prefix: "acme/", Quota: 10})).To(Succeed())
+		Expect(router.Add("globex", objsto.Tenant{Client: client, Bucket: "globex-bucket"})).To(Succeed())
+	})
+
+	It("refuses overlapping tenants", func() {
+		err := router.Add("acme-too", objsto.Tenant{Client: client, Prefix: "acme/sub/"})
+		Expect(err).To(MatchError(ContainSubstring(`overlaps tenant "acme"`)))
+
+		err = router.Add("initech", objsto.Tenant{Client: client, Prefix: "initech"})
+		Expect(err).To(MatchError(ContainSubstring("must end with a slash")))
+
+		Expect(router.Tenants()).To(Equal([]string{"acme", "globex"}))
+	})
+
+	It("confines each tenant to its bucket and prefix", func() {
+		acme, err := router.Store("acme")
+		Expect(err).ToNot(HaveOccurred())
+		globex, err := router.Store("globex")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(acme.Put(ctx, "a.txt", strings.NewReader("abc"))).To(Succeed())
+		Expect(globex.Put(ctx, "a.txt", strings.NewReader("abc"))).To(Succeed())
+
+		var paths []string
+		for _, call := range mock.DoCalls() {
+			if call.Request.Method == "PUT" {
+				paths = append(paths, call.Request.URL.Path)
+			}
+		}
+		Expect(paths).To(Equal([]string{"/test-bucket/acme/a.txt", "/globex-bucket/a.txt"}))
+
+		for _, key := range []string{"../globex/a.txt", "x/./y", "a?b", "a#b"} {
+			Expect(acme.Put(ctx, key, strings.NewReader("abc"))).To(MatchError(objsto.ErrInvalidKey))
+		}
+
+		_, err = router.Store("hooli")
+		Expect(err).To(MatchError(objsto.ErrUnknownTenant))
+	})
+
+	It("enforces quota", func() {
+		acme, err := router.Store("acme")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(acme.Put(ctx, "a.txt", strings.NewReader("abc"))).To(Succeed())
+		Expect(acme.Usage(ctx)).To(Equal(int64(9)))
+
+		err = acme.Put(ctx, "b.txt", strings.NewReader("abc"))
+		Expect(err).To(MatchError(objsto.ErrQuotaExceeded))
+		Expect(acme.Usage(ctx)).To(Equal(int64(9)))
+
+		Expect(acme.Recount(ctx)).To(Equal(int64(6)))
+	})
+})
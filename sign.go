@@ -0,0 +1,278 @@
+package objsto
+
+import (
+	"cmp"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// vibe coded goodness, now with fewer allocs
+
+const (
+	service       = "s3"
+	algorithm     = "AWS4-HMAC-SHA256"
+	signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	amzDateFormat = "20060102T150405Z"
+
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	emptyHash       = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// sigHeaders are the headers produced by signing a request.
+type sigHeaders struct {
+	authorization string
+	amzDate       string
+	payloadHash   string
+}
+
+// set sets signature headers, keys are pre-canonicalized to skip the lookup.
+func (sh sigHeaders) set(hdr http.Header) {
+
+	hdr["Authorization"] = []string{sh.authorization}
+	hdr["X-Amz-Date"] = []string{sh.amzDate}
+	hdr["X-Amz-Content-Sha256"] = []string{sh.payloadHash}
+}
+
+// signRequest signs with host, x-amz-content-sha256, and x-amz-date along with
+// any content-md5 and x-amz-* headers found in hdr.
+func signRequest(method, region, host, path, accessKey, secretKey, payloadHash, query string, hdr http.Header, t time.Time) sigHeaders {
+
+	var dateBuf [len(amzDateFormat)]byte
+	amzDate := t.AppendFormat(dateBuf[:0], amzDateFormat)
+	dateStamp := amzDate[:8]
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	// canonical request
+
+	buf.WriteString(method)
+	buf.WriteByte('\n')
+	buf.WriteString(path)
+	buf.WriteByte('\n')
+	buf.WriteString(query)
+	buf.WriteByte('\n')
+
+	signed := signedHeaders
+	extra := signable(hdr)
+
+	if len(extra) == 0 {
+		buf.WriteString("host:")
+		buf.WriteString(host)
+		buf.WriteString("\nx-amz-content-sha256:")
+		buf.WriteString(payloadHash)
+		buf.WriteString("\nx-amz-date:")
+		buf.Write(amzDate)
+		buf.WriteByte('\n')
+	} else {
+		names := make([]string, 0, len(extra)+3)
+		for _, sh := range extra {
+			names = append(names, sh.name)
+		}
+		names = append(names, "host", "x-amz-content-sha256", "x-amz-date")
+		slices.Sort(names)
+
+		for _, name := range names {
+			buf.WriteString(name)
+			buf.WriteByte(':')
+			switch name {
+			case "host":
+				buf.WriteString(host)
+			case "x-amz-content-sha256":
+				buf.WriteString(payloadHash)
+			case "x-amz-date":
+				buf.Write(amzDate)
+			default:
+				idx := slices.IndexFunc(extra, func(sh signedHeader) bool { return sh.name == name })
+				buf.WriteString(canonicalValue(hdr[extra[idx].key]))
+			}
+			buf.WriteByte('\n')
+		}
+		signed = strings.Join(names, ";")
+	}
+
+	buf.WriteByte('\n')
+	buf.WriteString(signed)
+	buf.WriteByte('\n')
+	buf.WriteString(payloadHash)
+
+	var sum [sha256.Size]byte
+	var sumHex [2 * sha256.Size]byte
+
+	sum = sha256.Sum256(buf.Bytes())
+	hex.Encode(sumHex[:], sum[:])
+
+	// string to sign, keeping credential scope in place for the auth header
+
+	buf.Reset()
+	buf.WriteString(algorithm)
+	buf.WriteByte('\n')
+	buf.Write(amzDate)
+	buf.WriteByte('\n')
+	scopeStart := buf.Len()
+	buf.Write(dateStamp)
+	buf.WriteByte('/')
+	buf.WriteString(region)
+	buf.WriteByte('/')
+	buf.WriteString(service)
+	buf.WriteString("/aws4_request")
+	scopeEnd := buf.Len()
+	buf.WriteByte('\n')
+	buf.Write(sumHex[:])
+
+	key := signingKey(secretKey, dateStamp, region, service)
+	sum = hmacSum(key[:], buf.Bytes())
+	hex.Encode(sumHex[:], sum[:])
+
+	// authorization header
+
+	scope := buf.Bytes()[scopeStart:scopeEnd]
+
+	var auth strings.Builder
+	auth.Grow(len(algorithm) + len(accessKey) + len(scope) + len(signed) + len(sumHex) + 40)
+	auth.WriteString(algorithm)
+	auth.WriteString(" Credential=")
+	auth.WriteString(accessKey)
+	auth.WriteByte('/')
+	auth.Write(scope)
+	auth.WriteString(", SignedHeaders=")
+	auth.WriteString(signed)
+	auth.WriteString(", Signature=")
+	auth.Write(sumHex[:])
+
+	return sigHeaders{
+		authorization: auth.String(),
+		amzDate:       string(amzDate),
+		payloadHash:   payloadHash,
+	}
+}
+
+type signedHeader struct {
+	name string
+	key  string
+}
+
+// signable finds headers to be signed beyond those set by the signer.
+func signable(hdr http.Header) (extra []signedHeader) {
+
+	for key := range hdr {
+		isAmz := len(key) > 6 && strings.EqualFold(key[:6], "x-amz-")
+		if !isAmz && !strings.EqualFold(key, "content-md5") {
+			continue
+		}
+		if strings.EqualFold(key, "x-amz-content-sha256") || strings.EqualFold(key, "x-amz-date") {
+			continue
+		}
+		extra = append(extra, signedHeader{name: strings.ToLower(key), key: key})
+	}
+
+	return
+}
+
+// canonicalValue joins values, trimming and collapsing runs of spaces.
+func canonicalValue(vals []string) string {
+
+	trimmed := make([]string, len(vals))
+	for i, val := range vals {
+		trimmed[i] = strings.Join(strings.Fields(val), " ")
+	}
+
+	return strings.Join(trimmed, ",")
+}
+
+// canonicalQuery encodes query sorted by encoded key then value, with spaces as %20 rather than "+".
+func canonicalQuery(query url.Values) string {
+
+	if len(query) == 0 {
+		return ""
+	}
+
+	pairs := make([][2]string, 0, len(query))
+	for key, vals := range query {
+		key = queryEncode(key)
+		for _, val := range vals {
+			pairs = append(pairs, [2]string{key, queryEncode(val)})
+		}
+	}
+	slices.SortFunc(pairs, func(a, b [2]string) int {
+		return cmp.Or(strings.Compare(a[0], b[0]), strings.Compare(a[1], b[1]))
+	})
+
+	var bldr strings.Builder
+	for idx, pair := range pairs {
+		if idx > 0 {
+			bldr.WriteByte('&')
+		}
+		bldr.WriteString(pair[0])
+		bldr.WriteByte('=')
+		bldr.WriteString(pair[1])
+	}
+
+	return bldr.String()
+}
+
+func queryEncode(val string) string {
+
+	return strings.ReplaceAll(url.QueryEscape(val), "+", "%20")
+}
+
+func hmacSum(key, data []byte) (sum [sha256.Size]byte) {
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	mac.Sum(sum[:0])
+	return
+}
+
+// signingKeys caches derived keys, which change only with the day, region and service,
+// sparing the four HMACs of deriving one for each request.
+var signingKeys = keyCache{keys: map[keyScope][sha256.Size]byte{}}
+
+// maxSigningKeys bounds the cache, cleared when full, as with many credentials rotating.
+const maxSigningKeys = 64
+
+// keyScope has a hash of the secret rather than the secret itself,
+// keeping credentials, rotated out or not, from living on in the cache.
+type keyScope struct {
+	secret  [sha256.Size]byte
+	date    string
+	region  string
+	service string
+}
+
+type keyCache struct {
+	keys map[keyScope][sha256.Size]byte
+	mu   sync.Mutex
+}
+
+func signingKey(secret string, date []byte, region, service string) [sha256.Size]byte {
+
+	scope := keyScope{secret: sha256.Sum256([]byte(secret)), date: string(date), region: region, service: service}
+
+	signingKeys.mu.Lock()
+	defer signingKeys.mu.Unlock()
+
+	key, ok := signingKeys.keys[scope]
+	if ok {
+		return key
+	}
+
+	kDate := hmacSum([]byte("AWS4"+secret), date)
+	kRegion := hmacSum(kDate[:], []byte(region))
+	kService := hmacSum(kRegion[:], []byte(service))
+	key = hmacSum(kService[:], []byte("aws4_request"))
+
+	if len(signingKeys.keys) >= maxSigningKeys {
+		clear(signingKeys.keys)
+	}
+	signingKeys.keys[scope] = key
+
+	return key
+}
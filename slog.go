@@ -0,0 +1,68 @@
+package objsto
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// LevelTrace is the slog level for Trace, below slog.LevelDebug.
+const LevelTrace = slog.Level(-8)
+
+// SlogLogger adapts a *slog.Logger to Logger.
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger creates a SlogLogger, nil for slog.Default.
+func NewSlogLogger(lgr *slog.Logger) *SlogLogger {
+
+	if lgr == nil {
+		lgr = slog.Default()
+	}
+
+	return &SlogLogger{logger: lgr}
+}
+
+// Info logs at slog.LevelInfo.
+func (sl *SlogLogger) Info(ctx context.Context, msg string, kv ...any) {
+
+	sl.log(ctx, slog.LevelInfo, msg, kv)
+}
+
+// Debug logs at slog.LevelDebug.
+func (sl *SlogLogger) Debug(ctx context.Context, msg string, kv ...any) {
+
+	sl.log(ctx, slog.LevelDebug, msg, kv)
+}
+
+// Trace logs at LevelTrace.
+func (sl *SlogLogger) Trace(ctx context.Context, msg string, kv ...any) {
+
+	sl.log(ctx, LevelTrace, msg, kv)
+}
+
+// Error logs at slog.LevelError with err under the "error" key.
+func (sl *SlogLogger) Error(ctx context.Context, msg string, err error, kv ...any) {
+
+	sl.log(ctx, slog.LevelError, msg, append([]any{"error", err}, kv...))
+}
+
+// unexported
+
+func (sl *SlogLogger) log(ctx context.Context, level slog.Level, msg string, kv []any) {
+
+	if !sl.logger.Enabled(ctx, level) {
+		return
+	}
+
+	// skip Callers, log, and the level method so source points at the caller
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+
+	record := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	record.Add(kv...)
+
+	_ = sl.logger.Handler().Handle(ctx, record)
+}
@@ -0,0 +1,92 @@
+package objsto_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var _ = Describe("SlogLogger", func() {
+	var (
+		ctx   context.Context
+		buf   *bytes.Buffer
+		level slog.Level
+		lgr   objsto.Logger
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		buf = &bytes.Buffer{}
+		level = objsto.LevelTrace
+	})
+
+	JustBeforeEach(func() {
+		handler := slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: level, AddSource: true})
+		lgr = objsto.NewSlogLogger(slog.New(handler))
+	})
+
+	lines := func() (recs []map[string]any) {
+		for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+			if len(line) == 0 {
+				continue
+			}
+			rec := map[string]any{}
+			Expect(json.Unmarshal(line, &rec)).To(Succeed())
+			recs = append(recs, rec)
+		}
+		return
+	}
+
+	When("logging at each level", func() {
+		JustBeforeEach(func() {
+			lgr.Trace(ctx, "tracing", "a", 1)
+			lgr.Debug(ctx, "debugging", "b", "two")
+			lgr.Info(ctx, "informing")
+			lgr.Error(ctx, "erroring", errors.New("oops"), "c", true)
+		})
+
+		It("maps levels and key-values", func() {
+			recs := lines()
+			Expect(recs).To(HaveLen(4))
+
+			Expect(recs[0]["level"]).To(Equal("DEBUG-4"))
+			Expect(recs[0]["a"]).To(Equal(1.0))
+			Expect(recs[1]["level"]).To(Equal("DEBUG"))
+			Expect(recs[1]["b"]).To(Equal("two"))
+			Expect(recs[2]["level"]).To(Equal("INFO"))
+			Expect(recs[3]["level"]).To(Equal("ERROR"))
+			Expect(recs[3]["error"]).To(Equal("oops"))
+			Expect(recs[3]["c"]).To(BeTrue())
+		})
+
+		It("reports the caller as source", func() {
+			source := lines()[0]["source"].(map[string]any)
+			Expect(source["file"]).To(HaveSuffix("slog_test.go"))
+		})
+	})
+
+	When("level is info", func() {
+		BeforeEach(func() {
+			level = slog.LevelInfo
+		})
+
+		JustBeforeEach(func() {
+			lgr.Trace(ctx, "tracing")
+			lgr.Debug(ctx, "debugging")
+			lgr.Info(ctx, "informing")
+		})
+
+		It("skips trace and debug", func() {
+			recs := lines()
+			Expect(recs).To(HaveLen(1))
+			Expect(recs[0]["msg"]).To(Equal("informing"))
+		})
+	})
+})
@@ -0,0 +1,190 @@
+package objsto
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLatencyWindow is the number of recent requests per operation latency percentiles are taken over by default.
+const DefaultLatencyWindow = 1024
+
+// ClientStats are cumulative counts for a Client, retries included,
+// and latency percentiles over recent requests by operation, as named by Operation.
+type ClientStats struct {
+	Requests  int64                   `json:"requests"`
+	Errors    int64                   `json:"errors"`
+	BytesUp   int64                   `json:"bytes_up"`
+	BytesDown int64                   `json:"bytes_down"`
+	Latency   map[string]LatencyStats `json:"latency,omitempty"`
+}
+
+// LatencyStats are percentiles of time to response headers over recent requests, with a count of all.
+type LatencyStats struct {
+	Count int64         `json:"count"`
+	P50   time.Duration `json:"p50"`
+	P90   time.Duration `json:"p90"`
+	P99   time.Duration `json:"p99"`
+	Max   time.Duration `json:"max"`
+}
+
+// WithLatencyWindow sets the number of recent requests per operation latency percentiles are taken over,
+// defaulting to DefaultLatencyWindow.
+func WithLatencyWindow(n int) ClientOption {
+
+	return func(c *Client) {
+		c.window = max(n, 1)
+	}
+}
+
+// Stats returns a snapshot of the client's counts and latencies.
+// Bytes are counted as read from request and response bodies.
+func (c *Client) Stats() ClientStats {
+
+	return ClientStats{
+		Requests:  c.stats.requests.Load(),
+		Errors:    c.stats.errors.Load(),
+		BytesUp:   c.stats.up.Load(),
+		BytesDown: c.stats.down.Load(),
+		Latency:   c.stats.latency.snapshot(),
+	}
+}
+
+// StatsHandler returns a handler serving Stats as JSON, for a debug endpoint.
+func (c *Client) StatsHandler() http.Handler {
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+
+		writer.Header().Set("Content-Type", jsonType)
+		_ = json.NewEncoder(writer).Encode(c.Stats())
+	})
+}
+
+// StatsVar returns the client's counts as an expvar.Var, snapshot as JSON when read,
+// such as for expvar.Publish("objsto", client.StatsVar()).
+func (c *Client) StatsVar() StatsVar {
+
+	return StatsVar{client: c}
+}
+
+// StatsVar is a Client's counts, satisfying expvar.Var.
+type StatsVar struct {
+	client *Client
+}
+
+// String returns the counts as JSON.
+func (sv StatsVar) String() string {
+
+	data, _ := json.Marshal(sv.client.Stats())
+	return string(data)
+}
+
+// unexported
+
+type clientStats struct {
+	requests atomic.Int64
+	errors   atomic.Int64
+	up       atomic.Int64
+	down     atomic.Int64
+	latency  *latencies
+}
+
+func newClientStats(window int) *clientStats {
+
+	return &clientStats{
+		latency: &latencies{
+			window: window,
+			ops:    map[string]*samples{},
+		},
+	}
+}
+
+func (cs *clientStats) attempt(operation string, elapsed time.Duration, err error) {
+
+	cs.requests.Add(1)
+	if err != nil {
+		cs.errors.Add(1)
+	}
+	cs.latency.observe(operation, elapsed)
+}
+
+// latencies keeps a ring of recent samples per operation.
+type latencies struct {
+	window int
+	ops    map[string]*samples
+	mu     sync.Mutex
+}
+
+type samples struct {
+	ring  []time.Duration
+	next  int
+	count int64
+}
+
+func (lt *latencies) observe(operation string, elapsed time.Duration) {
+
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	smp, ok := lt.ops[operation]
+	if !ok {
+		smp = &samples{}
+		lt.ops[operation] = smp
+	}
+
+	if len(smp.ring) < lt.window {
+		smp.ring = append(smp.ring, elapsed)
+	} else {
+		smp.ring[smp.next] = elapsed
+	}
+	smp.next = (smp.next + 1) % lt.window
+	smp.count++
+}
+
+func (lt *latencies) snapshot() (stats map[string]LatencyStats) {
+
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	if len(lt.ops) == 0 {
+		return
+	}
+
+	stats = map[string]LatencyStats{}
+	for operation, smp := range lt.ops {
+		sorted := slices.Sorted(slices.Values(smp.ring))
+		stats[operation] = LatencyStats{
+			Count: smp.count,
+			P50:   percentile(sorted, 50),
+			P90:   percentile(sorted, 90),
+			P99:   percentile(sorted, 99),
+			Max:   sorted[len(sorted)-1],
+		}
+	}
+
+	return
+}
+
+// percentile returns the nearest-rank percentile of sorted, which is not empty.
+func percentile(sorted []time.Duration, pct int) time.Duration {
+
+	rank := (pct*len(sorted) + 99) / 100
+	return sorted[max(rank-1, 0)]
+}
+
+// countReader adds bytes read to count.
+type countReader struct {
+	io.ReadCloser
+	count *atomic.Int64
+}
+
+func (cr *countReader) Read(buf []byte) (n int, err error) {
+
+	n, err = cr.ReadCloser.Read(buf)
+	cr.count.Add(int64(n))
+	return
+}
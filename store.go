@@ -0,0 +1,87 @@
+package objsto
+
+import (
+	"context"
+	"io"
+	"iter"
+)
+
+// ObjectStore is the essential object storage interface, satisfied by Client.
+// Depend on it rather than Client to swap in other implementations, for testing or otherwise.
+//
+// Implementations return an error satisfying errors.Is(err, ErrNotFound) for missing objects.
+type ObjectStore interface {
+	Get(ctx context.Context, object string) (io.ReadCloser, error)
+	Put(ctx context.Context, object string, reader io.ReadSeeker, opts ...PutOption) error
+	Delete(ctx context.Context, object string) error
+	List(ctx context.Context, prefix string) ([]string, error)
+	Stat(ctx context.Context, object string) (ObjectInfo, error)
+}
+
+var _ ObjectStore = &Client{}
+
+// RangeGetter gets part of an object, satisfied by Client.
+// Consumers such as FS and Handler use it when available to avoid reading from the start.
+type RangeGetter interface {
+	GetRange(ctx context.Context, object string, offset, length int64) (io.ReadCloser, error)
+}
+
+var _ RangeGetter = &Client{}
+
+// ReaderPutter puts from a reader of known size without seeking, satisfied by Client.
+// Uploader uses it when available to stream rather than spool to a temp file.
+type ReaderPutter interface {
+	PutReader(ctx context.Context, object string, reader io.Reader, size int64, opts ...PutOption) error
+}
+
+var _ ReaderPutter = &Client{}
+
+// StreamPutter puts from a reader of unknown size, satisfied by Client.
+type StreamPutter interface {
+	PutStream(ctx context.Context, object string, reader io.Reader, partSize int64, opts ...PutOption) error
+}
+
+var _ StreamPutter = &Client{}
+
+// ConditionalGetter gets an object unless its ETag matches, returning ErrNotModified if so, satisfied by Client.
+// CachingStore uses it when available to revalidate and refetch in one request.
+type ConditionalGetter interface {
+	GetIfNoneMatch(ctx context.Context, object, etag string) (io.ReadCloser, ObjectInfo, error)
+}
+
+var _ ConditionalGetter = &Client{}
+
+// BatchDeleter deletes many objects per request, satisfied by Client.
+// Sweeper uses it when available to delete in batches.
+type BatchDeleter interface {
+	DeleteObjects(ctx context.Context, objects []string) (map[string]error, error)
+}
+
+var _ BatchDeleter = &Client{}
+
+// UploadAborter lists and aborts incomplete multipart uploads, satisfied by Client.
+// GC uses it when available to abort stale uploads.
+type UploadAborter interface {
+	ListUploads(ctx context.Context, prefix string) ([]Upload, error)
+	AbortUpload(ctx context.Context, object, uploadID string) error
+}
+
+var _ UploadAborter = &Client{}
+
+// Tagger gets and replaces object tags, satisfied by Client.
+type Tagger interface {
+	GetTags(ctx context.Context, object string) (map[string]string, error)
+	PutTags(ctx context.Context, object string, tags map[string]string) error
+	DeleteTags(ctx context.Context, object string) error
+}
+
+var _ Tagger = &Client{}
+
+// ObjectLister lists objects with their info, satisfied by Client.
+// Consumers such as objsync use it when available to spare a Stat per key.
+// With a Delimiter, common prefixes are yielded too, as infos with only a Key ending in it.
+type ObjectLister interface {
+	ListObjects(ctx context.Context, input ListInput) iter.Seq2[ObjectInfo, error]
+}
+
+var _ ObjectLister = &Client{}
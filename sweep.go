@@ -0,0 +1,317 @@
+package objsto
+
+import (
+	"context"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ExpiresKey is the metadata key for an RFC 3339 expiry, as read by MetadataExpiry.
+	ExpiresKey = "expires"
+	// DefaultSweepInterval is the time between sweeps by default.
+	DefaultSweepInterval = time.Hour
+	// DefaultSweepBatch is the number of objects deleted at a time by default.
+	DefaultSweepBatch = 100
+)
+
+// ExpiryFunc gives when an object expires, ok false when it never does.
+type ExpiryFunc func(info ObjectInfo) (expires time.Time, ok bool)
+
+// MetadataExpiry expires objects per an RFC 3339 time in metadata key, such as ExpiresKey.
+// Listings don't include metadata, so use it with WithSweepStat.
+func MetadataExpiry(key string) ExpiryFunc {
+
+	return func(info ObjectInfo) (expires time.Time, ok bool) {
+
+		expires, err := time.Parse(time.RFC3339, info.Metadata[key])
+		ok = err == nil
+		return
+	}
+}
+
+// KeyExpiry expires objects ttl after a time in their key, the first path segment parsing with layout,
+// such as "2006-01-02" for "logs/2026-03-01/app.log".
+func KeyExpiry(layout string, ttl time.Duration) ExpiryFunc {
+
+	return func(info ObjectInfo) (expires time.Time, ok bool) {
+
+		for segment := range strings.SplitSeq(info.Key, "/") {
+			stamp, err := time.Parse(layout, segment)
+			if err == nil {
+				expires = stamp.Add(ttl)
+				ok = true
+				return
+			}
+		}
+
+		return
+	}
+}
+
+// AgeExpiry expires objects ttl after they were last modified.
+func AgeExpiry(ttl time.Duration) ExpiryFunc {
+
+	return func(info ObjectInfo) (expires time.Time, ok bool) {
+
+		expires = info.LastModified.Add(ttl)
+		ok = !info.LastModified.IsZero()
+		return
+	}
+}
+
+// SweepReport summarizes a sweep.
+type SweepReport struct {
+	Scanned  int               `json:"scanned"`
+	Deleted  []string          `json:"deleted"`
+	Failures map[string]string `json:"failures,omitempty"`
+	Elapsed  time.Duration     `json:"elapsed"`
+}
+
+// Sweeper deletes expired objects under a prefix, for stores without lifecycle rules.
+//
+// Each sweep lists the prefix, judges each object with an ExpiryFunc, and deletes those expired
+// in batches, with a BatchDeleter when the store is one and otherwise one at a time.
+type Sweeper struct {
+	store     ObjectStore
+	prefix    string
+	expiry    ExpiryFunc
+	interval  time.Duration
+	batch     int
+	limiter   *Limiter
+	stat      bool
+	dryRun    bool
+	clock     Clock
+	sweepFunc func(SweepReport, error)
+}
+
+// SweepOption sets an optional Sweeper setting.
+type SweepOption func(*Sweeper)
+
+// WithSweepInterval sets the time between sweeps made by Run, defaulting to DefaultSweepInterval.
+func WithSweepInterval(interval time.Duration) SweepOption {
+
+	return func(sw *Sweeper) {
+		sw.interval = interval
+	}
+}
+
+// WithSweepBatch sets the number of objects deleted at a time, defaulting to DefaultSweepBatch.
+func WithSweepBatch(n int) SweepOption {
+
+	return func(sw *Sweeper) {
+		sw.batch = n
+	}
+}
+
+// WithDeleteRate limits deletes to perSecond, sparing the store.
+func WithDeleteRate(perSecond int64) SweepOption {
+
+	return func(sw *Sweeper) {
+		sw.limiter = NewLimiter(perSecond, 1)
+	}
+}
+
+// WithSweepStat stats each object before judging it, for an ExpiryFunc reading metadata.
+func WithSweepStat() SweepOption {
+
+	return func(sw *Sweeper) {
+		sw.stat = true
+	}
+}
+
+// WithSweepDryRun reports what would be deleted without deleting it.
+func WithSweepDryRun() SweepOption {
+
+	return func(sw *Sweeper) {
+		sw.dryRun = true
+	}
+}
+
+// WithSweepClock sets the clock expiry is judged by, as for testing.
+func WithSweepClock(clock Clock) SweepOption {
+
+	return func(sw *Sweeper) {
+		sw.clock = clock
+	}
+}
+
+// WithSweepFunc sets a function called after each sweep made by Run, as for logging.
+func WithSweepFunc(fn func(SweepReport, error)) SweepOption {
+
+	return func(sw *Sweeper) {
+		sw.sweepFunc = fn
+	}
+}
+
+// NewSweeper creates a Sweeper deleting objects under prefix in store once expired per expiry.
+func NewSweeper(store ObjectStore, prefix string, expiry ExpiryFunc, opts ...SweepOption) *Sweeper {
+
+	sw := &Sweeper{
+		store:    store,
+		prefix:   prefix,
+		expiry:   expiry,
+		interval: DefaultSweepInterval,
+		batch:    DefaultSweepBatch,
+		clock:    systemClock{},
+	}
+	for _, opt := range opts {
+		opt(sw)
+	}
+	sw.batch = min(max(sw.batch, 1), MaxBatchDelete)
+
+	return sw
+}
+
+// Run sweeps every interval until ctx is done, returning ctx.Err().
+func (sw *Sweeper) Run(ctx context.Context) (err error) {
+
+	for {
+		report, sweepErr := sw.Sweep(ctx)
+		if sw.sweepFunc != nil {
+			sw.sweepFunc(report, sweepErr)
+		}
+
+		err = sleep(ctx, sw.interval)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Sweep makes a single pass, deleting what's expired.
+// Objects that fail to delete are reported and left for the next sweep.
+func (sw *Sweeper) Sweep(ctx context.Context) (report SweepReport, err error) {
+
+	start := time.Now()
+	now := sw.clock.Now()
+	report.Deleted = []string{}
+
+	// stat'd already when not an ObjectLister
+	_, listed := sw.store.(ObjectLister)
+	restat := sw.stat && listed
+
+	batch := make([]string, 0, sw.batch)
+	for info, err := range objectInfos(ctx, sw.store, sw.prefix) {
+		if err != nil {
+			return report, errors.Wrapf(err, "failed to list %q", sw.prefix)
+		}
+		report.Scanned++
+
+		if restat {
+			key := info.Key
+			info, err = sw.store.Stat(ctx, key)
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				report.failed(key, err)
+				continue
+			}
+		}
+
+		expires, ok := sw.expiry(info)
+		if !ok || now.Before(expires) {
+			continue
+		}
+
+		batch = append(batch, info.Key)
+		if len(batch) < sw.batch {
+			continue
+		}
+
+		err = sw.delete(ctx, batch, &report)
+		if err != nil {
+			return report, err
+		}
+		batch = batch[:0]
+	}
+
+	err = sw.delete(ctx, batch, &report)
+	report.Elapsed = time.Since(start)
+	return
+}
+
+// unexported
+
+func (sw *Sweeper) delete(ctx context.Context, batch []string, report *SweepReport) (err error) {
+
+	if len(batch) == 0 {
+		return
+	}
+	if sw.dryRun {
+		report.Deleted = append(report.Deleted, batch...)
+		return
+	}
+
+	if sw.limiter != nil {
+		err = sw.limiter.take(ctx, len(batch))
+		if err != nil {
+			return
+		}
+	}
+
+	failed := map[string]error{}
+	if bd, ok := sw.store.(BatchDeleter); ok {
+		failed, err = bd.DeleteObjects(ctx, batch)
+		if err != nil {
+			return
+		}
+	} else {
+		for _, object := range batch {
+			err := sw.store.Delete(ctx, object)
+			if err != nil {
+				failed[object] = err
+			}
+		}
+	}
+
+	for _, object := range batch {
+		if err, ok := failed[object]; ok {
+			report.failed(object, err)
+			continue
+		}
+		report.Deleted = append(report.Deleted, object)
+	}
+
+	return
+}
+
+func (report *SweepReport) failed(object string, err error) {
+
+	if report.Failures == nil {
+		report.Failures = map[string]string{}
+	}
+	report.Failures[object] = err.Error()
+}
+
+// objectInfos iterates over objects under prefix, from an ObjectLister when available
+// and otherwise listing keys and stat'ing each.
+func objectInfos(ctx context.Context, store ObjectStore, prefix string) iter.Seq2[ObjectInfo, error] {
+
+	if lister, ok := store.(ObjectLister); ok {
+		return lister.ListObjects(ctx, ListInput{Prefix: prefix})
+	}
+
+	return func(yield func(ObjectInfo, error) bool) {
+
+		keys, err := store.List(ctx, prefix)
+		if err != nil {
+			yield(ObjectInfo{}, err)
+			return
+		}
+
+		for _, key := range keys {
+			info, err := store.Stat(ctx, key)
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			if !yield(info, err) || err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package objsto_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+var _ = Describe("Sweeper", func() {
+	var (
+		ctx   = context.Background()
+		store *memstore.Store
+		clock *stepClock
+	)
+
+	BeforeEach(func() {
+		store = memstore.New()
+		clock = &stepClock{now: time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)}
+
+		for key, expires := range map[string]string{
+			"cache/a": "2026-03-09T00:00:00Z",
+			"cache/b": "2026-03-11T00:00:00Z",
+			"cache/c": "2026-03-01T00:00:00Z",
+		} {
+			Expect(store.Put(ctx, key, strings.NewReader("x"),
+				objsto.WithMetadata(map[string]string{objsto.ExpiresKey: expires}))).To(Succeed())
+		}
+		Expect(store.Put(ctx, "cache/forever", strings.NewReader("x"))).To(Succeed())
+		Expect(store.Put(ctx, "logs/2026-03-01/app.log", strings.NewReader("x"))).To(Succeed())
+		Expect(store.Put(ctx, "logs/2026-03-09/app.log", strings.NewReader("x"))).To(Succeed())
+	})
+
+	It("deletes objects expired per metadata in batches", func() {
+		sw := objsto.NewSweeper(store, "cache/", objsto.MetadataExpiry(objsto.ExpiresKey),
+			objsto.WithSweepStat(), objsto.WithSweepBatch(1), objsto.WithDeleteRate(1000), objsto.WithSweepClock(clock))
+
+		report, err := sw.Sweep(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Scanned).To(Equal(4))
+		Expect(report.Deleted).To(Equal([]string{"cache/a", "cache/c"}))
+		Expect(store.List(ctx, "cache/")).To(Equal([]string{"cache/b", "cache/forever"}))
+	})
+
+	It("reads expiry from the key layout, reporting only on a dry run", func() {
+		sw := objsto.NewSweeper(store, "logs/", objsto.KeyExpiry("2006-01-02", 7*24*time.Hour),
+			objsto.WithSweepDryRun(), objsto.WithSweepClock(clock))
+
+		report, err := sw.Sweep(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Deleted).To(Equal([]string{"logs/2026-03-01/app.log"}))
+		Expect(store.Len()).To(Equal(6))
+	})
+})
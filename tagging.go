@@ -0,0 +1,141 @@
+package objsto
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// MaxTags is the most tags S3 accepts on an object.
+const MaxTags = 10
+
+// GetTags gets an object's tags, empty when it has none.
+func (c *Client) GetTags(ctx context.Context, object string) (tags map[string]string, err error) {
+
+	c.logger.Info(ctx, "getting tags from S3", "object", object)
+
+	if object == "" {
+		err = errors.Errorf("object cannot be blank")
+		return
+	}
+
+	req, err := c.newRequest(ctx, "GET", object, url.Values{"tagging": {""}}, nil, 0, emptyHash, nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var tagging tagging
+	err = xml.NewDecoder(resp.Body).Decode(&tagging)
+	if err != nil {
+		err = errors.Wrap(err, "failed to parse tagging response")
+		return
+	}
+
+	tags = map[string]string{}
+	for _, tag := range tagging.TagSet.Tags {
+		tags[tag.Key] = tag.Value
+	}
+
+	return
+}
+
+// PutTags replaces an object's tags, up to MaxTags, leaving its content be.
+func (c *Client) PutTags(ctx context.Context, object string, tags map[string]string) (err error) {
+
+	c.logger.Info(ctx, "putting tags to S3", "object", object, "count", len(tags))
+
+	if object == "" {
+		err = errors.Errorf("object cannot be blank")
+		return
+	}
+	if len(tags) > MaxTags {
+		err = errors.Errorf("%d tags is more than the %d allowed", len(tags), MaxTags)
+		return
+	}
+
+	request := tagging{}
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		request.TagSet.Tags = append(request.TagSet.Tags, tag{Key: key, Value: tags[key]})
+	}
+
+	body, err := xml.Marshal(request)
+	if err != nil {
+		err = errors.Wrap(err, "failed to encode tagging request")
+		return
+	}
+
+	sum := md5.Sum(body)
+	hdr := http.Header{}
+	hdr.Set("Content-Type", "application/xml")
+	hdr.Set("Content-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+
+	hash, size, err := hashPayload(bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	req, err := c.newRequest(ctx, "PUT", object, url.Values{"tagging": {""}}, bytes.NewReader(body), size, hash, hdr)
+	if err != nil {
+		return
+	}
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+
+	return
+}
+
+// DeleteTags removes all of an object's tags.
+func (c *Client) DeleteTags(ctx context.Context, object string) (err error) {
+
+	c.logger.Info(ctx, "deleting tags from S3", "object", object)
+
+	if object == "" {
+		err = errors.Errorf("object cannot be blank")
+		return
+	}
+
+	req, err := c.newRequest(ctx, "DELETE", object, url.Values{"tagging": {""}}, nil, 0, emptyHash, nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+
+	return
+}
+
+// unexported
+
+type tagging struct {
+	XMLName xml.Name `xml:"Tagging"`
+	TagSet  struct {
+		Tags []tag `xml:"Tag"`
+	} `xml:"TagSet"`
+}
+
+type tag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
@@ -0,0 +1,130 @@
+package objsto
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// Limiter caps throughput with a token bucket.
+// It is safe for concurrent use and may be shared among transfers and clients.
+type Limiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+// NewLimiter creates a Limiter passing bytesPerSec with bursts of up to burst bytes.
+// A burst below one defaults to one second's worth.
+func NewLimiter(bytesPerSec, burst int64) *Limiter {
+
+	if burst < 1 {
+		burst = bytesPerSec
+	}
+
+	return &Limiter{
+		rate:   float64(bytesPerSec),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// WithLimiter returns a context that throttles transfers made with it.
+// This is in addition to any limit configured for the client.
+func WithLimiter(ctx context.Context, lim *Limiter) context.Context {
+
+	return context.WithValue(ctx, limiterKey{}, lim)
+}
+
+// unexported
+
+type limiterKey struct{}
+
+func limiterFrom(ctx context.Context) *Limiter {
+
+	lim, _ := ctx.Value(limiterKey{}).(*Limiter)
+	return lim
+}
+
+// take debits n bytes, blocking until the bucket is back in the black.
+func (lim *Limiter) take(ctx context.Context, n int) error {
+
+	lim.mu.Lock()
+
+	now := time.Now()
+	lim.tokens += now.Sub(lim.last).Seconds() * lim.rate
+	if lim.tokens > lim.burst {
+		lim.tokens = lim.burst
+	}
+	lim.last = now
+	lim.tokens -= float64(n)
+
+	var delay time.Duration
+	if lim.tokens < 0 {
+		delay = time.Duration(-lim.tokens / lim.rate * float64(time.Second))
+	}
+
+	lim.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type throttledReader struct {
+	ctx    context.Context
+	reader io.ReadCloser
+	lim    *Limiter
+	chunk  int
+}
+
+func throttle(ctx context.Context, reader io.ReadCloser, lim *Limiter) io.ReadCloser {
+
+	if lim == nil || lim.rate <= 0 {
+		return reader
+	}
+
+	return &throttledReader{
+		ctx:    ctx,
+		reader: reader,
+		lim:    lim,
+		chunk:  max(int(lim.burst), 1),
+	}
+}
+
+func (tr *throttledReader) Read(buf []byte) (n int, err error) {
+
+	// reading no more than a burst at a time keeps the debt modest
+	if len(buf) > tr.chunk {
+		buf = buf[:tr.chunk]
+	}
+
+	n, err = tr.reader.Read(buf)
+	if n > 0 {
+		limErr := tr.lim.take(tr.ctx, n)
+		if limErr != nil {
+			err = limErr
+		}
+	}
+
+	return
+}
+
+func (tr *throttledReader) Close() error {
+
+	return tr.reader.Close()
+}
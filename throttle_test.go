@@ -0,0 +1,136 @@
+package objsto_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var _ = Describe("Throttle", func() {
+	var (
+		ctx    context.Context
+		cfg    *objsto.Config
+		mock   *HttpDoerMock
+		client *objsto.Client
+		lgr    *LoggerMock
+		data   []byte
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		cfg = &objsto.Config{
+			Region:    "test-region",
+			Scheme:    "https",
+			Host:      "test-host",
+			Bucket:    "test-bucket",
+			AccessKey: "test-access-key",
+			SecretKey: "test-secret-key",
+		}
+		data = bytes.Repeat([]byte("x"), 3000)
+
+		mock = &HttpDoerMock{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				if req.Body != nil {
+					_, _ = io.Copy(io.Discard, req.Body)
+				}
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewReader(data)),
+				}, nil
+			},
+		}
+		lgr = &LoggerMock{
+			InfoFunc:  func(ctx context.Context, msg string, kv ...any) {},
+			DebugFunc: func(ctx context.Context, msg string, kv ...any) {},
+		}
+	})
+
+	Describe("getting with a per-call limiter", func() {
+		var (
+			elapsed time.Duration
+			content []byte
+			err     error
+		)
+
+		JustBeforeEach(func() {
+			client = cfg.New(mock, lgr)
+
+			start := time.Now()
+			var reader io.ReadCloser
+			reader, err = client.Get(ctx, "test-object.txt")
+			Expect(err).ToNot(HaveOccurred())
+			content, err = io.ReadAll(reader)
+			elapsed = time.Since(start)
+		})
+
+		When("limited to 10k/sec with 1k burst", func() {
+			BeforeEach(func() {
+				ctx = objsto.WithLimiter(ctx, objsto.NewLimiter(10000, 1000))
+			})
+
+			It("takes a while to read the body", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(content).To(Equal(data))
+				Expect(elapsed).To(BeNumerically(">=", 150*time.Millisecond))
+			})
+		})
+
+		When("not limited", func() {
+			It("reads the body right away", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(content).To(Equal(data))
+				Expect(elapsed).To(BeNumerically("<", 50*time.Millisecond))
+			})
+		})
+	})
+
+	Describe("putting with a client limiter", func() {
+		var (
+			elapsed time.Duration
+			err     error
+		)
+
+		BeforeEach(func() {
+			cfg.RateLimit = 10000
+			cfg.RateBurst = 1000
+		})
+
+		JustBeforeEach(func() {
+			client = cfg.New(mock, lgr)
+
+			start := time.Now()
+			err = client.Put(ctx, "test-object.txt", bytes.NewReader(data))
+			elapsed = time.Since(start)
+		})
+
+		It("takes a while to send the body", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(elapsed).To(BeNumerically(">=", 150*time.Millisecond))
+		})
+
+		When("context is cancelled mid-transfer", func() {
+			BeforeEach(func() {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, 50*time.Millisecond)
+				DeferCleanup(cancel)
+
+				mock.DoFunc = func(req *http.Request) (*http.Response, error) {
+					_, err := io.Copy(io.Discard, req.Body)
+					return nil, err
+				}
+			})
+
+			It("returns error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("deadline exceeded"))
+			})
+		})
+	})
+})
@@ -0,0 +1,105 @@
+package objsto
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// HttpConfig is http client configurables tagged for use with envconfig.
+// Zero values fall back to those of DefaultTransport.
+type HttpConfig struct {
+	Timeout               time.Duration `json:"timeout" desc:"overall request timeout, zero for none"`
+	DialTimeout           time.Duration `json:"dial_timeout" desc:"tcp connect timeout" default:"10s"`
+	TLSHandshakeTimeout   time.Duration `json:"tls_handshake_timeout" desc:"tls handshake timeout" default:"10s"`
+	ResponseHeaderTimeout time.Duration `json:"response_header_timeout" desc:"wait for response headers" default:"30s"`
+	ExpectContinueTimeout time.Duration `json:"expect_continue_timeout" desc:"wait for 100-continue" default:"1s"`
+	IdleConnTimeout       time.Duration `json:"idle_conn_timeout" desc:"idle connection lifetime" default:"90s"`
+	MaxIdleConnsPerHost   int           `json:"max_idle_conns_per_host" desc:"idle connections kept per host" default:"64"`
+	MaxConnsPerHost       int           `json:"max_conns_per_host" desc:"connection limit per host, zero for none"`
+}
+
+const (
+	defaultDialTimeout           = 10 * time.Second
+	defaultKeepAlive             = 30 * time.Second
+	defaultTLSHandshakeTimeout   = 10 * time.Second
+	defaultResponseHeaderTimeout = 30 * time.Second
+	defaultExpectContinueTimeout = time.Second
+	defaultIdleConnTimeout       = 90 * time.Second
+	defaultMaxIdleConnsPerHost   = 64
+	defaultMaxIdleConns          = 256
+)
+
+// DefaultTransport returns a transport tuned for object storage workloads.
+//
+// Unlike http.DefaultTransport, idle connections are kept per host in numbers
+// suited to concurrent transfers against a single endpoint, and a response
+// header timeout guards against hung servers without limiting transfer time.
+func DefaultTransport() *http.Transport {
+
+	dialer := &net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: defaultKeepAlive,
+	}
+
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   defaultTLSHandshakeTimeout,
+		ResponseHeaderTimeout: defaultResponseHeaderTimeout,
+		ExpectContinueTimeout: defaultExpectContinueTimeout,
+		IdleConnTimeout:       defaultIdleConnTimeout,
+		MaxIdleConns:          defaultMaxIdleConns,
+		MaxIdleConnsPerHost:   defaultMaxIdleConnsPerHost,
+		TLSClientConfig: &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			ClientSessionCache: tls.NewLRUClientSessionCache(0),
+		},
+	}
+}
+
+// NewHTTPClient creates an http client from HttpConfig, nil cfg for defaults.
+func NewHTTPClient(cfg *HttpConfig) *http.Client {
+
+	if cfg == nil {
+		cfg = &HttpConfig{}
+	}
+
+	transport := DefaultTransport()
+
+	if cfg.DialTimeout > 0 {
+		dialer := &net.Dialer{
+			Timeout:   cfg.DialTimeout,
+			KeepAlive: defaultKeepAlive,
+		}
+		transport.DialContext = dialer.DialContext
+	}
+	if cfg.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	}
+	if cfg.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+	}
+	if cfg.ExpectContinueTimeout > 0 {
+		transport.ExpectContinueTimeout = cfg.ExpectContinueTimeout
+	}
+	if cfg.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+		if cfg.MaxIdleConnsPerHost > transport.MaxIdleConns {
+			transport.MaxIdleConns = cfg.MaxIdleConnsPerHost
+		}
+	}
+	if cfg.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = cfg.MaxConnsPerHost
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   cfg.Timeout,
+	}
+}
@@ -0,0 +1,58 @@
+package objsto_test
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+)
+
+var _ = Describe("Transport", func() {
+
+	Describe("creating an http client", func() {
+		var (
+			cfg       *objsto.HttpConfig
+			client    *http.Client
+			transport *http.Transport
+		)
+
+		JustBeforeEach(func() {
+			client = objsto.NewHTTPClient(cfg)
+			transport = client.Transport.(*http.Transport)
+		})
+
+		When("config is nil", func() {
+			BeforeEach(func() {
+				cfg = nil
+			})
+
+			It("uses tuned defaults", func() {
+				Expect(client.Timeout).To(BeZero())
+				Expect(transport.MaxIdleConnsPerHost).To(Equal(64))
+				Expect(transport.ResponseHeaderTimeout).To(Equal(30 * time.Second))
+				Expect(transport.ExpectContinueTimeout).To(Equal(time.Second))
+				Expect(transport.ForceAttemptHTTP2).To(BeTrue())
+			})
+		})
+
+		When("config overrides", func() {
+			BeforeEach(func() {
+				cfg = &objsto.HttpConfig{
+					Timeout:             time.Minute,
+					MaxIdleConnsPerHost: 512,
+					MaxConnsPerHost:     1024,
+				}
+			})
+
+			It("applies them", func() {
+				Expect(client.Timeout).To(Equal(time.Minute))
+				Expect(transport.MaxIdleConnsPerHost).To(Equal(512))
+				Expect(transport.MaxIdleConns).To(Equal(512))
+				Expect(transport.MaxConnsPerHost).To(Equal(1024))
+			})
+		})
+	})
+})
@@ -0,0 +1,260 @@
+package objsto
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const sniffLen = 512
+
+// KeyFunc picks the key for an upload, given the request and the filename supplied by the user.
+type KeyFunc func(request *http.Request, filename string) (string, error)
+
+// RandomKey is a KeyFunc giving prefix plus a random hex name, keeping the filename's extension.
+func RandomKey(prefix string) KeyFunc {
+
+	return func(request *http.Request, filename string) (key string, err error) {
+
+		buf := make([]byte, 16)
+		_, err = rand.Read(buf)
+		if err != nil {
+			err = errors.Wrap(err, "failed to read random")
+			return
+		}
+
+		ext := strings.ToLower(path.Ext(path.Base(filename)))
+		if strings.ContainsFunc(ext[min(1, len(ext)):], notAlnum) {
+			ext = ""
+		}
+
+		key = prefix + hex.EncodeToString(buf) + ext
+		return
+	}
+}
+
+// UploadResult is the JSON response for a successful upload.
+type UploadResult struct {
+	PutResult
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+}
+
+// Uploader is an http.Handler accepting uploads from end users and putting them to a store.
+//
+// A POST is taken to be a multipart form, with the file in the "file" field by default,
+// and a PUT to be the raw content, named for the last element of the path.
+// Raw uploads are streamed when the store is a ReaderPutter, otherwise uploads are
+// spooled to a temp file since Put needs to seek.
+type Uploader struct {
+	store        ObjectStore
+	maxSize      int64
+	contentTypes []string
+	keyFunc      KeyFunc
+	field        string
+}
+
+// UploaderOption sets an optional setting for Uploader.
+type UploaderOption func(*Uploader)
+
+// WithMaxSize limits the size of uploads, defaulting to MaxPutSize.
+func WithMaxSize(size int64) UploaderOption {
+
+	return func(upl *Uploader) {
+		upl.maxSize = size
+	}
+}
+
+// WithContentTypes limits uploads to the given media types, such as "image/png".
+// Types are as declared by the user, falling back to sniffing the content.
+func WithContentTypes(types ...string) UploaderOption {
+
+	return func(upl *Uploader) {
+		upl.contentTypes = types
+	}
+}
+
+// WithKeyFunc sets the key policy, defaulting to RandomKey("").
+func WithKeyFunc(keyFunc KeyFunc) UploaderOption {
+
+	return func(upl *Uploader) {
+		upl.keyFunc = keyFunc
+	}
+}
+
+// WithFormField sets the name of the multipart form field holding the file.
+func WithFormField(field string) UploaderOption {
+
+	return func(upl *Uploader) {
+		upl.field = field
+	}
+}
+
+// NewUploader creates an Uploader putting to store.
+func NewUploader(store ObjectStore, opts ...UploaderOption) *Uploader {
+
+	upl := &Uploader{
+		store:   store,
+		maxSize: MaxPutSize,
+		keyFunc: RandomKey(""),
+		field:   "file",
+	}
+	for _, opt := range opts {
+		opt(upl)
+	}
+
+	return upl
+}
+
+// ServeHTTP accepts an upload, responding with an UploadResult.
+func (upl *Uploader) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+
+	switch request.Method {
+	case http.MethodPut:
+		if request.ContentLength > upl.maxSize {
+			http.Error(writer, "upload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		upl.upload(writer, request, request.Body, path.Base(request.URL.Path), request.Header.Get("Content-Type"), request.ContentLength)
+
+	case http.MethodPost:
+		reader, err := request.MultipartReader()
+		if err != nil {
+			http.Error(writer, "expected multipart form", http.StatusBadRequest)
+			return
+		}
+
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				http.Error(writer, "missing form field "+upl.field, http.StatusBadRequest)
+				return
+			}
+			if part.FormName() != upl.field {
+				part.Close()
+				continue
+			}
+
+			upl.upload(writer, request, part, part.FileName(), part.Header.Get("Content-Type"), -1)
+			part.Close()
+			return
+		}
+
+	default:
+		writer.Header().Set("Allow", "POST, PUT")
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// unexported
+
+func (upl *Uploader) upload(writer http.ResponseWriter, request *http.Request, body io.Reader, filename, contentType string, size int64) {
+
+	ctx := request.Context()
+
+	buffered := bufio.NewReaderSize(body, sniffLen)
+	if contentType == "" || contentType == "application/octet-stream" {
+		head, _ := buffered.Peek(sniffLen)
+		contentType = http.DetectContentType(head)
+	}
+
+	if !upl.allowed(contentType) {
+		http.Error(writer, "unsupported content type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	key, err := upl.keyFunc(request, filename)
+	if err != nil {
+		http.Error(writer, "failed to pick key", http.StatusInternalServerError)
+		return
+	}
+
+	var res PutResult
+	opts := []PutOption{WithContentType(contentType), WithResult(&res)}
+
+	rp, ok := upl.store.(ReaderPutter)
+	if ok && size >= 0 {
+		err = rp.PutReader(ctx, key, buffered, size, opts...)
+	} else {
+		size, err = upl.spool(ctx, buffered, key, opts)
+	}
+	if errors.Is(err, ErrTooLarge) {
+		http.Error(writer, "upload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(writer, "failed to store upload", http.StatusBadGateway)
+		return
+	}
+
+	writer.Header().Set("Content-Type", jsonType)
+	writer.WriteHeader(http.StatusCreated)
+	json.NewEncoder(writer).Encode(UploadResult{
+		PutResult:   res,
+		Size:        size,
+		ContentType: contentType,
+	})
+}
+
+func (upl *Uploader) spool(ctx context.Context, reader io.Reader, key string, opts []PutOption) (size int64, err error) {
+
+	tmp, err := os.CreateTemp("", "objsto-upload-*")
+	if err != nil {
+		err = errors.Wrap(err, "failed to create temp file")
+		return
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	size, err = io.Copy(tmp, io.LimitReader(reader, onePast(upl.maxSize)))
+	if err != nil {
+		err = errors.Wrap(err, "failed to spool upload")
+		return
+	}
+	if size > upl.maxSize {
+		err = errors.Wrapf(ErrTooLarge, "upload exceeds %d bytes", upl.maxSize)
+		return
+	}
+
+	_, err = tmp.Seek(0, io.SeekStart)
+	if err != nil {
+		err = errors.Wrap(err, "failed to rewind spooled upload")
+		return
+	}
+
+	err = upl.store.Put(ctx, key, tmp, opts...)
+	return
+}
+
+func notAlnum(rn rune) bool {
+
+	return !(rn >= 'a' && rn <= 'z' || rn >= '0' && rn <= '9')
+}
+
+func (upl *Uploader) allowed(contentType string) bool {
+
+	if len(upl.contentTypes) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return slices.Contains(upl.contentTypes, mediaType)
+}
@@ -0,0 +1,157 @@
+package objsto_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+var _ = Describe("Uploader", func() {
+	var (
+		ctx   = context.Background()
+		store *memstore.Store
+		opts  []objsto.UploaderOption
+		req   *http.Request
+		rec   *httptest.ResponseRecorder
+		res   objsto.UploadResult
+	)
+
+	multipartReq := func(field, filename, content string) *http.Request {
+
+		body := &bytes.Buffer{}
+		mw := multipart.NewWriter(body)
+		mw.WriteField("note", "ignored")
+		fw, err := mw.CreateFormFile(field, filename)
+		Expect(err).ToNot(HaveOccurred())
+		fw.Write([]byte(content))
+		mw.Close()
+
+		req := httptest.NewRequest("POST", "/upload", body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		return req
+	}
+
+	BeforeEach(func() {
+		store = memstore.New()
+		opts = []objsto.UploaderOption{objsto.WithKeyFunc(func(request *http.Request, filename string) (string, error) {
+			return "uploads/" + filename, nil
+		})}
+		res = objsto.UploadResult{}
+	})
+
+	JustBeforeEach(func() {
+		rec = httptest.NewRecorder()
+		objsto.NewUploader(store, opts...).ServeHTTP(rec, req)
+
+		if rec.Code == http.StatusCreated {
+			err := json.Unmarshal(rec.Body.Bytes(), &res)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	When("uploading a multipart form", func() {
+		BeforeEach(func() {
+			req = multipartReq("file", "notes.txt", "hello upload")
+		})
+
+		It("puts the file and responds with key and etag", func() {
+			Expect(rec.Code).To(Equal(http.StatusCreated))
+			Expect(res.Key).To(Equal("uploads/notes.txt"))
+			Expect(res.ETag).ToNot(BeEmpty())
+			Expect(res.Size).To(Equal(int64(12)))
+
+			reader, err := store.Get(ctx, "uploads/notes.txt")
+			Expect(err).ToNot(HaveOccurred())
+			data, _ := io.ReadAll(reader)
+			Expect(string(data)).To(Equal("hello upload"))
+		})
+	})
+
+	When("the form field is missing", func() {
+		BeforeEach(func() {
+			req = multipartReq("other", "notes.txt", "hello upload")
+		})
+
+		It("responds bad request", func() {
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("uploading raw", func() {
+		BeforeEach(func() {
+			req = httptest.NewRequest("PUT", "/upload/raw.json", strings.NewReader(`{"a":1}`))
+			req.Header.Set("Content-Type", "application/json")
+		})
+
+		It("puts the body with its content type", func() {
+			Expect(rec.Code).To(Equal(http.StatusCreated))
+			Expect(res.Key).To(Equal("uploads/raw.json"))
+			Expect(res.ContentType).To(Equal("application/json"))
+
+			info, err := store.Stat(ctx, "uploads/raw.json")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.ContentType).To(Equal("application/json"))
+			Expect(info.ETag).To(Equal(res.ETag))
+		})
+	})
+
+	When("the upload is too large", func() {
+		BeforeEach(func() {
+			opts = append(opts, objsto.WithMaxSize(4))
+			req = multipartReq("file", "notes.txt", "hello upload")
+		})
+
+		It("responds too large without putting", func() {
+			Expect(rec.Code).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(store.Len()).To(BeZero())
+		})
+	})
+
+	When("the limit is as big as can be", func() {
+		BeforeEach(func() {
+			opts = append(opts, objsto.WithMaxSize(math.MaxInt64))
+			req = multipartReq("file", "notes.txt", "hello upload")
+		})
+
+		It("puts the file", func() {
+			Expect(rec.Code).To(Equal(http.StatusCreated))
+			Expect(res.Size).To(Equal(int64(12)))
+		})
+	})
+
+	When("the content type is not allowed", func() {
+		BeforeEach(func() {
+			opts = append(opts, objsto.WithContentTypes("image/png"))
+			req = multipartReq("file", "notes.txt", "hello upload")
+		})
+
+		It("responds unsupported", func() {
+			Expect(rec.Code).To(Equal(http.StatusUnsupportedMediaType))
+			Expect(store.Len()).To(BeZero())
+		})
+	})
+
+	When("using the default key policy", func() {
+		BeforeEach(func() {
+			opts = nil
+			req = multipartReq("file", "Photo.PNG", "not really")
+		})
+
+		It("picks a random key keeping the extension", func() {
+			Expect(rec.Code).To(Equal(http.StatusCreated))
+			Expect(res.Key).To(MatchRegexp(`^[0-9a-f]{32}\.png$`))
+		})
+	})
+})
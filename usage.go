@@ -0,0 +1,309 @@
+package objsto
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultUsageMaxAge is how long a prefix's usage is trusted before rescanning by default.
+const DefaultUsageMaxAge = 5 * time.Minute
+
+// Usage is the count and total size of objects under a prefix.
+type Usage struct {
+	Objects int64 `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// Quota limits usage under a prefix, zero fields for no limit.
+type Quota struct {
+	Objects int64 `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// UsageScanner computes usage per prefix from listings, keeping a snapshot of sizes by key
+// that's updated as puts and deletes are observed, so that a prefix is only relisted once
+// its snapshot exceeds the max age.
+//
+// Set quotas with WithQuota for Check to enforce, as from a QuotaStore.
+// Changes made by other writers go unseen until a rescan, so treat quotas as soft.
+// Listing is done without holding up others using the scanner, the snapshot swapped in after.
+type UsageScanner struct {
+	store     ObjectStore
+	maxAge    time.Duration
+	quotas    map[string]Quota
+	clock     Clock
+	snapshots map[string]*snapshot
+	mu        sync.Mutex
+}
+
+// UsageOption sets an optional UsageScanner setting.
+type UsageOption func(*UsageScanner)
+
+// WithUsageMaxAge sets how long a prefix's usage is trusted before rescanning, defaulting to DefaultUsageMaxAge.
+func WithUsageMaxAge(maxAge time.Duration) UsageOption {
+
+	return func(us *UsageScanner) {
+		us.maxAge = maxAge
+	}
+}
+
+// WithQuota sets a quota on objects under prefix, enforced by Check.
+func WithQuota(prefix string, quota Quota) UsageOption {
+
+	return func(us *UsageScanner) {
+		us.quotas[prefix] = quota
+	}
+}
+
+// WithUsageClock sets the clock snapshot age is judged by, as for testing.
+func WithUsageClock(clock Clock) UsageOption {
+
+	return func(us *UsageScanner) {
+		us.clock = clock
+	}
+}
+
+// NewUsageScanner creates a UsageScanner of objects in store.
+func NewUsageScanner(store ObjectStore, opts ...UsageOption) *UsageScanner {
+
+	us := &UsageScanner{
+		store:     store,
+		maxAge:    DefaultUsageMaxAge,
+		quotas:    map[string]Quota{},
+		clock:     systemClock{},
+		snapshots: map[string]*snapshot{},
+	}
+	for _, opt := range opts {
+		opt(us)
+	}
+
+	return us
+}
+
+// Usage returns usage under prefix, scanning if there's no snapshot or it's too old.
+func (us *UsageScanner) Usage(ctx context.Context, prefix string) (usage Usage, err error) {
+
+	err = us.current(ctx, prefix)
+	if err != nil {
+		return
+	}
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	usage = us.snapshots[prefix].usage
+	return
+}
+
+// Scan lists objects under prefix, replacing any snapshot.
+func (us *UsageScanner) Scan(ctx context.Context, prefix string) (usage Usage, err error) {
+
+	return us.scan(ctx, prefix)
+}
+
+// Snapshot returns usage by prefix for those scanned, as last known.
+func (us *UsageScanner) Snapshot() (usages map[string]Usage) {
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	usages = map[string]Usage{}
+	for prefix, snap := range us.snapshots {
+		usages[prefix] = snap.usage
+	}
+
+	return
+}
+
+// Check fails with an error satisfying errors.Is(err, ErrQuotaExceeded) if putting size bytes
+// to object would exceed the quota of a prefix it's under, replacing any object already there.
+func (us *UsageScanner) Check(ctx context.Context, object string, size int64) (err error) {
+
+	for prefix, quota := range us.quotas {
+		if !strings.HasPrefix(object, prefix) {
+			continue
+		}
+
+		err = us.current(ctx, prefix)
+		if err != nil {
+			return
+		}
+
+		err = us.check(prefix, quota, object, size)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Observe updates snapshots for an object put with size bytes.
+func (us *UsageScanner) Observe(object string, size int64) {
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	for prefix, snap := range us.snapshots {
+		if strings.HasPrefix(object, prefix) {
+			snap.forget(object)
+			snap.sizes[object] = size
+			snap.usage.Objects++
+			snap.usage.Bytes += size
+		}
+	}
+}
+
+// Forget updates snapshots for a deleted object.
+func (us *UsageScanner) Forget(object string) {
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	for prefix, snap := range us.snapshots {
+		if strings.HasPrefix(object, prefix) {
+			snap.forget(object)
+		}
+	}
+}
+
+// QuotaStore is an ObjectStore enforcing quotas with a UsageScanner, keeping it informed of puts and deletes.
+type QuotaStore struct {
+	ObjectStore
+	scanner *UsageScanner
+}
+
+// NewQuotaStore creates a QuotaStore wrapping store, which scanner should also be scanning.
+func NewQuotaStore(store ObjectStore, scanner *UsageScanner) *QuotaStore {
+
+	return &QuotaStore{
+		ObjectStore: store,
+		scanner:     scanner,
+	}
+}
+
+// Put puts an object, failing with an error satisfying errors.Is(err, ErrQuotaExceeded) if over quota.
+func (qs *QuotaStore) Put(ctx context.Context, object string, reader io.ReadSeeker, opts ...PutOption) (err error) {
+
+	size, err := remaining(reader)
+	if err != nil {
+		return
+	}
+
+	err = qs.scanner.Check(ctx, object, size)
+	if err != nil {
+		return
+	}
+
+	err = qs.ObjectStore.Put(ctx, object, reader, opts...)
+	if err != nil {
+		return
+	}
+
+	qs.scanner.Observe(object, size)
+	return
+}
+
+// Delete deletes an object.
+func (qs *QuotaStore) Delete(ctx context.Context, object string) (err error) {
+
+	err = qs.ObjectStore.Delete(ctx, object)
+	if err != nil {
+		return
+	}
+
+	qs.scanner.Forget(object)
+	return
+}
+
+// unexported
+
+type snapshot struct {
+	sizes   map[string]int64
+	usage   Usage
+	scanned time.Time
+}
+
+func (snap *snapshot) forget(object string) {
+
+	size, ok := snap.sizes[object]
+	if !ok {
+		return
+	}
+
+	delete(snap.sizes, object)
+	snap.usage.Objects--
+	snap.usage.Bytes -= size
+}
+
+// current scans prefix when its snapshot is missing or stale.
+func (us *UsageScanner) current(ctx context.Context, prefix string) (err error) {
+
+	us.mu.Lock()
+	snap, ok := us.snapshots[prefix]
+	fresh := ok && us.clock.Now().Sub(snap.scanned) < us.maxAge
+	us.mu.Unlock()
+
+	if fresh {
+		return
+	}
+
+	_, err = us.scan(ctx, prefix)
+	return
+}
+
+// scan lists prefix into a new snapshot, without holding mu while listing,
+// and swaps it in when done.
+func (us *UsageScanner) scan(ctx context.Context, prefix string) (usage Usage, err error) {
+
+	fresh := &snapshot{
+		sizes:   map[string]int64{},
+		scanned: us.clock.Now(),
+	}
+
+	for info, err := range objectInfos(ctx, us.store, prefix) {
+		if err != nil {
+			return Usage{}, errors.Wrapf(err, "failed to scan usage of %q", prefix)
+		}
+		fresh.sizes[info.Key] = info.Size
+		fresh.usage.Objects++
+		fresh.usage.Bytes += info.Size
+	}
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	us.snapshots[prefix] = fresh
+	usage = fresh.usage
+	return
+}
+
+// check judges a put against the quota of prefix, as snapshotted.
+func (us *UsageScanner) check(prefix string, quota Quota, object string, size int64) (err error) {
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	snap := us.snapshots[prefix]
+
+	usage := snap.usage
+	replaced, ok := snap.sizes[object]
+	if !ok {
+		usage.Objects++
+	}
+	usage.Bytes += size - replaced
+
+	switch {
+	case quota.Objects > 0 && usage.Objects > quota.Objects:
+		err = errors.Wrapf(ErrQuotaExceeded, "prefix %q limited to %d objects", prefix, quota.Objects)
+	case quota.Bytes > 0 && usage.Bytes > quota.Bytes:
+		err = errors.Wrapf(ErrQuotaExceeded, "prefix %q using %d of %d bytes, cannot put %d", prefix, snap.usage.Bytes, quota.Bytes, size)
+	}
+
+	return
+}
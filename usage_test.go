@@ -0,0 +1,97 @@
+package objsto_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+var _ = Describe("UsageScanner", func() {
+	var (
+		ctx     = context.Background()
+		store   *memstore.Store
+		clock   *stepClock
+		scanner *objsto.UsageScanner
+	)
+
+	BeforeEach(func() {
+		store = memstore.New()
+		clock = &stepClock{now: time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)}
+
+		Expect(store.Put(ctx, "acme/a", strings.NewReader("aaaa"))).To(Succeed())
+		Expect(store.Put(ctx, "acme/b", strings.NewReader("bb"))).To(Succeed())
+		Expect(store.Put(ctx, "globex/c", strings.NewReader("c"))).To(Succeed())
+
+		scanner = objsto.NewUsageScanner(store, objsto.WithUsageClock(clock),
+			objsto.WithQuota("acme/", objsto.Quota{Bytes: 10}), objsto.WithQuota("globex/", objsto.Quota{Objects: 1}))
+	})
+
+	It("scans per prefix, tracking puts and deletes in between", func() {
+		Expect(scanner.Usage(ctx, "acme/")).To(Equal(objsto.Usage{Objects: 2, Bytes: 6}))
+
+		qs := objsto.NewQuotaStore(store, scanner)
+		Expect(qs.Put(ctx, "acme/a", strings.NewReader("a"))).To(Succeed())
+		Expect(qs.Put(ctx, "acme/d", strings.NewReader("ddd"))).To(Succeed())
+		Expect(qs.Delete(ctx, "acme/b")).To(Succeed())
+		Expect(scanner.Snapshot()).To(Equal(map[string]objsto.Usage{"acme/": {Objects: 2, Bytes: 4}}))
+
+		// unseen until rescanned
+		Expect(store.Put(ctx, "acme/e", strings.NewReader("e"))).To(Succeed())
+		Expect(scanner.Usage(ctx, "acme/")).To(Equal(objsto.Usage{Objects: 2, Bytes: 4}))
+
+		clock.now = clock.now.Add(objsto.DefaultUsageMaxAge)
+		Expect(scanner.Usage(ctx, "acme/")).To(Equal(objsto.Usage{Objects: 3, Bytes: 5}))
+	})
+
+	It("lists without holding up others", func() {
+		listing := make(chan struct{})
+		release := make(chan struct{})
+		var once sync.Once
+		store.SetFault(func(op memstore.Op, object string) error {
+			if op == memstore.OpList {
+				once.Do(func() { close(listing) })
+				<-release
+			}
+			return nil
+		})
+		unblock := sync.OnceFunc(func() { close(release) })
+		DeferCleanup(unblock)
+
+		scanned := make(chan objsto.Usage)
+		go func() {
+			defer GinkgoRecover()
+			usage, err := scanner.Usage(ctx, "acme/")
+			Expect(err).ToNot(HaveOccurred())
+			scanned <- usage
+		}()
+		<-listing
+
+		snapped := make(chan map[string]objsto.Usage)
+		go func() { snapped <- scanner.Snapshot() }()
+		Eventually(snapped).Should(Receive(BeEmpty()))
+
+		unblock()
+		Eventually(scanned).Should(Receive(Equal(objsto.Usage{Objects: 2, Bytes: 6})))
+	})
+
+	It("rejects puts over quota", func() {
+		qs := objsto.NewQuotaStore(store, scanner)
+
+		err := qs.Put(ctx, "acme/d", strings.NewReader("12345"))
+		Expect(err).To(MatchError(objsto.ErrQuotaExceeded))
+		Expect(qs.Put(ctx, "acme/a", strings.NewReader("12345678"))).To(Succeed())
+
+		err = qs.Put(ctx, "globex/d", strings.NewReader("d"))
+		Expect(err).To(MatchError(objsto.ErrQuotaExceeded))
+		Expect(qs.Put(ctx, "globex/c", strings.NewReader("cc"))).To(Succeed())
+
+		Expect(store.Len()).To(Equal(3))
+	})
+})
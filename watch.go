@@ -0,0 +1,177 @@
+package objsto
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultWatchInterval is the time between listings by default.
+const DefaultWatchInterval = time.Minute
+
+// EventType is the kind of change seen by a Watcher.
+type EventType string
+
+const (
+	// EventCreated is for an object that's appeared since the last listing.
+	EventCreated EventType = "created"
+	// EventUpdated is for an object whose etag, size, or modification time has changed.
+	EventUpdated EventType = "updated"
+	// EventDeleted is for an object that's gone since the last listing.
+	EventDeleted EventType = "deleted"
+)
+
+// Event is a change seen by a Watcher, with the object's latest info, or its last seen when deleted.
+type Event struct {
+	Type EventType  `json:"type"`
+	Info ObjectInfo `json:"info"`
+}
+
+// Watcher lists a prefix periodically, diffing against the previous listing to emit change events,
+// for providers without event notifications.
+//
+// Changes between listings are coalesced: an object created and deleted in between is never seen,
+// and several updates are seen as one.
+type Watcher struct {
+	store     ObjectStore
+	prefix    string
+	interval  time.Duration
+	initial   bool
+	errorFunc func(error)
+	events    chan Event
+	snapshot  map[string]ObjectInfo
+}
+
+// WatchOption sets an optional Watcher setting.
+type WatchOption func(*Watcher)
+
+// WithWatchInterval sets the time between listings made by Run, defaulting to DefaultWatchInterval.
+func WithWatchInterval(interval time.Duration) WatchOption {
+
+	return func(wt *Watcher) {
+		wt.interval = interval
+	}
+}
+
+// WithWatchBuffer sets the capacity of the events channel, defaulting to unbuffered.
+func WithWatchBuffer(n int) WatchOption {
+
+	return func(wt *Watcher) {
+		wt.events = make(chan Event, n)
+	}
+}
+
+// WithInitialEvents emits created events for objects found by the first listing,
+// which otherwise only sets the baseline.
+func WithInitialEvents() WatchOption {
+
+	return func(wt *Watcher) {
+		wt.initial = true
+	}
+}
+
+// WithWatchErrorFunc sets a function called when a listing made by Run fails, as for logging.
+// The failed listing is skipped, diffing the next against the one before.
+func WithWatchErrorFunc(fn func(error)) WatchOption {
+
+	return func(wt *Watcher) {
+		wt.errorFunc = fn
+	}
+}
+
+// NewWatcher creates a Watcher of objects under prefix in store.
+func NewWatcher(store ObjectStore, prefix string, opts ...WatchOption) *Watcher {
+
+	wt := &Watcher{
+		store:    store,
+		prefix:   prefix,
+		interval: DefaultWatchInterval,
+		events:   make(chan Event),
+	}
+	for _, opt := range opts {
+		opt(wt)
+	}
+
+	return wt
+}
+
+// Events returns the channel Run emits events on, closed when Run returns.
+func (wt *Watcher) Events() <-chan Event {
+
+	return wt.events
+}
+
+// Run lists every interval until ctx is done, emitting events and returning ctx.Err().
+func (wt *Watcher) Run(ctx context.Context) (err error) {
+
+	defer close(wt.events)
+
+	for {
+		events, pollErr := wt.Poll(ctx)
+		if pollErr != nil && wt.errorFunc != nil {
+			wt.errorFunc(pollErr)
+		}
+
+		for _, event := range events {
+			select {
+			case wt.events <- event:
+			case <-ctx.Done():
+				err = ctx.Err()
+				return
+			}
+		}
+
+		err = sleep(ctx, wt.interval)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Poll makes a single listing, returning changes since the last, for use instead of Run.
+func (wt *Watcher) Poll(ctx context.Context) (events []Event, err error) {
+
+	current := map[string]ObjectInfo{}
+	for info, err := range objectInfos(ctx, wt.store, wt.prefix) {
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list %q", wt.prefix)
+		}
+		current[info.Key] = info
+	}
+
+	previous := wt.snapshot
+	wt.snapshot = current
+	if previous == nil && !wt.initial {
+		return
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(current)) {
+		info := current[key]
+		last, ok := previous[key]
+
+		switch {
+		case !ok:
+			events = append(events, Event{Type: EventCreated, Info: info})
+		case changed(last, info):
+			events = append(events, Event{Type: EventUpdated, Info: info})
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(previous)) {
+		if _, ok := current[key]; !ok {
+			events = append(events, Event{Type: EventDeleted, Info: previous[key]})
+		}
+	}
+
+	return
+}
+
+// unexported
+
+func changed(last, info ObjectInfo) bool {
+
+	return last.ETag != info.ETag || last.Size != info.Size || !last.LastModified.Equal(info.LastModified)
+}
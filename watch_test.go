@@ -0,0 +1,74 @@
+package objsto_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+)
+
+var _ = Describe("Watcher", func() {
+	var (
+		ctx   = context.Background()
+		store *memstore.Store
+	)
+
+	BeforeEach(func() {
+		store = memstore.New()
+
+		Expect(store.Put(ctx, "in/a", strings.NewReader("a"))).To(Succeed())
+		Expect(store.Put(ctx, "in/b", strings.NewReader("b"))).To(Succeed())
+		Expect(store.Put(ctx, "out/c", strings.NewReader("c"))).To(Succeed())
+	})
+
+	kinds := func(events []objsto.Event) (got []string) {
+		for _, event := range events {
+			got = append(got, string(event.Type)+" "+event.Info.Key)
+		}
+		return
+	}
+
+	It("diffs listings", func() {
+		wt := objsto.NewWatcher(store, "in/")
+
+		events, err := wt.Poll(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(BeEmpty())
+
+		Expect(store.Put(ctx, "in/a", strings.NewReader("aa"))).To(Succeed())
+		Expect(store.Delete(ctx, "in/b")).To(Succeed())
+		Expect(store.Put(ctx, "in/d", strings.NewReader("d"))).To(Succeed())
+		Expect(store.Put(ctx, "out/e", strings.NewReader("e"))).To(Succeed())
+
+		events, err = wt.Poll(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(kinds(events)).To(Equal([]string{"updated in/a", "created in/d", "deleted in/b"}))
+
+		events, err = wt.Poll(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).To(BeEmpty())
+	})
+
+	It("emits on the channel until canceled", func() {
+		wt := objsto.NewWatcher(store, "in/", objsto.WithInitialEvents(), objsto.WithWatchInterval(time.Millisecond))
+
+		ctx, cancel := context.WithCancel(ctx)
+		done := make(chan error)
+		go func() { done <- wt.Run(ctx) }()
+
+		Expect((<-wt.Events()).Info.Key).To(Equal("in/a"))
+		Expect((<-wt.Events()).Info.Key).To(Equal("in/b"))
+
+		Expect(store.Delete(ctx, "in/a")).To(Succeed())
+		Expect(<-wt.Events()).To(HaveField("Type", objsto.EventDeleted))
+
+		cancel()
+		Expect(<-done).To(MatchError(context.Canceled))
+		Eventually(wt.Events()).Should(BeClosed())
+	})
+})
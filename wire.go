@@ -0,0 +1,48 @@
+package objsto
+
+import (
+	"context"
+	"net/http"
+	"net/http/httputil"
+)
+
+// WithWireTrace logs request and response lines and headers at Trace level, as sent and received,
+// with credentials and signatures redacted. Bodies are left out.
+func WithWireTrace() ClientOption {
+
+	return func(c *Client) {
+		c.wire = true
+	}
+}
+
+// unexported
+
+func (c *Client) dumpRequest(ctx context.Context, req *http.Request) {
+
+	out := req.Clone(ctx)
+	out.Header = redactHeader(req.Header)
+	out.Body = nil
+	out.GetBody = nil
+
+	dump, err := httputil.DumpRequestOut(out, false)
+	if err != nil {
+		c.logger.Error(ctx, "failed to dump request", err)
+		return
+	}
+
+	c.logger.Trace(ctx, "S3 wire request", "dump", string(dump))
+}
+
+func (c *Client) dumpResponse(ctx context.Context, resp *http.Response) {
+
+	out := *resp
+	out.Header = redactHeader(resp.Header)
+
+	dump, err := httputil.DumpResponse(&out, false)
+	if err != nil {
+		c.logger.Error(ctx, "failed to dump response", err)
+		return
+	}
+
+	c.logger.Trace(ctx, "S3 wire response", "dump", string(dump))
+}
@@ -0,0 +1,137 @@
+// Package zstd implements objsto.Compression with zstd, for use with objsto.CompressStore,
+// with a level and dictionary tuned for many small similar objects.
+package zstd
+
+import (
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+
+	"github.com/clarktrimble/objsto"
+)
+
+// Encoding is the Content-Encoding of objects compressed with zstd.
+const Encoding = "zstd"
+
+// DefaultLevel is the zstd level used by default.
+const DefaultLevel = 3
+
+// Compression is an objsto.Compression for zstd.
+// Encoders are pooled, being costly to create and reusable once closed.
+type Compression struct {
+	level    int
+	dict     []byte
+	encoders sync.Pool
+}
+
+var _ objsto.Compression = &Compression{}
+
+// Option sets an optional Compression setting.
+type Option func(*Compression)
+
+// WithLevel sets the level as for the zstd command, 1 to 22, defaulting to DefaultLevel.
+// Levels map to the nearest supported, of fastest, default, better and best.
+func WithLevel(level int) Option {
+
+	return func(comp *Compression) {
+		comp.level = level
+	}
+}
+
+// WithDictionary sets a dictionary, as trained by "zstd --train" or built with zstd.BuildDict,
+// improving compression of small objects similar to those it was trained on.
+// Objects compressed with a dictionary can only be read with it.
+func WithDictionary(dict []byte) Option {
+
+	return func(comp *Compression) {
+		comp.dict = dict
+	}
+}
+
+// New creates a Compression.
+func New(opts ...Option) *Compression {
+
+	comp := &Compression{level: DefaultLevel}
+	for _, opt := range opts {
+		opt(comp)
+	}
+
+	return comp
+}
+
+// Encoding returns the Content-Encoding, "zstd".
+func (comp *Compression) Encoding() string {
+
+	return Encoding
+}
+
+// NewWriter returns a writer compressing to writer, to be closed when done.
+func (comp *Compression) NewWriter(writer io.Writer) (wc io.WriteCloser, err error) {
+
+	enc, ok := comp.encoders.Get().(*zstd.Encoder)
+	if ok {
+		enc.Reset(writer)
+		wc = &pooledEncoder{Encoder: enc, pool: &comp.encoders}
+		return
+	}
+
+	eopts := []zstd.EOption{
+		zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(comp.level)),
+		zstd.WithEncoderConcurrency(1),
+	}
+	if comp.dict != nil {
+		eopts = append(eopts, zstd.WithEncoderDict(comp.dict))
+	}
+
+	enc, err = zstd.NewWriter(writer, eopts...)
+	if err != nil {
+		err = errors.Wrap(err, "failed to create zstd encoder")
+		return
+	}
+
+	wc = &pooledEncoder{Encoder: enc, pool: &comp.encoders}
+	return
+}
+
+// NewReader returns a reader decompressing from reader, to be closed when done.
+func (comp *Compression) NewReader(reader io.Reader) (rc io.ReadCloser, err error) {
+
+	dopts := []zstd.DOption{zstd.WithDecoderConcurrency(1)}
+	if comp.dict != nil {
+		dopts = append(dopts, zstd.WithDecoderDicts(comp.dict))
+	}
+
+	dec, err := zstd.NewReader(reader, dopts...)
+	if err != nil {
+		err = errors.Wrap(err, "failed to create zstd decoder")
+		return
+	}
+
+	rc = dec.IOReadCloser()
+	return
+}
+
+// unexported
+
+// pooledEncoder returns its encoder to the pool when closed.
+type pooledEncoder struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+func (pe *pooledEncoder) Close() (err error) {
+
+	if pe.Encoder == nil {
+		return
+	}
+
+	err = pe.Encoder.Close()
+	if err == nil {
+		pe.pool.Put(pe.Encoder)
+	}
+	pe.Encoder = nil
+
+	return
+}
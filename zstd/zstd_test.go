@@ -0,0 +1,119 @@
+package zstd_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	kzstd "github.com/klauspost/compress/zstd"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/clarktrimble/objsto"
+	"github.com/clarktrimble/objsto/memstore"
+	"github.com/clarktrimble/objsto/zstd"
+)
+
+func TestZstd(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Zstd Suite")
+}
+
+func read(reader io.ReadCloser, err error) string {
+
+	Expect(err).ToNot(HaveOccurred())
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	Expect(err).ToNot(HaveOccurred())
+	return string(data)
+}
+
+func sample(idx int) string {
+
+	return fmt.Sprintf(`{"id":%d,"kind":"reading","sensor":"greenhouse-north","unit":"celsius","value":%d.%d}`, idx, 20+idx%7, idx%10)
+}
+
+var _ = Describe("Compression", func() {
+	var (
+		ctx   = context.Background()
+		inner *memstore.Store
+		doc   = sample(99)
+	)
+
+	BeforeEach(func() {
+		inner = memstore.New()
+	})
+
+	stored := func(object string) int64 {
+
+		info, err := inner.Stat(ctx, object)
+		Expect(err).ToNot(HaveOccurred())
+		return info.Size
+	}
+
+	It("round trips without a dictionary", func() {
+		cs := objsto.NewCompressStore(inner, objsto.WithCompression(zstd.New()))
+
+		for _, object := range []string{"a.json", "b.json"} {
+			Expect(cs.Put(ctx, object, strings.NewReader(doc))).To(Succeed())
+			Expect(read(cs.Get(ctx, object))).To(Equal(doc))
+		}
+
+		info, err := inner.Stat(ctx, "a.json")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.ContentEncoding).To(Equal("zstd"))
+	})
+
+	It("round trips at a level", func() {
+		cs := objsto.NewCompressStore(inner, objsto.WithCompression(zstd.New(zstd.WithLevel(19))))
+
+		Expect(cs.Put(ctx, "a.json", strings.NewReader(doc))).To(Succeed())
+		Expect(read(cs.Get(ctx, "a.json"))).To(Equal(doc))
+	})
+
+	When("given a dictionary", func() {
+		var dict []byte
+
+		BeforeEach(func() {
+			var contents [][]byte
+			for idx := range 200 {
+				contents = append(contents, []byte(sample(idx)))
+			}
+
+			var err error
+			dict, err = kzstd.BuildDict(kzstd.BuildDictOptions{
+				ID:       1,
+				Contents: contents,
+				History:  []byte(strings.Repeat(sample(0), 8)),
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("round trips smaller than without", func() {
+			plain := objsto.NewCompressStore(inner, objsto.WithCompression(zstd.New()))
+			Expect(plain.Put(ctx, "plain.json", strings.NewReader(doc))).To(Succeed())
+
+			cs := objsto.NewCompressStore(inner, objsto.WithCompression(zstd.New(zstd.WithDictionary(dict))))
+			Expect(cs.Put(ctx, "dict.json", strings.NewReader(doc))).To(Succeed())
+			Expect(read(cs.Get(ctx, "dict.json"))).To(Equal(doc))
+
+			Expect(stored("dict.json")).To(BeNumerically("<", stored("plain.json")))
+		})
+
+		It("can't be read without it", func() {
+			cs := objsto.NewCompressStore(inner, objsto.WithCompression(zstd.New(zstd.WithDictionary(dict))))
+			Expect(cs.Put(ctx, "dict.json", strings.NewReader(doc))).To(Succeed())
+
+			plain := objsto.NewCompressStore(inner, objsto.WithCompression(zstd.New()))
+			reader, err := plain.Get(ctx, "dict.json")
+			if err == nil {
+				defer reader.Close()
+				_, err = io.ReadAll(reader)
+			}
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})